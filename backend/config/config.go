@@ -3,6 +3,7 @@ package config
 import (
 	"log"
 	"os"
+	"strconv"
 
 	"github.com/joho/godotenv"
 )
@@ -11,6 +12,20 @@ type Config struct {
 	DatabaseURL string
 	ServerPort  string
 	APIKey      string
+
+	// APIKeyAdmin дает запросам по общему API_KEY права администратора
+	// (API_KEY_ADMIN=true). Без него общий ключ — администратор, только пока
+	// не заведен ни один активный администратор
+	APIKeyAdmin bool
+
+	// StreamTokenSecret подписывает токены потока событий (STREAM_TOKEN_SECRET).
+	// Должен совпадать на всех экземплярах сервера; без него ключ создается
+	// при запуске, и токен действует только на выдавшем его экземпляре
+	StreamTokenSecret string
+
+	// WebhookBlockPrivateNetworks запрещает доставку вебхуков на loopback,
+	// частные и link-local адреса (WEBHOOK_BLOCK_PRIVATE_NETWORKS=true)
+	WebhookBlockPrivateNetworks bool
 }
 
 func LoadConfig() *Config {
@@ -39,5 +54,17 @@ func LoadConfig() *Config {
 		DatabaseURL: os.Getenv("DATABASE_URL"),
 		ServerPort:  os.Getenv("SERVER_PORT"),
 		APIKey:      os.Getenv("API_KEY"),
+		APIKeyAdmin: envBool("API_KEY_ADMIN"),
+
+		StreamTokenSecret: os.Getenv("STREAM_TOKEN_SECRET"),
+
+		WebhookBlockPrivateNetworks: envBool("WEBHOOK_BLOCK_PRIVATE_NETWORKS"),
 	}
 }
+
+// envBool читает логический флаг из переменной окружения; пустое или
+// некорректное значение означает false
+func envBool(name string) bool {
+	value, err := strconv.ParseBool(os.Getenv(name))
+	return err == nil && value
+}
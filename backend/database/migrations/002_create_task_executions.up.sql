@@ -0,0 +1,25 @@
+CREATE TABLE IF NOT EXISTS task_executions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    task_id UUID REFERENCES tasks(id) ON DELETE SET NULL,
+    project_id UUID REFERENCES projects(id) ON DELETE SET NULL,
+    command TEXT NOT NULL,
+    source VARCHAR(50) NOT NULL DEFAULT 'api', -- 'web', 'vscode', 'api'
+    user_id VARCHAR(255) NOT NULL DEFAULT '',
+    status VARCHAR(50) NOT NULL DEFAULT 'pending',
+    progress INTEGER NOT NULL DEFAULT 0,
+    message TEXT NOT NULL DEFAULT '',
+    task_title VARCHAR(255) NOT NULL DEFAULT '',
+    strategy VARCHAR(50) NOT NULL DEFAULT '',
+    error TEXT NOT NULL DEFAULT '',
+    steps JSONB NOT NULL DEFAULT '[]',
+    logs JSONB NOT NULL DEFAULT '[]',
+    result JSONB,
+    started_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    finished_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_task_executions_task_id ON task_executions(task_id);
+CREATE INDEX IF NOT EXISTS idx_task_executions_user_started ON task_executions(user_id, started_at DESC);
+CREATE INDEX IF NOT EXISTS idx_task_executions_status ON task_executions(status);
@@ -0,0 +1,5 @@
+UPDATE task_number_sequence
+SET current_value = GREATEST(current_value, COALESCE((SELECT MAX(current_value) FROM task_number_counters), 0))
+WHERE id = 1;
+
+DROP TABLE IF EXISTS task_number_counters;
@@ -0,0 +1,12 @@
+-- Счетчики номеров задач по префиксу функционального блока
+CREATE TABLE IF NOT EXISTS task_number_counters (
+    prefix VARCHAR(6) PRIMARY KEY,
+    current_value BIGINT NOT NULL DEFAULT 0,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Задачи без функционального блока продолжают нумерацию глобального счетчика,
+-- чтобы новые номера TASK-NNNN не пересекались с устаревшими TASK-NNNNNN
+INSERT INTO task_number_counters (prefix, current_value)
+SELECT 'TASK', current_value FROM task_number_sequence WHERE id = 1
+ON CONFLICT (prefix) DO NOTHING;
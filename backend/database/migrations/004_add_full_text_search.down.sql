@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx_documents_search_vector;
+DROP INDEX IF EXISTS idx_comments_search_vector;
+DROP INDEX IF EXISTS idx_tasks_search_vector;
+
+ALTER TABLE documents DROP COLUMN IF EXISTS search_vector;
+ALTER TABLE comments DROP COLUMN IF EXISTS search_vector;
+ALTER TABLE tasks DROP COLUMN IF EXISTS search_vector;
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS project_members;
+ALTER TABLE users DROP COLUMN IF EXISTS is_agent;
@@ -0,0 +1,17 @@
+-- Связи между задачами. Смысл связи зависит от вида:
+--   blocks     — depends_on_task_id блокирует task_id (task_id нельзя начать раньше)
+--   relates_to — задачи связаны по смыслу
+--   duplicates — task_id дублирует depends_on_task_id
+CREATE TABLE IF NOT EXISTS task_dependencies (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    task_id UUID NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+    depends_on_task_id UUID NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+    kind VARCHAR(20) NOT NULL CHECK (kind IN ('blocks', 'relates_to', 'duplicates')),
+    created_by VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CHECK (task_id <> depends_on_task_id),
+    UNIQUE (task_id, depends_on_task_id, kind)
+);
+
+CREATE INDEX IF NOT EXISTS idx_task_dependencies_task_id ON task_dependencies(task_id);
+CREATE INDEX IF NOT EXISTS idx_task_dependencies_depends_on_task_id ON task_dependencies(depends_on_task_id);
@@ -0,0 +1,8 @@
+-- Настраиваемые процессы (статусы и переходы) задач проекта.
+-- Проекты без записи используют процесс по умолчанию, заданный в коде.
+CREATE TABLE IF NOT EXISTS project_workflows (
+    project_id UUID PRIMARY KEY REFERENCES projects(id) ON DELETE CASCADE,
+    definition JSONB NOT NULL,
+    updated_by VARCHAR(255) NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
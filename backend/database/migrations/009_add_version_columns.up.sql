@@ -0,0 +1,3 @@
+-- Версии записей для оптимистичной блокировки при обновлении
+ALTER TABLE tasks ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE documents ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
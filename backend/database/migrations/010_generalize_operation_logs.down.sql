@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx_operation_logs_entity;
+
+DELETE FROM operation_logs WHERE task_id IS NULL;
+ALTER TABLE operation_logs ALTER COLUMN task_id SET NOT NULL;
+
+ALTER TABLE operation_logs DROP COLUMN IF EXISTS entity_id;
+ALTER TABLE operation_logs DROP COLUMN IF EXISTS entity_type;
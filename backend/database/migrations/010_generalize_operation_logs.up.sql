@@ -0,0 +1,11 @@
+-- Журнал операций ведется не только для задач, но и для документов и проектов
+ALTER TABLE operation_logs ADD COLUMN entity_type VARCHAR(50) NOT NULL DEFAULT 'task';
+ALTER TABLE operation_logs ADD COLUMN entity_id UUID;
+
+UPDATE operation_logs SET entity_id = task_id WHERE entity_id IS NULL;
+
+ALTER TABLE operation_logs ALTER COLUMN entity_id SET NOT NULL;
+ALTER TABLE operation_logs ALTER COLUMN entity_type DROP DEFAULT;
+ALTER TABLE operation_logs ALTER COLUMN task_id DROP NOT NULL;
+
+CREATE INDEX IF NOT EXISTS idx_operation_logs_entity ON operation_logs(entity_type, entity_id, created_at);
@@ -0,0 +1,13 @@
+ALTER TABLE tasks DROP CONSTRAINT IF EXISTS tasks_parent_task_id_fkey;
+ALTER TABLE tasks ADD CONSTRAINT tasks_parent_task_id_fkey
+    FOREIGN KEY (parent_task_id) REFERENCES tasks(id);
+
+ALTER TABLE operation_logs DROP CONSTRAINT IF EXISTS operation_logs_task_id_fkey;
+ALTER TABLE operation_logs ADD CONSTRAINT operation_logs_task_id_fkey
+    FOREIGN KEY (task_id) REFERENCES tasks(id) ON DELETE CASCADE;
+
+DROP INDEX IF EXISTS idx_projects_deleted_at;
+DROP INDEX IF EXISTS idx_tasks_deleted_at;
+
+ALTER TABLE projects DROP COLUMN IF EXISTS deleted_at;
+ALTER TABLE tasks DROP COLUMN IF EXISTS deleted_at;
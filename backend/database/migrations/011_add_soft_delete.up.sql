@@ -0,0 +1,16 @@
+-- Мягкое удаление задач и проектов: записи попадают в корзину и могут быть восстановлены
+ALTER TABLE tasks ADD COLUMN deleted_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE projects ADD COLUMN deleted_at TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX IF NOT EXISTS idx_tasks_deleted_at ON tasks(deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_projects_deleted_at ON projects(deleted_at) WHERE deleted_at IS NOT NULL;
+
+-- Журнал операций сохраняется и после окончательного удаления задачи
+ALTER TABLE operation_logs DROP CONSTRAINT IF EXISTS operation_logs_task_id_fkey;
+ALTER TABLE operation_logs ADD CONSTRAINT operation_logs_task_id_fkey
+    FOREIGN KEY (task_id) REFERENCES tasks(id) ON DELETE SET NULL;
+
+-- Окончательное удаление родительской задачи не блокируется подзадачами
+ALTER TABLE tasks DROP CONSTRAINT IF EXISTS tasks_parent_task_id_fkey;
+ALTER TABLE tasks ADD CONSTRAINT tasks_parent_task_id_fkey
+    FOREIGN KEY (parent_task_id) REFERENCES tasks(id) ON DELETE SET NULL;
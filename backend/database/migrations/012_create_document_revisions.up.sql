@@ -0,0 +1,21 @@
+-- Неизменяемая история содержимого документов. Номер ревизии совпадает
+-- с documents.version, которую получил документ при сохранении.
+CREATE TABLE IF NOT EXISTS document_revisions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    document_id UUID NOT NULL REFERENCES documents(id) ON DELETE CASCADE,
+    revision INTEGER NOT NULL,
+    type VARCHAR(50) NOT NULL,
+    title VARCHAR(255) NOT NULL,
+    content TEXT NOT NULL,
+    content_hash CHAR(64) NOT NULL,
+    author VARCHAR(255) NOT NULL,
+    restored_from INTEGER,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (document_id, revision)
+);
+
+-- Текущее состояние существующих документов становится их первой известной ревизией
+INSERT INTO document_revisions (document_id, revision, type, title, content, content_hash, author, created_at)
+SELECT id, version, type, title, content, encode(sha256(convert_to(content, 'UTF8')), 'hex'), 'system', updated_at
+FROM documents
+ON CONFLICT (document_id, revision) DO NOTHING;
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_tasks_source_document;
+ALTER TABLE tasks DROP COLUMN IF EXISTS source_section_anchor;
+ALTER TABLE tasks DROP COLUMN IF EXISTS source_document_id;
@@ -0,0 +1,5 @@
+-- Связь задачи с разделом документа, из которого она создана
+ALTER TABLE tasks ADD COLUMN source_document_id UUID REFERENCES documents(id) ON DELETE SET NULL;
+ALTER TABLE tasks ADD COLUMN source_section_anchor VARCHAR(255);
+
+CREATE INDEX IF NOT EXISTS idx_tasks_source_document ON tasks(source_document_id) WHERE source_document_id IS NOT NULL;
@@ -0,0 +1,20 @@
+-- Связи задач с документами (и при необходимости — с разделом документа).
+-- Пустой section_anchor означает ссылку на документ целиком.
+CREATE TABLE IF NOT EXISTS task_document_links (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    task_id UUID NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+    document_id UUID NOT NULL REFERENCES documents(id) ON DELETE CASCADE,
+    section_anchor VARCHAR(255) NOT NULL DEFAULT '',
+    created_by VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (task_id, document_id, section_anchor)
+);
+
+CREATE INDEX IF NOT EXISTS idx_task_document_links_document ON task_document_links(document_id);
+
+-- Задачи, созданные из документов, сразу связываются со своим разделом
+INSERT INTO task_document_links (task_id, document_id, section_anchor, created_by, created_at)
+SELECT id, source_document_id, COALESCE(source_section_anchor, ''), 'system', created_at
+FROM tasks
+WHERE source_document_id IS NOT NULL
+ON CONFLICT (task_id, document_id, section_anchor) DO NOTHING;
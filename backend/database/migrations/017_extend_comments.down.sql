@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS comment_edits;
+
+DROP INDEX IF EXISTS idx_comments_parent;
+ALTER TABLE comments DROP COLUMN IF EXISTS edited_at;
+ALTER TABLE comments DROP COLUMN IF EXISTS type;
+ALTER TABLE comments DROP COLUMN IF EXISTS parent_comment_id;
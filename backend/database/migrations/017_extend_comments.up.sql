@@ -0,0 +1,19 @@
+-- Ответы, типы и редактирование комментариев.
+-- При удалении комментария вместе с ним удаляется вся ветка ответов.
+ALTER TABLE comments ADD COLUMN IF NOT EXISTS parent_comment_id UUID REFERENCES comments(id) ON DELETE CASCADE;
+ALTER TABLE comments ADD COLUMN IF NOT EXISTS type VARCHAR(20) NOT NULL DEFAULT 'user'
+    CHECK (type IN ('user', 'system', 'agent', 'review'));
+ALTER TABLE comments ADD COLUMN IF NOT EXISTS edited_at TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX IF NOT EXISTS idx_comments_parent ON comments(parent_comment_id);
+
+-- История правок: каждая запись хранит текст комментария до правки
+CREATE TABLE IF NOT EXISTS comment_edits (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    comment_id UUID NOT NULL REFERENCES comments(id) ON DELETE CASCADE,
+    previous_content TEXT NOT NULL,
+    edited_by VARCHAR(255) NOT NULL,
+    edited_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_comment_edits_comment ON comment_edits(comment_id, edited_at);
@@ -0,0 +1,19 @@
+-- Исполнители задач: люди и агенты. Таблица могла быть создана вручную
+-- до появления миграции, поэтому недостающие колонки добавляются отдельно.
+CREATE TABLE IF NOT EXISTS executors (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+ALTER TABLE executors ADD COLUMN IF NOT EXISTS kind VARCHAR(20) NOT NULL DEFAULT 'human'
+    CHECK (kind IN ('human', 'agent'));
+ALTER TABLE executors ADD COLUMN IF NOT EXISTS user_id UUID REFERENCES users(id) ON DELETE SET NULL;
+ALTER TABLE executors ADD COLUMN IF NOT EXISTS updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_executors_user_id ON executors(user_id) WHERE user_id IS NOT NULL;
+
+-- Назначенный исполнитель задачи
+ALTER TABLE tasks ADD COLUMN assignee_id UUID REFERENCES executors(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_tasks_assignee_id ON tasks(assignee_id) WHERE assignee_id IS NOT NULL;
@@ -0,0 +1,7 @@
+-- Удаленный комментарий с ответами остается в ветке заглушкой без текста,
+-- поэтому удаление родителя больше не удаляет ответы каскадом.
+ALTER TABLE comments ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP WITH TIME ZONE;
+
+ALTER TABLE comments DROP CONSTRAINT IF EXISTS comments_parent_comment_id_fkey;
+ALTER TABLE comments ADD CONSTRAINT comments_parent_comment_id_fkey
+    FOREIGN KEY (parent_comment_id) REFERENCES comments(id);
@@ -0,0 +1,3 @@
+-- Прежний префикс TASK не возвращается: с ним номера задач блока снова
+-- пересекались бы с номерами задач без блока, поэтому откат ничего не меняет.
+SELECT 1;
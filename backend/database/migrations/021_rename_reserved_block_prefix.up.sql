@@ -0,0 +1,32 @@
+-- Префикс TASK зарезервирован за задачами без функционального блока (см. 003):
+-- блок, получивший его раньше, вел бы общий с ними счетчик номеров.
+-- Такой блок получает первый свободный префикс TASKA..TASKZ, TASKAA..TASKZZ;
+-- выданные ранее номера задач не меняются. Переименование фиксируется в
+-- журнале операций блока.
+WITH candidate AS (
+    SELECT c.prefix
+    FROM (
+        SELECT 'TASK' || chr(a) AS prefix, 1 AS length
+        FROM generate_series(65, 90) a
+        UNION ALL
+        SELECT 'TASK' || chr(a) || chr(b), 2
+        FROM generate_series(65, 90) a, generate_series(65, 90) b
+    ) c
+    WHERE NOT EXISTS (SELECT 1 FROM functional_blocks fb WHERE fb.prefix = c.prefix)
+    ORDER BY c.length, c.prefix
+    LIMIT 1
+), renamed AS (
+    UPDATE functional_blocks fb
+    SET prefix = candidate.prefix, updated_at = CURRENT_TIMESTAMP
+    FROM candidate
+    WHERE fb.prefix = 'TASK'
+    RETURNING fb.id, fb.name, fb.prefix
+)
+INSERT INTO operation_logs (entity_type, entity_id, user_identifier, operation_type, details)
+SELECT 'functional_block', id, 'system', 'UPDATE', jsonb_build_object(
+    'functional_block_id', id,
+    'name', name,
+    'reason', 'prefix TASK is reserved for tasks without a functional block',
+    'changes', jsonb_build_array(jsonb_build_object('field', 'prefix', 'before', 'TASK', 'after', prefix))
+)
+FROM renamed;
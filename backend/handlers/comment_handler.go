@@ -28,7 +28,7 @@ func (h *CommentHandler) CreateComment(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if err := h.service.CreateComment(r.Context(), &comment); err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		http.Error(w, err.Error(), serviceErrorStatus(err, http.StatusBadRequest))
 		return
 	}
 
@@ -42,7 +42,7 @@ func (h *CommentHandler) GetComment(w http.ResponseWriter, r *http.Request) {
 
 	comment, err := h.service.GetCommentByID(r.Context(), id)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		http.Error(w, err.Error(), serviceErrorStatus(err, http.StatusInternalServerError))
 		return
 	}
 
@@ -60,7 +60,7 @@ func (h *CommentHandler) GetCommentsByTask(w http.ResponseWriter, r *http.Reques
 
 	comments, err := h.service.GetCommentsByTaskID(r.Context(), taskID)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		http.Error(w, err.Error(), serviceErrorStatus(err, http.StatusBadRequest))
 		return
 	}
 
@@ -68,10 +68,120 @@ func (h *CommentHandler) GetCommentsByTask(w http.ResponseWriter, r *http.Reques
 	json.NewEncoder(w).Encode(comments)
 }
 
+// GetTaskCommentThreads возвращает комментарии задачи деревом ответов
+// GET /api/v1/tasks/{id}/comments
+func (h *CommentHandler) GetTaskCommentThreads(w http.ResponseWriter, r *http.Request) {
+	threads, err := h.service.GetCommentThreads(r.Context(), chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, err.Error(), serviceErrorStatus(err, http.StatusBadRequest))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(threads)
+}
+
+// CreateTaskComment добавляет комментарий или ответ к задаче
+// POST /api/v1/tasks/{id}/comments
+func (h *CommentHandler) CreateTaskComment(w http.ResponseWriter, r *http.Request) {
+	var comment models.Comment
+	if err := json.NewDecoder(r.Body).Decode(&comment); err != nil {
+		http.Error(w, "Invalid JSON: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	comment.TaskID = chi.URLParam(r, "id")
+
+	if err := h.service.CreateComment(r.Context(), &comment); err != nil {
+		http.Error(w, err.Error(), serviceErrorStatus(err, http.StatusBadRequest))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(comment)
+}
+
+// UpdateComment изменяет текст комментария
+// PUT /api/v1/comments/{id}
+func (h *CommentHandler) UpdateComment(w http.ResponseWriter, r *http.Request) {
+	var update models.Comment
+	if err := json.NewDecoder(r.Body).Decode(&update); err != nil {
+		http.Error(w, "Invalid JSON: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	comment, err := h.service.UpdateComment(r.Context(), chi.URLParam(r, "id"), update.Content)
+	if err != nil {
+		status := http.StatusBadRequest
+		if err.Error() == "comment not found" {
+			status = http.StatusNotFound
+		}
+		http.Error(w, err.Error(), serviceErrorStatus(err, status))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(comment)
+}
+
+// GetCommentEdits возвращает историю правок комментария
+// GET /api/v1/comments/{id}/edits
+func (h *CommentHandler) GetCommentEdits(w http.ResponseWriter, r *http.Request) {
+	edits, err := h.service.GetCommentEdits(r.Context(), chi.URLParam(r, "id"))
+	if err != nil {
+		status := http.StatusBadRequest
+		if err.Error() == "comment not found" {
+			status = http.StatusNotFound
+		}
+		http.Error(w, err.Error(), serviceErrorStatus(err, status))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(edits)
+}
+
+// GetCommentReferences возвращает упоминания и ссылки на задачи комментария
+// GET /api/v1/comments/{id}/references
+func (h *CommentHandler) GetCommentReferences(w http.ResponseWriter, r *http.Request) {
+	references, err := h.service.GetCommentReferences(r.Context(), chi.URLParam(r, "id"))
+	if err != nil {
+		status := http.StatusBadRequest
+		if err.Error() == "comment not found" {
+			status = http.StatusNotFound
+		}
+		http.Error(w, err.Error(), serviceErrorStatus(err, status))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(references)
+}
+
+// GetTaskBacklinks возвращает ссылки на задачу из комментариев других задач
+// GET /api/v1/tasks/{id}/backlinks
+func (h *CommentHandler) GetTaskBacklinks(w http.ResponseWriter, r *http.Request) {
+	backlinks, err := h.service.GetTaskBacklinks(r.Context(), chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, err.Error(), serviceErrorStatus(err, http.StatusBadRequest))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(backlinks)
+}
+
+// GetValidCommentTypes возвращает список типов комментариев
+// GET /api/v1/comments/types
+func (h *CommentHandler) GetValidCommentTypes(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(models.ValidCommentTypes())
+}
+
 func (h *CommentHandler) GetAllComments(w http.ResponseWriter, r *http.Request) {
 	comments, err := h.service.GetAllComments(r.Context())
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		http.Error(w, err.Error(), serviceErrorStatus(err, http.StatusInternalServerError))
 		return
 	}
 
@@ -83,11 +193,11 @@ func (h *CommentHandler) DeleteComment(w http.ResponseWriter, r *http.Request) {
 	id := chi.URLParam(r, "id")
 
 	if err := h.service.DeleteComment(r.Context(), id); err != nil {
-		if errors.Is(err, pgx.ErrNoRows) {
+		if errors.Is(err, pgx.ErrNoRows) || err.Error() == "comment not found" {
 			http.Error(w, "Comment not found", http.StatusNotFound)
 			return
 		}
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		http.Error(w, err.Error(), serviceErrorStatus(err, http.StatusInternalServerError))
 		return
 	}
 
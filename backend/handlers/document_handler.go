@@ -3,10 +3,14 @@ package handlers
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
+	"net/url"
+	"strconv"
 
 	"project-manager/models"
 	"project-manager/services"
+	"project-manager/utils"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/jackc/pgx/v5"
@@ -28,10 +32,11 @@ func (h *DocumentHandler) CreateDocument(w http.ResponseWriter, r *http.Request)
 	}
 
 	if err := h.service.CreateDocument(r.Context(), &document); err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		http.Error(w, err.Error(), serviceErrorStatus(err, http.StatusBadRequest))
 		return
 	}
 
+	setETag(w, document.Version)
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusCreated)
 	json.NewEncoder(w).Encode(document)
@@ -42,7 +47,7 @@ func (h *DocumentHandler) GetDocument(w http.ResponseWriter, r *http.Request) {
 
 	document, err := h.service.GetDocumentByID(r.Context(), id)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		http.Error(w, err.Error(), serviceErrorStatus(err, http.StatusInternalServerError))
 		return
 	}
 
@@ -51,6 +56,7 @@ func (h *DocumentHandler) GetDocument(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	setETag(w, document.Version)
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(document)
 }
@@ -58,7 +64,7 @@ func (h *DocumentHandler) GetDocument(w http.ResponseWriter, r *http.Request) {
 func (h *DocumentHandler) GetAllDocuments(w http.ResponseWriter, r *http.Request) {
 	documents, err := h.service.GetAllDocuments(r.Context())
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		http.Error(w, err.Error(), serviceErrorStatus(err, http.StatusInternalServerError))
 		return
 	}
 
@@ -71,7 +77,7 @@ func (h *DocumentHandler) GetDocumentsByProject(w http.ResponseWriter, r *http.R
 
 	documents, err := h.service.GetDocumentsByProjectID(r.Context(), projectID)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		http.Error(w, err.Error(), serviceErrorStatus(err, http.StatusBadRequest))
 		return
 	}
 
@@ -88,7 +94,7 @@ func (h *DocumentHandler) GetDocumentsByType(w http.ResponseWriter, r *http.Requ
 
 	documents, err := h.service.GetDocumentsByType(r.Context(), docType)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		http.Error(w, err.Error(), serviceErrorStatus(err, http.StatusBadRequest))
 		return
 	}
 
@@ -102,7 +108,7 @@ func (h *DocumentHandler) GetDocumentsByProjectAndType(w http.ResponseWriter, r
 
 	documents, err := h.service.GetDocumentsByProjectIDAndType(r.Context(), projectID, docType)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		http.Error(w, err.Error(), serviceErrorStatus(err, http.StatusBadRequest))
 		return
 	}
 
@@ -115,7 +121,7 @@ func (h *DocumentHandler) GetAgentEditableDocumentsByProject(w http.ResponseWrit
 
 	documents, err := h.service.GetAgentEditableDocumentsByProjectID(r.Context(), projectID)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		http.Error(w, err.Error(), serviceErrorStatus(err, http.StatusBadRequest))
 		return
 	}
 
@@ -134,19 +140,75 @@ func (h *DocumentHandler) UpdateDocument(w http.ResponseWriter, r *http.Request)
 
 	document.ID = id // Устанавливаем ID из URL
 
-	if err := h.service.UpdateDocument(r.Context(), &document); err != nil {
+	h.updateDocument(w, r, &document)
+}
+
+// PatchDocument частично обновляет документ по JSON Merge Patch (RFC 7396):
+// меняются только переданные поля
+func (h *DocumentHandler) PatchDocument(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+
+	current, err := h.service.GetDocumentByID(r.Context(), id)
+	if err != nil {
+		http.Error(w, err.Error(), serviceErrorStatus(err, http.StatusInternalServerError))
+		return
+	}
+	if current == nil {
+		http.Error(w, "Document not found", http.StatusNotFound)
+		return
+	}
+
+	document, err := decodeMergePatch(r, current)
+	if err != nil {
+		http.Error(w, "Invalid merge patch: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	document.ID = id // Устанавливаем ID из URL
+
+	h.updateDocument(w, r, document)
+}
+
+// updateDocument сохраняет документ и отвечает его новым состоянием; общий путь PUT и PATCH
+func (h *DocumentHandler) updateDocument(w http.ResponseWriter, r *http.Request, document *models.Document) {
+	// If-Match имеет приоритет над версией из тела запроса
+	version, err := parseIfMatch(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if version != 0 {
+		document.Version = version
+	}
+
+	if err := h.service.UpdateDocument(r.Context(), document); err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			http.Error(w, "Document not found", http.StatusNotFound)
 			return
 		}
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		if errors.Is(err, services.ErrVersionConflict) {
+			h.writeDocumentConflict(w, r, document.ID, err)
+			return
+		}
+		http.Error(w, err.Error(), serviceErrorStatus(err, http.StatusBadRequest))
 		return
 	}
 
+	setETag(w, document.Version)
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(document)
 }
 
+// writeDocumentConflict отвечает 409 с актуальной версией документа
+func (h *DocumentHandler) writeDocumentConflict(w http.ResponseWriter, r *http.Request, id string, err error) {
+	current, getErr := h.service.GetDocumentByID(r.Context(), id)
+	if getErr != nil || current == nil {
+		writeVersionConflict(w, err, nil, 0)
+		return
+	}
+	writeVersionConflict(w, err, current, current.Version)
+}
+
 func (h *DocumentHandler) DeleteDocument(w http.ResponseWriter, r *http.Request) {
 	id := chi.URLParam(r, "id")
 
@@ -155,13 +217,265 @@ func (h *DocumentHandler) DeleteDocument(w http.ResponseWriter, r *http.Request)
 			http.Error(w, "Document not found", http.StatusNotFound)
 			return
 		}
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		http.Error(w, err.Error(), serviceErrorStatus(err, http.StatusInternalServerError))
 		return
 	}
 
 	w.WriteHeader(http.StatusNoContent)
 }
 
+// GetDocumentRevisions возвращает историю ревизий документа
+// GET /api/v1/documents/{id}/revisions
+func (h *DocumentHandler) GetDocumentRevisions(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+
+	revisions, err := h.service.GetDocumentRevisions(r.Context(), id)
+	if err != nil {
+		http.Error(w, err.Error(), serviceErrorStatus(err, http.StatusBadRequest))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(revisions)
+}
+
+// GetDocumentRevision возвращает ревизию документа вместе с содержимым
+// GET /api/v1/documents/{id}/revisions/{revision}
+func (h *DocumentHandler) GetDocumentRevision(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	number, err := parseRevisionNumber(chi.URLParam(r, "revision"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	revision, err := h.service.GetDocumentRevision(r.Context(), id, number)
+	if err != nil {
+		http.Error(w, err.Error(), serviceErrorStatus(err, http.StatusBadRequest))
+		return
+	}
+
+	if revision == nil {
+		http.Error(w, "Revision not found", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(revision)
+}
+
+// DiffDocumentRevisions возвращает unified diff между ревизиями документа;
+// без параметра to сравнение идет с текущей ревизией
+// GET /api/v1/documents/{id}/diff?from=&to=
+func (h *DocumentHandler) DiffDocumentRevisions(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	query := r.URL.Query()
+
+	from, err := parseRevisionNumber(query.Get("from"))
+	if err != nil {
+		http.Error(w, "from: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	to := 0
+	if value := query.Get("to"); value != "" {
+		if to, err = parseRevisionNumber(value); err != nil {
+			http.Error(w, "to: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	diff, err := h.service.DiffDocumentRevisions(r.Context(), id, from, to)
+	if err != nil {
+		http.Error(w, err.Error(), serviceErrorStatus(err, http.StatusBadRequest))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(diff)
+}
+
+// RestoreDocumentRevision восстанавливает содержимое старой ревизии как новую ревизию.
+// Ожидаемая версия документа передается в If-Match.
+// POST /api/v1/documents/{id}/revisions/{revision}/restore
+func (h *DocumentHandler) RestoreDocumentRevision(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	number, err := parseRevisionNumber(chi.URLParam(r, "revision"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	version, err := parseIfMatch(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	document, err := h.service.RestoreDocumentRevision(r.Context(), id, number, version)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			http.Error(w, "Document not found", http.StatusNotFound)
+			return
+		}
+		if errors.Is(err, services.ErrVersionConflict) {
+			h.writeDocumentConflict(w, r, id, err)
+			return
+		}
+		http.Error(w, err.Error(), serviceErrorStatus(err, http.StatusBadRequest))
+		return
+	}
+
+	setETag(w, document.Version)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(document)
+}
+
+// GetDocumentSections возвращает дерево разделов документа
+// GET /api/v1/documents/{id}/sections
+func (h *DocumentHandler) GetDocumentSections(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+
+	sections, err := h.service.GetDocumentSections(r.Context(), id)
+	if err != nil {
+		http.Error(w, err.Error(), serviceErrorStatus(err, http.StatusBadRequest))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(sections)
+}
+
+// GetDocumentSection возвращает раздел документа вместе с содержимым
+// GET /api/v1/documents/{id}/sections/{anchor}
+func (h *DocumentHandler) GetDocumentSection(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	anchor, err := sectionAnchorParam(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	section, err := h.service.GetDocumentSection(r.Context(), id, anchor)
+	if err != nil {
+		http.Error(w, err.Error(), serviceErrorStatus(err, http.StatusBadRequest))
+		return
+	}
+
+	if section == nil {
+		http.Error(w, "Section not found", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(section)
+}
+
+// UpdateDocumentSectionRequest — новое содержимое раздела (без его заголовка)
+type UpdateDocumentSectionRequest struct {
+	Content string `json:"content"`
+	Version int    `json:"version"`
+}
+
+// UpdateDocumentSection заменяет содержимое раздела документа; заголовок сохраняется.
+// If-Match имеет приоритет над версией из тела запроса.
+// PUT /api/v1/documents/{id}/sections/{anchor}
+func (h *DocumentHandler) UpdateDocumentSection(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	anchor, err := sectionAnchorParam(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var req UpdateDocumentSectionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid JSON: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	version, err := parseIfMatch(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if version != 0 {
+		req.Version = version
+	}
+
+	document, err := h.service.UpdateDocumentSection(r.Context(), id, anchor, req.Content, req.Version)
+	if err != nil {
+		if errors.Is(err, utils.ErrSectionNotFound) {
+			http.Error(w, "Section not found", http.StatusNotFound)
+			return
+		}
+		if errors.Is(err, pgx.ErrNoRows) {
+			http.Error(w, "Document not found", http.StatusNotFound)
+			return
+		}
+		if errors.Is(err, services.ErrVersionConflict) {
+			h.writeDocumentConflict(w, r, id, err)
+			return
+		}
+		http.Error(w, err.Error(), serviceErrorStatus(err, http.StatusBadRequest))
+		return
+	}
+
+	setETag(w, document.Version)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(document)
+}
+
+// RenderDocumentHTML возвращает документ, преобразованный из Markdown в HTML.
+// Вывод экранирован, поэтому его можно встраивать в страницу без дополнительной очистки.
+// GET /api/v1/documents/{id}/html
+func (h *DocumentHandler) RenderDocumentHTML(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+
+	rendered, err := h.service.RenderDocumentHTML(r.Context(), id)
+	if err != nil {
+		http.Error(w, err.Error(), serviceErrorStatus(err, http.StatusBadRequest))
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Write([]byte(rendered))
+}
+
+// sectionAnchorParam возвращает якорь раздела из URL; кириллические якоря
+// приходят в процентной кодировке
+func sectionAnchorParam(r *http.Request) (string, error) {
+	anchor, err := url.PathUnescape(chi.URLParam(r, "anchor"))
+	if err != nil {
+		return "", fmt.Errorf("invalid section anchor: %w", err)
+	}
+	return anchor, nil
+}
+
+// parseRevisionNumber разбирает номер ревизии документа
+func parseRevisionNumber(value string) (int, error) {
+	number, err := strconv.Atoi(value)
+	if err != nil || number < 1 {
+		return 0, fmt.Errorf("invalid revision number: %q", value)
+	}
+	return number, nil
+}
+
+// GetRequiredSections возвращает разделы, обязательные для типа документа
+// GET /api/v1/documents/required-sections?type=
+func (h *DocumentHandler) GetRequiredSections(w http.ResponseWriter, r *http.Request) {
+	docType := r.URL.Query().Get("type")
+
+	sections, err := h.service.GetRequiredSections(docType)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"type": docType, "requiredSections": sections})
+}
+
 // GetValidDocumentTypes возвращает список валидных типов документов
 func (h *DocumentHandler) GetValidDocumentTypes(w http.ResponseWriter, r *http.Request) {
 	types := h.service.GetValidDocumentTypes()
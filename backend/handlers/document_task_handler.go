@@ -0,0 +1,73 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"project-manager/models"
+	"project-manager/services"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type DocumentTaskHandler struct {
+	service *services.DocumentTaskService
+}
+
+func NewDocumentTaskHandler(service *services.DocumentTaskService) *DocumentTaskHandler {
+	return &DocumentTaskHandler{service: service}
+}
+
+// PreviewTasks возвращает задачи, распознанные в документе AI Executable
+// GET /api/v1/documents/{id}/task-drafts
+func (h *DocumentTaskHandler) PreviewTasks(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+
+	preview, err := h.service.PreviewTasks(r.Context(), id)
+	if err != nil {
+		http.Error(w, err.Error(), serviceErrorStatus(err, http.StatusBadRequest))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(preview)
+}
+
+// CreateTasks создает задачи по черновикам документа; тело запроса необязательно
+// POST /api/v1/documents/{id}/tasks
+func (h *DocumentTaskHandler) CreateTasks(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+
+	var req models.CreateTasksFromDocumentRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Invalid JSON: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	result, err := h.service.CreateTasks(r.Context(), id, &req)
+	if err != nil {
+		http.Error(w, err.Error(), serviceErrorStatus(err, http.StatusBadRequest))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(result)
+}
+
+// GetTasksByDocument возвращает задачи, созданные из документа
+// GET /api/v1/documents/{id}/tasks
+func (h *DocumentTaskHandler) GetTasksByDocument(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+
+	tasks, err := h.service.GetTasksByDocument(r.Context(), id)
+	if err != nil {
+		http.Error(w, err.Error(), serviceErrorStatus(err, http.StatusBadRequest))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(tasks)
+}
@@ -0,0 +1,128 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"project-manager/models"
+	"project-manager/services"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/jackc/pgx/v5"
+)
+
+type DocumentTemplateHandler struct {
+	service *services.DocumentTemplateService
+}
+
+func NewDocumentTemplateHandler(service *services.DocumentTemplateService) *DocumentTemplateHandler {
+	return &DocumentTemplateHandler{service: service}
+}
+
+// GetTemplates возвращает встроенные и пользовательские шаблоны документов
+// GET /api/v1/document-templates?type=
+func (h *DocumentTemplateHandler) GetTemplates(w http.ResponseWriter, r *http.Request) {
+	templates, err := h.service.GetTemplates(r.Context(), r.URL.Query().Get("type"))
+	if err != nil {
+		http.Error(w, err.Error(), serviceErrorStatus(err, http.StatusBadRequest))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(templates)
+}
+
+func (h *DocumentTemplateHandler) GetTemplate(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+
+	template, err := h.service.GetTemplateByID(r.Context(), id)
+	if err != nil {
+		http.Error(w, err.Error(), serviceErrorStatus(err, http.StatusInternalServerError))
+		return
+	}
+
+	if template == nil {
+		http.Error(w, "Template not found", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(template)
+}
+
+func (h *DocumentTemplateHandler) CreateTemplate(w http.ResponseWriter, r *http.Request) {
+	var template models.DocumentTemplate
+	if err := json.NewDecoder(r.Body).Decode(&template); err != nil {
+		http.Error(w, "Invalid JSON: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	template.ID = ""
+
+	if err := h.service.CreateTemplate(r.Context(), &template); err != nil {
+		http.Error(w, err.Error(), serviceErrorStatus(err, http.StatusBadRequest))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(template)
+}
+
+func (h *DocumentTemplateHandler) UpdateTemplate(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+
+	var template models.DocumentTemplate
+	if err := json.NewDecoder(r.Body).Decode(&template); err != nil {
+		http.Error(w, "Invalid JSON: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	template.ID = id // Устанавливаем ID из URL
+
+	if err := h.service.UpdateTemplate(r.Context(), &template); err != nil {
+		http.Error(w, err.Error(), serviceErrorStatus(err, http.StatusBadRequest))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(template)
+}
+
+func (h *DocumentTemplateHandler) DeleteTemplate(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+
+	if err := h.service.DeleteTemplate(r.Context(), id); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			http.Error(w, "Template not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, err.Error(), serviceErrorStatus(err, http.StatusBadRequest))
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// CreateDocumentFromTemplate создает документ проекта из шаблона
+// POST /api/v1/projects/{projectID}/documents/from-template
+func (h *DocumentTemplateHandler) CreateDocumentFromTemplate(w http.ResponseWriter, r *http.Request) {
+	projectID := chi.URLParam(r, "projectID")
+
+	var req models.DocumentFromTemplate
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid JSON: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	document, err := h.service.CreateDocumentFromTemplate(r.Context(), projectID, &req)
+	if err != nil {
+		http.Error(w, err.Error(), serviceErrorStatus(err, http.StatusBadRequest))
+		return
+	}
+
+	setETag(w, document.Version)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(document)
+}
@@ -0,0 +1,33 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"project-manager/services"
+)
+
+// serviceErrorStatus возвращает HTTP-код для ошибки сервиса. Ошибки доступа
+// сопоставляются с 401/403, отсутствие задачи, исполнителя или вебхука —
+// с 404, конфликты порядка задач и импорта — с 409, нарушения процесса
+// статусов и структуры документов — с 422. Для остальных ошибок
+// используется fallback.
+func serviceErrorStatus(err error, fallback int) int {
+	switch {
+	case errors.Is(err, services.ErrUnauthorized):
+		return http.StatusUnauthorized
+	case errors.Is(err, services.ErrForbidden):
+		return http.StatusForbidden
+	case errors.Is(err, services.ErrTaskNotFound), errors.Is(err, services.ErrExecutorNotFound),
+		errors.Is(err, services.ErrWebhookNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, services.ErrDependencyCycle), errors.Is(err, services.ErrPlanOrderViolation),
+		errors.Is(err, services.ErrImportConflicts):
+		return http.StatusConflict
+	case errors.Is(err, services.ErrTransitionNotAllowed), errors.Is(err, services.ErrRequiredFieldsMissing),
+		errors.Is(err, services.ErrStatusNotInWorkflow), errors.Is(err, services.ErrRequiredSectionsMissing):
+		return http.StatusUnprocessableEntity
+	default:
+		return fallback
+	}
+}
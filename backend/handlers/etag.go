@@ -0,0 +1,56 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"project-manager/utils"
+)
+
+// versionConflictResponse — тело ответа 409 с актуальной копией записи,
+// по которой клиент может объединить свои изменения
+type versionConflictResponse struct {
+	utils.ErrorResponse
+	Current interface{} `json:"current"`
+}
+
+// setETag выставляет ETag по версии записи
+func setETag(w http.ResponseWriter, version int) {
+	w.Header().Set("ETag", strconv.Quote(strconv.Itoa(version)))
+}
+
+// parseIfMatch возвращает версию из заголовка If-Match. Отсутствующий
+// заголовок и "*" дают 0 — обновление без проверки версии.
+func parseIfMatch(r *http.Request) (int, error) {
+	value := strings.TrimSpace(r.Header.Get("If-Match"))
+	if value == "" || value == "*" {
+		return 0, nil
+	}
+
+	value = strings.TrimPrefix(value, "W/")
+	unquoted, err := strconv.Unquote(value)
+	if err != nil {
+		unquoted = value
+	}
+	version, err := strconv.Atoi(unquoted)
+	if err != nil || version <= 0 {
+		return 0, errors.New("invalid If-Match header: " + r.Header.Get("If-Match"))
+	}
+	return version, nil
+}
+
+// writeVersionConflict отвечает 409 с текущей версией записи
+func writeVersionConflict(w http.ResponseWriter, err error, current interface{}, version int) {
+	if current != nil {
+		setETag(w, version)
+	}
+	utils.WriteJSONResponse(w, http.StatusConflict, versionConflictResponse{
+		ErrorResponse: utils.ErrorResponse{
+			Error:   http.StatusText(http.StatusConflict),
+			Message: err.Error(),
+		},
+		Current: current,
+	})
+}
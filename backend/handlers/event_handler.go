@@ -0,0 +1,150 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"project-manager/models"
+	"project-manager/services"
+)
+
+// eventHeartbeatInterval — период комментариев-пингов, не дающих прокси
+// закрыть простаивающее соединение
+const eventHeartbeatInterval = 25 * time.Second
+
+// eventRetryMillis — рекомендуемая клиенту пауза перед переподключением
+const eventRetryMillis = 3000
+
+type EventHandler struct {
+	broker *services.EventBroker
+	auth   *services.AuthService
+}
+
+func NewEventHandler(broker *services.EventBroker, auth *services.AuthService) *EventHandler {
+	return &EventHandler{broker: broker, auth: auth}
+}
+
+// IssueStreamToken выдает краткосрочный токен для подключения к потоку
+// событий из браузера: new EventSource("/api/v1/events?access_token=...").
+// Токен проверяется при каждом подключении; получив ошибку после истечения
+// срока, клиент запрашивает новый токен и открывает поток заново с last_event_id.
+// POST /api/v1/events/token
+func (h *EventHandler) IssueStreamToken(w http.ResponseWriter, r *http.Request) {
+	token, err := h.auth.IssueStreamToken(r.Context())
+	if err != nil {
+		http.Error(w, err.Error(), serviceErrorStatus(err, http.StatusInternalServerError))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(token)
+}
+
+// StreamEvents отдает поток событий об изменениях (Server-Sent Events).
+// Параметры project_id и types (через запятую) фильтруют события. Пропущенные
+// события досылаются по заголовку Last-Event-ID или параметру last_event_id.
+// Кроме X-API-Key принимается токен потока в параметре access_token.
+// GET /api/v1/events
+func (h *EventHandler) StreamEvents(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "Streaming is not supported", http.StatusInternalServerError)
+		return
+	}
+
+	filter, err := parseEventFilter(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	lastEventID := r.Header.Get("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = r.URL.Query().Get("last_event_id")
+	}
+
+	subscription, missed := h.broker.Subscribe(filter, lastEventID)
+	defer subscription.Close()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	fmt.Fprintf(w, "retry: %d\n\n", eventRetryMillis)
+	for i := range missed {
+		if err := writeSSEEvent(w, &missed[i]); err != nil {
+			return
+		}
+	}
+	flusher.Flush()
+
+	heartbeat := time.NewTicker(eventHeartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case event, ok := <-subscription.Events():
+			if !ok {
+				// Подписчик не успевал получать события: клиент переподключится
+				// с Last-Event-ID и получит пропущенное из истории
+				return
+			}
+			if err := writeSSEEvent(w, &event); err != nil {
+				return
+			}
+			flusher.Flush()
+		case <-heartbeat.C:
+			if _, err := io.WriteString(w, ": ping\n\n"); err != nil {
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
+
+// GetValidEventTypes возвращает список типов событий
+// GET /api/v1/events/types
+func (h *EventHandler) GetValidEventTypes(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(models.ValidEventTypes())
+}
+
+func parseEventFilter(r *http.Request) (models.EventFilter, error) {
+	query := r.URL.Query()
+	filter := models.EventFilter{ProjectID: strings.TrimSpace(query.Get("project_id"))}
+
+	for _, raw := range strings.Split(query.Get("types"), ",") {
+		eventType := strings.TrimSpace(raw)
+		if eventType == "" {
+			continue
+		}
+		if !models.IsValidEventType(eventType) {
+			return filter, errors.New("invalid event type: " + eventType)
+		}
+		if filter.Types == nil {
+			filter.Types = make(map[models.EventType]bool)
+		}
+		filter.Types[models.EventType(eventType)] = true
+	}
+	return filter, nil
+}
+
+// writeSSEEvent записывает событие в формате text/event-stream
+func writeSSEEvent(w io.Writer, event *models.Event) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "id: %s\nevent: %s\ndata: %s\n\n", event.ID, event.Type, data)
+	return err
+}
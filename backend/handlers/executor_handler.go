@@ -2,8 +2,14 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
+
+	"project-manager/models"
 	"project-manager/services"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/jackc/pgx/v5"
 )
 
 type ExecutorHandler struct {
@@ -17,27 +23,103 @@ func NewExecutorHandler(service *services.ExecutorService) *ExecutorHandler {
 func (h *ExecutorHandler) GetAllExecutors(w http.ResponseWriter, r *http.Request) {
 	executors, err := h.service.GetAllExecutors(r.Context())
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		http.Error(w, err.Error(), serviceErrorStatus(err, http.StatusInternalServerError))
 		return
 	}
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(executors)
 }
 
+// GetExecutor возвращает исполнителя
+// GET /api/v1/executors/{id}
+func (h *ExecutorHandler) GetExecutor(w http.ResponseWriter, r *http.Request) {
+	executor, err := h.service.GetExecutorByID(r.Context(), chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, err.Error(), serviceErrorStatus(err, http.StatusInternalServerError))
+		return
+	}
+	if executor == nil {
+		http.Error(w, "Executor not found", http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(executor)
+}
+
 func (h *ExecutorHandler) CreateExecutor(w http.ResponseWriter, r *http.Request) {
+	var executor models.Executor
+	if err := json.NewDecoder(r.Body).Decode(&executor); err != nil {
+		http.Error(w, "Invalid JSON: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := h.service.CreateExecutor(r.Context(), &executor); err != nil {
+		http.Error(w, err.Error(), serviceErrorStatus(err, http.StatusBadRequest))
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(executor)
+}
+
+// UpdateExecutor изменяет имя, вид и учетную запись исполнителя
+// PUT /api/v1/executors/{id}
+func (h *ExecutorHandler) UpdateExecutor(w http.ResponseWriter, r *http.Request) {
+	var executor models.Executor
+	if err := json.NewDecoder(r.Body).Decode(&executor); err != nil {
+		http.Error(w, "Invalid JSON: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	executor.ID = chi.URLParam(r, "id")
+
+	if err := h.service.UpdateExecutor(r.Context(), &executor); err != nil {
+		http.Error(w, err.Error(), serviceErrorStatus(err, http.StatusBadRequest))
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(executor)
+}
+
+// DeleteExecutor удаляет исполнителя. Открытые задачи передаются исполнителю
+// reassign_to, без параметра остаются без исполнителя.
+// DELETE /api/v1/executors/{id}?reassign_to=
+func (h *ExecutorHandler) DeleteExecutor(w http.ResponseWriter, r *http.Request) {
+	tasks, err := h.service.DeleteExecutor(r.Context(), chi.URLParam(r, "id"), r.URL.Query().Get("reassign_to"))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			http.Error(w, "Executor not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, err.Error(), serviceErrorStatus(err, http.StatusBadRequest))
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"reassignedTasks": tasks})
+}
+
+// ReassignExecutorTasks передает открытые задачи исполнителя другому
+// исполнителю; пустой reassignTo снимает назначение
+// POST /api/v1/executors/{id}/reassign
+func (h *ExecutorHandler) ReassignExecutorTasks(w http.ResponseWriter, r *http.Request) {
 	var req struct {
-		Name string `json:"name"`
+		ReassignTo string `json:"reassignTo"`
 	}
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Name == "" {
-		http.Error(w, "Invalid JSON or empty name", http.StatusBadRequest)
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid JSON: "+err.Error(), http.StatusBadRequest)
 		return
 	}
-	executor, err := h.service.CreateExecutor(r.Context(), req.Name)
+
+	tasks, err := h.service.ReassignExecutorTasks(r.Context(), chi.URLParam(r, "id"), req.ReassignTo)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		http.Error(w, err.Error(), serviceErrorStatus(err, http.StatusBadRequest))
 		return
 	}
 	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusCreated)
-	json.NewEncoder(w).Encode(executor)
+	json.NewEncoder(w).Encode(map[string]interface{}{"reassignedTasks": tasks})
+}
+
+// GetValidExecutorKinds возвращает список видов исполнителей
+// GET /api/v1/executors/kinds
+func (h *ExecutorHandler) GetValidExecutorKinds(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(models.ValidExecutorKinds())
 }
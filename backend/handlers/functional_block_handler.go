@@ -28,7 +28,7 @@ func (h *FunctionalBlockHandler) CreateFunctionalBlock(w http.ResponseWriter, r
 	}
 
 	if err := h.service.CreateFunctionalBlock(r.Context(), &fb); err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		http.Error(w, err.Error(), serviceErrorStatus(err, http.StatusBadRequest))
 		return
 	}
 
@@ -42,7 +42,7 @@ func (h *FunctionalBlockHandler) GetFunctionalBlock(w http.ResponseWriter, r *ht
 
 	fb, err := h.service.GetFunctionalBlockByID(r.Context(), id)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		http.Error(w, err.Error(), serviceErrorStatus(err, http.StatusInternalServerError))
 		return
 	}
 
@@ -58,7 +58,7 @@ func (h *FunctionalBlockHandler) GetFunctionalBlock(w http.ResponseWriter, r *ht
 func (h *FunctionalBlockHandler) GetAllFunctionalBlocks(w http.ResponseWriter, r *http.Request) {
 	blocks, err := h.service.GetAllFunctionalBlocks(r.Context())
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		http.Error(w, err.Error(), serviceErrorStatus(err, http.StatusInternalServerError))
 		return
 	}
 
@@ -77,12 +77,43 @@ func (h *FunctionalBlockHandler) UpdateFunctionalBlock(w http.ResponseWriter, r
 
 	fb.ID = id // Устанавливаем ID из URL
 
-	if err := h.service.UpdateFunctionalBlock(r.Context(), &fb); err != nil {
+	h.updateFunctionalBlock(w, r, &fb)
+}
+
+// PatchFunctionalBlock частично обновляет функциональный блок по JSON Merge Patch (RFC 7396):
+// меняются только переданные поля
+func (h *FunctionalBlockHandler) PatchFunctionalBlock(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+
+	current, err := h.service.GetFunctionalBlockByID(r.Context(), id)
+	if err != nil {
+		http.Error(w, err.Error(), serviceErrorStatus(err, http.StatusInternalServerError))
+		return
+	}
+	if current == nil {
+		http.Error(w, "Functional block not found", http.StatusNotFound)
+		return
+	}
+
+	fb, err := decodeMergePatch(r, current)
+	if err != nil {
+		http.Error(w, "Invalid merge patch: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	fb.ID = id // Устанавливаем ID из URL
+
+	h.updateFunctionalBlock(w, r, fb)
+}
+
+// updateFunctionalBlock сохраняет функциональный блок и отвечает его новым состоянием; общий путь PUT и PATCH
+func (h *FunctionalBlockHandler) updateFunctionalBlock(w http.ResponseWriter, r *http.Request, fb *models.FunctionalBlock) {
+	if err := h.service.UpdateFunctionalBlock(r.Context(), fb); err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			http.Error(w, "Functional block not found", http.StatusNotFound)
 			return
 		}
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		http.Error(w, err.Error(), serviceErrorStatus(err, http.StatusBadRequest))
 		return
 	}
 
@@ -98,7 +129,7 @@ func (h *FunctionalBlockHandler) DeleteFunctionalBlock(w http.ResponseWriter, r
 			http.Error(w, "Functional block not found", http.StatusNotFound)
 			return
 		}
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		http.Error(w, err.Error(), serviceErrorStatus(err, http.StatusInternalServerError))
 		return
 	}
 
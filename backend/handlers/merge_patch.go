@@ -0,0 +1,35 @@
+package handlers
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+
+	"project-manager/utils"
+)
+
+// decodeMergePatch применяет тело запроса как JSON Merge Patch (RFC 7396)
+// к текущему состоянию записи и возвращает ее новую копию. Поля, которых
+// нет в патче, сохраняют текущие значения; null очищает поле.
+func decodeMergePatch[T any](r *http.Request, current *T) (*T, error) {
+	patch, err := io.ReadAll(r.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	original, err := json.Marshal(current)
+	if err != nil {
+		return nil, err
+	}
+
+	merged, err := utils.MergePatch(original, patch)
+	if err != nil {
+		return nil, err
+	}
+
+	var result T
+	if err := json.Unmarshal(merged, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
@@ -0,0 +1,131 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"project-manager/models"
+	"project-manager/services"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/jackc/pgx/v5"
+)
+
+type NotificationHandler struct {
+	service *services.NotificationService
+}
+
+func NewNotificationHandler(service *services.NotificationService) *NotificationHandler {
+	return &NotificationHandler{service: service}
+}
+
+// GetInbox возвращает уведомления текущего пользователя.
+// Параметры: unread=true — только непрочитанные, limit, offset.
+// GET /api/v1/notifications
+func (h *NotificationHandler) GetInbox(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	filter := &models.NotificationFilter{UnreadOnly: query.Get("unread") == "true"}
+
+	var err error
+	if filter.Limit, err = parseIntParam(query, "limit"); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if filter.Offset, err = parseIntParam(query, "offset"); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	page, err := h.service.GetInbox(r.Context(), filter)
+	if err != nil {
+		http.Error(w, err.Error(), serviceErrorStatus(err, http.StatusBadRequest))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(page)
+}
+
+// GetUnreadCount возвращает число непрочитанных уведомлений
+// GET /api/v1/notifications/unread-count
+func (h *NotificationHandler) GetUnreadCount(w http.ResponseWriter, r *http.Request) {
+	count, err := h.service.GetUnreadCount(r.Context())
+	if err != nil {
+		http.Error(w, err.Error(), serviceErrorStatus(err, http.StatusBadRequest))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]int{"unread": count})
+}
+
+// MarkRead отмечает уведомление прочитанным
+// POST /api/v1/notifications/{id}/read
+func (h *NotificationHandler) MarkRead(w http.ResponseWriter, r *http.Request) {
+	if err := h.service.MarkRead(r.Context(), chi.URLParam(r, "id")); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			http.Error(w, "Notification not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, err.Error(), serviceErrorStatus(err, http.StatusBadRequest))
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// MarkAllRead отмечает прочитанными все уведомления
+// POST /api/v1/notifications/read-all
+func (h *NotificationHandler) MarkAllRead(w http.ResponseWriter, r *http.Request) {
+	count, err := h.service.MarkAllRead(r.Context())
+	if err != nil {
+		http.Error(w, err.Error(), serviceErrorStatus(err, http.StatusBadRequest))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]int64{"marked": count})
+}
+
+// GetPreferences возвращает настройки уведомлений
+// GET /api/v1/notifications/preferences
+func (h *NotificationHandler) GetPreferences(w http.ResponseWriter, r *http.Request) {
+	preferences, err := h.service.GetPreferences(r.Context())
+	if err != nil {
+		http.Error(w, err.Error(), serviceErrorStatus(err, http.StatusBadRequest))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(preferences)
+}
+
+// UpdatePreferences сохраняет настройки уведомлений; не переданные поля не меняются
+// PUT /api/v1/notifications/preferences
+func (h *NotificationHandler) UpdatePreferences(w http.ResponseWriter, r *http.Request) {
+	preferences, err := h.service.GetPreferences(r.Context())
+	if err != nil {
+		http.Error(w, err.Error(), serviceErrorStatus(err, http.StatusBadRequest))
+		return
+	}
+	if err := json.NewDecoder(r.Body).Decode(preferences); err != nil {
+		http.Error(w, "Invalid JSON: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.service.UpdatePreferences(r.Context(), preferences); err != nil {
+		http.Error(w, err.Error(), serviceErrorStatus(err, http.StatusBadRequest))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(preferences)
+}
+
+// GetValidNotificationTypes возвращает список типов уведомлений
+// GET /api/v1/notifications/types
+func (h *NotificationHandler) GetValidNotificationTypes(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(models.ValidNotificationTypes())
+}
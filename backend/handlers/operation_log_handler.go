@@ -2,8 +2,12 @@ package handlers
 
 import (
 	"encoding/json"
+	"fmt"
 	"net/http"
+	"net/url"
+	"strconv"
 
+	"project-manager/models"
 	"project-manager/services"
 
 	"github.com/go-chi/chi/v5"
@@ -48,15 +52,66 @@ func (h *OperationLogHandler) GetLogsByTask(w http.ResponseWriter, r *http.Reque
 	json.NewEncoder(w).Encode(logs)
 }
 
+// GetTaskHistory возвращает хронологию изменений полей задачи
+// GET /api/v1/tasks/{id}/history
+func (h *OperationLogHandler) GetTaskHistory(w http.ResponseWriter, r *http.Request) {
+	taskID := chi.URLParam(r, "id")
+
+	history, err := h.service.GetTaskHistory(r.Context(), taskID)
+	if err != nil {
+		http.Error(w, err.Error(), serviceErrorStatus(err, http.StatusBadRequest))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(history)
+}
+
+// GetAllLogs возвращает журнал операций с фильтрами по записи, пользователю
+// и периоду. Общее количество записей передается в X-Total-Count.
+// GET /api/v1/operation-logs?entity_type=&entity_id=&user=&type=&from=&to=&limit=&offset=
 func (h *OperationLogHandler) GetAllLogs(w http.ResponseWriter, r *http.Request) {
-	logs, err := h.service.GetAllLogs(r.Context())
+	filter, err := parseOperationLogFilter(r.URL.Query())
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	page, err := h.service.FindLogs(r.Context(), filter)
+	if err != nil {
+		http.Error(w, err.Error(), serviceErrorStatus(err, http.StatusBadRequest))
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(logs)
+	w.Header().Set("X-Total-Count", strconv.Itoa(page.Total))
+	json.NewEncoder(w).Encode(page.Logs)
+}
+
+// parseOperationLogFilter разбирает параметры запроса журнала операций
+func parseOperationLogFilter(query url.Values) (*models.OperationLogFilter, error) {
+	filter := &models.OperationLogFilter{
+		EntityType:     query.Get("entity_type"),
+		EntityID:       query.Get("entity_id"),
+		UserIdentifier: query.Get("user"),
+		OperationType:  query.Get("type"),
+	}
+
+	var err error
+	if filter.From, err = parseDateParam(query.Get("from"), false); err != nil {
+		return nil, fmt.Errorf("invalid from: %w", err)
+	}
+	if filter.To, err = parseDateParam(query.Get("to"), true); err != nil {
+		return nil, fmt.Errorf("invalid to: %w", err)
+	}
+	if filter.Limit, err = parseIntParam(query, "limit"); err != nil {
+		return nil, err
+	}
+	if filter.Offset, err = parseIntParam(query, "offset"); err != nil {
+		return nil, err
+	}
+
+	return filter, nil
 }
 
 func (h *OperationLogHandler) GetLogsByOperationType(w http.ResponseWriter, r *http.Request) {
@@ -100,3 +155,11 @@ func (h *OperationLogHandler) GetValidOperationTypes(w http.ResponseWriter, r *h
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string][]string{"operationTypes": types})
 }
+
+// GetValidEntityTypes возвращает список типов журналируемых записей
+func (h *OperationLogHandler) GetValidEntityTypes(w http.ResponseWriter, r *http.Request) {
+	types := h.service.GetValidEntityTypes()
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string][]string{"entityTypes": types})
+}
@@ -0,0 +1,94 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"project-manager/models"
+	"project-manager/services"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// maxProjectArchiveSize ограничивает размер загружаемого архива проекта
+const maxProjectArchiveSize = 64 << 20
+
+type ProjectArchiveHandler struct {
+	service *services.ProjectArchiveService
+}
+
+func NewProjectArchiveHandler(service *services.ProjectArchiveService) *ProjectArchiveHandler {
+	return &ProjectArchiveHandler{service: service}
+}
+
+// ExportProject выгружает проект в JSON-архив
+// GET /api/v1/projects/{id}/export
+func (h *ProjectArchiveHandler) ExportProject(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+
+	archive, err := h.service.ExportProject(r.Context(), id)
+	if err != nil {
+		http.Error(w, err.Error(), serviceErrorStatus(err, http.StatusBadRequest))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Disposition", `attachment; filename="project-`+archive.Project.ID+`.json"`)
+	json.NewEncoder(w).Encode(archive)
+}
+
+// ImportProject создает проект из архива. Параметр dry_run=true только
+// проверяет архив, reuse_blocks=true разрешает использовать существующие
+// функциональные блоки с другим названием.
+// POST /api/v1/projects/import
+func (h *ProjectArchiveHandler) ImportProject(w http.ResponseWriter, r *http.Request) {
+	options, err := parseImportOptions(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var archive models.ProjectArchive
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxProjectArchiveSize)).Decode(&archive); err != nil {
+		http.Error(w, "Invalid JSON: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	result, err := h.service.ImportProject(r.Context(), &archive, options)
+	if err != nil {
+		if errors.Is(err, services.ErrImportConflicts) && result != nil {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusConflict)
+			json.NewEncoder(w).Encode(result)
+			return
+		}
+		http.Error(w, err.Error(), serviceErrorStatus(err, http.StatusBadRequest))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if !result.DryRun {
+		w.WriteHeader(http.StatusCreated)
+	}
+	json.NewEncoder(w).Encode(result)
+}
+
+func parseImportOptions(r *http.Request) (models.ProjectImportOptions, error) {
+	var options models.ProjectImportOptions
+	query := r.URL.Query()
+
+	for name, target := range map[string]*bool{"dry_run": &options.DryRun, "reuse_blocks": &options.ReuseBlocks} {
+		raw := query.Get(name)
+		if raw == "" {
+			continue
+		}
+		value, err := strconv.ParseBool(raw)
+		if err != nil {
+			return options, errors.New("invalid " + name + " parameter")
+		}
+		*target = value
+	}
+	return options, nil
+}
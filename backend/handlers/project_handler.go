@@ -28,7 +28,7 @@ func (h *ProjectHandler) CreateProject(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if err := h.service.CreateProject(r.Context(), &project); err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		http.Error(w, err.Error(), serviceErrorStatus(err, http.StatusBadRequest))
 		return
 	}
 
@@ -42,7 +42,7 @@ func (h *ProjectHandler) GetProject(w http.ResponseWriter, r *http.Request) {
 
 	project, err := h.service.GetProjectByID(r.Context(), id)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		http.Error(w, err.Error(), serviceErrorStatus(err, http.StatusInternalServerError))
 		return
 	}
 
@@ -58,7 +58,7 @@ func (h *ProjectHandler) GetProject(w http.ResponseWriter, r *http.Request) {
 func (h *ProjectHandler) GetAllProjects(w http.ResponseWriter, r *http.Request) {
 	projects, err := h.service.GetAllProjects(r.Context())
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		http.Error(w, err.Error(), serviceErrorStatus(err, http.StatusInternalServerError))
 		return
 	}
 
@@ -77,12 +77,43 @@ func (h *ProjectHandler) UpdateProject(w http.ResponseWriter, r *http.Request) {
 
 	project.ID = id // Устанавливаем ID из URL
 
-	if err := h.service.UpdateProject(r.Context(), &project); err != nil {
+	h.updateProject(w, r, &project)
+}
+
+// PatchProject частично обновляет проект по JSON Merge Patch (RFC 7396):
+// меняются только переданные поля
+func (h *ProjectHandler) PatchProject(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+
+	current, err := h.service.GetProjectByID(r.Context(), id)
+	if err != nil {
+		http.Error(w, err.Error(), serviceErrorStatus(err, http.StatusInternalServerError))
+		return
+	}
+	if current == nil {
+		http.Error(w, "Project not found", http.StatusNotFound)
+		return
+	}
+
+	project, err := decodeMergePatch(r, current)
+	if err != nil {
+		http.Error(w, "Invalid merge patch: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	project.ID = id // Устанавливаем ID из URL
+
+	h.updateProject(w, r, project)
+}
+
+// updateProject сохраняет проект и отвечает его новым состоянием; общий путь PUT и PATCH
+func (h *ProjectHandler) updateProject(w http.ResponseWriter, r *http.Request, project *models.Project) {
+	if err := h.service.UpdateProject(r.Context(), project); err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			http.Error(w, "Project not found", http.StatusNotFound)
 			return
 		}
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		http.Error(w, err.Error(), serviceErrorStatus(err, http.StatusBadRequest))
 		return
 	}
 
@@ -98,7 +129,52 @@ func (h *ProjectHandler) DeleteProject(w http.ResponseWriter, r *http.Request) {
 			http.Error(w, "Project not found", http.StatusNotFound)
 			return
 		}
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		http.Error(w, err.Error(), serviceErrorStatus(err, http.StatusInternalServerError))
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetDeletedProjects возвращает содержимое корзины
+// GET /api/v1/projects/trash
+func (h *ProjectHandler) GetDeletedProjects(w http.ResponseWriter, r *http.Request) {
+	projects, err := h.service.GetDeletedProjects(r.Context())
+	if err != nil {
+		http.Error(w, err.Error(), serviceErrorStatus(err, http.StatusInternalServerError))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(projects)
+}
+
+// RestoreProject возвращает проект из корзины
+// POST /api/v1/projects/{id}/restore
+func (h *ProjectHandler) RestoreProject(w http.ResponseWriter, r *http.Request) {
+	project, err := h.service.RestoreProject(r.Context(), chi.URLParam(r, "id"))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			http.Error(w, "Project not found in trash", http.StatusNotFound)
+			return
+		}
+		http.Error(w, err.Error(), serviceErrorStatus(err, http.StatusBadRequest))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(project)
+}
+
+// PurgeProject окончательно удаляет проект из корзины
+// DELETE /api/v1/projects/{id}/purge
+func (h *ProjectHandler) PurgeProject(w http.ResponseWriter, r *http.Request) {
+	if err := h.service.PurgeProject(r.Context(), chi.URLParam(r, "id")); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			http.Error(w, "Project not found in trash", http.StatusNotFound)
+			return
+		}
+		http.Error(w, err.Error(), serviceErrorStatus(err, http.StatusBadRequest))
 		return
 	}
 
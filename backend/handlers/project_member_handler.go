@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"project-manager/models"
+	"project-manager/services"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/jackc/pgx/v5"
+)
+
+type ProjectMemberHandler struct {
+	service *services.ProjectMemberService
+}
+
+func NewProjectMemberHandler(service *services.ProjectMemberService) *ProjectMemberHandler {
+	return &ProjectMemberHandler{service: service}
+}
+
+// GetMembers возвращает участников проекта
+// GET /api/v1/projects/{projectID}/members
+func (h *ProjectMemberHandler) GetMembers(w http.ResponseWriter, r *http.Request) {
+	projectID := chi.URLParam(r, "projectID")
+
+	members, err := h.service.GetMembers(r.Context(), projectID)
+	if err != nil {
+		http.Error(w, err.Error(), serviceErrorStatus(err, http.StatusBadRequest))
+		return
+	}
+	if members == nil {
+		members = []models.ProjectMember{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(members)
+}
+
+// AddMember добавляет участника проекта
+// POST /api/v1/projects/{projectID}/members
+func (h *ProjectMemberHandler) AddMember(w http.ResponseWriter, r *http.Request) {
+	var member models.ProjectMember
+	if err := json.NewDecoder(r.Body).Decode(&member); err != nil {
+		http.Error(w, "Invalid JSON: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	member.ProjectID = chi.URLParam(r, "projectID")
+
+	if err := h.service.SetMember(r.Context(), &member); err != nil {
+		http.Error(w, err.Error(), serviceErrorStatus(err, http.StatusBadRequest))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(member)
+}
+
+// UpdateMember меняет роль участника проекта
+// PUT /api/v1/projects/{projectID}/members/{userID}
+func (h *ProjectMemberHandler) UpdateMember(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Role string `json:"role"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid JSON: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	member := models.ProjectMember{
+		ProjectID: chi.URLParam(r, "projectID"),
+		UserID:    chi.URLParam(r, "userID"),
+		Role:      req.Role,
+	}
+
+	if err := h.service.SetMember(r.Context(), &member); err != nil {
+		http.Error(w, err.Error(), serviceErrorStatus(err, http.StatusBadRequest))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(member)
+}
+
+// RemoveMember исключает участника из проекта
+// DELETE /api/v1/projects/{projectID}/members/{userID}
+func (h *ProjectMemberHandler) RemoveMember(w http.ResponseWriter, r *http.Request) {
+	projectID := chi.URLParam(r, "projectID")
+	userID := chi.URLParam(r, "userID")
+
+	if err := h.service.RemoveMember(r.Context(), projectID, userID); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			http.Error(w, "Member not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, err.Error(), serviceErrorStatus(err, http.StatusBadRequest))
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetValidRoles возвращает список ролей участников проекта
+// GET /api/v1/projects/{projectID}/members/roles
+func (h *ProjectMemberHandler) GetValidRoles(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(h.service.GetValidRoles())
+}
@@ -28,7 +28,7 @@ func (h *ProjectPlanHandler) AddTaskToPlan(w http.ResponseWriter, r *http.Reques
 	taskID := chi.URLParam(r, "taskID")
 
 	if err := h.service.AddTaskToPlan(r.Context(), projectID, taskID); err != nil {
-		utils.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+		utils.WriteErrorResponse(w, serviceErrorStatus(err, http.StatusBadRequest), err.Error())
 		return
 	}
 
@@ -44,7 +44,7 @@ func (h *ProjectPlanHandler) RemoveTaskFromPlan(w http.ResponseWriter, r *http.R
 	taskID := chi.URLParam(r, "taskID")
 
 	if err := h.service.RemoveTaskFromPlan(r.Context(), projectID, taskID); err != nil {
-		utils.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+		utils.WriteErrorResponse(w, serviceErrorStatus(err, http.StatusBadRequest), err.Error())
 		return
 	}
 
@@ -60,7 +60,7 @@ func (h *ProjectPlanHandler) GetProjectPlan(w http.ResponseWriter, r *http.Reque
 
 	plan, err := h.service.GetProjectPlan(r.Context(), projectID)
 	if err != nil {
-		utils.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+		utils.WriteErrorResponse(w, serviceErrorStatus(err, http.StatusBadRequest), err.Error())
 		return
 	}
 
@@ -79,7 +79,7 @@ func (h *ProjectPlanHandler) ReorderTasks(w http.ResponseWriter, r *http.Request
 	}
 
 	if err := h.service.ReorderTasks(r.Context(), projectID, &reorderRequest); err != nil {
-		utils.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+		utils.WriteErrorResponse(w, serviceErrorStatus(err, http.StatusBadRequest), err.Error())
 		return
 	}
 
@@ -104,7 +104,7 @@ func (h *ProjectPlanHandler) MoveTaskToPosition(w http.ResponseWriter, r *http.R
 	}
 
 	if err := h.service.MoveTaskToPosition(r.Context(), projectID, taskID, request.Position); err != nil {
-		utils.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+		utils.WriteErrorResponse(w, serviceErrorStatus(err, http.StatusBadRequest), err.Error())
 		return
 	}
 
@@ -121,7 +121,7 @@ func (h *ProjectPlanHandler) GetTaskPosition(w http.ResponseWriter, r *http.Requ
 
 	position, err := h.service.GetTaskPosition(r.Context(), projectID, taskID)
 	if err != nil {
-		utils.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+		utils.WriteErrorResponse(w, serviceErrorStatus(err, http.StatusBadRequest), err.Error())
 		return
 	}
 
@@ -139,7 +139,7 @@ func (h *ProjectPlanHandler) IsTaskInPlan(w http.ResponseWriter, r *http.Request
 
 	inPlan, err := h.service.IsTaskInPlan(r.Context(), projectID, taskID)
 	if err != nil {
-		utils.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+		utils.WriteErrorResponse(w, serviceErrorStatus(err, http.StatusBadRequest), err.Error())
 		return
 	}
 
@@ -256,7 +256,7 @@ func (h *ProjectPlanHandler) GetProjectPlanStats(w http.ResponseWriter, r *http.
 
 	plan, err := h.service.GetProjectPlan(r.Context(), projectID)
 	if err != nil {
-		utils.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+		utils.WriteErrorResponse(w, serviceErrorStatus(err, http.StatusBadRequest), err.Error())
 		return
 	}
 
@@ -283,3 +283,36 @@ func (h *ProjectPlanHandler) GetProjectPlanStats(w http.ResponseWriter, r *http.
 
 	utils.WriteJSONResponse(w, http.StatusOK, stats)
 }
+
+// AutoOrderPlan упорядочивает план с учетом зависимостей и приоритетов
+// POST /api/v1/projects/{projectID}/plan/auto-order
+func (h *ProjectPlanHandler) AutoOrderPlan(w http.ResponseWriter, r *http.Request) {
+	projectID := chi.URLParam(r, "projectID")
+
+	plan, err := h.service.AutoOrderPlan(r.Context(), projectID)
+	if err != nil {
+		utils.WriteErrorResponse(w, serviceErrorStatus(err, http.StatusBadRequest), err.Error())
+		return
+	}
+
+	utils.WriteJSONResponse(w, http.StatusOK, plan)
+}
+
+// GetNextTask возвращает следующую готовую к работе задачу плана.
+// Если готовых задач нет, отвечает 204 No Content.
+// GET /api/v1/projects/{projectID}/plan/next
+func (h *ProjectPlanHandler) GetNextTask(w http.ResponseWriter, r *http.Request) {
+	projectID := chi.URLParam(r, "projectID")
+
+	item, err := h.service.GetNextTask(r.Context(), projectID)
+	if err != nil {
+		utils.WriteErrorResponse(w, serviceErrorStatus(err, http.StatusBadRequest), err.Error())
+		return
+	}
+	if item == nil {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	utils.WriteJSONResponse(w, http.StatusOK, item)
+}
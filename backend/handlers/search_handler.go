@@ -0,0 +1,53 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"project-manager/models"
+	"project-manager/services"
+)
+
+type SearchHandler struct {
+	service *services.SearchService
+}
+
+func NewSearchHandler(service *services.SearchService) *SearchHandler {
+	return &SearchHandler{service: service}
+}
+
+// Search выполняет полнотекстовый поиск
+// GET /api/v1/search?q=&project_id=&types=task,comment,document&limit=&offset=
+func (h *SearchHandler) Search(w http.ResponseWriter, r *http.Request) {
+	params := r.URL.Query()
+
+	query := &models.SearchQuery{
+		Query:       params.Get("q"),
+		ProjectID:   params.Get("project_id"),
+		EntityTypes: parseListParam(params, "types"),
+	}
+
+	var err error
+	if query.Limit, err = parseIntParam(params, "limit"); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if query.Offset, err = parseIntParam(params, "offset"); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	response, err := h.service.Search(r.Context(), query)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidSearchQuery) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		http.Error(w, err.Error(), serviceErrorStatus(err, http.StatusInternalServerError))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
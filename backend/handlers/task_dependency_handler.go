@@ -0,0 +1,96 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"project-manager/models"
+	"project-manager/services"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type TaskDependencyHandler struct {
+	service *services.TaskDependencyService
+}
+
+func NewTaskDependencyHandler(service *services.TaskDependencyService) *TaskDependencyHandler {
+	return &TaskDependencyHandler{service: service}
+}
+
+// GetTaskDependencies возвращает связи задачи
+// GET /api/v1/tasks/{id}/dependencies
+func (h *TaskDependencyHandler) GetTaskDependencies(w http.ResponseWriter, r *http.Request) {
+	taskID := chi.URLParam(r, "id")
+
+	dependencies, err := h.service.GetTaskDependencies(r.Context(), taskID)
+	if err != nil {
+		http.Error(w, err.Error(), serviceErrorStatus(err, http.StatusBadRequest))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(dependencies)
+}
+
+// AddDependency добавляет связь задачи с другой задачей
+// POST /api/v1/tasks/{id}/dependencies {"dependsOnTaskId": "...", "kind": "blocks"}
+func (h *TaskDependencyHandler) AddDependency(w http.ResponseWriter, r *http.Request) {
+	var dependency models.TaskDependency
+	if err := json.NewDecoder(r.Body).Decode(&dependency); err != nil {
+		http.Error(w, "Invalid JSON: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	dependency.TaskID = chi.URLParam(r, "id") // Устанавливаем ID из URL
+
+	if err := h.service.AddDependency(r.Context(), &dependency); err != nil {
+		http.Error(w, err.Error(), serviceErrorStatus(err, http.StatusBadRequest))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(dependency)
+}
+
+// RemoveDependency удаляет связь задачи
+// DELETE /api/v1/tasks/{id}/dependencies/{dependencyID}
+func (h *TaskDependencyHandler) RemoveDependency(w http.ResponseWriter, r *http.Request) {
+	taskID := chi.URLParam(r, "id")
+	dependencyID := chi.URLParam(r, "dependencyID")
+
+	if err := h.service.RemoveDependency(r.Context(), taskID, dependencyID); err != nil {
+		if errors.Is(err, services.ErrDependencyNotFound) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		http.Error(w, err.Error(), serviceErrorStatus(err, http.StatusBadRequest))
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetProjectGraph возвращает граф зависимостей задач проекта
+// GET /api/v1/projects/{projectID}/dependency-graph
+func (h *TaskDependencyHandler) GetProjectGraph(w http.ResponseWriter, r *http.Request) {
+	projectID := chi.URLParam(r, "projectID")
+
+	graph, err := h.service.GetProjectGraph(r.Context(), projectID)
+	if err != nil {
+		http.Error(w, err.Error(), serviceErrorStatus(err, http.StatusBadRequest))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(graph)
+}
+
+// GetValidDependencyKinds возвращает список видов связей
+// GET /api/v1/tasks/dependency-kinds
+func (h *TaskDependencyHandler) GetValidDependencyKinds(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(h.service.GetValidDependencyKinds())
+}
@@ -0,0 +1,117 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"project-manager/models"
+	"project-manager/services"
+	"project-manager/utils"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/jackc/pgx/v5"
+)
+
+type TaskDocumentLinkHandler struct {
+	service *services.TaskDocumentLinkService
+}
+
+func NewTaskDocumentLinkHandler(service *services.TaskDocumentLinkService) *TaskDocumentLinkHandler {
+	return &TaskDocumentLinkHandler{service: service}
+}
+
+// GetTaskLinks возвращает документы, связанные с задачей
+// GET /api/v1/tasks/{id}/document-links
+func (h *TaskDocumentLinkHandler) GetTaskLinks(w http.ResponseWriter, r *http.Request) {
+	links, err := h.service.GetTaskLinks(r.Context(), chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, err.Error(), serviceErrorStatus(err, http.StatusBadRequest))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(links)
+}
+
+// CreateTaskLink связывает задачу с документом
+// POST /api/v1/tasks/{id}/document-links
+func (h *TaskDocumentLinkHandler) CreateTaskLink(w http.ResponseWriter, r *http.Request) {
+	var link models.TaskDocumentLink
+	if err := json.NewDecoder(r.Body).Decode(&link); err != nil {
+		http.Error(w, "Invalid JSON: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	link.TaskID = chi.URLParam(r, "id")
+
+	h.createLink(w, r, &link)
+}
+
+// DeleteTaskLink удаляет связь задачи с документом
+// DELETE /api/v1/tasks/{id}/document-links/{linkID}
+func (h *TaskDocumentLinkHandler) DeleteTaskLink(w http.ResponseWriter, r *http.Request) {
+	h.deleteLink(w, r, chi.URLParam(r, "id"), "")
+}
+
+// GetDocumentLinks возвращает задачи, связанные с документом
+// GET /api/v1/documents/{id}/task-links
+func (h *TaskDocumentLinkHandler) GetDocumentLinks(w http.ResponseWriter, r *http.Request) {
+	links, err := h.service.GetDocumentLinks(r.Context(), chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, err.Error(), serviceErrorStatus(err, http.StatusBadRequest))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(links)
+}
+
+// CreateDocumentLink связывает документ с задачей
+// POST /api/v1/documents/{id}/task-links
+func (h *TaskDocumentLinkHandler) CreateDocumentLink(w http.ResponseWriter, r *http.Request) {
+	var link models.TaskDocumentLink
+	if err := json.NewDecoder(r.Body).Decode(&link); err != nil {
+		http.Error(w, "Invalid JSON: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	link.DocumentID = chi.URLParam(r, "id")
+
+	h.createLink(w, r, &link)
+}
+
+// DeleteDocumentLink удаляет связь документа с задачей
+// DELETE /api/v1/documents/{id}/task-links/{linkID}
+func (h *TaskDocumentLinkHandler) DeleteDocumentLink(w http.ResponseWriter, r *http.Request) {
+	h.deleteLink(w, r, "", chi.URLParam(r, "id"))
+}
+
+// createLink создает связь и отвечает ею; общий путь для обеих сторон связи
+func (h *TaskDocumentLinkHandler) createLink(w http.ResponseWriter, r *http.Request, link *models.TaskDocumentLink) {
+	if err := h.service.CreateLink(r.Context(), link); err != nil {
+		if errors.Is(err, utils.ErrSectionNotFound) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		http.Error(w, err.Error(), serviceErrorStatus(err, http.StatusBadRequest))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(link)
+}
+
+func (h *TaskDocumentLinkHandler) deleteLink(w http.ResponseWriter, r *http.Request, taskID, documentID string) {
+	linkID := chi.URLParam(r, "linkID")
+
+	if err := h.service.DeleteLink(r.Context(), linkID, taskID, documentID); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			http.Error(w, "Link not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, err.Error(), serviceErrorStatus(err, http.StatusBadRequest))
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,327 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"project-manager/models"
+	"project-manager/services"
+	"project-manager/utils"
+)
+
+type TaskExecutionHandler struct {
+	taskService      *services.TaskService
+	executionService *services.TaskExecutionService
+}
+
+type ExecuteTaskRequest struct {
+	Command   string  `json:"command"`
+	Source    string  `json:"source"` // "web", "vscode", "api"
+	UserID    string  `json:"user_id"`
+	ProjectID *string `json:"project_id,omitempty"`
+}
+
+type ExecutionStatusResponse struct {
+	ID            string                 `json:"id"`
+	Status        string                 `json:"status"`
+	Progress      int                    `json:"progress"`
+	Message       string                 `json:"message"`
+	TaskID        *string                `json:"task_id,omitempty"`
+	TaskTitle     string                 `json:"task_title"`
+	Strategy      string                 `json:"strategy"`
+	StartTime     time.Time              `json:"start_time"`
+	EndTime       *time.Time             `json:"end_time,omitempty"`
+	ExecutionTime *int64                 `json:"execution_time,omitempty"` // milliseconds
+	Error         string                 `json:"error,omitempty"`
+	Steps         []ExecutionStepStatus  `json:"steps"`
+	Logs          []string               `json:"logs"`
+	Result        map[string]interface{} `json:"result,omitempty"`
+}
+
+type ExecutionStepStatus struct {
+	ID          string     `json:"id"`
+	Name        string     `json:"name"`
+	Status      string     `json:"status"`
+	StartTime   time.Time  `json:"start_time"`
+	EndTime     *time.Time `json:"end_time,omitempty"`
+	Error       string     `json:"error,omitempty"`
+	Description string     `json:"description"`
+}
+
+func NewTaskExecutionHandler(taskService *services.TaskService, executionService *services.TaskExecutionService) *TaskExecutionHandler {
+	return &TaskExecutionHandler{
+		taskService:      taskService,
+		executionService: executionService,
+	}
+}
+
+// ExecuteTask запускает выполнение задачи по текстовой команде
+// POST /api/v1/tasks/execute
+func (h *TaskExecutionHandler) ExecuteTask(w http.ResponseWriter, r *http.Request) {
+	var req ExecuteTaskRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, "Invalid JSON format")
+		return
+	}
+
+	executionContext := &services.ExecutionContext{
+		Command:   req.Command,
+		Source:    req.Source,
+		UserID:    req.UserID,
+		ProjectID: req.ProjectID,
+	}
+
+	executionID, err := h.executionService.StartExecution(r.Context(), executionContext)
+	if err != nil {
+		utils.WriteErrorResponse(w, serviceErrorStatus(err, http.StatusBadRequest), err.Error())
+		return
+	}
+
+	utils.WriteJSONResponse(w, http.StatusAccepted, map[string]string{
+		"execution_id": executionID,
+		"status":       "started",
+		"message":      "Task execution started successfully",
+	})
+}
+
+// GetExecutionStatus возвращает состояние выполнения
+// GET /api/v1/tasks/execute/{executionID}/status
+func (h *TaskExecutionHandler) GetExecutionStatus(w http.ResponseWriter, r *http.Request) {
+	executionID := chi.URLParam(r, "executionID")
+
+	execution, err := h.executionService.GetExecutionStatus(r.Context(), executionID)
+	if err != nil {
+		if errors.Is(err, services.ErrExecutionNotFound) {
+			utils.WriteErrorResponse(w, serviceErrorStatus(err, http.StatusNotFound), err.Error())
+			return
+		}
+		utils.WriteErrorResponse(w, serviceErrorStatus(err, http.StatusInternalServerError), err.Error())
+		return
+	}
+
+	utils.WriteJSONResponse(w, http.StatusOK, mapExecutionToResponse(execution))
+}
+
+// GetExecutionHistory возвращает историю выполнений
+// GET /api/v1/tasks/execute/history
+func (h *TaskExecutionHandler) GetExecutionHistory(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	userID := query.Get("user_id")
+
+	limit, err := strconv.Atoi(query.Get("limit"))
+	if err != nil || limit <= 0 || limit > 100 {
+		limit = 50
+	}
+
+	offset, err := strconv.Atoi(query.Get("offset"))
+	if err != nil || offset < 0 {
+		offset = 0
+	}
+
+	history, total, err := h.executionService.GetExecutionHistory(r.Context(), userID, limit, offset)
+	if err != nil {
+		utils.WriteErrorResponse(w, serviceErrorStatus(err, http.StatusInternalServerError), err.Error())
+		return
+	}
+
+	responses := make([]ExecutionStatusResponse, 0, len(history))
+	for i := range history {
+		responses = append(responses, mapExecutionToResponse(&history[i]))
+	}
+
+	utils.WriteJSONResponse(w, http.StatusOK, map[string]interface{}{
+		"executions": responses,
+		"total":      total,
+		"limit":      limit,
+		"offset":     offset,
+	})
+}
+
+// CancelExecution отменяет активное выполнение
+// POST /api/v1/tasks/execute/{executionID}/cancel
+func (h *TaskExecutionHandler) CancelExecution(w http.ResponseWriter, r *http.Request) {
+	executionID := chi.URLParam(r, "executionID")
+
+	if err := h.executionService.CancelExecution(r.Context(), executionID); err != nil {
+		switch {
+		case errors.Is(err, services.ErrExecutionNotFound):
+			utils.WriteErrorResponse(w, serviceErrorStatus(err, http.StatusNotFound), err.Error())
+		case errors.Is(err, services.ErrExecutionNotCancellable):
+			utils.WriteErrorResponse(w, serviceErrorStatus(err, http.StatusConflict), err.Error())
+		default:
+			utils.WriteErrorResponse(w, serviceErrorStatus(err, http.StatusInternalServerError), err.Error())
+		}
+		return
+	}
+
+	utils.WriteJSONResponse(w, http.StatusOK, map[string]string{
+		"message": "Execution cancelled successfully",
+	})
+}
+
+// GetExecutionMetrics возвращает статистику выполнений
+// GET /api/v1/tasks/execute/metrics
+func (h *TaskExecutionHandler) GetExecutionMetrics(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	userID := query.Get("user_id")
+
+	days, err := strconv.Atoi(query.Get("days"))
+	if err != nil || days <= 0 || days > 365 {
+		days = 30
+	}
+
+	metrics, err := h.executionService.GetExecutionMetrics(r.Context(), userID, days)
+	if err != nil {
+		utils.WriteErrorResponse(w, serviceErrorStatus(err, http.StatusInternalServerError), err.Error())
+		return
+	}
+
+	utils.WriteJSONResponse(w, http.StatusOK, metrics)
+}
+
+// GetTaskExecuteButton возвращает конфигурацию кнопки выполнения задачи
+// GET /api/v1/tasks/{id}/execute-button
+func (h *TaskExecutionHandler) GetTaskExecuteButton(w http.ResponseWriter, r *http.Request) {
+	taskID := chi.URLParam(r, "id")
+
+	task, err := h.taskService.GetTaskByID(r.Context(), taskID)
+	if err != nil {
+		utils.WriteErrorResponse(w, serviceErrorStatus(err, http.StatusInternalServerError), err.Error())
+		return
+	}
+	if task == nil {
+		utils.WriteErrorResponse(w, http.StatusNotFound, "Task not found")
+		return
+	}
+
+	activeExecutions, err := h.executionService.GetActiveExecutionsForTask(r.Context(), task.ID)
+	if err != nil {
+		utils.WriteErrorResponse(w, serviceErrorStatus(err, http.StatusInternalServerError), err.Error())
+		return
+	}
+
+	canExecute, err := h.executionService.CanExecuteTask(r.Context(), task)
+	if err != nil {
+		utils.WriteErrorResponse(w, serviceErrorStatus(err, http.StatusInternalServerError), err.Error())
+		return
+	}
+	running := len(activeExecutions) > 0
+
+	utils.WriteJSONResponse(w, http.StatusOK, map[string]interface{}{
+		"task_id":           task.ID,
+		"task_title":        task.Title,
+		"can_execute":       canExecute,
+		"execution_command": "Выполнить задачу " + task.Number,
+		"active_executions": len(activeExecutions),
+		"button_config": map[string]interface{}{
+			"enabled": canExecute && !running,
+			"text":    getExecuteButtonText(task, running),
+			"variant": getExecuteButtonVariant(task, running),
+			"icon":    getExecuteButtonIcon(task, running),
+			"tooltip": getExecuteButtonTooltip(task, running),
+		},
+	})
+}
+
+func mapExecutionToResponse(execution *models.TaskExecution) ExecutionStatusResponse {
+	response := ExecutionStatusResponse{
+		ID:        execution.ID,
+		Status:    execution.Status,
+		Progress:  execution.Progress,
+		Message:   execution.Message,
+		TaskID:    execution.TaskID,
+		TaskTitle: execution.TaskTitle,
+		Strategy:  execution.Strategy,
+		StartTime: execution.StartedAt,
+		EndTime:   execution.FinishedAt,
+		Error:     execution.Error,
+		Steps:     []ExecutionStepStatus{},
+		Logs:      execution.Logs,
+		Result:    execution.Result,
+	}
+
+	if execution.FinishedAt != nil {
+		executionTime := execution.FinishedAt.Sub(execution.StartedAt).Milliseconds()
+		response.ExecutionTime = &executionTime
+	}
+
+	for _, step := range execution.Steps {
+		response.Steps = append(response.Steps, ExecutionStepStatus{
+			ID:          step.ID,
+			Name:        step.Name,
+			Status:      step.Status,
+			StartTime:   step.StartTime,
+			EndTime:     step.EndTime,
+			Error:       step.Error,
+			Description: step.Description,
+		})
+	}
+
+	return response
+}
+
+func getExecuteButtonText(task *models.Task, running bool) string {
+	if running {
+		return "Выполняется..."
+	}
+
+	switch task.Status {
+	case string(models.TaskStatusNew):
+		return "Выполнить задачу"
+	case string(models.TaskStatusInProgress):
+		return "Продолжить выполнение"
+	case string(models.TaskStatusDone):
+		return "Переопределить"
+	default:
+		return "Выполнить"
+	}
+}
+
+func getExecuteButtonVariant(task *models.Task, running bool) string {
+	if running {
+		return "secondary"
+	}
+
+	switch task.Priority {
+	case string(models.TaskPriorityCritical):
+		return "danger"
+	case string(models.TaskPriorityHigh):
+		return "warning"
+	default:
+		return "primary"
+	}
+}
+
+func getExecuteButtonIcon(task *models.Task, running bool) string {
+	if running {
+		return "loading"
+	}
+
+	switch task.Type {
+	case string(models.TaskTypeBugfix):
+		return "bug"
+	case string(models.TaskTypeNewFeature):
+		return "plus"
+	case string(models.TaskTypeDocumentation):
+		return "book"
+	default:
+		return "play"
+	}
+}
+
+func getExecuteButtonTooltip(task *models.Task, running bool) string {
+	if running {
+		return "Задача уже выполняется"
+	}
+
+	if task.Status == string(models.TaskStatusDone) {
+		return "Задача уже выполнена. Нажмите для переопределения."
+	}
+
+	return "Запустить автоматическое выполнение задачи"
+}
@@ -3,7 +3,12 @@ package handlers
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
 
 	"project-manager/models"
 	"project-manager/services"
@@ -14,10 +19,11 @@ import (
 
 type TaskHandler struct {
 	service *services.TaskService
+	links   *services.TaskDocumentLinkService
 }
 
-func NewTaskHandler(service *services.TaskService) *TaskHandler {
-	return &TaskHandler{service: service}
+func NewTaskHandler(service *services.TaskService, links *services.TaskDocumentLinkService) *TaskHandler {
+	return &TaskHandler{service: service, links: links}
 }
 
 func (h *TaskHandler) CreateTask(w http.ResponseWriter, r *http.Request) {
@@ -27,11 +33,16 @@ func (h *TaskHandler) CreateTask(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Связь с документом задается только при создании задач из документа
+	task.SourceDocumentID = nil
+	task.SourceSectionAnchor = nil
+
 	if err := h.service.CreateTask(r.Context(), &task); err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		http.Error(w, err.Error(), serviceErrorStatus(err, http.StatusBadRequest))
 		return
 	}
 
+	setETag(w, task.Version)
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusCreated)
 	json.NewEncoder(w).Encode(task)
@@ -42,7 +53,7 @@ func (h *TaskHandler) GetTask(w http.ResponseWriter, r *http.Request) {
 
 	task, err := h.service.GetTaskByID(r.Context(), id)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		http.Error(w, err.Error(), serviceErrorStatus(err, http.StatusInternalServerError))
 		return
 	}
 
@@ -51,8 +62,16 @@ func (h *TaskHandler) GetTask(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Связанные документы нужны исполнителю задачи вместе с ее описанием
+	linked, err := h.links.GetLinkedDocuments(r.Context(), task.ID)
+	if err != nil {
+		http.Error(w, err.Error(), serviceErrorStatus(err, http.StatusInternalServerError))
+		return
+	}
+
+	setETag(w, task.Version)
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(task)
+	json.NewEncoder(w).Encode(models.TaskDetails{Task: *task, LinkedDocuments: linked})
 }
 
 func (h *TaskHandler) GetTaskByNumber(w http.ResponseWriter, r *http.Request) {
@@ -60,7 +79,7 @@ func (h *TaskHandler) GetTaskByNumber(w http.ResponseWriter, r *http.Request) {
 
 	task, err := h.service.GetTaskByNumber(r.Context(), number)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		http.Error(w, err.Error(), serviceErrorStatus(err, http.StatusInternalServerError))
 		return
 	}
 
@@ -73,15 +92,135 @@ func (h *TaskHandler) GetTaskByNumber(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(task)
 }
 
+// GetAllTasks возвращает задачи с фильтрацией, сортировкой и пагинацией.
+// Общее количество передается в заголовке X-Total-Count, курсор следующей
+// страницы — в X-Next-Cursor.
+// GET /api/v1/tasks?project_id=&status=&priority=&type=&assignee_id=&q=&sort=-created_at&limit=&offset=&cursor=
 func (h *TaskHandler) GetAllTasks(w http.ResponseWriter, r *http.Request) {
-	tasks, err := h.service.GetAllTasks(r.Context())
+	filter, err := parseTaskFilter(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	page, err := h.service.FindTasks(r.Context(), filter)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		if errors.Is(err, services.ErrInvalidCursor) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		http.Error(w, err.Error(), serviceErrorStatus(err, http.StatusInternalServerError))
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(tasks)
+	w.Header().Set("X-Total-Count", strconv.Itoa(page.Total))
+	if page.NextCursor != "" {
+		w.Header().Set("X-Next-Cursor", page.NextCursor)
+	}
+	json.NewEncoder(w).Encode(page.Tasks)
+}
+
+// parseTaskFilter разбирает параметры запроса списка задач.
+// Списочные параметры (status, priority, type) принимаются как повторяющиеся
+// или через запятую; sort=-field задает сортировку по убыванию.
+func parseTaskFilter(query url.Values) (*models.TaskFilter, error) {
+	filter := &models.TaskFilter{
+		ProjectID:         query.Get("project_id"),
+		FunctionalBlockID: query.Get("functional_block_id"),
+		Statuses:          parseListParam(query, "status"),
+		Priorities:        parseListParam(query, "priority"),
+		Types:             parseListParam(query, "type"),
+		Role:              query.Get("role"),
+		AssigneeID:        query.Get("assignee_id"),
+		ParentTaskID:      query.Get("parent_task_id"),
+		Query:             query.Get("q"),
+		SortOrder:         strings.ToLower(query.Get("order")),
+		Cursor:            query.Get("cursor"),
+	}
+
+	sort := query.Get("sort")
+	if strings.HasPrefix(sort, "-") {
+		sort = strings.TrimPrefix(sort, "-")
+		filter.SortOrder = models.SortOrderDesc
+	}
+	filter.SortBy = sort
+
+	dates := []struct {
+		name   string
+		target **time.Time
+		endOf  bool
+	}{
+		{"created_from", &filter.CreatedFrom, false},
+		{"created_to", &filter.CreatedTo, true},
+		{"updated_from", &filter.UpdatedFrom, false},
+		{"updated_to", &filter.UpdatedTo, true},
+	}
+	for _, date := range dates {
+		value, err := parseDateParam(query.Get(date.name), date.endOf)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", date.name, err)
+		}
+		*date.target = value
+	}
+
+	var err error
+	if filter.Limit, err = parseIntParam(query, "limit"); err != nil {
+		return nil, err
+	}
+	if filter.Offset, err = parseIntParam(query, "offset"); err != nil {
+		return nil, err
+	}
+
+	if err := filter.Validate(); err != nil {
+		return nil, err
+	}
+	return filter, nil
+}
+
+// parseListParam собирает значения повторяющегося параметра или параметра через запятую
+func parseListParam(query url.Values, name string) []string {
+	var values []string
+	for _, raw := range query[name] {
+		for _, value := range strings.Split(raw, ",") {
+			if value = strings.TrimSpace(value); value != "" {
+				values = append(values, value)
+			}
+		}
+	}
+	return values
+}
+
+// parseIntParam разбирает необязательный целочисленный параметр
+func parseIntParam(query url.Values, name string) (int, error) {
+	raw := query.Get(name)
+	if raw == "" {
+		return 0, nil
+	}
+	value, err := strconv.Atoi(raw)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s: must be an integer", name)
+	}
+	return value, nil
+}
+
+// parseDateParam разбирает дату в формате RFC3339 или YYYY-MM-DD.
+// Для верхней границы дата без времени означает конец дня.
+func parseDateParam(raw string, endOfDay bool) (*time.Time, error) {
+	if raw == "" {
+		return nil, nil
+	}
+	if value, err := time.Parse(time.RFC3339, raw); err == nil {
+		return &value, nil
+	}
+	value, err := time.Parse("2006-01-02", raw)
+	if err != nil {
+		return nil, errors.New("expected RFC3339 or YYYY-MM-DD")
+	}
+	if endOfDay {
+		value = value.Add(24*time.Hour - time.Nanosecond)
+	}
+	return &value, nil
 }
 
 func (h *TaskHandler) GetTasksByProject(w http.ResponseWriter, r *http.Request) {
@@ -89,7 +228,7 @@ func (h *TaskHandler) GetTasksByProject(w http.ResponseWriter, r *http.Request)
 
 	tasks, err := h.service.GetTasksByProjectID(r.Context(), projectID)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		http.Error(w, err.Error(), serviceErrorStatus(err, http.StatusBadRequest))
 		return
 	}
 
@@ -106,7 +245,7 @@ func (h *TaskHandler) GetTasksByStatus(w http.ResponseWriter, r *http.Request) {
 
 	tasks, err := h.service.GetTasksByStatus(r.Context(), status)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		http.Error(w, err.Error(), serviceErrorStatus(err, http.StatusBadRequest))
 		return
 	}
 
@@ -119,7 +258,7 @@ func (h *TaskHandler) GetTasksByFunctionalBlock(w http.ResponseWriter, r *http.R
 
 	tasks, err := h.service.GetTasksByFunctionalBlockID(r.Context(), functionalBlockID)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		http.Error(w, err.Error(), serviceErrorStatus(err, http.StatusBadRequest))
 		return
 	}
 
@@ -138,19 +277,75 @@ func (h *TaskHandler) UpdateTask(w http.ResponseWriter, r *http.Request) {
 
 	task.ID = id // Устанавливаем ID из URL
 
-	if err := h.service.UpdateTask(r.Context(), &task); err != nil {
+	h.updateTask(w, r, &task)
+}
+
+// PatchTask частично обновляет задачу по JSON Merge Patch (RFC 7396):
+// меняются только переданные поля
+func (h *TaskHandler) PatchTask(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+
+	current, err := h.service.GetTaskByID(r.Context(), id)
+	if err != nil {
+		http.Error(w, err.Error(), serviceErrorStatus(err, http.StatusInternalServerError))
+		return
+	}
+	if current == nil {
+		http.Error(w, "Task not found", http.StatusNotFound)
+		return
+	}
+
+	task, err := decodeMergePatch(r, current)
+	if err != nil {
+		http.Error(w, "Invalid merge patch: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	task.ID = id // Устанавливаем ID из URL
+
+	h.updateTask(w, r, task)
+}
+
+// updateTask сохраняет задачу и отвечает ее новым состоянием; общий путь PUT и PATCH
+func (h *TaskHandler) updateTask(w http.ResponseWriter, r *http.Request, task *models.Task) {
+	// If-Match имеет приоритет над версией из тела запроса
+	version, err := parseIfMatch(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if version != 0 {
+		task.Version = version
+	}
+
+	if err := h.service.UpdateTask(r.Context(), task); err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			http.Error(w, "Task not found", http.StatusNotFound)
 			return
 		}
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		if errors.Is(err, services.ErrVersionConflict) {
+			h.writeTaskConflict(w, r, task.ID, err)
+			return
+		}
+		http.Error(w, err.Error(), serviceErrorStatus(err, http.StatusBadRequest))
 		return
 	}
 
+	setETag(w, task.Version)
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(task)
 }
 
+// writeTaskConflict отвечает 409 с актуальной версией задачи
+func (h *TaskHandler) writeTaskConflict(w http.ResponseWriter, r *http.Request, id string, err error) {
+	current, getErr := h.service.GetTaskByID(r.Context(), id)
+	if getErr != nil || current == nil {
+		writeVersionConflict(w, err, nil, 0)
+		return
+	}
+	writeVersionConflict(w, err, current, current.Version)
+}
+
 func (h *TaskHandler) DeleteTask(w http.ResponseWriter, r *http.Request) {
 	id := chi.URLParam(r, "id")
 
@@ -159,19 +354,28 @@ func (h *TaskHandler) DeleteTask(w http.ResponseWriter, r *http.Request) {
 			http.Error(w, "Task not found", http.StatusNotFound)
 			return
 		}
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		http.Error(w, err.Error(), serviceErrorStatus(err, http.StatusInternalServerError))
 		return
 	}
 
 	w.WriteHeader(http.StatusNoContent)
 }
 
-// GetValidStatuses возвращает список валидных статусов
+// GetValidStatuses возвращает статусы и переходы процесса; с project_id —
+// процесса этого проекта, иначе процесса по умолчанию
 func (h *TaskHandler) GetValidStatuses(w http.ResponseWriter, r *http.Request) {
-	statuses := h.service.GetValidStatuses()
+	workflow, err := h.service.GetWorkflow(r.Context(), r.URL.Query().Get("project_id"))
+	if err != nil {
+		http.Error(w, err.Error(), serviceErrorStatus(err, http.StatusInternalServerError))
+		return
+	}
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string][]string{"statuses": statuses})
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"statuses":     workflow.States,
+		"initialState": workflow.InitialState,
+		"transitions":  workflow.Transitions,
+	})
 }
 
 // GetValidPriorities возвращает список валидных приоритетов
@@ -189,3 +393,114 @@ func (h *TaskHandler) GetValidTypes(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string][]string{"types": types})
 }
+
+// GetDeletedTasks возвращает содержимое корзины
+// GET /api/v1/tasks/trash?project_id=
+func (h *TaskHandler) GetDeletedTasks(w http.ResponseWriter, r *http.Request) {
+	tasks, err := h.service.GetDeletedTasks(r.Context(), r.URL.Query().Get("project_id"))
+	if err != nil {
+		http.Error(w, err.Error(), serviceErrorStatus(err, http.StatusInternalServerError))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(tasks)
+}
+
+// AssignTask назначает задаче исполнителя; If-Match задает ожидаемую версию задачи
+// POST /api/v1/tasks/{id}/assignee
+func (h *TaskHandler) AssignTask(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		ExecutorID string `json:"executorId"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid JSON: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	version, err := parseIfMatch(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	id := chi.URLParam(r, "id")
+	task, err := h.service.AssignTask(r.Context(), id, req.ExecutorID, version)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			http.Error(w, "Task not found", http.StatusNotFound)
+			return
+		}
+		if errors.Is(err, services.ErrVersionConflict) {
+			h.writeTaskConflict(w, r, id, err)
+			return
+		}
+		http.Error(w, err.Error(), serviceErrorStatus(err, http.StatusBadRequest))
+		return
+	}
+
+	setETag(w, task.Version)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(task)
+}
+
+// UnassignTask снимает назначение исполнителя с задачи; If-Match задает ожидаемую версию
+// DELETE /api/v1/tasks/{id}/assignee
+func (h *TaskHandler) UnassignTask(w http.ResponseWriter, r *http.Request) {
+	version, err := parseIfMatch(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	id := chi.URLParam(r, "id")
+	task, err := h.service.UnassignTask(r.Context(), id, version)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			http.Error(w, "Task not found", http.StatusNotFound)
+			return
+		}
+		if errors.Is(err, services.ErrVersionConflict) {
+			h.writeTaskConflict(w, r, id, err)
+			return
+		}
+		http.Error(w, err.Error(), serviceErrorStatus(err, http.StatusBadRequest))
+		return
+	}
+
+	setETag(w, task.Version)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(task)
+}
+
+// RestoreTask возвращает задачу из корзины
+// POST /api/v1/tasks/{id}/restore
+func (h *TaskHandler) RestoreTask(w http.ResponseWriter, r *http.Request) {
+	task, err := h.service.RestoreTask(r.Context(), chi.URLParam(r, "id"))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			http.Error(w, "Task not found in trash", http.StatusNotFound)
+			return
+		}
+		http.Error(w, err.Error(), serviceErrorStatus(err, http.StatusBadRequest))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(task)
+}
+
+// PurgeTask окончательно удаляет задачу из корзины
+// DELETE /api/v1/tasks/{id}/purge
+func (h *TaskHandler) PurgeTask(w http.ResponseWriter, r *http.Request) {
+	if err := h.service.PurgeTask(r.Context(), chi.URLParam(r, "id")); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			http.Error(w, "Task not found in trash", http.StatusNotFound)
+			return
+		}
+		http.Error(w, err.Error(), serviceErrorStatus(err, http.StatusBadRequest))
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
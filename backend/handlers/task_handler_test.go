@@ -0,0 +1,92 @@
+package handlers
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"project-manager/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTaskFilter(t *testing.T) {
+	query := url.Values{
+		"project_id":   {"8b1f3c1e-0000-4000-8000-000000000001"},
+		"status":       {"new, in_progress", "done"},
+		"priority":     {"Высокий"},
+		"sort":         {"-updated_at"},
+		"created_from": {"2024-01-10"},
+		"created_to":   {"2024-01-20"},
+		"updated_from": {"2024-01-15T10:00:00Z"},
+		"limit":        {"20"},
+		"cursor":       {"abc"},
+	}
+
+	filter, err := parseTaskFilter(query)
+	require.NoError(t, err)
+
+	assert.Equal(t, "8b1f3c1e-0000-4000-8000-000000000001", filter.ProjectID)
+	assert.Equal(t, []string{"new", "in_progress", "done"}, filter.Statuses)
+	assert.Equal(t, []string{"Высокий"}, filter.Priorities)
+	assert.Nil(t, filter.Types)
+	assert.Equal(t, "updated_at", filter.SortBy)
+	assert.Equal(t, models.SortOrderDesc, filter.SortOrder)
+	assert.Equal(t, 20, filter.Limit)
+	assert.Equal(t, "abc", filter.Cursor)
+
+	// Дата без времени в верхней границе означает конец дня
+	assert.Equal(t, time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC), *filter.CreatedFrom)
+	assert.Equal(t, time.Date(2024, 1, 20, 23, 59, 59, 999999999, time.UTC), *filter.CreatedTo)
+	assert.Equal(t, time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC), *filter.UpdatedFrom)
+	assert.Nil(t, filter.UpdatedTo)
+}
+
+func TestParseTaskFilterSortOrder(t *testing.T) {
+	filter, err := parseTaskFilter(url.Values{"sort": {"title"}, "order": {"DESC"}})
+	require.NoError(t, err)
+	assert.Equal(t, "title", filter.SortBy)
+	assert.Equal(t, models.SortOrderDesc, filter.SortOrder)
+
+	filter, err = parseTaskFilter(url.Values{})
+	require.NoError(t, err)
+	assert.Empty(t, filter.SortBy)
+	assert.Empty(t, filter.SortOrder)
+	assert.Zero(t, filter.Limit)
+}
+
+func TestParseTaskFilterNoneReferences(t *testing.T) {
+	filter, err := parseTaskFilter(url.Values{
+		"functional_block_id": {models.TaskFilterNone},
+		"assignee_id":         {models.TaskFilterNone},
+		"parent_task_id":      {models.TaskFilterNone},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, models.TaskFilterNone, filter.FunctionalBlockID)
+	assert.Equal(t, models.TaskFilterNone, filter.AssigneeID)
+	assert.Equal(t, models.TaskFilterNone, filter.ParentTaskID)
+}
+
+func TestParseTaskFilterErrors(t *testing.T) {
+	cases := map[string]url.Values{
+		"date":        {"created_from": {"10.01.2024"}},
+		"limit":       {"limit": {"ten"}},
+		"offset":      {"offset": {"-1"}},
+		"priority":    {"priority": {"Срочный"}},
+		"type":        {"type": {"unknown"}},
+		"sort field":  {"sort": {"assignee"}},
+		"sort order":  {"order": {"sideways"}},
+		"long status": {"status": {"ssssssssssssssssssssssssssssssssssssssssssssssssssss"}},
+		"project":     {"project_id": {"p1"}},
+		"block":       {"functional_block_id": {"auth"}},
+		"assignee":    {"assignee_id": {"42"}},
+		"parent":      {"parent_task_id": {"TASK-1"}},
+	}
+	for name, query := range cases {
+		t.Run(name, func(t *testing.T) {
+			_, err := parseTaskFilter(query)
+			assert.Error(t, err)
+		})
+	}
+}
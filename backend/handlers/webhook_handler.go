@@ -0,0 +1,141 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"project-manager/models"
+	"project-manager/services"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/jackc/pgx/v5"
+)
+
+type WebhookHandler struct {
+	service *services.WebhookService
+}
+
+func NewWebhookHandler(service *services.WebhookService) *WebhookHandler {
+	return &WebhookHandler{service: service}
+}
+
+// GetProjectWebhooks возвращает вебхуки проекта
+// GET /api/v1/projects/{projectID}/webhooks
+func (h *WebhookHandler) GetProjectWebhooks(w http.ResponseWriter, r *http.Request) {
+	webhooks, err := h.service.GetProjectWebhooks(r.Context(), chi.URLParam(r, "projectID"))
+	if err != nil {
+		http.Error(w, err.Error(), serviceErrorStatus(err, http.StatusBadRequest))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(webhooks)
+}
+
+// CreateWebhook создает вебхук проекта; секрет возвращается только в этом ответе
+// POST /api/v1/projects/{projectID}/webhooks
+func (h *WebhookHandler) CreateWebhook(w http.ResponseWriter, r *http.Request) {
+	webhook := models.Webhook{IsActive: true}
+	if err := json.NewDecoder(r.Body).Decode(&webhook); err != nil {
+		http.Error(w, "Invalid JSON: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	webhook.ProjectID = chi.URLParam(r, "projectID")
+
+	if err := h.service.CreateWebhook(r.Context(), &webhook); err != nil {
+		http.Error(w, err.Error(), webhookErrorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(webhook)
+}
+
+// GetWebhook возвращает вебхук
+// GET /api/v1/webhooks/{id}
+func (h *WebhookHandler) GetWebhook(w http.ResponseWriter, r *http.Request) {
+	webhook, err := h.service.GetWebhook(r.Context(), chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, err.Error(), webhookErrorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(webhook)
+}
+
+// UpdateWebhook изменяет вебхук; пустой secret оставляет прежний
+// PUT /api/v1/webhooks/{id}
+func (h *WebhookHandler) UpdateWebhook(w http.ResponseWriter, r *http.Request) {
+	var webhook models.Webhook
+	if err := json.NewDecoder(r.Body).Decode(&webhook); err != nil {
+		http.Error(w, "Invalid JSON: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	webhook.ID = chi.URLParam(r, "id")
+
+	if err := h.service.UpdateWebhook(r.Context(), &webhook); err != nil {
+		http.Error(w, err.Error(), webhookErrorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(webhook)
+}
+
+// DeleteWebhook удаляет вебхук вместе с журналом доставок
+// DELETE /api/v1/webhooks/{id}
+func (h *WebhookHandler) DeleteWebhook(w http.ResponseWriter, r *http.Request) {
+	if err := h.service.DeleteWebhook(r.Context(), chi.URLParam(r, "id")); err != nil {
+		http.Error(w, err.Error(), webhookErrorStatus(err))
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetDeliveries возвращает журнал последних доставок вебхука
+// GET /api/v1/webhooks/{id}/deliveries
+func (h *WebhookHandler) GetDeliveries(w http.ResponseWriter, r *http.Request) {
+	deliveries, err := h.service.GetDeliveries(r.Context(), chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, err.Error(), webhookErrorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(deliveries)
+}
+
+// Redeliver ставит доставку в очередь повторно
+// POST /api/v1/webhooks/{id}/deliveries/{deliveryID}/redeliver
+func (h *WebhookHandler) Redeliver(w http.ResponseWriter, r *http.Request) {
+	delivery, err := h.service.Redeliver(r.Context(), chi.URLParam(r, "id"), chi.URLParam(r, "deliveryID"))
+	if err != nil {
+		http.Error(w, err.Error(), webhookErrorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(delivery)
+}
+
+// GetValidEventTypes возвращает типы операций, на которые можно подписать вебхук
+// GET /api/v1/webhooks/event-types
+func (h *WebhookHandler) GetValidEventTypes(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(models.ValidWebhookEventTypes())
+}
+
+// webhookErrorStatus возвращает HTTP-код для ошибок сервиса вебхуков:
+// отсутствие проекта, вебхука или доставки соответствует 404
+func webhookErrorStatus(err error) int {
+	if errors.Is(err, pgx.ErrNoRows) || strings.HasSuffix(err.Error(), "not found") {
+		return http.StatusNotFound
+	}
+	return serviceErrorStatus(err, http.StatusBadRequest)
+}
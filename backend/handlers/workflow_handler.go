@@ -0,0 +1,63 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"project-manager/models"
+	"project-manager/services"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type WorkflowHandler struct {
+	service *services.WorkflowService
+}
+
+func NewWorkflowHandler(service *services.WorkflowService) *WorkflowHandler {
+	return &WorkflowHandler{service: service}
+}
+
+// GetWorkflow возвращает процесс статусов проекта
+// GET /api/v1/projects/{projectID}/workflow
+func (h *WorkflowHandler) GetWorkflow(w http.ResponseWriter, r *http.Request) {
+	workflow, err := h.service.GetWorkflow(r.Context(), chi.URLParam(r, "projectID"))
+	if err != nil {
+		http.Error(w, err.Error(), serviceErrorStatus(err, http.StatusInternalServerError))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(workflow)
+}
+
+// SetWorkflow задаёт процесс статусов проекта
+// PUT /api/v1/projects/{projectID}/workflow
+func (h *WorkflowHandler) SetWorkflow(w http.ResponseWriter, r *http.Request) {
+	var workflow models.Workflow
+	if err := json.NewDecoder(r.Body).Decode(&workflow); err != nil {
+		http.Error(w, "Invalid JSON: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	workflow.ProjectID = chi.URLParam(r, "projectID")
+
+	if err := h.service.SetWorkflow(r.Context(), &workflow); err != nil {
+		http.Error(w, err.Error(), serviceErrorStatus(err, http.StatusBadRequest))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(workflow)
+}
+
+// ResetWorkflow возвращает проекту процесс по умолчанию
+// DELETE /api/v1/projects/{projectID}/workflow
+func (h *WorkflowHandler) ResetWorkflow(w http.ResponseWriter, r *http.Request) {
+	if err := h.service.ResetWorkflow(r.Context(), chi.URLParam(r, "projectID")); err != nil {
+		http.Error(w, err.Error(), serviceErrorStatus(err, http.StatusBadRequest))
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
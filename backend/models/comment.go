@@ -3,9 +3,58 @@ package models
 import "time"
 
 type Comment struct {
-	ID             string    `json:"id" db:"id"`
-	TaskID         string    `json:"taskId" db:"task_id"`
-	UserIdentifier string    `json:"userIdentifier" db:"user_identifier"`
-	Content        string    `json:"content" db:"content"`
-	CreatedAt      time.Time `json:"createdAt" db:"created_at"`
+	ID              string     `json:"id" db:"id"`
+	TaskID          string     `json:"taskId" db:"task_id"`
+	ParentCommentID *string    `json:"parentCommentId" db:"parent_comment_id"`
+	Type            string     `json:"type" db:"type"`
+	UserIdentifier  string     `json:"userIdentifier" db:"user_identifier"`
+	Content         string     `json:"content" db:"content"`
+	CreatedAt       time.Time  `json:"createdAt" db:"created_at"`
+	EditedAt        *time.Time `json:"editedAt" db:"edited_at"`
+	DeletedAt       *time.Time `json:"deletedAt,omitempty" db:"deleted_at"`
+}
+
+// CommentType определяет происхождение комментария
+type CommentType string
+
+const (
+	CommentTypeUser   CommentType = "user"
+	CommentTypeSystem CommentType = "system"
+	CommentTypeAgent  CommentType = "agent"
+	CommentTypeReview CommentType = "review"
+)
+
+// ValidCommentTypes возвращает список типов комментариев
+func ValidCommentTypes() []string {
+	return []string{
+		string(CommentTypeUser),
+		string(CommentTypeSystem),
+		string(CommentTypeAgent),
+		string(CommentTypeReview),
+	}
+}
+
+// IsValidCommentType проверяет валидность типа комментария
+func IsValidCommentType(commentType string) bool {
+	for _, validType := range ValidCommentTypes() {
+		if commentType == validType {
+			return true
+		}
+	}
+	return false
+}
+
+// CommentThread — комментарий с ответами на него
+type CommentThread struct {
+	Comment
+	Replies []CommentThread `json:"replies"`
+}
+
+// CommentEdit — предыдущая версия текста комментария
+type CommentEdit struct {
+	ID              string    `json:"id" db:"id"`
+	CommentID       string    `json:"commentId" db:"comment_id"`
+	PreviousContent string    `json:"previousContent" db:"previous_content"`
+	EditedBy        string    `json:"editedBy" db:"edited_by"`
+	EditedAt        time.Time `json:"editedAt" db:"edited_at"`
 }
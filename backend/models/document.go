@@ -9,6 +9,7 @@ type Document struct {
 	Title         string    `json:"title" db:"title"`
 	Content       string    `json:"content" db:"content"`
 	AgentEditable bool      `json:"agentEditable" db:"agent_editable"`
+	Version       int       `json:"version" db:"version"`
 	CreatedAt     time.Time `json:"createdAt" db:"created_at"`
 	UpdatedAt     time.Time `json:"updatedAt" db:"updated_at"`
 }
@@ -0,0 +1,36 @@
+package models
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+)
+
+// DocumentRevision — неизменяемый снимок документа после очередного сохранения.
+// Revision совпадает с версией документа, полученной при этом сохранении.
+type DocumentRevision struct {
+	ID           string    `json:"id" db:"id"`
+	DocumentID   string    `json:"documentId" db:"document_id"`
+	Revision     int       `json:"revision" db:"revision"`
+	Type         string    `json:"type" db:"type"`
+	Title        string    `json:"title" db:"title"`
+	Content      string    `json:"content,omitempty" db:"content"`
+	ContentHash  string    `json:"contentHash" db:"content_hash"`
+	Author       string    `json:"author" db:"author"`
+	RestoredFrom *int      `json:"restoredFrom,omitempty" db:"restored_from"`
+	CreatedAt    time.Time `json:"createdAt" db:"created_at"`
+}
+
+// DocumentDiff — построчная разница содержимого двух ревизий документа
+type DocumentDiff struct {
+	DocumentID string `json:"documentId"`
+	From       int    `json:"from"`
+	To         int    `json:"to"`
+	Diff       string `json:"diff"`
+}
+
+// ContentHash возвращает SHA-256 содержимого документа в шестнадцатеричном виде
+func ContentHash(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,153 @@
+package models
+
+import (
+	"regexp"
+	"strings"
+	"time"
+)
+
+// BuiltinTemplatePrefix — префикс идентификаторов встроенных шаблонов
+const BuiltinTemplatePrefix = "builtin-"
+
+// Переменные, которые подставляются в шаблон при создании документа
+const (
+	TemplateVarProjectName        = "project.name"
+	TemplateVarProjectDescription = "project.description"
+	TemplateVarProjectStatus      = "project.status"
+	TemplateVarDate               = "date"
+	TemplateVarAuthor             = "author"
+)
+
+// templateVariablePattern распознает подстановки вида {{ project.name }}
+var templateVariablePattern = regexp.MustCompile(`\{\{\s*([A-Za-z0-9_.]+)\s*\}\}`)
+
+// DocumentTemplate — заготовка содержимого документа определенного типа
+type DocumentTemplate struct {
+	ID          string     `json:"id" db:"id"`
+	Type        string     `json:"type" db:"type"`
+	Name        string     `json:"name" db:"name"`
+	Description string     `json:"description" db:"description"`
+	Content     string     `json:"content" db:"content"`
+	IsBuiltin   bool       `json:"isBuiltin"`
+	CreatedBy   string     `json:"createdBy,omitempty" db:"created_by"`
+	CreatedAt   *time.Time `json:"createdAt,omitempty" db:"created_at"`
+	UpdatedAt   *time.Time `json:"updatedAt,omitempty" db:"updated_at"`
+}
+
+// DocumentFromTemplate — запрос на создание документа проекта из шаблона.
+// Variables дополняют и переопределяют переменные проекта.
+type DocumentFromTemplate struct {
+	TemplateID    string            `json:"templateId"`
+	Title         string            `json:"title"`
+	AgentEditable bool              `json:"agentEditable"`
+	Variables     map[string]string `json:"variables,omitempty"`
+}
+
+// documentOutline — обязательная структура документа определенного типа
+type documentOutline struct {
+	title    string
+	sections []outlineSection
+}
+
+type outlineSection struct {
+	title string
+	hint  string
+}
+
+// documentOutlines задает разделы второго уровня, которые должен содержать
+// документ каждого типа; из них же собираются встроенные шаблоны
+var documentOutlines = map[DocumentType]documentOutline{
+	DocumentTypeBRD: {title: "Бизнес-требования", sections: []outlineSection{
+		{"Цели", "Какую бизнес-задачу решает проект и как измеряется успех."},
+		{"Заинтересованные стороны", "Кто использует систему и кто принимает решения."},
+		{"Функциональные требования", "Что система должна делать."},
+		{"Нефункциональные требования", "Производительность, надежность, безопасность."},
+		{"Критерии приемки", "Условия, при которых проект считается выполненным."},
+	}},
+	DocumentTypeSAD: {title: "Архитектура системы", sections: []outlineSection{
+		{"Обзор", "Назначение системы и ее границы."},
+		{"Компоненты", "Основные компоненты и их ответственность."},
+		{"Данные", "Модель данных и хранилища."},
+		{"Интеграции", "Внешние системы и протоколы взаимодействия."},
+		{"Развертывание", "Окружения, инфраструктура и порядок выкладки."},
+	}},
+	DocumentTypeAIReady: {title: "Постановка для AI-агента", sections: []outlineSection{
+		{"Контекст", "Что агенту нужно знать о проекте."},
+		{"Требования", "Что должно быть сделано."},
+		{"Ограничения", "Чего делать нельзя и какие соглашения соблюдать."},
+		{"Критерии приемки", "Как проверить результат."},
+	}},
+	DocumentTypeAIExecutable: {title: "План выполнения", sections: []outlineSection{
+		{"Контекст", "Что агенту нужно знать о проекте."},
+		{"Задачи", "Каждая задача — подраздел третьего уровня с заголовком задачи и ее описанием."},
+		{"Критерии приемки", "Как проверить результат."},
+	}},
+	DocumentTypeTechnical: {title: "Техническое описание", sections: []outlineSection{
+		{"Обзор", "Что описывает документ."},
+		{"Детали реализации", "Как устроено решение."},
+		{"Ограничения", "Известные ограничения и компромиссы."},
+	}},
+	DocumentTypeUserGuide: {title: "Руководство пользователя", sections: []outlineSection{
+		{"Введение", "Для кого руководство и что в нем описано."},
+		{"Начало работы", "Установка и первые шаги."},
+		{"Использование", "Основные сценарии работы."},
+		{"Частые вопросы", "Ответы на типовые вопросы."},
+	}},
+	DocumentTypeAPIDoc: {title: "Документация API", sections: []outlineSection{
+		{"Обзор", "Назначение API и базовый адрес."},
+		{"Аутентификация", "Как получить доступ к API."},
+		{"Эндпоинты", "Описание методов, параметров и ответов."},
+		{"Ошибки", "Коды ошибок и их значение."},
+	}},
+}
+
+// RequiredDocumentSections возвращает заголовки разделов, обязательных для типа документа
+func RequiredDocumentSections(docType string) []string {
+	outline, ok := documentOutlines[DocumentType(docType)]
+	if !ok {
+		return nil
+	}
+	titles := make([]string, 0, len(outline.sections))
+	for _, section := range outline.sections {
+		titles = append(titles, section.title)
+	}
+	return titles
+}
+
+// BuiltinDocumentTemplates возвращает встроенные шаблоны — по одному на каждый тип документа
+func BuiltinDocumentTemplates() []DocumentTemplate {
+	templates := make([]DocumentTemplate, 0, len(documentOutlines))
+	for _, docType := range ValidDocumentTypes() {
+		outline := documentOutlines[DocumentType(docType)]
+
+		var content strings.Builder
+		content.WriteString("# {{" + TemplateVarProjectName + "}}: " + outline.title + "\n\n")
+		content.WriteString("{{" + TemplateVarProjectDescription + "}}\n\n")
+		content.WriteString("_Автор: {{" + TemplateVarAuthor + "}}, {{" + TemplateVarDate + "}}_\n")
+		for _, section := range outline.sections {
+			content.WriteString("\n## " + section.title + "\n\n" + section.hint + "\n")
+		}
+
+		templates = append(templates, DocumentTemplate{
+			ID:          BuiltinTemplatePrefix + strings.ToLower(strings.ReplaceAll(docType, " ", "-")),
+			Type:        docType,
+			Name:        outline.title,
+			Description: "Встроенный шаблон документа " + docType,
+			Content:     content.String(),
+			IsBuiltin:   true,
+		})
+	}
+	return templates
+}
+
+// FillTemplate подставляет значения переменных в шаблон. Неизвестные
+// переменные остаются в тексте без изменений.
+func FillTemplate(content string, variables map[string]string) string {
+	return templateVariablePattern.ReplaceAllStringFunc(content, func(match string) string {
+		name := templateVariablePattern.FindStringSubmatch(match)[1]
+		if value, ok := variables[name]; ok {
+			return value
+		}
+		return match
+	})
+}
@@ -0,0 +1,35 @@
+package models
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuiltinDocumentTemplates_CoverRequiredSections(t *testing.T) {
+	templates := BuiltinDocumentTemplates()
+	assert.Len(t, templates, len(ValidDocumentTypes()))
+
+	for _, template := range templates {
+		assert.True(t, strings.HasPrefix(template.ID, BuiltinTemplatePrefix))
+		assert.True(t, template.IsBuiltin)
+
+		required := RequiredDocumentSections(template.Type)
+		assert.NotEmpty(t, required, template.Type)
+		for _, title := range required {
+			assert.Contains(t, template.Content, "\n## "+title+"\n", template.Type)
+		}
+	}
+}
+
+func TestFillTemplate(t *testing.T) {
+	content := "# {{project.name}}\n\n{{ project.description }} {{unknown}}"
+
+	filled := FillTemplate(content, map[string]string{
+		TemplateVarProjectName:        "Project Master",
+		TemplateVarProjectDescription: "Менеджер проектов",
+	})
+
+	assert.Equal(t, "# Project Master\n\nМенеджер проектов {{unknown}}", filled)
+}
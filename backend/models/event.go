@@ -0,0 +1,82 @@
+package models
+
+import "time"
+
+// EventType определяет вид события об изменении данных
+type EventType string
+
+const (
+	EventTaskCreated     EventType = "task.created"
+	EventTaskUpdated     EventType = "task.updated"
+	EventTaskDeleted     EventType = "task.deleted"
+	EventTaskRestored    EventType = "task.restored"
+	EventTaskPurged      EventType = "task.purged"
+	EventCommentCreated  EventType = "comment.created"
+	EventCommentUpdated  EventType = "comment.updated"
+	EventCommentDeleted  EventType = "comment.deleted"
+	EventDocumentCreated EventType = "document.created"
+	EventDocumentUpdated EventType = "document.updated"
+	EventDocumentDeleted EventType = "document.deleted"
+	EventPlanUpdated     EventType = "plan.updated"
+
+	// EventReset сообщает клиенту, что часть событий потеряна и данные нужно
+	// перечитать целиком. Отправляется при возобновлении потока.
+	EventReset EventType = "reset"
+)
+
+// ValidEventTypes возвращает список типов событий, на которые можно подписаться
+func ValidEventTypes() []string {
+	return []string{
+		string(EventTaskCreated),
+		string(EventTaskUpdated),
+		string(EventTaskDeleted),
+		string(EventTaskRestored),
+		string(EventTaskPurged),
+		string(EventCommentCreated),
+		string(EventCommentUpdated),
+		string(EventCommentDeleted),
+		string(EventDocumentCreated),
+		string(EventDocumentUpdated),
+		string(EventDocumentDeleted),
+		string(EventPlanUpdated),
+	}
+}
+
+// IsValidEventType проверяет валидность типа события
+func IsValidEventType(eventType string) bool {
+	for _, validType := range ValidEventTypes() {
+		if eventType == validType {
+			return true
+		}
+	}
+	return false
+}
+
+// Event — событие об изменении записи проекта. ID возрастает в пределах
+// работы одного экземпляра сервера и используется для Last-Event-ID.
+type Event struct {
+	ID        string      `json:"id"`
+	Type      EventType   `json:"type"`
+	ProjectID string      `json:"projectId"`
+	EntityID  string      `json:"entityId"`
+	Actor     string      `json:"actor"`
+	Data      interface{} `json:"data,omitempty"`
+	CreatedAt time.Time   `json:"createdAt"`
+}
+
+// EventFilter ограничивает события подписки проектом и типами; пустые поля не фильтруют
+type EventFilter struct {
+	ProjectID string
+	Types     map[EventType]bool
+}
+
+// Matches проверяет, проходит ли событие фильтр. Событие reset проходит всегда.
+func (f EventFilter) Matches(event *Event) bool {
+	if event.Type == EventReset {
+		return true
+	}
+	if f.ProjectID != "" && event.ProjectID != f.ProjectID {
+		return false
+	}
+	return len(f.Types) == 0 || f.Types[event.Type]
+}
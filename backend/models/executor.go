@@ -2,8 +2,39 @@ package models
 
 import "time"
 
+// ExecutorKind определяет, кто выполняет задачи: человек или агент
+type ExecutorKind string
+
+const (
+	ExecutorKindHuman ExecutorKind = "human"
+	ExecutorKindAgent ExecutorKind = "agent"
+)
+
+// ValidExecutorKinds возвращает список видов исполнителей
+func ValidExecutorKinds() []string {
+	return []string{
+		string(ExecutorKindHuman),
+		string(ExecutorKindAgent),
+	}
+}
+
+// IsValidExecutorKind проверяет валидность вида исполнителя
+func IsValidExecutorKind(kind string) bool {
+	for _, validKind := range ValidExecutorKinds() {
+		if kind == validKind {
+			return true
+		}
+	}
+	return false
+}
+
+// Executor — исполнитель, которому назначаются задачи. UserID связывает
+// исполнителя с учетной записью, чтобы он получал уведомления о назначениях.
 type Executor struct {
 	ID        string    `json:"id" db:"id"`
 	Name      string    `json:"name" db:"name"`
+	Kind      string    `json:"kind" db:"kind"`
+	UserID    *string   `json:"userId" db:"user_id"`
 	CreatedAt time.Time `json:"createdAt" db:"created_at"`
+	UpdatedAt time.Time `json:"updatedAt" db:"updated_at"`
 }
@@ -0,0 +1,84 @@
+package models
+
+import (
+	"reflect"
+	"strings"
+	"time"
+)
+
+// FieldChange — изменение одного поля записи
+type FieldChange struct {
+	Field  string      `json:"field"`
+	Before interface{} `json:"before"`
+	After  interface{} `json:"after"`
+}
+
+// diffIgnoredFields — служебные поля, которые меняются при каждом сохранении
+// и не попадают в историю изменений
+var diffIgnoredFields = map[string]bool{
+	"createdAt": true,
+	"updatedAt": true,
+	"version":   true,
+	"deletedAt": true,
+}
+
+// DiffFields сравнивает две версии записи одного типа и возвращает изменения
+// полей в порядке их объявления. Поля именуются по JSON-тегам, указатели
+// разыменовываются (nil сохраняется как null).
+func DiffFields(before, after interface{}) []FieldChange {
+	beforeValue := reflect.Indirect(reflect.ValueOf(before))
+	afterValue := reflect.Indirect(reflect.ValueOf(after))
+	if beforeValue.Kind() != reflect.Struct || beforeValue.Type() != afterValue.Type() {
+		return nil
+	}
+
+	changes := []FieldChange{}
+	structType := beforeValue.Type()
+	for i := 0; i < structType.NumField(); i++ {
+		field := structType.Field(i)
+		name := jsonFieldName(field)
+		if name == "" || diffIgnoredFields[name] {
+			continue
+		}
+
+		oldValue := plainValue(beforeValue.Field(i))
+		newValue := plainValue(afterValue.Field(i))
+		if reflect.DeepEqual(oldValue, newValue) {
+			continue
+		}
+		changes = append(changes, FieldChange{Field: name, Before: oldValue, After: newValue})
+	}
+	return changes
+}
+
+// jsonFieldName возвращает имя поля в JSON или пустую строку для
+// неэкспортируемых и скрытых полей
+func jsonFieldName(field reflect.StructField) string {
+	if !field.IsExported() {
+		return ""
+	}
+	tag := field.Tag.Get("json")
+	if tag == "-" {
+		return ""
+	}
+	if name, _, _ := strings.Cut(tag, ","); name != "" {
+		return name
+	}
+	return field.Name
+}
+
+// plainValue разыменовывает указатель и приводит время к UTC, чтобы
+// сравнение не зависело от часового пояса
+func plainValue(value reflect.Value) interface{} {
+	if value.Kind() == reflect.Pointer {
+		if value.IsNil() {
+			return nil
+		}
+		value = value.Elem()
+	}
+	result := value.Interface()
+	if t, ok := result.(time.Time); ok {
+		return t.UTC()
+	}
+	return result
+}
@@ -0,0 +1,57 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiffFields(t *testing.T) {
+	blockID := "block-1"
+	before := Task{
+		ID:                "task-1",
+		Title:             "Старое название",
+		Status:            string(TaskStatusNew),
+		Priority:          string(TaskPriorityLow),
+		FunctionalBlockID: &blockID,
+		Version:           1,
+		UpdatedAt:         time.Now(),
+	}
+	after := before
+	after.Title = "Новое название"
+	after.Priority = string(TaskPriorityHigh)
+	after.FunctionalBlockID = nil
+	after.Version = 2
+	after.UpdatedAt = before.UpdatedAt.Add(time.Minute)
+
+	changes := DiffFields(&before, &after)
+
+	assert.Equal(t, []FieldChange{
+		{Field: "functionalBlockId", Before: "block-1", After: nil},
+		{Field: "title", Before: "Старое название", After: "Новое название"},
+		{Field: "priority", Before: "Низкий", After: "Высокий"},
+	}, changes)
+}
+
+func TestDiffFieldsNoChanges(t *testing.T) {
+	document := Document{ID: "doc-1", Title: "SAD", Content: "text"}
+	copyDocument := document
+
+	assert.Empty(t, DiffFields(document, copyDocument))
+}
+
+func TestDiffFieldsDifferentTypes(t *testing.T) {
+	assert.Nil(t, DiffFields(Task{}, Document{}))
+}
+
+func TestDiffFieldsAssignee(t *testing.T) {
+	executorID := "executor-1"
+	before := Task{ID: "task-1", Title: "Задача"}
+	after := before
+	after.AssigneeID = &executorID
+
+	assert.Equal(t, []FieldChange{
+		{Field: "assigneeId", Before: nil, After: "executor-1"},
+	}, DiffFields(&before, &after))
+}
@@ -0,0 +1,102 @@
+package models
+
+import "time"
+
+// NotificationType определяет повод уведомления
+type NotificationType string
+
+const (
+	NotificationMention      NotificationType = "mention"
+	NotificationComment      NotificationType = "comment"
+	NotificationStatusChange NotificationType = "status_change"
+	NotificationAssignment   NotificationType = "assignment"
+)
+
+// ValidNotificationTypes возвращает список типов уведомлений
+func ValidNotificationTypes() []string {
+	return []string{
+		string(NotificationMention),
+		string(NotificationComment),
+		string(NotificationStatusChange),
+		string(NotificationAssignment),
+	}
+}
+
+// Notification — уведомление во входящих пользователя
+type Notification struct {
+	ID        string     `json:"id" db:"id"`
+	UserID    string     `json:"userId" db:"user_id"`
+	Type      string     `json:"type" db:"type"`
+	ProjectID *string    `json:"projectId" db:"project_id"`
+	TaskID    *string    `json:"taskId" db:"task_id"`
+	CommentID *string    `json:"commentId" db:"comment_id"`
+	Actor     string     `json:"actor" db:"actor"`
+	Message   string     `json:"message" db:"message"`
+	ReadAt    *time.Time `json:"readAt" db:"read_at"`
+	CreatedAt time.Time  `json:"createdAt" db:"created_at"`
+}
+
+// NotificationFilter описывает выборку входящих пользователя
+type NotificationFilter struct {
+	UserID     string
+	UnreadOnly bool
+	Limit      int
+	Offset     int
+}
+
+// NotificationPage — страница входящих вместе со счетчиком непрочитанных
+type NotificationPage struct {
+	Items  []Notification `json:"items"`
+	Unread int            `json:"unread"`
+	Limit  int            `json:"limit"`
+	Offset int            `json:"offset"`
+}
+
+// NotificationPreferences — какие события создают уведомления пользователю
+type NotificationPreferences struct {
+	UserID       string    `json:"userId" db:"user_id"`
+	Mention      bool      `json:"mention" db:"mention"`
+	Comment      bool      `json:"comment" db:"comment"`
+	StatusChange bool      `json:"statusChange" db:"status_change"`
+	Assignment   bool      `json:"assignment" db:"assignment"`
+	UpdatedAt    time.Time `json:"updatedAt" db:"updated_at"`
+}
+
+// DefaultNotificationPreferences возвращает настройки пользователя, который их не менял
+func DefaultNotificationPreferences(userID string) NotificationPreferences {
+	return NotificationPreferences{
+		UserID:       userID,
+		Mention:      true,
+		Comment:      true,
+		StatusChange: true,
+		Assignment:   true,
+	}
+}
+
+// NotificationRecipient — пользователь, которому может быть адресовано уведомление
+type NotificationRecipient struct {
+	UserID   string
+	Username string
+}
+
+// CommentMention — упомянутый в комментарии пользователь
+type CommentMention struct {
+	CommentID string `json:"commentId" db:"comment_id"`
+	UserID    string `json:"userId" db:"user_id"`
+	Username  string `json:"username" db:"username"`
+}
+
+// CommentTaskReference — ссылка из комментария одной задачи на другую задачу
+type CommentTaskReference struct {
+	CommentID    string `json:"commentId" db:"comment_id"`
+	SourceTaskID string `json:"sourceTaskId" db:"source_task_id"`
+	TaskID       string `json:"taskId" db:"task_id"`
+	TaskNumber   string `json:"taskNumber" db:"task_number"`
+	TaskTitle    string `json:"taskTitle" db:"task_title"`
+}
+
+// CommentReferences — упоминания и ссылки на задачи одного комментария
+type CommentReferences struct {
+	Mentions []CommentMention       `json:"mentions"`
+	Tasks    []CommentTaskReference `json:"tasks"`
+}
@@ -7,13 +7,78 @@ import (
 
 type OperationLog struct {
 	ID             string          `json:"id" db:"id"`
-	TaskID         string          `json:"taskId" db:"task_id"`
+	TaskID         *string         `json:"taskId" db:"task_id"`
+	EntityType     string          `json:"entityType" db:"entity_type"`
+	EntityID       string          `json:"entityId" db:"entity_id"`
 	UserIdentifier string          `json:"userIdentifier" db:"user_identifier"`
 	OperationType  string          `json:"operationType" db:"operation_type"`
 	Details        json.RawMessage `json:"details" db:"details"`
 	CreatedAt      time.Time       `json:"createdAt" db:"created_at"`
 }
 
+// LogEntityType определяет типы записей, операции над которыми журналируются
+type LogEntityType string
+
+const (
+	LogEntityTask            LogEntityType = "task"
+	LogEntityDocument        LogEntityType = "document"
+	LogEntityProject         LogEntityType = "project"
+	LogEntityFunctionalBlock LogEntityType = "functional_block"
+	LogEntityProjectPlan     LogEntityType = "project_plan"
+	LogEntityExecutor        LogEntityType = "executor"
+)
+
+// ValidLogEntityTypes возвращает список типов журналируемых записей
+func ValidLogEntityTypes() []string {
+	return []string{
+		string(LogEntityTask),
+		string(LogEntityDocument),
+		string(LogEntityProject),
+		string(LogEntityFunctionalBlock),
+		string(LogEntityProjectPlan),
+		string(LogEntityExecutor),
+	}
+}
+
+// IsValidLogEntityType проверяет валидность типа журналируемой записи
+func IsValidLogEntityType(entityType string) bool {
+	for _, validType := range ValidLogEntityTypes() {
+		if entityType == validType {
+			return true
+		}
+	}
+	return false
+}
+
+// OperationLogFilter описывает параметры выборки журнала операций
+type OperationLogFilter struct {
+	EntityType     string
+	EntityID       string
+	UserIdentifier string
+	OperationType  string
+	From           *time.Time
+	To             *time.Time
+
+	// Limit = 0 означает выборку без ограничения
+	Limit  int
+	Offset int
+}
+
+// OperationLogPage представляет страницу результатов выборки журнала
+type OperationLogPage struct {
+	Logs  []OperationLog `json:"logs"`
+	Total int            `json:"total"`
+}
+
+// HistoryEntry — запись истории изменений: кто, когда и какие поля изменил
+type HistoryEntry struct {
+	LogID          string        `json:"logId"`
+	OperationType  string        `json:"operationType"`
+	UserIdentifier string        `json:"userIdentifier"`
+	Changes        []FieldChange `json:"changes"`
+	CreatedAt      time.Time     `json:"createdAt"`
+}
+
 // OperationType определяет возможные типы операций
 type OperationType string
 
@@ -23,6 +88,8 @@ const (
 	OperationTypeDelete       OperationType = "DELETE"
 	OperationTypeComment      OperationType = "COMMENT"
 	OperationTypeStatusChange OperationType = "STATUS_CHANGE"
+	OperationTypeRestore      OperationType = "RESTORE"
+	OperationTypePurge        OperationType = "PURGE"
 )
 
 // ValidOperationTypes возвращает список валидных типов операций
@@ -33,6 +100,8 @@ func ValidOperationTypes() []string {
 		string(OperationTypeDelete),
 		string(OperationTypeComment),
 		string(OperationTypeStatusChange),
+		string(OperationTypeRestore),
+		string(OperationTypePurge),
 	}
 }
 
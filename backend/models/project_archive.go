@@ -0,0 +1,115 @@
+package models
+
+import "time"
+
+// Формат архива проекта. Версия увеличивается при несовместимых изменениях
+// структуры; импорт принимает только известные версии.
+const (
+	ProjectArchiveFormat  = "project-manager/project"
+	ProjectArchiveVersion = 1
+)
+
+// ProjectArchive — переносимая выгрузка проекта со всеми связанными записями.
+// Идентификаторы в архиве исходные: при импорте они заменяются новыми, а ссылки
+// между записями пересчитываются. Plan содержит идентификаторы задач в порядке плана.
+type ProjectArchive struct {
+	Format            string             `json:"format"`
+	Version           int                `json:"version"`
+	ExportedAt        time.Time          `json:"exportedAt"`
+	Project           Project            `json:"project"`
+	Workflow          *Workflow          `json:"workflow,omitempty"`
+	FunctionalBlocks  []FunctionalBlock  `json:"functionalBlocks"`
+	Tasks             []Task             `json:"tasks"`
+	TaskDependencies  []TaskDependency   `json:"taskDependencies"`
+	Comments          []Comment          `json:"comments"`
+	Documents         []Document         `json:"documents"`
+	DocumentRevisions []DocumentRevision `json:"documentRevisions"`
+	TaskDocumentLinks []TaskDocumentLink `json:"taskDocumentLinks"`
+	Plan              []string           `json:"plan"`
+	OperationLogs     []OperationLog     `json:"operationLogs"`
+}
+
+// ProjectArchiveCounts — количество записей каждого вида в архиве
+type ProjectArchiveCounts struct {
+	FunctionalBlocks  int `json:"functionalBlocks"`
+	Tasks             int `json:"tasks"`
+	TaskDependencies  int `json:"taskDependencies"`
+	Comments          int `json:"comments"`
+	Documents         int `json:"documents"`
+	DocumentRevisions int `json:"documentRevisions"`
+	TaskDocumentLinks int `json:"taskDocumentLinks"`
+	PlanItems         int `json:"planItems"`
+	OperationLogs     int `json:"operationLogs"`
+}
+
+// Counts подсчитывает записи архива
+func (a *ProjectArchive) Counts() ProjectArchiveCounts {
+	return ProjectArchiveCounts{
+		FunctionalBlocks:  len(a.FunctionalBlocks),
+		Tasks:             len(a.Tasks),
+		TaskDependencies:  len(a.TaskDependencies),
+		Comments:          len(a.Comments),
+		Documents:         len(a.Documents),
+		DocumentRevisions: len(a.DocumentRevisions),
+		TaskDocumentLinks: len(a.TaskDocumentLinks),
+		PlanItems:         len(a.Plan),
+		OperationLogs:     len(a.OperationLogs),
+	}
+}
+
+// ImportConflictKind определяет вид конфликта при импорте проекта
+type ImportConflictKind string
+
+const (
+	// ImportConflictDuplicatePrefix — функциональный блок с таким префиксом уже есть
+	ImportConflictDuplicatePrefix ImportConflictKind = "duplicate_prefix"
+	// ImportConflictInvalidPrefix — префикс блока не соответствует формату
+	ImportConflictInvalidPrefix ImportConflictKind = "invalid_prefix"
+	// ImportConflictDuplicateID — идентификатор повторяется внутри архива
+	ImportConflictDuplicateID ImportConflictKind = "duplicate_id"
+	// ImportConflictMissingReference — запись ссылается на отсутствующую в архиве запись
+	ImportConflictMissingReference ImportConflictKind = "missing_reference"
+	// ImportConflictProjectName — проект с таким именем уже существует
+	ImportConflictProjectName ImportConflictKind = "project_name_exists"
+	// ImportConflictInvalidData — запись не проходит проверку
+	ImportConflictInvalidData ImportConflictKind = "invalid_data"
+)
+
+// ProjectImportConflict — проблема, найденная при проверке архива. Блокирующие
+// конфликты запрещают импорт, остальные описывают, как будет разрешена ситуация.
+type ProjectImportConflict struct {
+	Kind      ImportConflictKind `json:"kind"`
+	Entity    string             `json:"entity"`
+	Reference string             `json:"reference"`
+	Message   string             `json:"message"`
+	Blocking  bool               `json:"blocking"`
+}
+
+// ProjectImportResult — отчет об импорте или его пробном запуске.
+// TaskNumbers сопоставляет исходные номера задач с новыми.
+type ProjectImportResult struct {
+	DryRun      bool                    `json:"dryRun"`
+	ProjectID   string                  `json:"projectId,omitempty"`
+	Counts      ProjectArchiveCounts    `json:"counts"`
+	TaskNumbers map[string]string       `json:"taskNumbers,omitempty"`
+	Conflicts   []ProjectImportConflict `json:"conflicts"`
+}
+
+// HasBlockingConflicts сообщает, есть ли в отчете конфликты, запрещающие импорт
+func (r *ProjectImportResult) HasBlockingConflicts() bool {
+	for _, conflict := range r.Conflicts {
+		if conflict.Blocking {
+			return true
+		}
+	}
+	return false
+}
+
+// ProjectImportOptions — параметры импорта проекта
+type ProjectImportOptions struct {
+	// DryRun проверяет архив и возвращает отчет, ничего не создавая
+	DryRun bool
+	// ReuseBlocks разрешает использовать существующий функциональный блок с тем
+	// же префиксом, даже если его название отличается от названия в архиве
+	ReuseBlocks bool
+}
@@ -0,0 +1,78 @@
+package models
+
+import "time"
+
+type ProjectMember struct {
+	ProjectID string    `json:"projectId" db:"project_id"`
+	UserID    string    `json:"userId" db:"user_id"`
+	Username  string    `json:"username" db:"username"`
+	Role      string    `json:"role" db:"role"`
+	CreatedAt time.Time `json:"createdAt" db:"created_at"`
+	UpdatedAt time.Time `json:"updatedAt" db:"updated_at"`
+}
+
+// ProjectRole определяет роль участника проекта
+type ProjectRole string
+
+const (
+	ProjectRoleOwner      ProjectRole = "owner"
+	ProjectRoleMaintainer ProjectRole = "maintainer"
+	ProjectRoleDeveloper  ProjectRole = "developer"
+	ProjectRoleViewer     ProjectRole = "viewer"
+	ProjectRoleAgent      ProjectRole = "agent"
+)
+
+// ValidProjectRoles возвращает список валидных ролей участников проекта
+func ValidProjectRoles() []string {
+	return []string{
+		string(ProjectRoleOwner),
+		string(ProjectRoleMaintainer),
+		string(ProjectRoleDeveloper),
+		string(ProjectRoleViewer),
+		string(ProjectRoleAgent),
+	}
+}
+
+// IsValidProjectRole проверяет валидность роли участника проекта
+func IsValidProjectRole(role string) bool {
+	for _, validRole := range ValidProjectRoles() {
+		if role == validRole {
+			return true
+		}
+	}
+	return false
+}
+
+// Permission определяет действие над проектом, требующее прав
+type Permission string
+
+const (
+	// PermissionEditTasks — создание и изменение задач, комментариев и плана
+	PermissionEditTasks Permission = "edit_tasks"
+	// PermissionEditDocuments — создание, изменение и удаление документов
+	PermissionEditDocuments Permission = "edit_documents"
+	// PermissionManageProject — изменение проекта и состава участников
+	PermissionManageProject Permission = "manage_project"
+	// PermissionDeleteProject — удаление проекта
+	PermissionDeleteProject Permission = "delete_project"
+)
+
+var rolePermissions = map[ProjectRole][]Permission{
+	ProjectRoleOwner:      {PermissionEditTasks, PermissionEditDocuments, PermissionManageProject, PermissionDeleteProject},
+	ProjectRoleMaintainer: {PermissionEditTasks, PermissionEditDocuments, PermissionManageProject},
+	ProjectRoleDeveloper:  {PermissionEditTasks, PermissionEditDocuments},
+	ProjectRoleViewer:     {},
+	// Агенты редактируют документы только с флагом agent_editable,
+	// это проверяется отдельно при обновлении документа
+	ProjectRoleAgent: {PermissionEditTasks},
+}
+
+// RoleHasPermission проверяет, разрешено ли роли действие
+func RoleHasPermission(role string, permission Permission) bool {
+	for _, granted := range rolePermissions[ProjectRole(role)] {
+		if granted == permission {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,19 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRoleHasPermission(t *testing.T) {
+	assert.True(t, RoleHasPermission("owner", PermissionDeleteProject))
+	assert.False(t, RoleHasPermission("maintainer", PermissionDeleteProject))
+	assert.True(t, RoleHasPermission("maintainer", PermissionManageProject))
+	assert.True(t, RoleHasPermission("developer", PermissionEditDocuments))
+	assert.False(t, RoleHasPermission("developer", PermissionManageProject))
+	assert.False(t, RoleHasPermission("viewer", PermissionEditTasks))
+	assert.True(t, RoleHasPermission("agent", PermissionEditTasks))
+	assert.False(t, RoleHasPermission("agent", PermissionEditDocuments))
+	assert.False(t, RoleHasPermission("unknown", PermissionEditTasks))
+}
@@ -0,0 +1,57 @@
+package models
+
+import "time"
+
+// Типы сущностей, участвующих в полнотекстовом поиске
+const (
+	SearchEntityTask     = "task"
+	SearchEntityComment  = "comment"
+	SearchEntityDocument = "document"
+)
+
+// ValidSearchEntityTypes возвращает список типов сущностей для поиска
+func ValidSearchEntityTypes() []string {
+	return []string{SearchEntityTask, SearchEntityComment, SearchEntityDocument}
+}
+
+// IsValidSearchEntityType проверяет валидность типа сущности для поиска
+func IsValidSearchEntityType(entityType string) bool {
+	for _, validType := range ValidSearchEntityTypes() {
+		if entityType == validType {
+			return true
+		}
+	}
+	return false
+}
+
+// SearchQuery описывает параметры полнотекстового поиска
+type SearchQuery struct {
+	Query       string
+	ProjectID   string
+	EntityTypes []string
+	Limit       int
+	Offset      int
+}
+
+// SearchResult представляет найденную сущность.
+// Snippet — готовый к вставке в HTML фрагмент: текст экранирован, единственная
+// разметка — теги <mark> вокруг совпадений. Title — обычный текст без экранирования.
+type SearchResult struct {
+	EntityType string    `json:"entityType"`
+	ID         string    `json:"id"`
+	ProjectID  string    `json:"projectId"`
+	TaskID     *string   `json:"taskId,omitempty"`
+	Title      string    `json:"title"`
+	Snippet    string    `json:"snippet"`
+	Rank       float64   `json:"rank"`
+	UpdatedAt  time.Time `json:"updatedAt"`
+}
+
+// SearchResponse представляет страницу результатов поиска
+type SearchResponse struct {
+	Query   string         `json:"query"`
+	Total   int            `json:"total"`
+	Limit   int            `json:"limit"`
+	Offset  int            `json:"offset"`
+	Results []SearchResult `json:"results"`
+}
@@ -1,24 +1,75 @@
 package models
 
 import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
 	"time"
 )
 
 type Task struct {
-	ID                string    `json:"id" db:"id"`
-	ProjectID         string    `json:"projectId" db:"project_id"`
-	FunctionalBlockID *string   `json:"functionalBlockId" db:"functional_block_id"`
-	Number            string    `json:"number" db:"number"`
-	Title             string    `json:"title" db:"title"`
-	Description       string    `json:"description" db:"description"`
-	Status            string    `json:"status" db:"status"`
-	Priority          string    `json:"priority" db:"priority"`
-	Type              string    `json:"type" db:"type"`
-	Role              string    `json:"role" db:"role"`
-	Result            string    `json:"result" db:"result"`
-	ParentTaskID      *string   `json:"parentTaskId" db:"parent_task_id"`
-	CreatedAt         time.Time `json:"createdAt" db:"created_at"`
-	UpdatedAt         time.Time `json:"updatedAt" db:"updated_at"`
+	ID                  string     `json:"id" db:"id"`
+	ProjectID           string     `json:"projectId" db:"project_id"`
+	FunctionalBlockID   *string    `json:"functionalBlockId" db:"functional_block_id"`
+	Number              string     `json:"number" db:"number"`
+	Title               string     `json:"title" db:"title"`
+	Description         string     `json:"description" db:"description"`
+	Status              string     `json:"status" db:"status"`
+	Priority            string     `json:"priority" db:"priority"`
+	Type                string     `json:"type" db:"type"`
+	Role                string     `json:"role" db:"role"`
+	AssigneeID          *string    `json:"assigneeId" db:"assignee_id"`
+	Result              string     `json:"result" db:"result"`
+	ParentTaskID        *string    `json:"parentTaskId" db:"parent_task_id"`
+	SourceDocumentID    *string    `json:"sourceDocumentId,omitempty" db:"source_document_id"`
+	SourceSectionAnchor *string    `json:"sourceSectionAnchor,omitempty" db:"source_section_anchor"`
+	Version             int        `json:"version" db:"version"`
+	CreatedAt           time.Time  `json:"createdAt" db:"created_at"`
+	UpdatedAt           time.Time  `json:"updatedAt" db:"updated_at"`
+	DeletedAt           *time.Time `json:"deletedAt,omitempty" db:"deleted_at"`
+}
+
+// DefaultTaskNumberPrefix — префикс номеров задач без функционального блока
+const DefaultTaskNumberPrefix = "TASK"
+
+var taskNumberRegex = regexp.MustCompile(`^([A-Z]{1,6})-?(\d+)$`)
+
+// FormatTaskNumber формирует номер задачи вида AUTH-0042
+func FormatTaskNumber(prefix string, value int64) string {
+	return fmt.Sprintf("%s-%04d", prefix, value)
+}
+
+// TaskNumberCandidates возвращает возможные записи номера задачи в текущем
+// (AUTH-0042) и устаревшем (TASK-000042) форматах. Регистр и ведущие нули не важны.
+func TaskNumberCandidates(number string) []string {
+	normalized := strings.ToUpper(strings.TrimSpace(number))
+	candidates := []string{normalized}
+
+	match := taskNumberRegex.FindStringSubmatch(normalized)
+	if match == nil {
+		return candidates
+	}
+
+	value, err := strconv.ParseInt(match[2], 10, 64)
+	if err != nil {
+		return candidates
+	}
+
+	add := func(candidate string) {
+		for _, existing := range candidates {
+			if existing == candidate {
+				return
+			}
+		}
+		candidates = append(candidates, candidate)
+	}
+
+	add(FormatTaskNumber(match[1], value))
+	if match[1] == DefaultTaskNumberPrefix {
+		add(fmt.Sprintf("%s-%06d", DefaultTaskNumberPrefix, value))
+	}
+	return candidates
 }
 
 // TaskStatus определяет возможные статусы задач
@@ -74,6 +125,16 @@ func ValidPriorities() []string {
 	}
 }
 
+// PriorityRank возвращает вес приоритета: чем важнее, тем больше (0 — неизвестный)
+func PriorityRank(priority string) int {
+	for i, validPriority := range ValidPriorities() {
+		if priority == validPriority {
+			return i + 1
+		}
+	}
+	return 0
+}
+
 // ValidTypes возвращает список валидных типов
 func ValidTypes() []string {
 	return []string{
@@ -0,0 +1,63 @@
+package models
+
+import "time"
+
+// TaskDependency — связь задачи TaskID с задачей DependsOnTaskID
+type TaskDependency struct {
+	ID              string    `json:"id" db:"id"`
+	TaskID          string    `json:"taskId" db:"task_id"`
+	DependsOnTaskID string    `json:"dependsOnTaskId" db:"depends_on_task_id"`
+	Kind            string    `json:"kind" db:"kind"`
+	CreatedBy       string    `json:"createdBy" db:"created_by"`
+	CreatedAt       time.Time `json:"createdAt" db:"created_at"`
+}
+
+// DependencyKind определяет вид связи между задачами
+type DependencyKind string
+
+const (
+	// DependencyKindBlocks — DependsOnTaskID блокирует TaskID
+	DependencyKindBlocks DependencyKind = "blocks"
+	// DependencyKindRelatesTo — задачи связаны по смыслу
+	DependencyKindRelatesTo DependencyKind = "relates_to"
+	// DependencyKindDuplicates — TaskID дублирует DependsOnTaskID
+	DependencyKindDuplicates DependencyKind = "duplicates"
+)
+
+// ValidDependencyKinds возвращает список валидных видов связей
+func ValidDependencyKinds() []string {
+	return []string{
+		string(DependencyKindBlocks),
+		string(DependencyKindRelatesTo),
+		string(DependencyKindDuplicates),
+	}
+}
+
+// IsValidDependencyKind проверяет валидность вида связи
+func IsValidDependencyKind(kind string) bool {
+	for _, validKind := range ValidDependencyKinds() {
+		if kind == validKind {
+			return true
+		}
+	}
+	return false
+}
+
+// DependencyGraphNode — задача в графе зависимостей проекта.
+// Ready означает, что задача открыта и все блокирующие ее задачи закрыты.
+type DependencyGraphNode struct {
+	TaskID    string   `json:"taskId"`
+	Number    string   `json:"number"`
+	Title     string   `json:"title"`
+	Status    string   `json:"status"`
+	Priority  string   `json:"priority"`
+	BlockedBy []string `json:"blockedBy"`
+	Ready     bool     `json:"ready"`
+}
+
+// DependencyGraph — граф зависимостей задач проекта
+type DependencyGraph struct {
+	ProjectID string                `json:"projectId"`
+	Nodes     []DependencyGraphNode `json:"nodes"`
+	Edges     []TaskDependency      `json:"edges"`
+}
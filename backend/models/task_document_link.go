@@ -0,0 +1,38 @@
+package models
+
+import "time"
+
+// TaskDocumentLink — связь задачи с документом или его разделом.
+// Поля документа и задачи заполняются при чтении для удобства клиентов.
+type TaskDocumentLink struct {
+	ID            string    `json:"id" db:"id"`
+	TaskID        string    `json:"taskId" db:"task_id"`
+	DocumentID    string    `json:"documentId" db:"document_id"`
+	SectionAnchor string    `json:"sectionAnchor,omitempty" db:"section_anchor"`
+	CreatedBy     string    `json:"createdBy" db:"created_by"`
+	CreatedAt     time.Time `json:"createdAt" db:"created_at"`
+
+	DocumentTitle string `json:"documentTitle,omitempty"`
+	DocumentType  string `json:"documentType,omitempty"`
+	TaskNumber    string `json:"taskNumber,omitempty"`
+	TaskTitle     string `json:"taskTitle,omitempty"`
+	TaskStatus    string `json:"taskStatus,omitempty"`
+}
+
+// LinkedDocument — документ, связанный с задачей, вместе с содержимым
+// связанного раздела (или всего документа, если раздел не указан)
+type LinkedDocument struct {
+	LinkID        string `json:"linkId"`
+	DocumentID    string `json:"documentId"`
+	Title         string `json:"title"`
+	Type          string `json:"type"`
+	SectionAnchor string `json:"sectionAnchor,omitempty"`
+	SectionTitle  string `json:"sectionTitle,omitempty"`
+	Content       string `json:"content"`
+}
+
+// TaskDetails — задача вместе со связанными документами
+type TaskDetails struct {
+	Task
+	LinkedDocuments []LinkedDocument `json:"linkedDocuments"`
+}
@@ -0,0 +1,48 @@
+package models
+
+// TaskDraft — задача, распознанная в документе, до ее создания
+type TaskDraft struct {
+	Index                 int      `json:"index"`
+	Title                 string   `json:"title"`
+	Description           string   `json:"description"`
+	Type                  string   `json:"type"`
+	Priority              string   `json:"priority"`
+	FunctionalBlockPrefix string   `json:"functionalBlockPrefix,omitempty"`
+	FunctionalBlockID     *string  `json:"functionalBlockId,omitempty"`
+	SectionAnchor         string   `json:"sectionAnchor"`
+	Line                  int      `json:"line"`
+	ExistingTaskID        *string  `json:"existingTaskId,omitempty"`
+	Errors                []string `json:"errors,omitempty"`
+}
+
+// TaskDraftPreview — задачи, которые будут созданы из документа
+type TaskDraftPreview struct {
+	DocumentID string      `json:"documentId"`
+	ProjectID  string      `json:"projectId"`
+	Drafts     []TaskDraft `json:"drafts"`
+}
+
+// CreateTasksFromDocumentRequest выбирает черновики для создания по индексам
+// (пустой список — все) и указывает, добавлять ли задачи в план проекта
+type CreateTasksFromDocumentRequest struct {
+	Indexes   []int `json:"indexes,omitempty"`
+	AddToPlan bool  `json:"addToPlan"`
+}
+
+// PartiallyCreatedTask — задача, созданная по черновику Index, для которой не
+// удались связь с документом или добавление в план
+type PartiallyCreatedTask struct {
+	Index  int      `json:"index"`
+	Task   Task     `json:"task"`
+	Errors []string `json:"errors"`
+}
+
+// CreateTasksFromDocumentResult — результат создания задач из документа.
+// Created — задачи, созданные полностью; Partial — созданные задачи с
+// ошибками последующих шагов; Skipped — черновики, по которым задача не
+// создавалась: с ошибками или уже созданные ранее (ExistingTaskID).
+type CreateTasksFromDocumentResult struct {
+	Created []Task                 `json:"created"`
+	Partial []PartiallyCreatedTask `json:"partial"`
+	Skipped []TaskDraft            `json:"skipped"`
+}
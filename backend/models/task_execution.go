@@ -0,0 +1,72 @@
+package models
+
+import "time"
+
+// TaskExecution представляет запуск автоматического выполнения задачи
+type TaskExecution struct {
+	ID         string                 `json:"id" db:"id"`
+	TaskID     *string                `json:"taskId" db:"task_id"`
+	ProjectID  *string                `json:"projectId" db:"project_id"`
+	Command    string                 `json:"command" db:"command"`
+	Source     string                 `json:"source" db:"source"`
+	UserID     string                 `json:"userId" db:"user_id"`
+	Status     string                 `json:"status" db:"status"`
+	Progress   int                    `json:"progress" db:"progress"`
+	Message    string                 `json:"message" db:"message"`
+	TaskTitle  string                 `json:"taskTitle" db:"task_title"`
+	Strategy   string                 `json:"strategy" db:"strategy"`
+	Error      string                 `json:"error" db:"error"`
+	Steps      []TaskExecutionStep    `json:"steps" db:"steps"`
+	Logs       []string               `json:"logs" db:"logs"`
+	Result     map[string]interface{} `json:"result" db:"result"`
+	StartedAt  time.Time              `json:"startedAt" db:"started_at"`
+	FinishedAt *time.Time             `json:"finishedAt" db:"finished_at"`
+	CreatedAt  time.Time              `json:"createdAt" db:"created_at"`
+	UpdatedAt  time.Time              `json:"updatedAt" db:"updated_at"`
+}
+
+// TaskExecutionStep представляет этап выполнения задачи
+type TaskExecutionStep struct {
+	ID          string     `json:"id"`
+	Name        string     `json:"name"`
+	Status      string     `json:"status"`
+	StartTime   time.Time  `json:"start_time"`
+	EndTime     *time.Time `json:"end_time,omitempty"`
+	Error       string     `json:"error,omitempty"`
+	Description string     `json:"description"`
+}
+
+// TaskExecutionMetrics представляет агрегированную статистику выполнений
+type TaskExecutionMetrics struct {
+	TotalExecutions      int            `json:"total_executions"`
+	SuccessfulExecutions int            `json:"successful_executions"`
+	FailedExecutions     int            `json:"failed_executions"`
+	CancelledExecutions  int            `json:"cancelled_executions"`
+	AverageExecutionTime float64        `json:"average_execution_time"` // milliseconds
+	StrategiesUsed       map[string]int `json:"strategies_used"`
+	CommonErrors         map[string]int `json:"common_errors"`
+}
+
+// TaskExecutionStatus определяет возможные статусы выполнения
+type TaskExecutionStatus string
+
+const (
+	TaskExecutionStatusPending   TaskExecutionStatus = "pending"
+	TaskExecutionStatusRunning   TaskExecutionStatus = "running"
+	TaskExecutionStatusCompleted TaskExecutionStatus = "completed"
+	TaskExecutionStatusFailed    TaskExecutionStatus = "failed"
+	TaskExecutionStatusCancelled TaskExecutionStatus = "cancelled"
+)
+
+// TaskExecutionStrategy определяет стратегии выполнения задач
+type TaskExecutionStrategy string
+
+const (
+	TaskExecutionStrategyDevelopment TaskExecutionStrategy = "development"
+	TaskExecutionStrategyBugFix      TaskExecutionStrategy = "bugfix"
+)
+
+// IsActive проверяет, выполняется ли запуск в данный момент
+func (e *TaskExecution) IsActive() bool {
+	return e.Status == string(TaskExecutionStatusPending) || e.Status == string(TaskExecutionStatusRunning)
+}
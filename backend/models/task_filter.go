@@ -0,0 +1,121 @@
+package models
+
+import (
+	"errors"
+	"regexp"
+	"time"
+	"unicode/utf8"
+)
+
+// TaskFilterNone — значение фильтра по ссылке, означающее отсутствие связи
+// (например, parent_task_id=none выбирает задачи верхнего уровня)
+const TaskFilterNone = "none"
+
+var uuidRegex = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// IsValidUUID проверяет, что строка — UUID в каноническом виде
+func IsValidUUID(value string) bool {
+	return uuidRegex.MatchString(value)
+}
+
+// TaskFilter описывает параметры выборки задач
+type TaskFilter struct {
+	ProjectID         string
+	FunctionalBlockID string
+	Statuses          []string
+	Priorities        []string
+	Types             []string
+	Role              string
+	AssigneeID        string
+	ParentTaskID      string
+	CreatedFrom       *time.Time
+	CreatedTo         *time.Time
+	UpdatedFrom       *time.Time
+	UpdatedTo         *time.Time
+	Query             string
+
+	SortBy    string
+	SortOrder string
+
+	// Limit = 0 означает выборку без ограничения (кроме режима курсора)
+	Limit  int
+	Offset int
+	Cursor string
+}
+
+// TaskPage представляет страницу результатов выборки задач
+type TaskPage struct {
+	Tasks      []Task `json:"tasks"`
+	Total      int    `json:"total"`
+	NextCursor string `json:"nextCursor,omitempty"`
+}
+
+// Направления сортировки
+const (
+	SortOrderAsc  = "asc"
+	SortOrderDesc = "desc"
+)
+
+// ValidTaskSortFields возвращает список полей, по которым можно сортировать задачи
+func ValidTaskSortFields() []string {
+	return []string{"created_at", "updated_at", "number", "title", "status", "priority", "type"}
+}
+
+// IsValidTaskSortField проверяет валидность поля сортировки
+func IsValidTaskSortField(field string) bool {
+	for _, validField := range ValidTaskSortFields() {
+		if field == validField {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate проверяет значения фильтра, сортировку и пагинацию.
+// Статусы задаются процессом проекта, поэтому проверяется только их формат.
+func (f *TaskFilter) Validate() error {
+	if f.ProjectID != "" && !IsValidUUID(f.ProjectID) {
+		return errors.New("invalid project_id: " + f.ProjectID)
+	}
+	references := []struct {
+		name  string
+		value string
+	}{
+		{"functional_block_id", f.FunctionalBlockID},
+		{"assignee_id", f.AssigneeID},
+		{"parent_task_id", f.ParentTaskID},
+	}
+	for _, reference := range references {
+		if reference.value != "" && reference.value != TaskFilterNone && !IsValidUUID(reference.value) {
+			return errors.New("invalid " + reference.name + ": " + reference.value)
+		}
+	}
+
+	for _, status := range f.Statuses {
+		if utf8.RuneCountInString(status) > 50 {
+			return errors.New("invalid status: " + status)
+		}
+	}
+	for _, priority := range f.Priorities {
+		if !IsValidPriority(priority) {
+			return errors.New("invalid priority: " + priority)
+		}
+	}
+	for _, taskType := range f.Types {
+		if !IsValidType(taskType) {
+			return errors.New("invalid type: " + taskType)
+		}
+	}
+
+	if f.SortBy != "" && !IsValidTaskSortField(f.SortBy) {
+		return errors.New("invalid sort field: " + f.SortBy)
+	}
+	if f.SortOrder != "" && f.SortOrder != SortOrderAsc && f.SortOrder != SortOrderDesc {
+		return errors.New("sort order must be asc or desc")
+	}
+
+	if f.Limit < 0 || f.Offset < 0 {
+		return errors.New("limit and offset must not be negative")
+	}
+	return nil
+}
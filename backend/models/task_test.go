@@ -0,0 +1,24 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormatTaskNumber(t *testing.T) {
+	assert.Equal(t, "AUTH-0042", FormatTaskNumber("AUTH", 42))
+	assert.Equal(t, "AUTH-12345", FormatTaskNumber("AUTH", 12345))
+}
+
+func TestTaskNumberCandidates(t *testing.T) {
+	// Новый формат с произвольным регистром и без ведущих нулей
+	assert.Equal(t, []string{"AUTH-42", "AUTH-0042"}, TaskNumberCandidates(" auth-42 "))
+
+	// Устаревший формат TASK-NNNNNN
+	assert.Equal(t, []string{"TASK-000123", "TASK-0123"}, TaskNumberCandidates("TASK-000123"))
+	assert.Equal(t, []string{"TASK-123", "TASK-0123", "TASK-000123"}, TaskNumberCandidates("task-123"))
+
+	// Значения, не похожие на номер, ищутся как есть
+	assert.Equal(t, []string{"SOMETHING"}, TaskNumberCandidates("something"))
+}
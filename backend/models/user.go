@@ -0,0 +1,59 @@
+package models
+
+import "time"
+
+// SystemUsername — идентификатор, под которым записываются операции без
+// аутентифицированного пользователя (фоновые задачи)
+const SystemUsername = "system"
+
+// APIKeyUsername — идентификатор, под которым записываются запросы по общему API_KEY
+const APIKeyUsername = "api-key"
+
+// APITokenPrefix — префикс персональных токенов доступа
+const APITokenPrefix = "pm_"
+
+// StreamTokenPrefix — префикс краткосрочных токенов потока событий
+const StreamTokenPrefix = "pms_"
+
+type User struct {
+	ID          string    `json:"id" db:"id"`
+	Username    string    `json:"username" db:"username"`
+	DisplayName *string   `json:"displayName" db:"display_name"`
+	Email       *string   `json:"email" db:"email"`
+	IsAdmin     bool      `json:"isAdmin" db:"is_admin"`
+	IsAgent     bool      `json:"isAgent" db:"is_agent"`
+	IsActive    bool      `json:"isActive" db:"is_active"`
+	CreatedAt   time.Time `json:"createdAt" db:"created_at"`
+	UpdatedAt   time.Time `json:"updatedAt" db:"updated_at"`
+}
+
+// APIToken описывает персональный токен доступа. Сам токен не хранится,
+// Token заполняется только в ответе на создание.
+type APIToken struct {
+	ID          string     `json:"id" db:"id"`
+	UserID      string     `json:"userId" db:"user_id"`
+	Name        string     `json:"name" db:"name"`
+	TokenPrefix string     `json:"tokenPrefix" db:"token_prefix"`
+	Token       string     `json:"token,omitempty" db:"-"`
+	CreatedAt   time.Time  `json:"createdAt" db:"created_at"`
+	LastUsedAt  *time.Time `json:"lastUsedAt" db:"last_used_at"`
+	ExpiresAt   *time.Time `json:"expiresAt" db:"expires_at"`
+	RevokedAt   *time.Time `json:"revokedAt" db:"revoked_at"`
+}
+
+// StreamToken — краткосрочный токен для подключения к потоку событий
+type StreamToken struct {
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+// Principal — аутентифицированный субъект запроса
+type Principal struct {
+	UserID   string `json:"userId,omitempty"`
+	Username string `json:"username"`
+	IsAdmin  bool   `json:"isAdmin"`
+	IsAgent  bool   `json:"isAgent"`
+	TokenID  string `json:"tokenId,omitempty"`
+	// Legacy — запрос аутентифицирован общим API_KEY и не привязан к пользователю
+	Legacy bool `json:"legacy"`
+}
@@ -0,0 +1,95 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Webhook — подписка проекта на исходящие уведомления об операциях.
+// Secret используется для подписи доставок и возвращается только при создании.
+type Webhook struct {
+	ID         string    `json:"id" db:"id"`
+	ProjectID  string    `json:"projectId" db:"project_id"`
+	URL        string    `json:"url" db:"url"`
+	EventTypes []string  `json:"eventTypes" db:"event_types"`
+	Secret     string    `json:"secret,omitempty" db:"secret"`
+	IsActive   bool      `json:"isActive" db:"is_active"`
+	CreatedBy  string    `json:"createdBy" db:"created_by"`
+	CreatedAt  time.Time `json:"createdAt" db:"created_at"`
+	UpdatedAt  time.Time `json:"updatedAt" db:"updated_at"`
+}
+
+// Subscribed проверяет, подписан ли вебхук на тип операции; пустой список — на все
+func (w *Webhook) Subscribed(eventType string) bool {
+	if len(w.EventTypes) == 0 {
+		return true
+	}
+	for _, subscribed := range w.EventTypes {
+		if subscribed == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidWebhookEventTypes возвращает типы операций, о которых отправляются уведомления
+func ValidWebhookEventTypes() []string {
+	return []string{
+		string(OperationTypeCreate),
+		string(OperationTypeUpdate),
+		string(OperationTypeDelete),
+		string(OperationTypeComment),
+		string(OperationTypeStatusChange),
+	}
+}
+
+// IsValidWebhookEventType проверяет, можно ли подписаться на тип операции
+func IsValidWebhookEventType(eventType string) bool {
+	for _, validType := range ValidWebhookEventTypes() {
+		if eventType == validType {
+			return true
+		}
+	}
+	return false
+}
+
+// WebhookDeliveryStatus определяет состояние доставки
+type WebhookDeliveryStatus string
+
+const (
+	WebhookDeliveryPending   WebhookDeliveryStatus = "pending"
+	WebhookDeliverySucceeded WebhookDeliveryStatus = "succeeded"
+	WebhookDeliveryFailed    WebhookDeliveryStatus = "failed"
+)
+
+// WebhookDelivery — одна доставка уведомления вместе с результатом последней попытки
+type WebhookDelivery struct {
+	ID             string          `json:"id" db:"id"`
+	WebhookID      string          `json:"webhookId" db:"webhook_id"`
+	OperationLogID *string         `json:"operationLogId" db:"operation_log_id"`
+	EventType      string          `json:"eventType" db:"event_type"`
+	Payload        json.RawMessage `json:"payload" db:"payload"`
+	Status         string          `json:"status" db:"status"`
+	Attempts       int             `json:"attempts" db:"attempts"`
+	NextAttemptAt  *time.Time      `json:"nextAttemptAt" db:"next_attempt_at"`
+	LastAttemptAt  *time.Time      `json:"lastAttemptAt" db:"last_attempt_at"`
+	ResponseStatus *int            `json:"responseStatus" db:"response_status"`
+	ResponseBody   string          `json:"responseBody" db:"response_body"`
+	Error          string          `json:"error" db:"error"`
+	RedeliveryOf   *string         `json:"redeliveryOf,omitempty" db:"redelivery_of"`
+	CreatedAt      time.Time       `json:"createdAt" db:"created_at"`
+	UpdatedAt      time.Time       `json:"updatedAt" db:"updated_at"`
+}
+
+// WebhookPayload — тело уведомления, отправляемого подписчику
+type WebhookPayload struct {
+	Event      string          `json:"event"`
+	EntityType string          `json:"entityType"`
+	EntityID   string          `json:"entityId"`
+	ProjectID  string          `json:"projectId"`
+	TaskID     *string         `json:"taskId,omitempty"`
+	Actor      string          `json:"actor"`
+	Details    json.RawMessage `json:"details"`
+	LogID      string          `json:"logId"`
+	OccurredAt time.Time       `json:"occurredAt"`
+}
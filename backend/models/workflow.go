@@ -0,0 +1,236 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// maxWorkflowStateLength соответствует размеру колонки tasks.status
+const maxWorkflowStateLength = 50
+
+// Поля задачи, которые переход может требовать заполненными
+const (
+	WorkflowFieldResult          = "result"
+	WorkflowFieldDescription     = "description"
+	WorkflowFieldRole            = "role"
+	WorkflowFieldFunctionalBlock = "functional_block_id"
+)
+
+// ValidWorkflowFields возвращает список полей, доступных в requiredFields
+func ValidWorkflowFields() []string {
+	return []string{WorkflowFieldResult, WorkflowFieldDescription, WorkflowFieldRole, WorkflowFieldFunctionalBlock}
+}
+
+// WorkflowTransition — разрешенный переход между статусами
+type WorkflowTransition struct {
+	From           string   `json:"from"`
+	To             string   `json:"to"`
+	RequiredFields []string `json:"requiredFields,omitempty"`
+}
+
+// Workflow описывает статусы задач проекта и разрешенные переходы между ними.
+// TerminalStates — конечные статусы: задача в них закрыта и не требует работы.
+// DoneStates — конечные статусы, в которых работа выполнена; только они
+// снимают блокировку с зависимых задач.
+type Workflow struct {
+	ProjectID      string               `json:"projectId,omitempty"`
+	InitialState   string               `json:"initialState"`
+	States         []string             `json:"states"`
+	TerminalStates []string             `json:"terminalStates"`
+	DoneStates     []string             `json:"doneStates"`
+	Transitions    []WorkflowTransition `json:"transitions"`
+	IsDefault      bool                 `json:"isDefault"`
+	UpdatedBy      string               `json:"updatedBy,omitempty"`
+	UpdatedAt      *time.Time           `json:"updatedAt,omitempty"`
+}
+
+// DefaultWorkflow возвращает процесс, действующий для проектов без собственного
+func DefaultWorkflow() *Workflow {
+	newStatus := string(TaskStatusNew)
+	inProgress := string(TaskStatusInProgress)
+	testing := string(TaskStatusTesting)
+	done := string(TaskStatusDone)
+	cancelled := string(TaskStatusCancelled)
+	requireResult := []string{WorkflowFieldResult}
+
+	return &Workflow{
+		InitialState:   newStatus,
+		States:         ValidStatuses(),
+		TerminalStates: []string{done, cancelled},
+		DoneStates:     []string{done},
+		Transitions: []WorkflowTransition{
+			{From: newStatus, To: inProgress},
+			{From: newStatus, To: cancelled},
+			{From: inProgress, To: newStatus},
+			{From: inProgress, To: testing},
+			{From: inProgress, To: done, RequiredFields: requireResult},
+			{From: inProgress, To: cancelled},
+			{From: testing, To: inProgress},
+			{From: testing, To: done, RequiredFields: requireResult},
+			{From: testing, To: cancelled},
+			{From: done, To: inProgress},
+			{From: cancelled, To: newStatus},
+		},
+		IsDefault: true,
+	}
+}
+
+// Validate проверяет целостность описания процесса
+func (w *Workflow) Validate() error {
+	if len(w.States) == 0 {
+		return errors.New("workflow must have at least one state")
+	}
+
+	seen := make(map[string]bool, len(w.States))
+	for _, state := range w.States {
+		if strings.TrimSpace(state) == "" || utf8.RuneCountInString(state) > maxWorkflowStateLength {
+			return fmt.Errorf("state name must be between 1 and %d characters", maxWorkflowStateLength)
+		}
+		if seen[state] {
+			return fmt.Errorf("duplicate state: %s", state)
+		}
+		seen[state] = true
+	}
+
+	if !seen[w.InitialState] {
+		return errors.New("initial state must be one of the states")
+	}
+
+	terminal := make(map[string]bool, len(w.TerminalStates))
+	for _, state := range w.TerminalStates {
+		if !seen[state] {
+			return fmt.Errorf("terminal state %s is not one of the states", state)
+		}
+		if terminal[state] {
+			return fmt.Errorf("duplicate terminal state: %s", state)
+		}
+		terminal[state] = true
+	}
+	if terminal[w.InitialState] {
+		return errors.New("initial state cannot be terminal")
+	}
+
+	done := make(map[string]bool, len(w.DoneStates))
+	for _, state := range w.DoneStates {
+		if !terminal[state] {
+			return fmt.Errorf("done state %s is not one of the terminal states", state)
+		}
+		if done[state] {
+			return fmt.Errorf("duplicate done state: %s", state)
+		}
+		done[state] = true
+	}
+
+	validFields := make(map[string]bool)
+	for _, field := range ValidWorkflowFields() {
+		validFields[field] = true
+	}
+	transitions := make(map[string]bool, len(w.Transitions))
+	for _, transition := range w.Transitions {
+		if !seen[transition.From] || !seen[transition.To] {
+			return fmt.Errorf("transition %s -> %s references unknown state", transition.From, transition.To)
+		}
+		if transition.From == transition.To {
+			return fmt.Errorf("transition %s -> %s must change the state", transition.From, transition.To)
+		}
+		key := transition.From + "\x00" + transition.To
+		if transitions[key] {
+			return fmt.Errorf("duplicate transition: %s -> %s", transition.From, transition.To)
+		}
+		transitions[key] = true
+		for _, field := range transition.RequiredFields {
+			if !validFields[field] {
+				return fmt.Errorf("invalid required field: %s", field)
+			}
+		}
+	}
+	return nil
+}
+
+// HasState проверяет наличие статуса в процессе
+func (w *Workflow) HasState(state string) bool {
+	for _, existing := range w.States {
+		if existing == state {
+			return true
+		}
+	}
+	return false
+}
+
+// IsTerminal проверяет, что статус конечный: задача в нем закрыта
+func (w *Workflow) IsTerminal(state string) bool {
+	for _, terminal := range w.TerminalStates {
+		if terminal == state {
+			return true
+		}
+	}
+	return false
+}
+
+// IsDone проверяет, что статус означает выполненную работу
+func (w *Workflow) IsDone(state string) bool {
+	for _, done := range w.DoneStates {
+		if done == state {
+			return true
+		}
+	}
+	return false
+}
+
+// FillTerminalStates задает конечные статусы процессу, сохраненному до
+// появления признака: ими считаются статусы процесса по умолчанию
+// «Выполнена» и «Отменена», если они есть в процессе. Выполненными
+// считаются все конечные статусы, кроме «Отменена».
+func (w *Workflow) FillTerminalStates() {
+	if w.TerminalStates == nil {
+		w.TerminalStates = []string{}
+		for _, state := range []string{string(TaskStatusDone), string(TaskStatusCancelled)} {
+			if w.HasState(state) {
+				w.TerminalStates = append(w.TerminalStates, state)
+			}
+		}
+	}
+	if w.DoneStates == nil {
+		w.DoneStates = []string{}
+		for _, state := range w.TerminalStates {
+			if state != string(TaskStatusCancelled) {
+				w.DoneStates = append(w.DoneStates, state)
+			}
+		}
+	}
+}
+
+// Transition возвращает переход from -> to или nil, если он запрещен
+func (w *Workflow) Transition(from, to string) *WorkflowTransition {
+	for i := range w.Transitions {
+		if w.Transitions[i].From == from && w.Transitions[i].To == to {
+			return &w.Transitions[i]
+		}
+	}
+	return nil
+}
+
+// MissingFields возвращает обязательные для перехода поля, не заполненные в задаче
+func (t *WorkflowTransition) MissingFields(task *Task) []string {
+	var missing []string
+	for _, field := range t.RequiredFields {
+		var filled bool
+		switch field {
+		case WorkflowFieldResult:
+			filled = strings.TrimSpace(task.Result) != ""
+		case WorkflowFieldDescription:
+			filled = strings.TrimSpace(task.Description) != ""
+		case WorkflowFieldRole:
+			filled = strings.TrimSpace(task.Role) != ""
+		case WorkflowFieldFunctionalBlock:
+			filled = task.FunctionalBlockID != nil && *task.FunctionalBlockID != ""
+		}
+		if !filled {
+			missing = append(missing, field)
+		}
+	}
+	return missing
+}
@@ -0,0 +1,102 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDefaultWorkflowIsValid(t *testing.T) {
+	assert.NoError(t, DefaultWorkflow().Validate())
+}
+
+func TestWorkflowValidate(t *testing.T) {
+	workflow := &Workflow{
+		InitialState: "Open",
+		States:       []string{"Open", "Closed"},
+		Transitions:  []WorkflowTransition{{From: "Open", To: "Closed", RequiredFields: []string{"result"}}},
+	}
+	assert.NoError(t, workflow.Validate())
+
+	workflow.InitialState = "Draft"
+	assert.Error(t, workflow.Validate())
+	workflow.InitialState = "Open"
+
+	workflow.Transitions = append(workflow.Transitions, WorkflowTransition{From: "Closed", To: "Archived"})
+	assert.Error(t, workflow.Validate())
+
+	workflow.Transitions = []WorkflowTransition{{From: "Open", To: "Closed", RequiredFields: []string{"estimate"}}}
+	assert.Error(t, workflow.Validate())
+}
+
+func TestWorkflowTransition(t *testing.T) {
+	workflow := DefaultWorkflow()
+
+	// Из отмененной задачи нельзя сразу перейти в выполненную
+	assert.Nil(t, workflow.Transition(string(TaskStatusCancelled), string(TaskStatusDone)))
+
+	transition := workflow.Transition(string(TaskStatusInProgress), string(TaskStatusDone))
+	if assert.NotNil(t, transition) {
+		assert.Equal(t, []string{WorkflowFieldResult}, transition.MissingFields(&Task{Result: "  "}))
+		assert.Empty(t, transition.MissingFields(&Task{Result: "Готово"}))
+	}
+}
+
+func TestWorkflowTerminalStates(t *testing.T) {
+	workflow := &Workflow{
+		InitialState:   "Open",
+		States:         []string{"Open", "Closed"},
+		TerminalStates: []string{"Closed"},
+	}
+	assert.NoError(t, workflow.Validate())
+	assert.True(t, workflow.IsTerminal("Closed"))
+	assert.False(t, workflow.IsTerminal("Open"))
+
+	workflow.TerminalStates = []string{"Archived"}
+	assert.Error(t, workflow.Validate())
+
+	workflow.TerminalStates = []string{"Open"}
+	assert.Error(t, workflow.Validate())
+}
+
+func TestWorkflowDoneStates(t *testing.T) {
+	workflow := &Workflow{
+		InitialState:   "Open",
+		States:         []string{"Open", "Closed", "Rejected"},
+		TerminalStates: []string{"Closed", "Rejected"},
+		DoneStates:     []string{"Closed"},
+	}
+	assert.NoError(t, workflow.Validate())
+	assert.True(t, workflow.IsDone("Closed"))
+	assert.False(t, workflow.IsDone("Rejected"))
+	assert.False(t, workflow.IsDone("Open"))
+
+	// Выполненный статус должен быть конечным
+	workflow.DoneStates = []string{"Open"}
+	assert.Error(t, workflow.Validate())
+}
+
+func TestWorkflowFillTerminalStates(t *testing.T) {
+	// Процесс, сохраненный до появления конечных статусов
+	workflow := &Workflow{
+		InitialState: string(TaskStatusNew),
+		States:       []string{string(TaskStatusNew), string(TaskStatusDone), "Архив"},
+	}
+	workflow.FillTerminalStates()
+	assert.Equal(t, []string{string(TaskStatusDone)}, workflow.TerminalStates)
+	assert.Equal(t, []string{string(TaskStatusDone)}, workflow.DoneStates)
+
+	// Явно заданный пустой список сохраняется
+	workflow.TerminalStates = []string{}
+	workflow.FillTerminalStates()
+	assert.Empty(t, workflow.TerminalStates)
+
+	// Отмена не считается выполнением
+	workflow = &Workflow{
+		InitialState:   string(TaskStatusNew),
+		States:         ValidStatuses(),
+		TerminalStates: []string{string(TaskStatusDone), string(TaskStatusCancelled)},
+	}
+	workflow.FillTerminalStates()
+	assert.Equal(t, []string{string(TaskStatusDone)}, workflow.DoneStates)
+}
@@ -10,6 +10,9 @@ import (
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
+// commentColumns — список колонок комментария в порядке, ожидаемом scanComment
+const commentColumns = `id, task_id, parent_comment_id, type, user_identifier, content, created_at, edited_at, deleted_at`
+
 type CommentRepository struct {
 	db *pgxpool.Pool
 }
@@ -18,31 +21,58 @@ func NewCommentRepository(db *pgxpool.Pool) *CommentRepository {
 	return &CommentRepository{db: db}
 }
 
+func scanComment(row pgx.Row) (*models.Comment, error) {
+	comment := &models.Comment{}
+	err := row.Scan(
+		&comment.ID,
+		&comment.TaskID,
+		&comment.ParentCommentID,
+		&comment.Type,
+		&comment.UserIdentifier,
+		&comment.Content,
+		&comment.CreatedAt,
+		&comment.EditedAt,
+		&comment.DeletedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return comment, nil
+}
+
+func collectComments(rows pgx.Rows) ([]models.Comment, error) {
+	defer rows.Close()
+
+	comments := []models.Comment{}
+	for rows.Next() {
+		comment, err := scanComment(rows)
+		if err != nil {
+			return nil, err
+		}
+		comments = append(comments, *comment)
+	}
+	return comments, rows.Err()
+}
+
 func (r *CommentRepository) Create(ctx context.Context, comment *models.Comment) error {
-	query := `INSERT INTO comments (task_id, user_identifier, content) 
-			  VALUES ($1, $2, $3) 
+	query := `INSERT INTO comments (task_id, parent_comment_id, type, user_identifier, content)
+			  VALUES ($1, $2, $3, $4, $5)
 			  RETURNING id, created_at`
 
 	return r.db.QueryRow(ctx, query,
 		comment.TaskID,
+		comment.ParentCommentID,
+		comment.Type,
 		comment.UserIdentifier,
 		comment.Content,
 	).Scan(&comment.ID, &comment.CreatedAt)
 }
 
 func (r *CommentRepository) GetByID(ctx context.Context, id string) (*models.Comment, error) {
-	query := `SELECT id, task_id, user_identifier, content, created_at 
+	query := `SELECT ` + commentColumns + `
 			  FROM comments WHERE id = $1`
 
-	comment := &models.Comment{}
-	err := r.db.QueryRow(ctx, query, id).Scan(
-		&comment.ID,
-		&comment.TaskID,
-		&comment.UserIdentifier,
-		&comment.Content,
-		&comment.CreatedAt,
-	)
-
+	comment, err := scanComment(r.db.QueryRow(ctx, query, id))
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, nil
@@ -53,69 +83,217 @@ func (r *CommentRepository) GetByID(ctx context.Context, id string) (*models.Com
 }
 
 func (r *CommentRepository) GetByTaskID(ctx context.Context, taskID string) ([]models.Comment, error) {
-	query := `SELECT id, task_id, user_identifier, content, created_at 
+	query := `SELECT ` + commentColumns + `
 			  FROM comments WHERE task_id = $1 ORDER BY created_at ASC`
 
 	rows, err := r.db.Query(ctx, query, taskID)
 	if err != nil {
 		return nil, err
 	}
-	defer rows.Close()
-
-	var comments []models.Comment
-	for rows.Next() {
-		var comment models.Comment
-		err := rows.Scan(
-			&comment.ID,
-			&comment.TaskID,
-			&comment.UserIdentifier,
-			&comment.Content,
-			&comment.CreatedAt,
-		)
-		if err != nil {
-			return nil, err
-		}
-		comments = append(comments, comment)
-	}
-	return comments, nil
+	return collectComments(rows)
 }
 
 func (r *CommentRepository) GetAll(ctx context.Context) ([]models.Comment, error) {
-	query := `SELECT id, task_id, user_identifier, content, created_at 
+	query := `SELECT ` + commentColumns + `
 			  FROM comments ORDER BY created_at DESC`
 
 	rows, err := r.db.Query(ctx, query)
 	if err != nil {
 		return nil, err
 	}
+	return collectComments(rows)
+}
+
+// UpdateContent заменяет текст комментария, сохраняя прежний в истории правок
+func (r *CommentRepository) UpdateContent(ctx context.Context, comment *models.Comment, editedBy string) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	_, err = tx.Exec(ctx, `INSERT INTO comment_edits (comment_id, previous_content, edited_by)
+			  SELECT id, content, $2 FROM comments WHERE id = $1`, comment.ID, editedBy)
+	if err != nil {
+		return err
+	}
+
+	query := `UPDATE comments SET content = $1, edited_at = CURRENT_TIMESTAMP
+			  WHERE id = $2
+			  RETURNING edited_at`
+	if err := tx.QueryRow(ctx, query, comment.Content, comment.ID).Scan(&comment.EditedAt); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// GetEdits возвращает историю правок комментария в хронологическом порядке
+func (r *CommentRepository) GetEdits(ctx context.Context, commentID string) ([]models.CommentEdit, error) {
+	query := `SELECT id, comment_id, previous_content, edited_by, edited_at
+			  FROM comment_edits WHERE comment_id = $1 ORDER BY edited_at ASC`
+
+	rows, err := r.db.Query(ctx, query, commentID)
+	if err != nil {
+		return nil, err
+	}
 	defer rows.Close()
 
-	var comments []models.Comment
+	edits := []models.CommentEdit{}
 	for rows.Next() {
-		var comment models.Comment
-		err := rows.Scan(
-			&comment.ID,
-			&comment.TaskID,
-			&comment.UserIdentifier,
-			&comment.Content,
-			&comment.CreatedAt,
-		)
-		if err != nil {
+		var edit models.CommentEdit
+		if err := rows.Scan(&edit.ID, &edit.CommentID, &edit.PreviousContent, &edit.EditedBy, &edit.EditedAt); err != nil {
 			return nil, err
 		}
-		comments = append(comments, comment)
+		edits = append(edits, edit)
 	}
-	return comments, nil
+	return edits, rows.Err()
 }
 
+// Delete удаляет комментарий. Комментарий с ответами заменяется заглушкой без
+// текста, чтобы ветка ответов сохранилась; заглушки, у которых после удаления
+// не осталось ответов, удаляются следом.
 func (r *CommentRepository) Delete(ctx context.Context, id string) error {
-	query := `DELETE FROM comments WHERE id = $1`
-	result, err := r.db.Exec(ctx, query, id)
+	tx, err := r.db.Begin(ctx)
 	if err != nil {
 		return err
 	}
-	if result.RowsAffected() == 0 {
-		return pgx.ErrNoRows
+	defer tx.Rollback(ctx)
+
+	var hasReplies bool
+	err = tx.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM comments WHERE parent_comment_id = $1)`, id).Scan(&hasReplies)
+	if err != nil {
+		return err
+	}
+
+	if hasReplies {
+		result, err := tx.Exec(ctx, `UPDATE comments SET content = '', deleted_at = CURRENT_TIMESTAMP
+				  WHERE id = $1 AND deleted_at IS NULL`, id)
+		if err != nil {
+			return err
+		}
+		if result.RowsAffected() == 0 {
+			return pgx.ErrNoRows
+		}
+		// Прежний текст и ссылки из него не должны оставаться у заглушки
+		for _, table := range []string{"comment_edits", "comment_mentions", "comment_task_references"} {
+			if _, err := tx.Exec(ctx, `DELETE FROM `+table+` WHERE comment_id = $1`, id); err != nil {
+				return err
+			}
+		}
+		return tx.Commit(ctx)
+	}
+
+	var parentID *string
+	err = tx.QueryRow(ctx, `DELETE FROM comments WHERE id = $1 AND deleted_at IS NULL
+			  RETURNING parent_comment_id`, id).Scan(&parentID)
+	if err != nil {
+		return err
+	}
+	for parentID != nil {
+		err = tx.QueryRow(ctx, `DELETE FROM comments
+				  WHERE id = $1 AND deleted_at IS NOT NULL
+				    AND NOT EXISTS (SELECT 1 FROM comments WHERE parent_comment_id = $1)
+				  RETURNING parent_comment_id`, *parentID).Scan(&parentID)
+		if errors.Is(err, pgx.ErrNoRows) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Commit(ctx)
+}
+
+// ReplaceReferences заменяет упомянутых в комментарии пользователей и задачи,
+// на которые он ссылается
+func (r *CommentRepository) ReplaceReferences(ctx context.Context, commentID string, userIDs, taskIDs []string) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `DELETE FROM comment_mentions WHERE comment_id = $1`, commentID); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(ctx, `DELETE FROM comment_task_references WHERE comment_id = $1`, commentID); err != nil {
+		return err
+	}
+
+	for _, userID := range userIDs {
+		_, err := tx.Exec(ctx, `INSERT INTO comment_mentions (comment_id, user_id) VALUES ($1, $2)
+				  ON CONFLICT DO NOTHING`, commentID, userID)
+		if err != nil {
+			return err
+		}
+	}
+	for _, taskID := range taskIDs {
+		_, err := tx.Exec(ctx, `INSERT INTO comment_task_references (comment_id, task_id) VALUES ($1, $2)
+				  ON CONFLICT DO NOTHING`, commentID, taskID)
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Commit(ctx)
+}
+
+// GetReferences возвращает упоминания и ссылки на задачи комментария
+func (r *CommentRepository) GetReferences(ctx context.Context, commentID string) (*models.CommentReferences, error) {
+	references := &models.CommentReferences{
+		Mentions: []models.CommentMention{},
+		Tasks:    []models.CommentTaskReference{},
+	}
+
+	rows, err := r.db.Query(ctx, `SELECT m.comment_id, m.user_id, u.username FROM comment_mentions m
+			  JOIN users u ON u.id = m.user_id
+			  WHERE m.comment_id = $1 ORDER BY u.username`, commentID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var mention models.CommentMention
+		if err := rows.Scan(&mention.CommentID, &mention.UserID, &mention.Username); err != nil {
+			return nil, err
+		}
+		references.Mentions = append(references.Mentions, mention)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	references.Tasks, err = r.queryTaskReferences(ctx, `WHERE ref.comment_id = $1`, commentID)
+	if err != nil {
+		return nil, err
+	}
+	return references, nil
+}
+
+// GetTaskBacklinks возвращает ссылки на задачу из комментариев других задач
+func (r *CommentRepository) GetTaskBacklinks(ctx context.Context, taskID string) ([]models.CommentTaskReference, error) {
+	return r.queryTaskReferences(ctx, `WHERE ref.task_id = $1 AND c.task_id <> $1`, taskID)
+}
+
+func (r *CommentRepository) queryTaskReferences(ctx context.Context, where string, arg string) ([]models.CommentTaskReference, error) {
+	rows, err := r.db.Query(ctx, `SELECT ref.comment_id, c.task_id, ref.task_id, t.number, t.title
+			  FROM comment_task_references ref
+			  JOIN comments c ON c.id = ref.comment_id
+			  JOIN tasks source ON source.id = c.task_id
+			  JOIN tasks t ON t.id = ref.task_id
+			  `+where+` AND t.deleted_at IS NULL AND source.deleted_at IS NULL
+			  ORDER BY c.created_at ASC, t.number ASC`, arg)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	references := []models.CommentTaskReference{}
+	for rows.Next() {
+		var reference models.CommentTaskReference
+		if err := rows.Scan(&reference.CommentID, &reference.SourceTaskID, &reference.TaskID, &reference.TaskNumber, &reference.TaskTitle); err != nil {
+			return nil, err
+		}
+		references = append(references, reference)
 	}
-	return nil
+	return references, rows.Err()
 }
@@ -10,6 +10,9 @@ import (
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
+// documentColumns — список колонок документа в порядке, ожидаемом scanDocument
+const documentColumns = `id, project_id, type, title, content, agent_editable, version, created_at, updated_at`
+
 type DocumentRepository struct {
 	db *pgxpool.Pool
 }
@@ -18,36 +21,75 @@ func NewDocumentRepository(db *pgxpool.Pool) *DocumentRepository {
 	return &DocumentRepository{db: db}
 }
 
-func (r *DocumentRepository) Create(ctx context.Context, document *models.Document) error {
+// scanDocument считывает документ из строки результата, выбранной по documentColumns
+func scanDocument(row pgx.Row) (*models.Document, error) {
+	document := &models.Document{}
+	err := row.Scan(
+		&document.ID,
+		&document.ProjectID,
+		&document.Type,
+		&document.Title,
+		&document.Content,
+		&document.AgentEditable,
+		&document.Version,
+		&document.CreatedAt,
+		&document.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return document, nil
+}
+
+// collectDocuments считывает все документы из результата запроса
+func collectDocuments(rows pgx.Rows) ([]models.Document, error) {
+	defer rows.Close()
+
+	var documents []models.Document
+	for rows.Next() {
+		document, err := scanDocument(rows)
+		if err != nil {
+			return nil, err
+		}
+		documents = append(documents, *document)
+	}
+	return documents, rows.Err()
+}
+
+// Create сохраняет новый документ и его первую ревизию в одной транзакции
+func (r *DocumentRepository) Create(ctx context.Context, document *models.Document, revision *models.DocumentRevision) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
 	query := `INSERT INTO documents (project_id, type, title, content, agent_editable) 
 			  VALUES ($1, $2, $3, $4, $5) 
-			  RETURNING id, created_at, updated_at`
+			  RETURNING id, version, created_at, updated_at`
 
-	return r.db.QueryRow(ctx, query,
+	err = tx.QueryRow(ctx, query,
 		document.ProjectID,
 		document.Type,
 		document.Title,
 		document.Content,
 		document.AgentEditable,
-	).Scan(&document.ID, &document.CreatedAt, &document.UpdatedAt)
+	).Scan(&document.ID, &document.Version, &document.CreatedAt, &document.UpdatedAt)
+	if err != nil {
+		return err
+	}
+
+	if err := insertDocumentRevision(ctx, tx, document, revision); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
 }
 
 func (r *DocumentRepository) GetByID(ctx context.Context, id string) (*models.Document, error) {
-	query := `SELECT id, project_id, type, title, content, agent_editable, created_at, updated_at 
+	query := `SELECT ` + documentColumns + ` 
 			  FROM documents WHERE id = $1`
 
-	document := &models.Document{}
-	err := r.db.QueryRow(ctx, query, id).Scan(
-		&document.ID,
-		&document.ProjectID,
-		&document.Type,
-		&document.Title,
-		&document.Content,
-		&document.AgentEditable,
-		&document.CreatedAt,
-		&document.UpdatedAt,
-	)
-
+	document, err := scanDocument(r.db.QueryRow(ctx, query, id))
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, nil
@@ -58,177 +100,99 @@ func (r *DocumentRepository) GetByID(ctx context.Context, id string) (*models.Do
 }
 
 func (r *DocumentRepository) GetAll(ctx context.Context) ([]models.Document, error) {
-	query := `SELECT id, project_id, type, title, content, agent_editable, created_at, updated_at 
+	query := `SELECT ` + documentColumns + ` 
 			  FROM documents ORDER BY created_at DESC`
 
 	rows, err := r.db.Query(ctx, query)
 	if err != nil {
 		return nil, err
 	}
-	defer rows.Close()
-
-	var documents []models.Document
-	for rows.Next() {
-		var document models.Document
-		err := rows.Scan(
-			&document.ID,
-			&document.ProjectID,
-			&document.Type,
-			&document.Title,
-			&document.Content,
-			&document.AgentEditable,
-			&document.CreatedAt,
-			&document.UpdatedAt,
-		)
-		if err != nil {
-			return nil, err
-		}
-		documents = append(documents, document)
-	}
-	return documents, nil
+	return collectDocuments(rows)
 }
 
 func (r *DocumentRepository) GetByProjectID(ctx context.Context, projectID string) ([]models.Document, error) {
-	query := `SELECT id, project_id, type, title, content, agent_editable, created_at, updated_at 
+	query := `SELECT ` + documentColumns + ` 
 			  FROM documents WHERE project_id = $1 ORDER BY created_at DESC`
 
 	rows, err := r.db.Query(ctx, query, projectID)
 	if err != nil {
 		return nil, err
 	}
-	defer rows.Close()
-
-	var documents []models.Document
-	for rows.Next() {
-		var document models.Document
-		err := rows.Scan(
-			&document.ID,
-			&document.ProjectID,
-			&document.Type,
-			&document.Title,
-			&document.Content,
-			&document.AgentEditable,
-			&document.CreatedAt,
-			&document.UpdatedAt,
-		)
-		if err != nil {
-			return nil, err
-		}
-		documents = append(documents, document)
-	}
-	return documents, nil
+	return collectDocuments(rows)
 }
 
 func (r *DocumentRepository) GetByType(ctx context.Context, docType string) ([]models.Document, error) {
-	query := `SELECT id, project_id, type, title, content, agent_editable, created_at, updated_at 
+	query := `SELECT ` + documentColumns + ` 
 			  FROM documents WHERE type = $1 ORDER BY created_at DESC`
 
 	rows, err := r.db.Query(ctx, query, docType)
 	if err != nil {
 		return nil, err
 	}
-	defer rows.Close()
-
-	var documents []models.Document
-	for rows.Next() {
-		var document models.Document
-		err := rows.Scan(
-			&document.ID,
-			&document.ProjectID,
-			&document.Type,
-			&document.Title,
-			&document.Content,
-			&document.AgentEditable,
-			&document.CreatedAt,
-			&document.UpdatedAt,
-		)
-		if err != nil {
-			return nil, err
-		}
-		documents = append(documents, document)
-	}
-	return documents, nil
+	return collectDocuments(rows)
 }
 
 func (r *DocumentRepository) GetByProjectIDAndType(ctx context.Context, projectID, docType string) ([]models.Document, error) {
-	query := `SELECT id, project_id, type, title, content, agent_editable, created_at, updated_at 
+	query := `SELECT ` + documentColumns + ` 
 			  FROM documents WHERE project_id = $1 AND type = $2 ORDER BY created_at DESC`
 
 	rows, err := r.db.Query(ctx, query, projectID, docType)
 	if err != nil {
 		return nil, err
 	}
-	defer rows.Close()
-
-	var documents []models.Document
-	for rows.Next() {
-		var document models.Document
-		err := rows.Scan(
-			&document.ID,
-			&document.ProjectID,
-			&document.Type,
-			&document.Title,
-			&document.Content,
-			&document.AgentEditable,
-			&document.CreatedAt,
-			&document.UpdatedAt,
-		)
-		if err != nil {
-			return nil, err
-		}
-		documents = append(documents, document)
-	}
-	return documents, nil
+	return collectDocuments(rows)
 }
 
 func (r *DocumentRepository) GetAgentEditableByProjectID(ctx context.Context, projectID string) ([]models.Document, error) {
-	query := `SELECT id, project_id, type, title, content, agent_editable, created_at, updated_at 
+	query := `SELECT ` + documentColumns + ` 
 			  FROM documents WHERE project_id = $1 AND agent_editable = true ORDER BY created_at DESC`
 
 	rows, err := r.db.Query(ctx, query, projectID)
 	if err != nil {
 		return nil, err
 	}
-	defer rows.Close()
+	return collectDocuments(rows)
+}
 
-	var documents []models.Document
-	for rows.Next() {
-		var document models.Document
-		err := rows.Scan(
-			&document.ID,
-			&document.ProjectID,
-			&document.Type,
-			&document.Title,
-			&document.Content,
-			&document.AgentEditable,
-			&document.CreatedAt,
-			&document.UpdatedAt,
-		)
-		if err != nil {
-			return nil, err
-		}
-		documents = append(documents, document)
+// Update сохраняет документ и новую ревизию, если версия документа в базе совпадает
+// с document.Version. При несовпадении возвращается ErrVersionConflict, при отсутствии
+// документа — pgx.ErrNoRows
+func (r *DocumentRepository) Update(ctx context.Context, document *models.Document, revision *models.DocumentRevision) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
 	}
-	return documents, nil
-}
+	defer tx.Rollback(ctx)
 
-func (r *DocumentRepository) Update(ctx context.Context, document *models.Document) error {
 	query := `UPDATE documents SET 
 			  type = $1, 
 			  title = $2, 
 			  content = $3, 
 			  agent_editable = $4, 
+			  version = version + 1, 
 			  updated_at = CURRENT_TIMESTAMP 
-			  WHERE id = $5 
-			  RETURNING updated_at`
+			  WHERE id = $5 AND version = $6 
+			  RETURNING version, updated_at`
 
-	return r.db.QueryRow(ctx, query,
+	err = tx.QueryRow(ctx, query,
 		document.Type,
 		document.Title,
 		document.Content,
 		document.AgentEditable,
 		document.ID,
-	).Scan(&document.UpdatedAt)
+		document.Version,
+	).Scan(&document.Version, &document.UpdatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return versionMismatchError(ctx, r.db, "documents", document.ID)
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := insertDocumentRevision(ctx, tx, document, revision); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
 }
 
 func (r *DocumentRepository) Delete(ctx context.Context, id string) error {
@@ -0,0 +1,108 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+
+	"project-manager/models"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// documentRevisionColumns — список колонок ревизии в порядке, ожидаемом scanDocumentRevision
+const documentRevisionColumns = `id, document_id, revision, type, title, content, content_hash, author, restored_from, created_at`
+
+// documentRevisionSummaryColumns совпадает с documentRevisionColumns, но без содержимого
+const documentRevisionSummaryColumns = `id, document_id, revision, type, title, '' AS content, content_hash, author, restored_from, created_at`
+
+type DocumentRevisionRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewDocumentRevisionRepository(db *pgxpool.Pool) *DocumentRevisionRepository {
+	return &DocumentRevisionRepository{db: db}
+}
+
+func scanDocumentRevision(row pgx.Row) (*models.DocumentRevision, error) {
+	revision := &models.DocumentRevision{}
+	err := row.Scan(
+		&revision.ID,
+		&revision.DocumentID,
+		&revision.Revision,
+		&revision.Type,
+		&revision.Title,
+		&revision.Content,
+		&revision.ContentHash,
+		&revision.Author,
+		&revision.RestoredFrom,
+		&revision.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return revision, nil
+}
+
+// insertDocumentRevision сохраняет снимок документа в текущей транзакции.
+// Author и RestoredFrom берутся из revision, остальные поля заполняются по документу.
+func insertDocumentRevision(ctx context.Context, tx pgx.Tx, document *models.Document, revision *models.DocumentRevision) error {
+	revision.DocumentID = document.ID
+	revision.Revision = document.Version
+	revision.Type = document.Type
+	revision.Title = document.Title
+	revision.Content = document.Content
+	revision.ContentHash = models.ContentHash(document.Content)
+
+	query := `INSERT INTO document_revisions (document_id, revision, type, title, content, content_hash, author, restored_from)
+			  VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+			  RETURNING id, created_at`
+
+	return tx.QueryRow(ctx, query,
+		revision.DocumentID,
+		revision.Revision,
+		revision.Type,
+		revision.Title,
+		revision.Content,
+		revision.ContentHash,
+		revision.Author,
+		revision.RestoredFrom,
+	).Scan(&revision.ID, &revision.CreatedAt)
+}
+
+// GetByDocumentID возвращает ревизии документа без содержимого, начиная с последней
+func (r *DocumentRevisionRepository) GetByDocumentID(ctx context.Context, documentID string) ([]models.DocumentRevision, error) {
+	query := `SELECT ` + documentRevisionSummaryColumns + `
+			  FROM document_revisions WHERE document_id = $1 ORDER BY revision DESC`
+
+	rows, err := r.db.Query(ctx, query, documentID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	revisions := []models.DocumentRevision{}
+	for rows.Next() {
+		revision, err := scanDocumentRevision(rows)
+		if err != nil {
+			return nil, err
+		}
+		revisions = append(revisions, *revision)
+	}
+	return revisions, rows.Err()
+}
+
+// GetByRevision возвращает ревизию документа с содержимым или nil, если ее нет
+func (r *DocumentRevisionRepository) GetByRevision(ctx context.Context, documentID string, number int) (*models.DocumentRevision, error) {
+	query := `SELECT ` + documentRevisionColumns + `
+			  FROM document_revisions WHERE document_id = $1 AND revision = $2`
+
+	revision, err := scanDocumentRevision(r.db.QueryRow(ctx, query, documentID, number))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return revision, nil
+}
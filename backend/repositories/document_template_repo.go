@@ -0,0 +1,124 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+
+	"project-manager/models"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// documentTemplateColumns — список колонок шаблона в порядке, ожидаемом scanDocumentTemplate
+const documentTemplateColumns = `id, type, name, description, content, created_by, created_at, updated_at`
+
+type DocumentTemplateRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewDocumentTemplateRepository(db *pgxpool.Pool) *DocumentTemplateRepository {
+	return &DocumentTemplateRepository{db: db}
+}
+
+func scanDocumentTemplate(row pgx.Row) (*models.DocumentTemplate, error) {
+	template := &models.DocumentTemplate{}
+	err := row.Scan(
+		&template.ID,
+		&template.Type,
+		&template.Name,
+		&template.Description,
+		&template.Content,
+		&template.CreatedBy,
+		&template.CreatedAt,
+		&template.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return template, nil
+}
+
+func (r *DocumentTemplateRepository) Create(ctx context.Context, template *models.DocumentTemplate) error {
+	query := `INSERT INTO document_templates (type, name, description, content, created_by)
+			  VALUES ($1, $2, $3, $4, $5)
+			  RETURNING id, created_at, updated_at`
+
+	return r.db.QueryRow(ctx, query,
+		template.Type,
+		template.Name,
+		template.Description,
+		template.Content,
+		template.CreatedBy,
+	).Scan(&template.ID, &template.CreatedAt, &template.UpdatedAt)
+}
+
+func (r *DocumentTemplateRepository) GetByID(ctx context.Context, id string) (*models.DocumentTemplate, error) {
+	query := `SELECT ` + documentTemplateColumns + ` FROM document_templates WHERE id = $1`
+
+	template, err := scanDocumentTemplate(r.db.QueryRow(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return template, nil
+}
+
+// GetAll возвращает пользовательские шаблоны; пустой docType — шаблоны всех типов
+func (r *DocumentTemplateRepository) GetAll(ctx context.Context, docType string) ([]models.DocumentTemplate, error) {
+	query := `SELECT ` + documentTemplateColumns + ` FROM document_templates
+			  WHERE ($1 = '' OR type = $1)
+			  ORDER BY type, name`
+
+	rows, err := r.db.Query(ctx, query, docType)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var templates []models.DocumentTemplate
+	for rows.Next() {
+		template, err := scanDocumentTemplate(rows)
+		if err != nil {
+			return nil, err
+		}
+		templates = append(templates, *template)
+	}
+	return templates, rows.Err()
+}
+
+// ExistsByName проверяет, занято ли имя шаблона для типа документа другим шаблоном
+func (r *DocumentTemplateRepository) ExistsByName(ctx context.Context, docType, name, excludeID string) (bool, error) {
+	query := `SELECT EXISTS (SELECT 1 FROM document_templates
+			  WHERE type = $1 AND name = $2 AND ($3 = '' OR id::text <> $3))`
+
+	var exists bool
+	err := r.db.QueryRow(ctx, query, docType, name, excludeID).Scan(&exists)
+	return exists, err
+}
+
+func (r *DocumentTemplateRepository) Update(ctx context.Context, template *models.DocumentTemplate) error {
+	query := `UPDATE document_templates SET
+			  type = $1,
+			  name = $2,
+			  description = $3,
+			  content = $4,
+			  updated_at = CURRENT_TIMESTAMP
+			  WHERE id = $5
+			  RETURNING updated_at`
+
+	return r.db.QueryRow(ctx, query,
+		template.Type,
+		template.Name,
+		template.Description,
+		template.Content,
+		template.ID,
+	).Scan(&template.UpdatedAt)
+}
+
+func (r *DocumentTemplateRepository) Delete(ctx context.Context, id string) error {
+	query := `DELETE FROM document_templates WHERE id = $1`
+	return execAffectingRow(ctx, r.db, query, id)
+}
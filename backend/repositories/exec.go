@@ -0,0 +1,26 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// execAffectingRow выполняет запрос и возвращает pgx.ErrNoRows, если он не
+// затронул ни одной строки
+func execAffectingRow(ctx context.Context, db *pgxpool.Pool, query string, args ...interface{}) error {
+	result, err := db.Exec(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+	return nil
+}
+
+// rowQuerier — общий для пула соединений и транзакции метод выборки одной строки
+type rowQuerier interface {
+	QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row
+}
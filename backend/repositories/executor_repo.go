@@ -2,11 +2,17 @@ package repositories
 
 import (
 	"context"
+	"errors"
+
 	"project-manager/models"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
+// executorColumns — список колонок исполнителя в порядке, ожидаемом scanExecutor
+const executorColumns = `id, name, kind, user_id, created_at, updated_at`
+
 type ExecutorRepository struct {
 	db *pgxpool.Pool
 }
@@ -15,32 +21,159 @@ func NewExecutorRepository(db *pgxpool.Pool) *ExecutorRepository {
 	return &ExecutorRepository{db: db}
 }
 
+func scanExecutor(row pgx.Row) (*models.Executor, error) {
+	executor := &models.Executor{}
+	err := row.Scan(
+		&executor.ID,
+		&executor.Name,
+		&executor.Kind,
+		&executor.UserID,
+		&executor.CreatedAt,
+		&executor.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return executor, nil
+}
+
 func (r *ExecutorRepository) GetAll(ctx context.Context) ([]models.Executor, error) {
-	query := `SELECT id, name, created_at FROM executors ORDER BY name ASC`
+	query := `SELECT ` + executorColumns + ` FROM executors ORDER BY name ASC`
 	rows, err := r.db.Query(ctx, query)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	var executors []models.Executor
+	executors := []models.Executor{}
 	for rows.Next() {
-		var e models.Executor
-		err := rows.Scan(&e.ID, &e.Name, &e.CreatedAt)
+		executor, err := scanExecutor(rows)
 		if err != nil {
 			return nil, err
 		}
-		executors = append(executors, e)
+		executors = append(executors, *executor)
+	}
+	return executors, rows.Err()
+}
+
+func (r *ExecutorRepository) GetByID(ctx context.Context, id string) (*models.Executor, error) {
+	query := `SELECT ` + executorColumns + ` FROM executors WHERE id = $1`
+
+	executor, err := scanExecutor(r.db.QueryRow(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return executor, nil
+}
+
+// GetByUserID возвращает исполнителя, связанного с учетной записью, или nil
+func (r *ExecutorRepository) GetByUserID(ctx context.Context, userID string) (*models.Executor, error) {
+	query := `SELECT ` + executorColumns + ` FROM executors WHERE user_id = $1`
+
+	executor, err := scanExecutor(r.db.QueryRow(ctx, query, userID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return executor, nil
+}
+
+func (r *ExecutorRepository) Create(ctx context.Context, executor *models.Executor) error {
+	query := `INSERT INTO executors (name, kind, user_id) VALUES ($1, $2, $3)
+			  RETURNING id, created_at, updated_at`
+
+	return r.db.QueryRow(ctx, query, executor.Name, executor.Kind, executor.UserID).
+		Scan(&executor.ID, &executor.CreatedAt, &executor.UpdatedAt)
+}
+
+func (r *ExecutorRepository) Update(ctx context.Context, executor *models.Executor) error {
+	query := `UPDATE executors SET name = $1, kind = $2, user_id = $3, updated_at = CURRENT_TIMESTAMP
+			  WHERE id = $4
+			  RETURNING created_at, updated_at`
+
+	return r.db.QueryRow(ctx, query, executor.Name, executor.Kind, executor.UserID, executor.ID).
+		Scan(&executor.CreatedAt, &executor.UpdatedAt)
+}
+
+// GetAssignedTasks возвращает задачи исполнителя, кроме находящихся в корзине
+func (r *ExecutorRepository) GetAssignedTasks(ctx context.Context, executorID string) ([]models.Task, error) {
+	query := `SELECT ` + taskColumns + ` FROM tasks
+			  WHERE assignee_id = $1 AND deleted_at IS NULL ORDER BY created_at ASC`
+
+	rows, err := r.db.Query(ctx, query, executorID)
+	if err != nil {
+		return nil, err
+	}
+	return collectTasks(rows)
+}
+
+// ReassignTasks передает задачи taskIDs исполнителя fromID исполнителю toID
+// (nil снимает назначение) и возвращает измененные задачи
+func (r *ExecutorRepository) ReassignTasks(ctx context.Context, fromID string, taskIDs []string, toID *string) ([]models.Task, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	tasks, err := reassignTasks(ctx, tx, fromID, taskIDs, toID)
+	if err != nil {
+		return nil, err
 	}
-	return executors, nil
+	return tasks, tx.Commit(ctx)
 }
 
-func (r *ExecutorRepository) Create(ctx context.Context, name string) (*models.Executor, error) {
-	query := `INSERT INTO executors (name) VALUES ($1) RETURNING id, name, created_at`
-	var e models.Executor
-	err := r.db.QueryRow(ctx, query, name).Scan(&e.ID, &e.Name, &e.CreatedAt)
+// Delete удаляет исполнителя, предварительно передав задачи taskIDs
+// исполнителю toID (nil снимает назначение). Остальные задачи теряют
+// назначение по ограничению внешнего ключа.
+func (r *ExecutorRepository) Delete(ctx context.Context, id string, taskIDs []string, toID *string) ([]models.Task, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	tasks, err := reassignTasks(ctx, tx, id, taskIDs, toID)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := tx.Exec(ctx, `DELETE FROM executors WHERE id = $1`, id)
+	if err != nil {
+		return nil, err
+	}
+	if result.RowsAffected() == 0 {
+		return nil, pgx.ErrNoRows
+	}
+	return tasks, tx.Commit(ctx)
+}
+
+// reassignTasks меняет исполнителя задач, которые все еще назначены fromID
+func reassignTasks(ctx context.Context, tx pgx.Tx, fromID string, taskIDs []string, toID *string) ([]models.Task, error) {
+	tasks := []models.Task{}
+	if len(taskIDs) == 0 {
+		return tasks, nil
+	}
+
+	query := `UPDATE tasks SET
+			  assignee_id = $2,
+			  version = version + 1,
+			  updated_at = CURRENT_TIMESTAMP
+			  WHERE id = ANY($3) AND assignee_id = $1 AND deleted_at IS NULL
+			  RETURNING ` + taskColumns
+
+	rows, err := tx.Query(ctx, query, fromID, toID, taskIDs)
+	if err != nil {
+		return nil, err
+	}
+	updated, err := collectTasks(rows)
 	if err != nil {
 		return nil, err
 	}
-	return &e, nil
+	return append(tasks, updated...), nil
 }
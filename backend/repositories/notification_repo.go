@@ -0,0 +1,222 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+
+	"project-manager/models"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// notificationColumns — список колонок уведомления в порядке, ожидаемом scanNotification
+const notificationColumns = `id, user_id, type, project_id, task_id, comment_id, actor, message, read_at, created_at`
+
+type NotificationRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewNotificationRepository(db *pgxpool.Pool) *NotificationRepository {
+	return &NotificationRepository{db: db}
+}
+
+func scanNotification(row pgx.Row) (*models.Notification, error) {
+	notification := &models.Notification{}
+	err := row.Scan(
+		&notification.ID,
+		&notification.UserID,
+		&notification.Type,
+		&notification.ProjectID,
+		&notification.TaskID,
+		&notification.CommentID,
+		&notification.Actor,
+		&notification.Message,
+		&notification.ReadAt,
+		&notification.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return notification, nil
+}
+
+func (r *NotificationRepository) Create(ctx context.Context, notification *models.Notification) error {
+	query := `INSERT INTO notifications (user_id, type, project_id, task_id, comment_id, actor, message)
+			  VALUES ($1, $2, $3, $4, $5, $6, $7)
+			  RETURNING id, created_at`
+
+	return r.db.QueryRow(ctx, query,
+		notification.UserID,
+		notification.Type,
+		notification.ProjectID,
+		notification.TaskID,
+		notification.CommentID,
+		notification.Actor,
+		notification.Message,
+	).Scan(&notification.ID, &notification.CreatedAt)
+}
+
+// Find возвращает входящие пользователя, начиная с новых
+func (r *NotificationRepository) Find(ctx context.Context, filter *models.NotificationFilter) ([]models.Notification, error) {
+	query := `SELECT ` + notificationColumns + ` FROM notifications
+			  WHERE user_id = $1 AND (NOT $2 OR read_at IS NULL)
+			  ORDER BY created_at DESC, id DESC
+			  LIMIT $3 OFFSET $4`
+
+	rows, err := r.db.Query(ctx, query, filter.UserID, filter.UnreadOnly, filter.Limit, filter.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	notifications := []models.Notification{}
+	for rows.Next() {
+		notification, err := scanNotification(rows)
+		if err != nil {
+			return nil, err
+		}
+		notifications = append(notifications, *notification)
+	}
+	return notifications, rows.Err()
+}
+
+// CountUnread возвращает число непрочитанных уведомлений пользователя
+func (r *NotificationRepository) CountUnread(ctx context.Context, userID string) (int, error) {
+	var count int
+	err := r.db.QueryRow(ctx, `SELECT COUNT(*) FROM notifications WHERE user_id = $1 AND read_at IS NULL`, userID).Scan(&count)
+	return count, err
+}
+
+// MarkRead отмечает уведомление пользователя прочитанным
+func (r *NotificationRepository) MarkRead(ctx context.Context, userID, id string) error {
+	query := `UPDATE notifications SET read_at = COALESCE(read_at, CURRENT_TIMESTAMP)
+			  WHERE id = $1 AND user_id = $2`
+	return execAffectingRow(ctx, r.db, query, id, userID)
+}
+
+// MarkAllRead отмечает прочитанными все уведомления пользователя и возвращает их число
+func (r *NotificationRepository) MarkAllRead(ctx context.Context, userID string) (int64, error) {
+	result, err := r.db.Exec(ctx, `UPDATE notifications SET read_at = CURRENT_TIMESTAMP
+			  WHERE user_id = $1 AND read_at IS NULL`, userID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+// GetPreferences возвращает настройки пользователя или nil, если он их не менял
+func (r *NotificationRepository) GetPreferences(ctx context.Context, userID string) (*models.NotificationPreferences, error) {
+	query := `SELECT user_id, mention, comment, status_change, assignment, updated_at
+			  FROM notification_preferences WHERE user_id = $1`
+
+	preferences := &models.NotificationPreferences{}
+	err := r.db.QueryRow(ctx, query, userID).Scan(
+		&preferences.UserID,
+		&preferences.Mention,
+		&preferences.Comment,
+		&preferences.StatusChange,
+		&preferences.Assignment,
+		&preferences.UpdatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return preferences, nil
+}
+
+func (r *NotificationRepository) SavePreferences(ctx context.Context, preferences *models.NotificationPreferences) error {
+	query := `INSERT INTO notification_preferences (user_id, mention, comment, status_change, assignment)
+			  VALUES ($1, $2, $3, $4, $5)
+			  ON CONFLICT (user_id) DO UPDATE SET
+			  mention = EXCLUDED.mention,
+			  comment = EXCLUDED.comment,
+			  status_change = EXCLUDED.status_change,
+			  assignment = EXCLUDED.assignment,
+			  updated_at = CURRENT_TIMESTAMP
+			  RETURNING updated_at`
+
+	return r.db.QueryRow(ctx, query,
+		preferences.UserID,
+		preferences.Mention,
+		preferences.Comment,
+		preferences.StatusChange,
+		preferences.Assignment,
+	).Scan(&preferences.UpdatedAt)
+}
+
+// FilterRecipients оставляет пользователей, включивших уведомления данного
+// типа; пользователи без настроек получают все уведомления
+func (r *NotificationRepository) FilterRecipients(ctx context.Context, userIDs []string, notificationType models.NotificationType) ([]string, error) {
+	if len(userIDs) == 0 {
+		return nil, nil
+	}
+
+	// Имя колонки выбирается из фиксированного набора, а не подставляется из ввода
+	var column string
+	switch notificationType {
+	case models.NotificationMention:
+		column = "mention"
+	case models.NotificationComment:
+		column = "comment"
+	case models.NotificationStatusChange:
+		column = "status_change"
+	case models.NotificationAssignment:
+		column = "assignment"
+	default:
+		return nil, errors.New("invalid notification type")
+	}
+
+	query := `SELECT u.id FROM users u
+			  LEFT JOIN notification_preferences p ON p.user_id = u.id
+			  WHERE u.id = ANY($1) AND u.is_active AND COALESCE(p.` + column + `, TRUE)`
+
+	rows, err := r.db.Query(ctx, query, userIDs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	recipients := []string{}
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		recipients = append(recipients, id)
+	}
+	return recipients, rows.Err()
+}
+
+// GetTaskParticipants возвращает пользователей, причастных к задаче: автора
+// задачи, авторов комментариев и упомянутых в комментариях
+func (r *NotificationRepository) GetTaskParticipants(ctx context.Context, taskID string) ([]models.NotificationRecipient, error) {
+	query := `SELECT u.id, u.username FROM users u
+			  WHERE u.is_active AND (
+				  u.username IN (SELECT user_identifier FROM comments WHERE task_id = $1)
+				  OR u.username IN (SELECT user_identifier FROM operation_logs
+									WHERE task_id = $1 AND operation_type = 'CREATE')
+				  OR u.id IN (SELECT m.user_id FROM comment_mentions m
+							  JOIN comments c ON c.id = m.comment_id WHERE c.task_id = $1)
+			  )
+			  ORDER BY u.username`
+
+	rows, err := r.db.Query(ctx, query, taskID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	participants := []models.NotificationRecipient{}
+	for rows.Next() {
+		var participant models.NotificationRecipient
+		if err := rows.Scan(&participant.UserID, &participant.Username); err != nil {
+			return nil, err
+		}
+		participants = append(participants, participant)
+	}
+	return participants, rows.Err()
+}
@@ -10,6 +10,9 @@ import (
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
+// operationLogColumns — список колонок лога в порядке, ожидаемом scanOperationLog
+const operationLogColumns = `id, task_id, entity_type, entity_id, user_identifier, operation_type, details, created_at`
+
 type OperationLogRepository struct {
 	db *pgxpool.Pool
 }
@@ -18,13 +21,49 @@ func NewOperationLogRepository(db *pgxpool.Pool) *OperationLogRepository {
 	return &OperationLogRepository{db: db}
 }
 
+// scanOperationLog считывает лог из строки результата, выбранной по operationLogColumns
+func scanOperationLog(row pgx.Row) (*models.OperationLog, error) {
+	log := &models.OperationLog{}
+	err := row.Scan(
+		&log.ID,
+		&log.TaskID,
+		&log.EntityType,
+		&log.EntityID,
+		&log.UserIdentifier,
+		&log.OperationType,
+		&log.Details,
+		&log.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return log, nil
+}
+
+// collectOperationLogs считывает все логи из результата запроса
+func collectOperationLogs(rows pgx.Rows) ([]models.OperationLog, error) {
+	defer rows.Close()
+
+	var logs []models.OperationLog
+	for rows.Next() {
+		log, err := scanOperationLog(rows)
+		if err != nil {
+			return nil, err
+		}
+		logs = append(logs, *log)
+	}
+	return logs, rows.Err()
+}
+
 func (r *OperationLogRepository) Create(ctx context.Context, log *models.OperationLog) error {
-	query := `INSERT INTO operation_logs (task_id, user_identifier, operation_type, details) 
-			  VALUES ($1, $2, $3, $4) 
+	query := `INSERT INTO operation_logs (task_id, entity_type, entity_id, user_identifier, operation_type, details) 
+			  VALUES ($1, $2, $3, $4, $5, $6) 
 			  RETURNING id, created_at`
 
 	return r.db.QueryRow(ctx, query,
 		log.TaskID,
+		log.EntityType,
+		log.EntityID,
 		log.UserIdentifier,
 		log.OperationType,
 		log.Details,
@@ -32,19 +71,10 @@ func (r *OperationLogRepository) Create(ctx context.Context, log *models.Operati
 }
 
 func (r *OperationLogRepository) GetByID(ctx context.Context, id string) (*models.OperationLog, error) {
-	query := `SELECT id, task_id, user_identifier, operation_type, details, created_at 
+	query := `SELECT ` + operationLogColumns + ` 
 			  FROM operation_logs WHERE id = $1`
 
-	log := &models.OperationLog{}
-	err := r.db.QueryRow(ctx, query, id).Scan(
-		&log.ID,
-		&log.TaskID,
-		&log.UserIdentifier,
-		&log.OperationType,
-		&log.Details,
-		&log.CreatedAt,
-	)
-
+	log, err := scanOperationLog(r.db.QueryRow(ctx, query, id))
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, nil
@@ -55,117 +85,108 @@ func (r *OperationLogRepository) GetByID(ctx context.Context, id string) (*model
 }
 
 func (r *OperationLogRepository) GetByTaskID(ctx context.Context, taskID string) ([]models.OperationLog, error) {
-	query := `SELECT id, task_id, user_identifier, operation_type, details, created_at 
+	query := `SELECT ` + operationLogColumns + ` 
 			  FROM operation_logs WHERE task_id = $1 ORDER BY created_at DESC`
 
 	rows, err := r.db.Query(ctx, query, taskID)
 	if err != nil {
 		return nil, err
 	}
-	defer rows.Close()
+	return collectOperationLogs(rows)
+}
 
-	var logs []models.OperationLog
-	for rows.Next() {
-		var log models.OperationLog
-		err := rows.Scan(
-			&log.ID,
-			&log.TaskID,
-			&log.UserIdentifier,
-			&log.OperationType,
-			&log.Details,
-			&log.CreatedAt,
-		)
-		if err != nil {
-			return nil, err
-		}
-		logs = append(logs, log)
+// GetByEntity возвращает логи записи в хронологическом порядке
+func (r *OperationLogRepository) GetByEntity(ctx context.Context, entityType, entityID string) ([]models.OperationLog, error) {
+	query := `SELECT ` + operationLogColumns + ` 
+			  FROM operation_logs WHERE entity_type = $1 AND entity_id = $2 ORDER BY created_at, id`
+
+	rows, err := r.db.Query(ctx, query, entityType, entityID)
+	if err != nil {
+		return nil, err
 	}
-	return logs, nil
+	return collectOperationLogs(rows)
 }
 
 func (r *OperationLogRepository) GetAll(ctx context.Context) ([]models.OperationLog, error) {
-	query := `SELECT id, task_id, user_identifier, operation_type, details, created_at 
+	query := `SELECT ` + operationLogColumns + ` 
 			  FROM operation_logs ORDER BY created_at DESC`
 
 	rows, err := r.db.Query(ctx, query)
 	if err != nil {
 		return nil, err
 	}
-	defer rows.Close()
-
-	var logs []models.OperationLog
-	for rows.Next() {
-		var log models.OperationLog
-		err := rows.Scan(
-			&log.ID,
-			&log.TaskID,
-			&log.UserIdentifier,
-			&log.OperationType,
-			&log.Details,
-			&log.CreatedAt,
-		)
-		if err != nil {
-			return nil, err
-		}
-		logs = append(logs, log)
-	}
-	return logs, nil
+	return collectOperationLogs(rows)
 }
 
 func (r *OperationLogRepository) GetByOperationType(ctx context.Context, operationType string) ([]models.OperationLog, error) {
-	query := `SELECT id, task_id, user_identifier, operation_type, details, created_at 
+	query := `SELECT ` + operationLogColumns + ` 
 			  FROM operation_logs WHERE operation_type = $1 ORDER BY created_at DESC`
 
 	rows, err := r.db.Query(ctx, query, operationType)
 	if err != nil {
 		return nil, err
 	}
-	defer rows.Close()
-
-	var logs []models.OperationLog
-	for rows.Next() {
-		var log models.OperationLog
-		err := rows.Scan(
-			&log.ID,
-			&log.TaskID,
-			&log.UserIdentifier,
-			&log.OperationType,
-			&log.Details,
-			&log.CreatedAt,
-		)
-		if err != nil {
-			return nil, err
-		}
-		logs = append(logs, log)
-	}
-	return logs, nil
+	return collectOperationLogs(rows)
 }
 
 func (r *OperationLogRepository) GetByUserIdentifier(ctx context.Context, userIdentifier string) ([]models.OperationLog, error) {
-	query := `SELECT id, task_id, user_identifier, operation_type, details, created_at 
+	query := `SELECT ` + operationLogColumns + ` 
 			  FROM operation_logs WHERE user_identifier = $1 ORDER BY created_at DESC`
 
 	rows, err := r.db.Query(ctx, query, userIdentifier)
 	if err != nil {
 		return nil, err
 	}
-	defer rows.Close()
+	return collectOperationLogs(rows)
+}
 
-	var logs []models.OperationLog
-	for rows.Next() {
-		var log models.OperationLog
-		err := rows.Scan(
-			&log.ID,
-			&log.TaskID,
-			&log.UserIdentifier,
-			&log.OperationType,
-			&log.Details,
-			&log.CreatedAt,
-		)
-		if err != nil {
-			return nil, err
-		}
-		logs = append(logs, log)
+// Find выбирает логи по фильтру, новые первыми
+func (r *OperationLogRepository) Find(ctx context.Context, filter *models.OperationLogFilter) (*models.OperationLogPage, error) {
+	b := &queryBuilder{}
+	if filter.EntityType != "" {
+		b.where("entity_type = " + b.arg(filter.EntityType))
+	}
+	if filter.EntityID != "" {
+		b.where("entity_id = " + b.arg(filter.EntityID))
+	}
+	if filter.UserIdentifier != "" {
+		b.where("user_identifier = " + b.arg(filter.UserIdentifier))
+	}
+	if filter.OperationType != "" {
+		b.where("operation_type = " + b.arg(filter.OperationType))
+	}
+	if filter.From != nil {
+		b.where("created_at >= " + b.arg(*filter.From))
+	}
+	if filter.To != nil {
+		b.where("created_at <= " + b.arg(*filter.To))
+	}
+
+	page := &models.OperationLogPage{Logs: []models.OperationLog{}}
+	countQuery := `SELECT COUNT(*) FROM operation_logs` + b.whereClause()
+	if err := r.db.QueryRow(ctx, countQuery, b.args...).Scan(&page.Total); err != nil {
+		return nil, err
+	}
+
+	query := `SELECT ` + operationLogColumns + ` FROM operation_logs` + b.whereClause() +
+		` ORDER BY created_at DESC, id DESC`
+	if filter.Limit > 0 {
+		query += " LIMIT " + b.arg(filter.Limit)
+	}
+	if filter.Offset > 0 {
+		query += " OFFSET " + b.arg(filter.Offset)
+	}
+
+	rows, err := r.db.Query(ctx, query, b.args...)
+	if err != nil {
+		return nil, err
+	}
+	logs, err := collectOperationLogs(rows)
+	if err != nil {
+		return nil, err
+	}
+	if logs != nil {
+		page.Logs = logs
 	}
-	return logs, nil
+	return page, nil
 }
@@ -0,0 +1,572 @@
+package repositories
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"project-manager/models"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// archiveTaskReferenceRegex находит ссылки на задачи вида #AUTH-0042 в тексте
+// задач и комментариев; формат совпадает с разбором ссылок в комментариях
+var archiveTaskReferenceRegex = regexp.MustCompile(`(^|[^\w#])#([A-Za-z]{1,6}-?\d+)\b`)
+
+// ProjectArchiveRepository выгружает проект со связанными записями и загружает
+// его обратно. Обе операции выполняются в одной транзакции.
+type ProjectArchiveRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewProjectArchiveRepository(db *pgxpool.Pool) *ProjectArchiveRepository {
+	return &ProjectArchiveRepository{db: db}
+}
+
+// Export возвращает архив проекта или nil, если проекта нет. Задачи из корзины
+// и связанные с ними записи в архив не попадают. Чтение идет в транзакции
+// repeatable read, чтобы архив был согласованным.
+func (r *ProjectArchiveRepository) Export(ctx context.Context, projectID string) (*models.ProjectArchive, error) {
+	tx, err := r.db.BeginTx(ctx, pgx.TxOptions{IsoLevel: pgx.RepeatableRead, AccessMode: pgx.ReadOnly})
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	project, err := scanProject(tx.QueryRow(ctx, `SELECT `+projectColumns+` FROM projects WHERE id = $1 AND deleted_at IS NULL`, projectID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	archive := &models.ProjectArchive{
+		Format:  models.ProjectArchiveFormat,
+		Version: models.ProjectArchiveVersion,
+		Project: *project,
+	}
+
+	if archive.Workflow, err = exportWorkflow(ctx, tx, projectID); err != nil {
+		return nil, err
+	}
+	if archive.FunctionalBlocks, err = exportFunctionalBlocks(ctx, tx, projectID); err != nil {
+		return nil, err
+	}
+
+	rows, err := tx.Query(ctx, `SELECT `+taskColumns+` FROM tasks
+			  WHERE project_id = $1 AND deleted_at IS NULL ORDER BY created_at ASC, number ASC`, projectID)
+	if err != nil {
+		return nil, err
+	}
+	if archive.Tasks, err = collectTasks(rows); err != nil {
+		return nil, err
+	}
+
+	rows, err = tx.Query(ctx, `SELECT `+taskDependencyColumns+` FROM task_dependencies d
+			  JOIN tasks t ON t.id = d.task_id
+			  WHERE t.project_id = $1 AND `+liveDependencyCondition+`
+			  ORDER BY d.created_at ASC`, projectID)
+	if err != nil {
+		return nil, err
+	}
+	if archive.TaskDependencies, err = collectTaskDependencies(rows); err != nil {
+		return nil, err
+	}
+
+	if archive.Comments, err = exportComments(ctx, tx, projectID); err != nil {
+		return nil, err
+	}
+
+	rows, err = tx.Query(ctx, `SELECT `+documentColumns+` FROM documents
+			  WHERE project_id = $1 ORDER BY created_at ASC`, projectID)
+	if err != nil {
+		return nil, err
+	}
+	if archive.Documents, err = collectDocuments(rows); err != nil {
+		return nil, err
+	}
+
+	if archive.DocumentRevisions, err = exportDocumentRevisions(ctx, tx, projectID); err != nil {
+		return nil, err
+	}
+
+	rows, err = tx.Query(ctx, `SELECT `+taskDocumentLinkColumns+taskDocumentLinkJoins+`
+			  WHERE t.project_id = $1 ORDER BY l.created_at ASC`, projectID)
+	if err != nil {
+		return nil, err
+	}
+	if archive.TaskDocumentLinks, err = collectTaskDocumentLinks(rows); err != nil {
+		return nil, err
+	}
+
+	if archive.Plan, err = exportPlan(ctx, tx, projectID); err != nil {
+		return nil, err
+	}
+
+	rows, err = tx.Query(ctx, `SELECT `+operationLogColumns+` FROM operation_logs
+			  WHERE (entity_type IN ('project', 'project_plan') AND entity_id = $1)
+			  OR (entity_type = 'task' AND entity_id IN (SELECT id FROM tasks WHERE project_id = $1 AND deleted_at IS NULL))
+			  OR (entity_type = 'document' AND entity_id IN (SELECT id FROM documents WHERE project_id = $1))
+			  ORDER BY created_at ASC`, projectID)
+	if err != nil {
+		return nil, err
+	}
+	if archive.OperationLogs, err = collectOperationLogs(rows); err != nil {
+		return nil, err
+	}
+	if archive.OperationLogs == nil {
+		archive.OperationLogs = []models.OperationLog{}
+	}
+
+	return archive, tx.Commit(ctx)
+}
+
+func exportWorkflow(ctx context.Context, tx pgx.Tx, projectID string) (*models.Workflow, error) {
+	var raw []byte
+	workflow := &models.Workflow{}
+	err := tx.QueryRow(ctx, `SELECT definition, updated_by, updated_at FROM project_workflows WHERE project_id = $1`, projectID).
+		Scan(&raw, &workflow.UpdatedBy, &workflow.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var definition workflowDefinition
+	if err := json.Unmarshal(raw, &definition); err != nil {
+		return nil, err
+	}
+	definition.apply(workflow)
+	return workflow, nil
+}
+
+// exportFunctionalBlocks возвращает функциональные блоки, используемые задачами проекта
+func exportFunctionalBlocks(ctx context.Context, tx pgx.Tx, projectID string) ([]models.FunctionalBlock, error) {
+	rows, err := tx.Query(ctx, `SELECT fb.id, fb.name, fb.prefix, fb.created_at, fb.updated_at FROM functional_blocks fb
+			  WHERE fb.id IN (SELECT functional_block_id FROM tasks WHERE project_id = $1 AND deleted_at IS NULL)
+			  ORDER BY fb.prefix ASC`, projectID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	blocks := []models.FunctionalBlock{}
+	for rows.Next() {
+		var fb models.FunctionalBlock
+		if err := rows.Scan(&fb.ID, &fb.Name, &fb.Prefix, &fb.CreatedAt, &fb.UpdatedAt); err != nil {
+			return nil, err
+		}
+		blocks = append(blocks, fb)
+	}
+	return blocks, rows.Err()
+}
+
+func exportComments(ctx context.Context, tx pgx.Tx, projectID string) ([]models.Comment, error) {
+	rows, err := tx.Query(ctx, `SELECT c.id, c.task_id, c.parent_comment_id, c.type, c.user_identifier, c.content, c.created_at, c.edited_at, c.deleted_at
+			  FROM comments c
+			  JOIN tasks t ON t.id = c.task_id
+			  WHERE t.project_id = $1 AND t.deleted_at IS NULL
+			  ORDER BY c.created_at ASC`, projectID)
+	if err != nil {
+		return nil, err
+	}
+	return collectComments(rows)
+}
+
+func exportDocumentRevisions(ctx context.Context, tx pgx.Tx, projectID string) ([]models.DocumentRevision, error) {
+	rows, err := tx.Query(ctx, `SELECT `+documentRevisionColumns+` FROM document_revisions
+			  WHERE document_id IN (SELECT id FROM documents WHERE project_id = $1)
+			  ORDER BY document_id ASC, revision ASC`, projectID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	revisions := []models.DocumentRevision{}
+	for rows.Next() {
+		revision, err := scanDocumentRevision(rows)
+		if err != nil {
+			return nil, err
+		}
+		revisions = append(revisions, *revision)
+	}
+	return revisions, rows.Err()
+}
+
+func exportPlan(ctx context.Context, tx pgx.Tx, projectID string) ([]string, error) {
+	rows, err := tx.Query(ctx, `SELECT p.task_id FROM project_plan_sequences p
+			  JOIN tasks t ON t.id = p.task_id AND t.deleted_at IS NULL
+			  WHERE p.project_id = $1 ORDER BY p.sequence_order ASC`, projectID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	plan := []string{}
+	for rows.Next() {
+		var taskID string
+		if err := rows.Scan(&taskID); err != nil {
+			return nil, err
+		}
+		plan = append(plan, taskID)
+	}
+	return plan, rows.Err()
+}
+
+// ProjectImport — итог загрузки архива: новый проект и новые номера задач
+// по исходным номерам
+type ProjectImport struct {
+	Project     *models.Project
+	TaskNumbers map[string]string
+}
+
+// Import создает проект по архиву с новыми идентификаторами и номерами задач.
+// Функциональные блоки сопоставляются по префиксу: существующий блок
+// используется повторно. Ссылки на записи, которых нет в архиве, сбрасываются
+// или пропускаются, ссылки #НОМЕР в задачах и комментариях переводятся на
+// новые номера. Архив должен быть заранее проверен сервисом.
+func (r *ProjectArchiveRepository) Import(ctx context.Context, archive *models.ProjectArchive, actor string) (*ProjectImport, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	project := archive.Project
+	err = tx.QueryRow(ctx, `INSERT INTO projects (name, description, status) VALUES ($1, $2, $3) RETURNING id, created_at, updated_at`,
+		project.Name, project.Description, project.Status).Scan(&project.ID, &project.CreatedAt, &project.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	project.DeletedAt = nil
+
+	if archive.Workflow != nil {
+		definition, err := json.Marshal(newWorkflowDefinition(archive.Workflow))
+		if err != nil {
+			return nil, err
+		}
+		if _, err := tx.Exec(ctx, `INSERT INTO project_workflows (project_id, definition, updated_by) VALUES ($1, $2, $3)`,
+			project.ID, definition, actor); err != nil {
+			return nil, err
+		}
+	}
+
+	blockIDs, blockPrefixes, err := importFunctionalBlocks(ctx, tx, archive.FunctionalBlocks)
+	if err != nil {
+		return nil, err
+	}
+
+	documentIDs, err := importDocuments(ctx, tx, project.ID, archive, actor)
+	if err != nil {
+		return nil, err
+	}
+
+	taskIDs, taskNumbers, err := importTasks(ctx, tx, project.ID, archive.Tasks, blockIDs, blockPrefixes, documentIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, dependency := range archive.TaskDependencies {
+		taskID, dependsOnID := taskIDs[dependency.TaskID], taskIDs[dependency.DependsOnTaskID]
+		if taskID == "" || dependsOnID == "" {
+			continue
+		}
+		_, err := tx.Exec(ctx, `INSERT INTO task_dependencies (task_id, depends_on_task_id, kind, created_by, created_at)
+				  VALUES ($1, $2, $3, $4, $5) ON CONFLICT DO NOTHING`,
+			taskID, dependsOnID, dependency.Kind, dependency.CreatedBy, dependency.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Комментарии экспортируются по времени создания, поэтому родитель
+	// ответа к моменту его вставки уже импортирован
+	commentIDs := make(map[string]string, len(archive.Comments))
+	for _, comment := range archive.Comments {
+		taskID := taskIDs[comment.TaskID]
+		if taskID == "" {
+			continue
+		}
+		var parentID *string
+		if comment.ParentCommentID != nil {
+			if id, ok := commentIDs[*comment.ParentCommentID]; ok {
+				parentID = &id
+			}
+		}
+		commentType := comment.Type
+		if commentType == "" {
+			commentType = string(models.CommentTypeUser)
+		}
+
+		var id string
+		content := rewriteTaskReferences(comment.Content, taskNumbers)
+		err := tx.QueryRow(ctx, `INSERT INTO comments (task_id, parent_comment_id, type, user_identifier, content, created_at, edited_at, deleted_at)
+				  VALUES ($1, $2, $3, $4, $5, $6, $7, $8) RETURNING id`,
+			taskID, parentID, commentType, comment.UserIdentifier, content, comment.CreatedAt, comment.EditedAt, comment.DeletedAt).Scan(&id)
+		if err != nil {
+			return nil, err
+		}
+		commentIDs[comment.ID] = id
+	}
+
+	for _, link := range archive.TaskDocumentLinks {
+		taskID, documentID := taskIDs[link.TaskID], documentIDs[link.DocumentID]
+		if taskID == "" || documentID == "" {
+			continue
+		}
+		_, err := tx.Exec(ctx, `INSERT INTO task_document_links (task_id, document_id, section_anchor, created_by, created_at)
+				  VALUES ($1, $2, $3, $4, $5) ON CONFLICT DO NOTHING`,
+			taskID, documentID, link.SectionAnchor, link.CreatedBy, link.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	order := 0
+	planned := make(map[string]bool, len(archive.Plan))
+	for _, archivedID := range archive.Plan {
+		taskID := taskIDs[archivedID]
+		if taskID == "" || planned[taskID] {
+			continue
+		}
+		planned[taskID] = true
+		order++
+		if _, err := tx.Exec(ctx, `INSERT INTO project_plan_sequences (project_id, task_id, sequence_order) VALUES ($1, $2, $3)`,
+			project.ID, taskID, order); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := importOperationLogs(ctx, tx, project.ID, archive, taskIDs, documentIDs, actor); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return &ProjectImport{Project: &project, TaskNumbers: taskNumbers}, nil
+}
+
+// importFunctionalBlocks возвращает новые идентификаторы и префиксы блоков по исходным идентификаторам
+func importFunctionalBlocks(ctx context.Context, tx pgx.Tx, blocks []models.FunctionalBlock) (map[string]string, map[string]string, error) {
+	ids := make(map[string]string, len(blocks))
+	prefixes := make(map[string]string, len(blocks))
+	for _, block := range blocks {
+		var id string
+		err := tx.QueryRow(ctx, `SELECT id FROM functional_blocks WHERE prefix = $1`, block.Prefix).Scan(&id)
+		if errors.Is(err, pgx.ErrNoRows) {
+			err = tx.QueryRow(ctx, `INSERT INTO functional_blocks (name, prefix) VALUES ($1, $2) RETURNING id`,
+				block.Name, block.Prefix).Scan(&id)
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		ids[block.ID] = id
+		prefixes[block.ID] = block.Prefix
+	}
+	return ids, prefixes, nil
+}
+
+// importDocuments создает документы с их ревизиями. Если ревизий в архиве нет,
+// текущее состояние документа сохраняется как ревизия от имени actor.
+func importDocuments(ctx context.Context, tx pgx.Tx, projectID string, archive *models.ProjectArchive, actor string) (map[string]string, error) {
+	revisions := make(map[string][]models.DocumentRevision)
+	for _, revision := range archive.DocumentRevisions {
+		revisions[revision.DocumentID] = append(revisions[revision.DocumentID], revision)
+	}
+
+	ids := make(map[string]string, len(archive.Documents))
+	for _, archived := range archive.Documents {
+		document := archived
+		document.ProjectID = projectID
+		if document.Version < 1 {
+			document.Version = 1
+		}
+		err := tx.QueryRow(ctx, `INSERT INTO documents (project_id, type, title, content, agent_editable, version, created_at, updated_at)
+				  VALUES ($1, $2, $3, $4, $5, $6, $7, $8) RETURNING id`,
+			document.ProjectID, document.Type, document.Title, document.Content, document.AgentEditable,
+			document.Version, document.CreatedAt, document.UpdatedAt).Scan(&document.ID)
+		if err != nil {
+			return nil, err
+		}
+		ids[archived.ID] = document.ID
+
+		history := revisions[archived.ID]
+		if len(history) == 0 {
+			if err := insertDocumentRevision(ctx, tx, &document, &models.DocumentRevision{Author: actor}); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		for _, revision := range history {
+			_, err := tx.Exec(ctx, `INSERT INTO document_revisions (document_id, revision, type, title, content, content_hash, author, restored_from, created_at)
+					  VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9) ON CONFLICT DO NOTHING`,
+				document.ID, revision.Revision, revision.Type, revision.Title, revision.Content,
+				models.ContentHash(revision.Content), revision.Author, revision.RestoredFrom, revision.CreatedAt)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	return ids, nil
+}
+
+// importTasks создает задачи с новыми номерами. Номера выделяются заранее,
+// чтобы ссылки #НОМЕР в описаниях и результатах указывали на новые номера
+// независимо от порядка задач в архиве. Родительские задачи проставляются
+// после создания всех задач, так как порядок в архиве произвольный.
+func importTasks(ctx context.Context, tx pgx.Tx, projectID string, tasks []models.Task, blockIDs, blockPrefixes, documentIDs map[string]string) (map[string]string, map[string]string, error) {
+	ids := make(map[string]string, len(tasks))
+	numbers := make(map[string]string, len(tasks))
+	assigned := make([]string, len(tasks))
+	blocks := make([]*string, len(tasks))
+	for i, task := range tasks {
+		prefix := models.DefaultTaskNumberPrefix
+		if task.FunctionalBlockID != nil {
+			if id, ok := blockIDs[*task.FunctionalBlockID]; ok {
+				blocks[i] = &id
+				prefix = blockPrefixes[*task.FunctionalBlockID]
+			}
+		}
+
+		number, err := nextTaskNumber(ctx, tx, prefix)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to generate task number: %w", err)
+		}
+		assigned[i] = number
+		numbers[task.Number] = number
+	}
+
+	for i, task := range tasks {
+		var documentID *string
+		if task.SourceDocumentID != nil {
+			if id, ok := documentIDs[*task.SourceDocumentID]; ok {
+				documentID = &id
+			}
+		}
+		anchor := task.SourceSectionAnchor
+		if documentID == nil {
+			anchor = nil
+		}
+
+		version := task.Version
+		if version < 1 {
+			version = 1
+		}
+		description := rewriteTaskReferences(task.Description, numbers)
+		result := rewriteTaskReferences(task.Result, numbers)
+
+		var id string
+		// Исполнители общие для экземпляра: назначение сохраняется, только если исполнитель существует
+		err := tx.QueryRow(ctx, `INSERT INTO tasks (project_id, functional_block_id, number, title, description, status, priority, type, role, assignee_id, result, source_document_id, source_section_anchor, version, created_at, updated_at)
+				  VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, (SELECT id FROM executors WHERE id = $10), $11, $12, $13, $14, $15, $16) RETURNING id`,
+			projectID, blocks[i], assigned[i], task.Title, description, task.Status, task.Priority, task.Type,
+			task.Role, task.AssigneeID, result, documentID, anchor, version, task.CreatedAt, task.UpdatedAt).Scan(&id)
+		if err != nil {
+			return nil, nil, err
+		}
+		ids[task.ID] = id
+	}
+
+	for _, task := range tasks {
+		if task.ParentTaskID == nil {
+			continue
+		}
+		parentID, ok := ids[*task.ParentTaskID]
+		if !ok {
+			continue
+		}
+		if _, err := tx.Exec(ctx, `UPDATE tasks SET parent_task_id = $1 WHERE id = $2`, parentID, ids[task.ID]); err != nil {
+			return nil, nil, err
+		}
+	}
+	return ids, numbers, nil
+}
+
+// rewriteTaskReferences заменяет в тексте ссылки #НОМЕР на номера, которые
+// задачи получили при импорте. Ссылки на задачи вне архива не меняются.
+func rewriteTaskReferences(text string, numbers map[string]string) string {
+	if len(numbers) == 0 || !strings.Contains(text, "#") {
+		return text
+	}
+	return archiveTaskReferenceRegex.ReplaceAllStringFunc(text, func(match string) string {
+		parts := archiveTaskReferenceRegex.FindStringSubmatch(match)
+		number, ok := numbers[strings.ToUpper(parts[2])]
+		if !ok {
+			return match
+		}
+		return parts[1] + "#" + number
+	})
+}
+
+// importOperationLogs переносит журнал операций, пересчитывая ссылки на записи.
+// Записи о сущностях, которых нет в архиве, пропускаются. Архив присылает
+// клиент, поэтому записи приписываются импортирующему actor и получают время
+// импорта, а исходные автор и время сохраняются в деталях под ключом "archived".
+func importOperationLogs(ctx context.Context, tx pgx.Tx, projectID string, archive *models.ProjectArchive, taskIDs, documentIDs map[string]string, actor string) error {
+	for _, log := range archive.OperationLogs {
+		var entityID string
+		switch models.LogEntityType(log.EntityType) {
+		case models.LogEntityProject, models.LogEntityProjectPlan:
+			if log.EntityID == archive.Project.ID {
+				entityID = projectID
+			}
+		case models.LogEntityTask:
+			entityID = taskIDs[log.EntityID]
+		case models.LogEntityDocument:
+			entityID = documentIDs[log.EntityID]
+		}
+		if entityID == "" {
+			continue
+		}
+
+		var taskID *string
+		if log.TaskID != nil {
+			if id, ok := taskIDs[*log.TaskID]; ok {
+				taskID = &id
+			}
+		}
+
+		details, err := archivedLogDetails(log)
+		if err != nil {
+			return err
+		}
+		// clock_timestamp сохраняет порядок записей внутри транзакции импорта
+		_, err = tx.Exec(ctx, `INSERT INTO operation_logs (task_id, entity_type, entity_id, user_identifier, operation_type, details, created_at)
+				  VALUES ($1, $2, $3, $4, $5, $6, clock_timestamp())`,
+			taskID, log.EntityType, entityID, actor, log.OperationType, details)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// archivedLogDetails добавляет к деталям записи журнала из архива ее исходных
+// автора и время. Детали, не являющиеся объектом, переносятся в поле "value".
+func archivedLogDetails(log models.OperationLog) (json.RawMessage, error) {
+	fields := make(map[string]json.RawMessage)
+	if len(log.Details) > 0 && json.Unmarshal(log.Details, &fields) != nil {
+		fields = map[string]json.RawMessage{"value": log.Details}
+	}
+	if fields == nil {
+		fields = make(map[string]json.RawMessage)
+	}
+
+	archived, err := json.Marshal(map[string]interface{}{
+		"userIdentifier": log.UserIdentifier,
+		"createdAt":      log.CreatedAt,
+	})
+	if err != nil {
+		return nil, err
+	}
+	fields["archived"] = archived
+	return json.Marshal(fields)
+}
@@ -0,0 +1,66 @@
+package repositories
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"project-manager/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRewriteTaskReferences(t *testing.T) {
+	numbers := map[string]string{
+		"TASK-0001": "TASK-0007",
+		"AUTH-0002": "AUTH-0010",
+	}
+
+	cases := []struct {
+		name string
+		text string
+		want string
+	}{
+		{"single", "См. #TASK-0001", "См. #TASK-0007"},
+		{"start of text", "#AUTH-0002 блокирует", "#AUTH-0010 блокирует"},
+		{"several", "#TASK-0001, #AUTH-0002 и (#TASK-0001)", "#TASK-0007, #AUTH-0010 и (#TASK-0007)"},
+		{"case insensitive", "после #task-0001", "после #TASK-0007"},
+		{"outside archive", "#TASK-0099 и #OTHER-1", "#TASK-0099 и #OTHER-1"},
+		{"not a reference", "issue#TASK-0001 ##TASK-0001 #TASK-00011", "issue#TASK-0001 ##TASK-0001 #TASK-00011"},
+		{"no references", "Без ссылок", "Без ссылок"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, rewriteTaskReferences(tc.text, numbers))
+		})
+	}
+
+	// Замена выполняется за один проход: новый номер не переписывается повторно
+	chain := map[string]string{"TASK-1": "TASK-2", "TASK-2": "TASK-3"}
+	assert.Equal(t, "#TASK-2 #TASK-3", rewriteTaskReferences("#TASK-1 #TASK-2", chain))
+}
+
+func TestArchivedLogDetails(t *testing.T) {
+	createdAt := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	log := models.OperationLog{
+		UserIdentifier: "admin",
+		CreatedAt:      createdAt,
+		Details:        json.RawMessage(`{"title":"Вход"}`),
+	}
+
+	details, err := archivedLogDetails(log)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"title":"Вход","archived":{"userIdentifier":"admin","createdAt":"2024-03-01T10:00:00Z"}}`, string(details))
+
+	// Детали, не являющиеся объектом, не теряются
+	log.Details = json.RawMessage(`"переименование"`)
+	details, err = archivedLogDetails(log)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"value":"переименование","archived":{"userIdentifier":"admin","createdAt":"2024-03-01T10:00:00Z"}}`, string(details))
+
+	log.Details = nil
+	details, err = archivedLogDetails(log)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"archived":{"userIdentifier":"admin","createdAt":"2024-03-01T10:00:00Z"}}`, string(details))
+}
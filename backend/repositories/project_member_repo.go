@@ -0,0 +1,98 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+
+	"project-manager/models"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type ProjectMemberRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewProjectMemberRepository(db *pgxpool.Pool) *ProjectMemberRepository {
+	return &ProjectMemberRepository{db: db}
+}
+
+// Upsert добавляет участника проекта или меняет его роль
+func (r *ProjectMemberRepository) Upsert(ctx context.Context, member *models.ProjectMember) error {
+	query := `INSERT INTO project_members (project_id, user_id, role) VALUES ($1, $2, $3)
+			  ON CONFLICT (project_id, user_id) DO UPDATE SET
+			  role = EXCLUDED.role,
+			  updated_at = CURRENT_TIMESTAMP
+			  RETURNING created_at, updated_at`
+
+	return r.db.QueryRow(ctx, query, member.ProjectID, member.UserID, member.Role).
+		Scan(&member.CreatedAt, &member.UpdatedAt)
+}
+
+func (r *ProjectMemberRepository) GetByProjectID(ctx context.Context, projectID string) ([]models.ProjectMember, error) {
+	query := `SELECT m.project_id, m.user_id, u.username, m.role, m.created_at, m.updated_at
+			  FROM project_members m JOIN users u ON u.id = m.user_id
+			  WHERE m.project_id = $1 ORDER BY u.username ASC`
+
+	rows, err := r.db.Query(ctx, query, projectID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var members []models.ProjectMember
+	for rows.Next() {
+		var m models.ProjectMember
+		if err := rows.Scan(&m.ProjectID, &m.UserID, &m.Username, &m.Role, &m.CreatedAt, &m.UpdatedAt); err != nil {
+			return nil, err
+		}
+		members = append(members, m)
+	}
+	return members, rows.Err()
+}
+
+// GetRole возвращает роль пользователя в проекте или пустую строку
+func (r *ProjectMemberRepository) GetRole(ctx context.Context, projectID, userID string) (string, error) {
+	query := `SELECT role FROM project_members WHERE project_id = $1 AND user_id = $2`
+
+	var role string
+	err := r.db.QueryRow(ctx, query, projectID, userID).Scan(&role)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", nil
+		}
+		return "", err
+	}
+	return role, nil
+}
+
+// HasRoleInAnyProject проверяет, имеет ли пользователь одну из ролей хотя бы в одном проекте
+func (r *ProjectMemberRepository) HasRoleInAnyProject(ctx context.Context, userID string, roles []string) (bool, error) {
+	query := `SELECT EXISTS (SELECT 1 FROM project_members WHERE user_id = $1 AND role = ANY($2))`
+
+	var exists bool
+	err := r.db.QueryRow(ctx, query, userID, roles).Scan(&exists)
+	return exists, err
+}
+
+// CountOwners возвращает количество владельцев проекта
+func (r *ProjectMemberRepository) CountOwners(ctx context.Context, projectID string) (int, error) {
+	query := `SELECT COUNT(*) FROM project_members WHERE project_id = $1 AND role = $2`
+
+	var count int
+	err := r.db.QueryRow(ctx, query, projectID, string(models.ProjectRoleOwner)).Scan(&count)
+	return count, err
+}
+
+func (r *ProjectMemberRepository) Delete(ctx context.Context, projectID, userID string) error {
+	query := `DELETE FROM project_members WHERE project_id = $1 AND user_id = $2`
+	result, err := r.db.Exec(ctx, query, projectID, userID)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+	return nil
+}
@@ -84,7 +84,7 @@ func (r *ProjectPlanRepository) GetProjectPlan(ctx context.Context, projectID st
 				t.number, t.title, t.description, t.status, t.priority, t.type
 			  FROM project_plan_sequences pps
 			  JOIN tasks t ON pps.task_id = t.id
-			  WHERE pps.project_id = $1
+			  WHERE pps.project_id = $1 AND t.deleted_at IS NULL
 			  ORDER BY pps.sequence_order ASC`
 
 	rows, err := r.db.Query(ctx, query, projectID)
@@ -3,6 +3,7 @@ package repositories
 import (
 	"context"
 	"errors"
+	"time"
 
 	"project-manager/models"
 
@@ -10,6 +11,9 @@ import (
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
+// projectColumns — список колонок проекта в порядке, ожидаемом scanProject
+const projectColumns = `id, name, description, status, created_at, updated_at, deleted_at`
+
 type ProjectRepository struct {
 	db *pgxpool.Pool
 }
@@ -18,15 +22,49 @@ func NewProjectRepository(db *pgxpool.Pool) *ProjectRepository {
 	return &ProjectRepository{db: db}
 }
 
+// scanProject считывает проект из строки результата, выбранной по projectColumns
+func scanProject(row pgx.Row) (*models.Project, error) {
+	project := &models.Project{}
+	err := row.Scan(&project.ID, &project.Name, &project.Description, &project.Status, &project.CreatedAt, &project.UpdatedAt, &project.DeletedAt)
+	if err != nil {
+		return nil, err
+	}
+	return project, nil
+}
+
+// collectProjects считывает все проекты из результата запроса
+func collectProjects(rows pgx.Rows) ([]models.Project, error) {
+	defer rows.Close()
+
+	var projects []models.Project
+	for rows.Next() {
+		project, err := scanProject(rows)
+		if err != nil {
+			return nil, err
+		}
+		projects = append(projects, *project)
+	}
+	return projects, rows.Err()
+}
+
 func (r *ProjectRepository) Create(ctx context.Context, project *models.Project) error {
 	query := `INSERT INTO projects (name, description, status) VALUES ($1, $2, $3) RETURNING id, created_at, updated_at`
 	return r.db.QueryRow(ctx, query, project.Name, project.Description, project.Status).Scan(&project.ID, &project.CreatedAt, &project.UpdatedAt)
 }
 
 func (r *ProjectRepository) GetByID(ctx context.Context, id string) (*models.Project, error) {
-	query := `SELECT id, name, description, status, created_at, updated_at FROM projects WHERE id = $1`
-	project := &models.Project{}
-	err := r.db.QueryRow(ctx, query, id).Scan(&project.ID, &project.Name, &project.Description, &project.Status, &project.CreatedAt, &project.UpdatedAt)
+	query := `SELECT ` + projectColumns + ` FROM projects WHERE id = $1 AND deleted_at IS NULL`
+	return r.getOne(ctx, query, id)
+}
+
+// GetByIDWithDeleted ищет проект по id, включая находящиеся в корзине
+func (r *ProjectRepository) GetByIDWithDeleted(ctx context.Context, id string) (*models.Project, error) {
+	query := `SELECT ` + projectColumns + ` FROM projects WHERE id = $1`
+	return r.getOne(ctx, query, id)
+}
+
+func (r *ProjectRepository) getOne(ctx context.Context, query string, args ...interface{}) (*models.Project, error) {
+	project, err := scanProject(r.db.QueryRow(ctx, query, args...))
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, nil
@@ -37,38 +75,82 @@ func (r *ProjectRepository) GetByID(ctx context.Context, id string) (*models.Pro
 }
 
 func (r *ProjectRepository) GetAll(ctx context.Context) ([]models.Project, error) {
-	query := `SELECT id, name, description, status, created_at, updated_at FROM projects ORDER BY created_at DESC`
+	query := `SELECT ` + projectColumns + ` FROM projects WHERE deleted_at IS NULL ORDER BY created_at DESC`
 	rows, err := r.db.Query(ctx, query)
 	if err != nil {
 		return nil, err
 	}
-	defer rows.Close()
+	return collectProjects(rows)
+}
 
-	var projects []models.Project
-	for rows.Next() {
-		var project models.Project
-		err := rows.Scan(&project.ID, &project.Name, &project.Description, &project.Status, &project.CreatedAt, &project.UpdatedAt)
-		if err != nil {
-			return nil, err
-		}
-		projects = append(projects, project)
+// GetDeleted возвращает проекты в корзине
+func (r *ProjectRepository) GetDeleted(ctx context.Context) ([]models.Project, error) {
+	query := `SELECT ` + projectColumns + ` FROM projects WHERE deleted_at IS NOT NULL ORDER BY deleted_at DESC`
+	rows, err := r.db.Query(ctx, query)
+	if err != nil {
+		return nil, err
 	}
-	return projects, nil
+	return collectProjects(rows)
 }
 
 func (r *ProjectRepository) Update(ctx context.Context, project *models.Project) error {
-	query := `UPDATE projects SET name = $1, description = $2, status = $3, updated_at = CURRENT_TIMESTAMP WHERE id = $4 RETURNING updated_at`
+	query := `UPDATE projects SET name = $1, description = $2, status = $3, updated_at = CURRENT_TIMESTAMP WHERE id = $4 AND deleted_at IS NULL RETURNING updated_at`
 	return r.db.QueryRow(ctx, query, project.Name, project.Description, project.Status, project.ID).Scan(&project.UpdatedAt)
 }
 
+// Delete перемещает проект в корзину вместе с его задачами. Задачи получают
+// ту же метку времени, что и проект, чтобы при восстановлении вернуть только их,
+// а не удаленные ранее по отдельности.
 func (r *ProjectRepository) Delete(ctx context.Context, id string) error {
-	query := `DELETE FROM projects WHERE id = $1`
-	result, err := r.db.Exec(ctx, query, id)
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	var deletedAt time.Time
+	query := `UPDATE projects SET deleted_at = CURRENT_TIMESTAMP WHERE id = $1 AND deleted_at IS NULL RETURNING deleted_at`
+	if err := tx.QueryRow(ctx, query, id).Scan(&deletedAt); err != nil {
+		return err
+	}
+
+	tasksQuery := `UPDATE tasks SET deleted_at = $2 WHERE project_id = $1 AND deleted_at IS NULL`
+	if _, err := tx.Exec(ctx, tasksQuery, id, deletedAt); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// Restore возвращает проект из корзины вместе с задачами, удаленными вместе с ним
+func (r *ProjectRepository) Restore(ctx context.Context, id string) error {
+	tx, err := r.db.Begin(ctx)
 	if err != nil {
 		return err
 	}
-	if result.RowsAffected() == 0 {
-		return pgx.ErrNoRows
+	defer tx.Rollback(ctx)
+
+	var deletedAt time.Time
+	query := `SELECT deleted_at FROM projects WHERE id = $1 AND deleted_at IS NOT NULL FOR UPDATE`
+	if err := tx.QueryRow(ctx, query, id).Scan(&deletedAt); err != nil {
+		return err
+	}
+
+	tasksQuery := `UPDATE tasks SET deleted_at = NULL WHERE project_id = $1 AND deleted_at = $2`
+	if _, err := tx.Exec(ctx, tasksQuery, id, deletedAt); err != nil {
+		return err
+	}
+
+	restoreQuery := `UPDATE projects SET deleted_at = NULL, updated_at = CURRENT_TIMESTAMP WHERE id = $1`
+	if _, err := tx.Exec(ctx, restoreQuery, id); err != nil {
+		return err
 	}
-	return nil
+
+	return tx.Commit(ctx)
+}
+
+// Purge окончательно удаляет проект из корзины со всеми данными
+func (r *ProjectRepository) Purge(ctx context.Context, id string) error {
+	query := `DELETE FROM projects WHERE id = $1 AND deleted_at IS NOT NULL`
+	return execAffectingRow(ctx, r.db, query, id)
 }
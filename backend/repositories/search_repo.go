@@ -0,0 +1,111 @@
+package repositories
+
+import (
+	"context"
+	"strings"
+
+	"project-manager/models"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// searchHeadlineOptions задает параметры выделения совпадений во фрагментах
+const searchHeadlineOptions = `StartSel=<mark>, StopSel=</mark>, MaxWords=35, MinWords=15, MaxFragments=2, FragmentDelimiter=" … "`
+
+// searchEscapedBody экранирует спецсимволы HTML в тексте до построения
+// фрагмента, чтобы единственной разметкой в нем остались теги <mark>.
+// Сущности вида &lt; парсер ts_headline считает отдельными лексемами и не режет.
+const searchEscapedBody = `replace(replace(replace(replace(replace(body,
+	'&', '&amp;'), '<', '&lt;'), '>', '&gt;'), '"', '&quot;'), '''', '&#39;')`
+
+// searchSources — подзапросы по каждому типу сущности; body — текст для
+// построения фрагмента. Параметры:
+// $1 — поисковая строка, $2 — фильтр по проекту (пустая строка — без фильтра).
+var searchSources = map[string]string{
+	models.SearchEntityTask: `
+		SELECT 'task' AS entity_type, t.id, t.project_id, t.id AS task_id,
+		       t.number || ' ' || t.title AS title,
+		       coalesce(t.description, '') || ' ' || coalesce(t.result, '') AS body,
+		       ts_rank_cd(t.search_vector, q.query) AS rank,
+		       t.updated_at
+		FROM tasks t, q
+		WHERE t.search_vector @@ q.query AND t.deleted_at IS NULL AND ($2 = '' OR t.project_id::text = $2)`,
+	models.SearchEntityComment: `
+		SELECT 'comment' AS entity_type, c.id, t.project_id, c.task_id,
+		       t.number || ' ' || t.title AS title,
+		       c.content AS body,
+		       ts_rank_cd(c.search_vector, q.query) AS rank,
+		       c.created_at AS updated_at
+		FROM comments c JOIN tasks t ON t.id = c.task_id, q
+		WHERE c.search_vector @@ q.query AND t.deleted_at IS NULL AND ($2 = '' OR t.project_id::text = $2)`,
+	models.SearchEntityDocument: `
+		SELECT 'document' AS entity_type, d.id, d.project_id, NULL::uuid AS task_id,
+		       d.title,
+		       d.content AS body,
+		       ts_rank_cd(d.search_vector, q.query) AS rank,
+		       d.updated_at
+		FROM documents d JOIN projects p ON p.id = d.project_id, q
+		WHERE d.search_vector @@ q.query AND p.deleted_at IS NULL AND ($2 = '' OR d.project_id::text = $2)`,
+}
+
+type SearchRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewSearchRepository(db *pgxpool.Pool) *SearchRepository {
+	return &SearchRepository{db: db}
+}
+
+// Search выполняет полнотекстовый поиск по выбранным типам сущностей.
+// Результаты упорядочены по релевантности, общее количество возвращается отдельно.
+func (r *SearchRepository) Search(ctx context.Context, query *models.SearchQuery) ([]models.SearchResult, int, error) {
+	var sources []string
+	for _, entityType := range query.EntityTypes {
+		sources = append(sources, searchSources[entityType])
+	}
+
+	// Фрагменты строятся только для записей текущей страницы: ts_headline
+	// заметно дороже ранжирования на длинных документах
+	sql := `WITH q AS (SELECT websearch_to_tsquery('russian', $1) AS query),
+			page AS (
+				SELECT *, COUNT(*) OVER () AS total
+				FROM (` + strings.Join(sources, " UNION ALL ") + `) AS results
+				ORDER BY rank DESC, updated_at DESC, id
+				LIMIT $3 OFFSET $4
+			)
+			SELECT entity_type, id, project_id, task_id, title,
+			       ts_headline('russian', ` + searchEscapedBody + `, q.query, '` + searchHeadlineOptions + `') AS snippet,
+			       rank, updated_at, total
+			FROM page, q
+			ORDER BY rank DESC, updated_at DESC, id`
+
+	rows, err := r.db.Query(ctx, sql, query.Query, query.ProjectID, query.Limit, query.Offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	results := []models.SearchResult{}
+	total := 0
+	for rows.Next() {
+		var result models.SearchResult
+		var rank float32
+		err := rows.Scan(
+			&result.EntityType,
+			&result.ID,
+			&result.ProjectID,
+			&result.TaskID,
+			&result.Title,
+			&result.Snippet,
+			&rank,
+			&result.UpdatedAt,
+			&total,
+		)
+		if err != nil {
+			return nil, 0, err
+		}
+		result.Rank = float64(rank)
+		results = append(results, result)
+	}
+	return results, total, rows.Err()
+}
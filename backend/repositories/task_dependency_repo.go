@@ -0,0 +1,131 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+
+	"project-manager/models"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// taskDependencyColumns — список колонок связи в порядке, ожидаемом scanTaskDependency
+const taskDependencyColumns = `d.id, d.task_id, d.depends_on_task_id, d.kind, d.created_by, d.created_at`
+
+// liveDependencyCondition исключает связи, в которых участвует задача из корзины
+const liveDependencyCondition = `NOT EXISTS (SELECT 1 FROM tasks x
+			  WHERE x.id IN (d.task_id, d.depends_on_task_id) AND x.deleted_at IS NOT NULL)`
+
+type TaskDependencyRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewTaskDependencyRepository(db *pgxpool.Pool) *TaskDependencyRepository {
+	return &TaskDependencyRepository{db: db}
+}
+
+func scanTaskDependency(row pgx.Row) (*models.TaskDependency, error) {
+	dependency := &models.TaskDependency{}
+	err := row.Scan(
+		&dependency.ID,
+		&dependency.TaskID,
+		&dependency.DependsOnTaskID,
+		&dependency.Kind,
+		&dependency.CreatedBy,
+		&dependency.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return dependency, nil
+}
+
+func collectTaskDependencies(rows pgx.Rows) ([]models.TaskDependency, error) {
+	defer rows.Close()
+
+	dependencies := []models.TaskDependency{}
+	for rows.Next() {
+		dependency, err := scanTaskDependency(rows)
+		if err != nil {
+			return nil, err
+		}
+		dependencies = append(dependencies, *dependency)
+	}
+	return dependencies, rows.Err()
+}
+
+func (r *TaskDependencyRepository) Create(ctx context.Context, dependency *models.TaskDependency) error {
+	query := `INSERT INTO task_dependencies (task_id, depends_on_task_id, kind, created_by)
+			  VALUES ($1, $2, $3, $4)
+			  RETURNING id, created_at`
+
+	return r.db.QueryRow(ctx, query,
+		dependency.TaskID,
+		dependency.DependsOnTaskID,
+		dependency.Kind,
+		dependency.CreatedBy,
+	).Scan(&dependency.ID, &dependency.CreatedAt)
+}
+
+func (r *TaskDependencyRepository) GetByID(ctx context.Context, id string) (*models.TaskDependency, error) {
+	query := `SELECT ` + taskDependencyColumns + ` FROM task_dependencies d WHERE d.id = $1`
+
+	dependency, err := scanTaskDependency(r.db.QueryRow(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return dependency, nil
+}
+
+// Exists проверяет наличие связи заданного вида между задачами
+func (r *TaskDependencyRepository) Exists(ctx context.Context, taskID, dependsOnTaskID, kind string) (bool, error) {
+	query := `SELECT EXISTS (SELECT 1 FROM task_dependencies
+			  WHERE task_id = $1 AND depends_on_task_id = $2 AND kind = $3)`
+
+	var exists bool
+	err := r.db.QueryRow(ctx, query, taskID, dependsOnTaskID, kind).Scan(&exists)
+	return exists, err
+}
+
+// GetByTaskID возвращает все связи, в которых участвует задача
+func (r *TaskDependencyRepository) GetByTaskID(ctx context.Context, taskID string) ([]models.TaskDependency, error) {
+	query := `SELECT ` + taskDependencyColumns + ` FROM task_dependencies d
+			  WHERE (d.task_id = $1 OR d.depends_on_task_id = $1) AND ` + liveDependencyCondition + `
+			  ORDER BY d.created_at ASC`
+
+	rows, err := r.db.Query(ctx, query, taskID)
+	if err != nil {
+		return nil, err
+	}
+	return collectTaskDependencies(rows)
+}
+
+// GetByProjectID возвращает связи между задачами проекта
+func (r *TaskDependencyRepository) GetByProjectID(ctx context.Context, projectID string) ([]models.TaskDependency, error) {
+	query := `SELECT ` + taskDependencyColumns + ` FROM task_dependencies d
+			  JOIN tasks t ON t.id = d.task_id
+			  WHERE t.project_id = $1 AND ` + liveDependencyCondition + `
+			  ORDER BY d.created_at ASC`
+
+	rows, err := r.db.Query(ctx, query, projectID)
+	if err != nil {
+		return nil, err
+	}
+	return collectTaskDependencies(rows)
+}
+
+func (r *TaskDependencyRepository) Delete(ctx context.Context, id string) error {
+	query := `DELETE FROM task_dependencies WHERE id = $1`
+	result, err := r.db.Exec(ctx, query, id)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+	return nil
+}
@@ -0,0 +1,128 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+
+	"project-manager/models"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// taskDocumentLinkColumns — список колонок связи в порядке, ожидаемом scanTaskDocumentLink
+const taskDocumentLinkColumns = `l.id, l.task_id, l.document_id, l.section_anchor, l.created_by, l.created_at,
+			  d.title, d.type, t.number, t.title, t.status`
+
+// taskDocumentLinkJoins присоединяет документ и задачу; связи задач из корзины не показываются
+const taskDocumentLinkJoins = ` FROM task_document_links l
+			  JOIN documents d ON d.id = l.document_id
+			  JOIN tasks t ON t.id = l.task_id AND t.deleted_at IS NULL`
+
+type TaskDocumentLinkRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewTaskDocumentLinkRepository(db *pgxpool.Pool) *TaskDocumentLinkRepository {
+	return &TaskDocumentLinkRepository{db: db}
+}
+
+func scanTaskDocumentLink(row pgx.Row) (*models.TaskDocumentLink, error) {
+	link := &models.TaskDocumentLink{}
+	err := row.Scan(
+		&link.ID,
+		&link.TaskID,
+		&link.DocumentID,
+		&link.SectionAnchor,
+		&link.CreatedBy,
+		&link.CreatedAt,
+		&link.DocumentTitle,
+		&link.DocumentType,
+		&link.TaskNumber,
+		&link.TaskTitle,
+		&link.TaskStatus,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return link, nil
+}
+
+func collectTaskDocumentLinks(rows pgx.Rows) ([]models.TaskDocumentLink, error) {
+	defer rows.Close()
+
+	links := []models.TaskDocumentLink{}
+	for rows.Next() {
+		link, err := scanTaskDocumentLink(rows)
+		if err != nil {
+			return nil, err
+		}
+		links = append(links, *link)
+	}
+	return links, rows.Err()
+}
+
+func (r *TaskDocumentLinkRepository) Create(ctx context.Context, link *models.TaskDocumentLink) error {
+	query := `INSERT INTO task_document_links (task_id, document_id, section_anchor, created_by)
+			  VALUES ($1, $2, $3, $4)
+			  RETURNING id, created_at`
+
+	return r.db.QueryRow(ctx, query,
+		link.TaskID,
+		link.DocumentID,
+		link.SectionAnchor,
+		link.CreatedBy,
+	).Scan(&link.ID, &link.CreatedAt)
+}
+
+func (r *TaskDocumentLinkRepository) GetByID(ctx context.Context, id string) (*models.TaskDocumentLink, error) {
+	query := `SELECT ` + taskDocumentLinkColumns + taskDocumentLinkJoins + ` WHERE l.id = $1`
+
+	link, err := scanTaskDocumentLink(r.db.QueryRow(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return link, nil
+}
+
+// Exists проверяет наличие связи задачи с документом (или его разделом)
+func (r *TaskDocumentLinkRepository) Exists(ctx context.Context, taskID, documentID, sectionAnchor string) (bool, error) {
+	query := `SELECT EXISTS (SELECT 1 FROM task_document_links
+			  WHERE task_id = $1 AND document_id = $2 AND section_anchor = $3)`
+
+	var exists bool
+	err := r.db.QueryRow(ctx, query, taskID, documentID, sectionAnchor).Scan(&exists)
+	return exists, err
+}
+
+// GetByTaskID возвращает документы, связанные с задачей
+func (r *TaskDocumentLinkRepository) GetByTaskID(ctx context.Context, taskID string) ([]models.TaskDocumentLink, error) {
+	query := `SELECT ` + taskDocumentLinkColumns + taskDocumentLinkJoins + `
+			  WHERE l.task_id = $1 ORDER BY l.created_at ASC`
+
+	rows, err := r.db.Query(ctx, query, taskID)
+	if err != nil {
+		return nil, err
+	}
+	return collectTaskDocumentLinks(rows)
+}
+
+// GetByDocumentID возвращает задачи, связанные с документом
+func (r *TaskDocumentLinkRepository) GetByDocumentID(ctx context.Context, documentID string) ([]models.TaskDocumentLink, error) {
+	query := `SELECT ` + taskDocumentLinkColumns + taskDocumentLinkJoins + `
+			  WHERE l.document_id = $1 ORDER BY l.section_anchor ASC, t.number ASC`
+
+	rows, err := r.db.Query(ctx, query, documentID)
+	if err != nil {
+		return nil, err
+	}
+	return collectTaskDocumentLinks(rows)
+}
+
+func (r *TaskDocumentLinkRepository) Delete(ctx context.Context, id string) error {
+	query := `DELETE FROM task_document_links WHERE id = $1`
+	return execAffectingRow(ctx, r.db, query, id)
+}
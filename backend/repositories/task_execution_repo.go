@@ -0,0 +1,321 @@
+package repositories
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"project-manager/models"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const taskExecutionColumns = `id, task_id, project_id, command, source, user_id, status, progress, message, task_title, strategy, error, steps, logs, result, started_at, finished_at, created_at, updated_at`
+
+type TaskExecutionRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewTaskExecutionRepository(db *pgxpool.Pool) *TaskExecutionRepository {
+	return &TaskExecutionRepository{db: db}
+}
+
+// scanTaskExecution считывает строку task_executions, разбирая JSONB-поля
+func scanTaskExecution(row pgx.Row) (*models.TaskExecution, error) {
+	var execution models.TaskExecution
+	var steps, logs, result []byte
+
+	err := row.Scan(
+		&execution.ID,
+		&execution.TaskID,
+		&execution.ProjectID,
+		&execution.Command,
+		&execution.Source,
+		&execution.UserID,
+		&execution.Status,
+		&execution.Progress,
+		&execution.Message,
+		&execution.TaskTitle,
+		&execution.Strategy,
+		&execution.Error,
+		&steps,
+		&logs,
+		&result,
+		&execution.StartedAt,
+		&execution.FinishedAt,
+		&execution.CreatedAt,
+		&execution.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(steps, &execution.Steps); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(logs, &execution.Logs); err != nil {
+		return nil, err
+	}
+	if len(result) > 0 {
+		if err := json.Unmarshal(result, &execution.Result); err != nil {
+			return nil, err
+		}
+	}
+	return &execution, nil
+}
+
+// marshalExecutionState сериализует изменяемые JSONB-поля выполнения
+func marshalExecutionState(execution *models.TaskExecution) (steps, logs, result []byte, err error) {
+	if execution.Steps == nil {
+		execution.Steps = []models.TaskExecutionStep{}
+	}
+	if execution.Logs == nil {
+		execution.Logs = []string{}
+	}
+
+	if steps, err = json.Marshal(execution.Steps); err != nil {
+		return nil, nil, nil, err
+	}
+	if logs, err = json.Marshal(execution.Logs); err != nil {
+		return nil, nil, nil, err
+	}
+	if execution.Result != nil {
+		if result, err = json.Marshal(execution.Result); err != nil {
+			return nil, nil, nil, err
+		}
+	}
+	return steps, logs, result, nil
+}
+
+func (r *TaskExecutionRepository) Create(ctx context.Context, execution *models.TaskExecution) error {
+	steps, logs, result, err := marshalExecutionState(execution)
+	if err != nil {
+		return err
+	}
+
+	query := `INSERT INTO task_executions (task_id, project_id, command, source, user_id, status, progress, message, steps, logs, result)
+			  VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+			  RETURNING id, started_at, created_at, updated_at`
+
+	return r.db.QueryRow(ctx, query,
+		execution.TaskID,
+		execution.ProjectID,
+		execution.Command,
+		execution.Source,
+		execution.UserID,
+		execution.Status,
+		execution.Progress,
+		execution.Message,
+		steps,
+		logs,
+		result,
+	).Scan(&execution.ID, &execution.StartedAt, &execution.CreatedAt, &execution.UpdatedAt)
+}
+
+func (r *TaskExecutionRepository) GetByID(ctx context.Context, id string) (*models.TaskExecution, error) {
+	query := `SELECT ` + taskExecutionColumns + ` FROM task_executions WHERE id = $1`
+
+	execution, err := scanTaskExecution(r.db.QueryRow(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return execution, nil
+}
+
+// UpdateProgress сохраняет состояние выполнения, если оно еще активно.
+// Возвращает false, если выполнение уже завершено или отменено.
+func (r *TaskExecutionRepository) UpdateProgress(ctx context.Context, execution *models.TaskExecution) (bool, error) {
+	steps, logs, result, err := marshalExecutionState(execution)
+	if err != nil {
+		return false, err
+	}
+
+	query := `UPDATE task_executions SET
+			  task_id = $1,
+			  task_title = $2,
+			  strategy = $3,
+			  status = $4,
+			  progress = $5,
+			  message = $6,
+			  error = $7,
+			  steps = $8,
+			  logs = $9,
+			  result = $10,
+			  finished_at = $11,
+			  updated_at = CURRENT_TIMESTAMP
+			  WHERE id = $12 AND status IN ('pending', 'running')
+			  RETURNING updated_at`
+
+	err = r.db.QueryRow(ctx, query,
+		execution.TaskID,
+		execution.TaskTitle,
+		execution.Strategy,
+		execution.Status,
+		execution.Progress,
+		execution.Message,
+		execution.Error,
+		steps,
+		logs,
+		result,
+		execution.FinishedAt,
+		execution.ID,
+	).Scan(&execution.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// FailActive помечает все незавершенные выполнения как проваленные
+func (r *TaskExecutionRepository) FailActive(ctx context.Context, reason string, startedBefore time.Time) (int64, error) {
+	query := `UPDATE task_executions SET
+			  status = 'failed',
+			  error = $1,
+			  message = $1,
+			  finished_at = CURRENT_TIMESTAMP,
+			  updated_at = CURRENT_TIMESTAMP
+			  WHERE status IN ('pending', 'running') AND started_at < $2`
+
+	result, err := r.db.Exec(ctx, query, reason, startedBefore)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+func (r *TaskExecutionRepository) GetHistory(ctx context.Context, userID string, limit, offset int) ([]models.TaskExecution, int, error) {
+	var total int
+	countQuery := `SELECT COUNT(*) FROM task_executions WHERE ($1 = '' OR user_id = $1)`
+	if err := r.db.QueryRow(ctx, countQuery, userID).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	query := `SELECT ` + taskExecutionColumns + ` FROM task_executions
+			  WHERE ($1 = '' OR user_id = $1)
+			  ORDER BY started_at DESC
+			  LIMIT $2 OFFSET $3`
+
+	rows, err := r.db.Query(ctx, query, userID, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var executions []models.TaskExecution
+	for rows.Next() {
+		execution, err := scanTaskExecution(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		executions = append(executions, *execution)
+	}
+	return executions, total, rows.Err()
+}
+
+func (r *TaskExecutionRepository) GetActiveByTaskID(ctx context.Context, taskID string) ([]models.TaskExecution, error) {
+	query := `SELECT ` + taskExecutionColumns + ` FROM task_executions
+			  WHERE task_id = $1 AND status IN ('pending', 'running')
+			  ORDER BY started_at DESC`
+
+	rows, err := r.db.Query(ctx, query, taskID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var executions []models.TaskExecution
+	for rows.Next() {
+		execution, err := scanTaskExecution(rows)
+		if err != nil {
+			return nil, err
+		}
+		executions = append(executions, *execution)
+	}
+	return executions, rows.Err()
+}
+
+func (r *TaskExecutionRepository) GetMetrics(ctx context.Context, userID string, since time.Time) (*models.TaskExecutionMetrics, error) {
+	metrics := &models.TaskExecutionMetrics{
+		StrategiesUsed: make(map[string]int),
+		CommonErrors:   make(map[string]int),
+	}
+
+	totalsQuery := `SELECT
+				COUNT(*),
+				COUNT(*) FILTER (WHERE status = 'completed'),
+				COUNT(*) FILTER (WHERE status = 'failed'),
+				COUNT(*) FILTER (WHERE status = 'cancelled'),
+				COALESCE(AVG(EXTRACT(EPOCH FROM (finished_at - started_at)) * 1000) FILTER (WHERE finished_at IS NOT NULL), 0)
+			  FROM task_executions
+			  WHERE started_at >= $1 AND ($2 = '' OR user_id = $2)`
+
+	err := r.db.QueryRow(ctx, totalsQuery, since, userID).Scan(
+		&metrics.TotalExecutions,
+		&metrics.SuccessfulExecutions,
+		&metrics.FailedExecutions,
+		&metrics.CancelledExecutions,
+		&metrics.AverageExecutionTime,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	strategiesQuery := `SELECT strategy, COUNT(*) FROM task_executions
+				WHERE started_at >= $1 AND ($2 = '' OR user_id = $2) AND strategy <> ''
+				GROUP BY strategy`
+	if err := r.collectCounts(ctx, strategiesQuery, metrics.StrategiesUsed, since, userID); err != nil {
+		return nil, err
+	}
+
+	errorsQuery := `SELECT error, COUNT(*) FROM task_executions
+			WHERE started_at >= $1 AND ($2 = '' OR user_id = $2) AND status = 'failed' AND error <> ''
+			GROUP BY error ORDER BY COUNT(*) DESC LIMIT 10`
+	if err := r.collectCounts(ctx, errorsQuery, metrics.CommonErrors, since, userID); err != nil {
+		return nil, err
+	}
+
+	return metrics, nil
+}
+
+// collectCounts заполняет карту результатами запроса вида (ключ, количество)
+func (r *TaskExecutionRepository) collectCounts(ctx context.Context, query string, target map[string]int, args ...interface{}) error {
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var key string
+		var count int
+		if err := rows.Scan(&key, &count); err != nil {
+			return err
+		}
+		target[key] = count
+	}
+	return rows.Err()
+}
+
+// Cancel отменяет активное выполнение. Возвращает false, если выполнение уже не активно.
+func (r *TaskExecutionRepository) Cancel(ctx context.Context, id, message string) (bool, error) {
+	query := `UPDATE task_executions SET
+			  status = 'cancelled',
+			  message = $1,
+			  finished_at = CURRENT_TIMESTAMP,
+			  updated_at = CURRENT_TIMESTAMP
+			  WHERE id = $2 AND status IN ('pending', 'running')`
+
+	result, err := r.db.Exec(ctx, query, message, id)
+	if err != nil {
+		return false, err
+	}
+	return result.RowsAffected() > 0, nil
+}
@@ -0,0 +1,262 @@
+package repositories
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"project-manager/models"
+)
+
+// ErrInvalidCursor возвращается при передаче поврежденного курсора пагинации
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// taskSortField описывает SQL-выражение для сортировки и тип значения курсора
+type taskSortField struct {
+	expr string
+	cast string
+}
+
+var taskSortFields = map[string]taskSortField{
+	"created_at": {expr: "created_at", cast: "timestamptz"},
+	"updated_at": {expr: "updated_at", cast: "timestamptz"},
+	"number":     {expr: "number", cast: "text"},
+	"title":      {expr: "title", cast: "text"},
+	"status":     {expr: "status", cast: "text"},
+	"type":       {expr: "type", cast: "text"},
+	"priority": {
+		expr: `CASE priority WHEN 'Низкий' THEN 1 WHEN 'Средний' THEN 2 WHEN 'Высокий' THEN 3 WHEN 'Критический' THEN 4 ELSE 0 END`,
+		cast: "int",
+	},
+}
+
+// taskCursor — позиция в выборке: сортировка, для которой он выдан, значение
+// поля сортировки и id последней задачи
+type taskCursor struct {
+	Sort  string `json:"s"`
+	Value string `json:"v"`
+	ID    string `json:"id"`
+}
+
+// taskCursorSort описывает сортировку для курсора: поле и направление
+func taskCursorSort(sortBy string, descending bool) string {
+	if descending {
+		return sortBy + ":" + models.SortOrderDesc
+	}
+	return sortBy + ":" + models.SortOrderAsc
+}
+
+func encodeTaskCursor(cursor taskCursor) string {
+	data, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// decodeTaskCursor разбирает курсор и проверяет, что он выдан для сортировки
+// sort по полю field: значение курсора подставляется в запрос с приведением
+// к типу поля, и чужой курсор иначе привел бы к ошибке БД
+func decodeTaskCursor(encoded, sort string, field taskSortField) (*taskCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	var cursor taskCursor
+	if err := json.Unmarshal(data, &cursor); err != nil || !models.IsValidUUID(cursor.ID) || cursor.Sort != sort {
+		return nil, ErrInvalidCursor
+	}
+
+	switch field.cast {
+	case "timestamptz":
+		_, err = time.Parse(time.RFC3339Nano, cursor.Value)
+	case "int":
+		_, err = strconv.Atoi(cursor.Value)
+	}
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	return &cursor, nil
+}
+
+// taskSortValue возвращает значение поля сортировки задачи для курсора
+func taskSortValue(task *models.Task, field string) string {
+	switch field {
+	case "updated_at":
+		return task.UpdatedAt.Format(time.RFC3339Nano)
+	case "number":
+		return task.Number
+	case "title":
+		return task.Title
+	case "status":
+		return task.Status
+	case "type":
+		return task.Type
+	case "priority":
+		return strconv.Itoa(models.PriorityRank(task.Priority))
+	default:
+		return task.CreatedAt.Format(time.RFC3339Nano)
+	}
+}
+
+// escapeLike экранирует спецсимволы шаблона LIKE
+func escapeLike(value string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+	return replacer.Replace(value)
+}
+
+// queryBuilder собирает условия WHERE и аргументы динамического запроса
+type queryBuilder struct {
+	conditions []string
+	args       []interface{}
+}
+
+// arg добавляет аргумент и возвращает его плейсхолдер
+func (b *queryBuilder) arg(value interface{}) string {
+	b.args = append(b.args, value)
+	return fmt.Sprintf("$%d", len(b.args))
+}
+
+func (b *queryBuilder) where(condition string) {
+	b.conditions = append(b.conditions, condition)
+}
+
+func (b *queryBuilder) whereClause() string {
+	if len(b.conditions) == 0 {
+		return ""
+	}
+	return " WHERE " + strings.Join(b.conditions, " AND ")
+}
+
+// applyTaskFilter добавляет в построитель условия фильтра
+func applyTaskFilter(b *queryBuilder, filter *models.TaskFilter) {
+	b.where("deleted_at IS NULL")
+	if filter.ProjectID != "" {
+		b.where("project_id = " + b.arg(filter.ProjectID))
+	}
+	switch filter.FunctionalBlockID {
+	case "":
+	case models.TaskFilterNone:
+		b.where("functional_block_id IS NULL")
+	default:
+		b.where("functional_block_id = " + b.arg(filter.FunctionalBlockID))
+	}
+	switch filter.ParentTaskID {
+	case "":
+	case models.TaskFilterNone:
+		b.where("parent_task_id IS NULL")
+	default:
+		b.where("parent_task_id = " + b.arg(filter.ParentTaskID))
+	}
+	switch filter.AssigneeID {
+	case "":
+	case models.TaskFilterNone:
+		b.where("assignee_id IS NULL")
+	default:
+		b.where("assignee_id = " + b.arg(filter.AssigneeID))
+	}
+	if len(filter.Statuses) > 0 {
+		b.where("status = ANY(" + b.arg(filter.Statuses) + ")")
+	}
+	if len(filter.Priorities) > 0 {
+		b.where("priority = ANY(" + b.arg(filter.Priorities) + ")")
+	}
+	if len(filter.Types) > 0 {
+		b.where("type = ANY(" + b.arg(filter.Types) + ")")
+	}
+	if filter.Role != "" {
+		b.where("role = " + b.arg(filter.Role))
+	}
+	if filter.CreatedFrom != nil {
+		b.where("created_at >= " + b.arg(*filter.CreatedFrom))
+	}
+	if filter.CreatedTo != nil {
+		b.where("created_at <= " + b.arg(*filter.CreatedTo))
+	}
+	if filter.UpdatedFrom != nil {
+		b.where("updated_at >= " + b.arg(*filter.UpdatedFrom))
+	}
+	if filter.UpdatedTo != nil {
+		b.where("updated_at <= " + b.arg(*filter.UpdatedTo))
+	}
+	if query := strings.TrimSpace(filter.Query); query != "" {
+		pattern := b.arg("%" + escapeLike(query) + "%")
+		b.where("(title ILIKE " + pattern + " OR description ILIKE " + pattern + " OR number ILIKE " + pattern + ")")
+	}
+}
+
+// Find выбирает задачи по фильтру с сортировкой и пагинацией (limit/offset или курсор)
+func (r *TaskRepository) Find(ctx context.Context, filter *models.TaskFilter) (*models.TaskPage, error) {
+	sortBy := filter.SortBy
+	if sortBy == "" {
+		sortBy = "created_at"
+	}
+	sortField, ok := taskSortFields[sortBy]
+	if !ok {
+		return nil, fmt.Errorf("invalid sort field: %s", sortBy)
+	}
+	descending := filter.SortOrder != models.SortOrderAsc
+
+	b := &queryBuilder{}
+	applyTaskFilter(b, filter)
+
+	// Общее количество считается без учета пагинации
+	page := &models.TaskPage{Tasks: []models.Task{}}
+	countQuery := `SELECT COUNT(*) FROM tasks` + b.whereClause()
+	if err := r.db.QueryRow(ctx, countQuery, b.args...).Scan(&page.Total); err != nil {
+		return nil, err
+	}
+
+	if filter.Cursor != "" {
+		cursor, err := decodeTaskCursor(filter.Cursor, taskCursorSort(sortBy, descending), sortField)
+		if err != nil {
+			return nil, err
+		}
+		comparison := ">"
+		if descending {
+			comparison = "<"
+		}
+		b.where(fmt.Sprintf("(%s, id) %s (%s::%s, %s::uuid)",
+			sortField.expr, comparison, b.arg(cursor.Value), sortField.cast, b.arg(cursor.ID)))
+	}
+
+	direction := "ASC"
+	if descending {
+		direction = "DESC"
+	}
+	query := `SELECT ` + taskColumns + ` FROM tasks` + b.whereClause() +
+		fmt.Sprintf(" ORDER BY %s %s, id %s", sortField.expr, direction, direction)
+
+	if filter.Limit > 0 {
+		// Запрашиваем на одну запись больше, чтобы понять, есть ли следующая страница
+		query += " LIMIT " + b.arg(filter.Limit+1)
+	}
+	if filter.Cursor == "" && filter.Offset > 0 {
+		query += " OFFSET " + b.arg(filter.Offset)
+	}
+
+	rows, err := r.db.Query(ctx, query, b.args...)
+	if err != nil {
+		return nil, err
+	}
+	tasks, err := collectTasks(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	if filter.Limit > 0 && len(tasks) > filter.Limit {
+		tasks = tasks[:filter.Limit]
+		last := &tasks[len(tasks)-1]
+		page.NextCursor = encodeTaskCursor(taskCursor{
+			Sort:  taskCursorSort(sortBy, descending),
+			Value: taskSortValue(last, sortBy),
+			ID:    last.ID,
+		})
+	}
+	if tasks != nil {
+		page.Tasks = tasks
+	}
+	return page, nil
+}
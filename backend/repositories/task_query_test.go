@@ -0,0 +1,55 @@
+package repositories
+
+import (
+	"encoding/base64"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const cursorTaskID = "8b1f3c1e-0000-4000-8000-000000000001"
+
+func TestTaskCursorRoundTrip(t *testing.T) {
+	sort := taskCursorSort("created_at", true)
+	cursor := taskCursor{Sort: sort, Value: "2024-01-10T12:00:00.123456Z", ID: cursorTaskID}
+
+	encoded := encodeTaskCursor(cursor)
+	assert.NotContains(t, encoded, "=")
+
+	decoded, err := decodeTaskCursor(encoded, sort, taskSortFields["created_at"])
+	require.NoError(t, err)
+	assert.Equal(t, cursor, *decoded)
+
+	// Пустое значение текстового поля сортировки допустимо
+	sort = taskCursorSort("title", false)
+	empty := taskCursor{Sort: sort, ID: cursorTaskID}
+	decoded, err = decodeTaskCursor(encodeTaskCursor(empty), sort, taskSortFields["title"])
+	require.NoError(t, err)
+	assert.Equal(t, empty, *decoded)
+}
+
+func TestDecodeTaskCursorInvalid(t *testing.T) {
+	sort := taskCursorSort("created_at", true)
+	cases := map[string]string{
+		"not base64":  "!!!",
+		"not json":    base64.RawURLEncoding.EncodeToString([]byte("cursor")),
+		"missing id":  base64.RawURLEncoding.EncodeToString([]byte(`{"s":"created_at:desc","v":"2024-01-10T12:00:00Z"}`)),
+		"invalid id":  encodeTaskCursor(taskCursor{Sort: sort, Value: "2024-01-10T12:00:00Z", ID: "id"}),
+		"other field": encodeTaskCursor(taskCursor{Sort: taskCursorSort("title", true), Value: "Вход", ID: cursorTaskID}),
+		"other order": encodeTaskCursor(taskCursor{Sort: taskCursorSort("created_at", false), Value: "2024-01-10T12:00:00Z", ID: cursorTaskID}),
+		"bad value":   encodeTaskCursor(taskCursor{Sort: sort, Value: "Вход", ID: cursorTaskID}),
+		"empty input": "",
+	}
+	for name, encoded := range cases {
+		t.Run(name, func(t *testing.T) {
+			_, err := decodeTaskCursor(encoded, sort, taskSortFields["created_at"])
+			assert.ErrorIs(t, err, ErrInvalidCursor)
+		})
+	}
+
+	// Значение приоритета должно быть числом
+	prioritySort := taskCursorSort("priority", false)
+	_, err := decodeTaskCursor(encodeTaskCursor(taskCursor{Sort: prioritySort, Value: "high", ID: cursorTaskID}), prioritySort, taskSortFields["priority"])
+	assert.ErrorIs(t, err, ErrInvalidCursor)
+}
@@ -11,6 +11,9 @@ import (
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
+// taskColumns — список колонок задачи в порядке, ожидаемом scanTask
+const taskColumns = `id, project_id, functional_block_id, number, title, description, status, priority, type, role, assignee_id, result, parent_task_id, source_document_id, source_section_anchor, version, created_at, updated_at, deleted_at`
+
 type TaskRepository struct {
 	db *pgxpool.Pool
 }
@@ -19,28 +22,95 @@ func NewTaskRepository(db *pgxpool.Pool) *TaskRepository {
 	return &TaskRepository{db: db}
 }
 
-// generateTaskNumber генерирует уникальный номер задачи
-func (r *TaskRepository) generateTaskNumber(ctx context.Context) (string, error) {
+// scanTask считывает задачу из строки результата, выбранной по taskColumns
+func scanTask(row pgx.Row) (*models.Task, error) {
+	task := &models.Task{}
+	err := row.Scan(
+		&task.ID,
+		&task.ProjectID,
+		&task.FunctionalBlockID,
+		&task.Number,
+		&task.Title,
+		&task.Description,
+		&task.Status,
+		&task.Priority,
+		&task.Type,
+		&task.Role,
+		&task.AssigneeID,
+		&task.Result,
+		&task.ParentTaskID,
+		&task.SourceDocumentID,
+		&task.SourceSectionAnchor,
+		&task.Version,
+		&task.CreatedAt,
+		&task.UpdatedAt,
+		&task.DeletedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return task, nil
+}
+
+// collectTasks считывает все задачи из результата запроса
+func collectTasks(rows pgx.Rows) ([]models.Task, error) {
+	defer rows.Close()
+
+	var tasks []models.Task
+	for rows.Next() {
+		task, err := scanTask(rows)
+		if err != nil {
+			return nil, err
+		}
+		tasks = append(tasks, *task)
+	}
+	return tasks, rows.Err()
+}
+
+// generateTaskNumber генерирует уникальный номер задачи вида PREFIX-0042.
+// Префикс берется из функционального блока, для задач без блока используется
+// models.DefaultTaskNumberPrefix. Каждый префикс имеет собственный счетчик.
+func (r *TaskRepository) generateTaskNumber(ctx context.Context, functionalBlockID *string) (string, error) {
+	prefix := models.DefaultTaskNumberPrefix
+	if functionalBlockID != nil && *functionalBlockID != "" {
+		prefixQuery := `SELECT prefix FROM functional_blocks WHERE id = $1`
+		if err := r.db.QueryRow(ctx, prefixQuery, *functionalBlockID).Scan(&prefix); err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return "", errors.New("functional block not found")
+			}
+			return "", err
+		}
+	}
+
+	return nextTaskNumber(ctx, r.db, prefix)
+}
+
+// nextTaskNumber увеличивает счетчик префикса и возвращает очередной номер задачи
+func nextTaskNumber(ctx context.Context, q rowQuerier, prefix string) (string, error) {
 	var nextNumber int64
-	query := `UPDATE task_number_sequence SET current_value = current_value + 1 WHERE id = 1 RETURNING current_value`
-	err := r.db.QueryRow(ctx, query).Scan(&nextNumber)
+	query := `INSERT INTO task_number_counters (prefix, current_value) VALUES ($1, 1)
+			  ON CONFLICT (prefix) DO UPDATE SET
+			  current_value = task_number_counters.current_value + 1,
+			  updated_at = CURRENT_TIMESTAMP
+			  RETURNING current_value`
+	err := q.QueryRow(ctx, query, prefix).Scan(&nextNumber)
 	if err != nil {
 		return "", err
 	}
-	return fmt.Sprintf("TASK-%06d", nextNumber), nil
+	return models.FormatTaskNumber(prefix, nextNumber), nil
 }
 
 func (r *TaskRepository) Create(ctx context.Context, task *models.Task) error {
 	// Генерируем номер задачи
-	number, err := r.generateTaskNumber(ctx)
+	number, err := r.generateTaskNumber(ctx, task.FunctionalBlockID)
 	if err != nil {
 		return fmt.Errorf("failed to generate task number: %w", err)
 	}
 	task.Number = number
 
-	query := `INSERT INTO tasks (project_id, functional_block_id, number, title, description, status, priority, type, role, result, parent_task_id) 
-			  VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11) 
-			  RETURNING id, created_at, updated_at`
+	query := `INSERT INTO tasks (project_id, functional_block_id, number, title, description, status, priority, type, role, result, parent_task_id, source_document_id, source_section_anchor) 
+			  VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13) 
+			  RETURNING id, version, created_at, updated_at`
 
 	return r.db.QueryRow(ctx, query,
 		task.ProjectID,
@@ -54,31 +124,16 @@ func (r *TaskRepository) Create(ctx context.Context, task *models.Task) error {
 		task.Role,
 		task.Result,
 		task.ParentTaskID,
-	).Scan(&task.ID, &task.CreatedAt, &task.UpdatedAt)
+		task.SourceDocumentID,
+		task.SourceSectionAnchor,
+	).Scan(&task.ID, &task.Version, &task.CreatedAt, &task.UpdatedAt)
 }
 
 func (r *TaskRepository) GetByID(ctx context.Context, id string) (*models.Task, error) {
-	query := `SELECT id, project_id, functional_block_id, number, title, description, status, priority, type, role, result, parent_task_id, created_at, updated_at 
-			  FROM tasks WHERE id = $1`
-
-	task := &models.Task{}
-	err := r.db.QueryRow(ctx, query, id).Scan(
-		&task.ID,
-		&task.ProjectID,
-		&task.FunctionalBlockID,
-		&task.Number,
-		&task.Title,
-		&task.Description,
-		&task.Status,
-		&task.Priority,
-		&task.Type,
-		&task.Role,
-		&task.Result,
-		&task.ParentTaskID,
-		&task.CreatedAt,
-		&task.UpdatedAt,
-	)
+	query := `SELECT ` + taskColumns + `
+			  FROM tasks WHERE id = $1 AND deleted_at IS NULL`
 
+	task, err := scanTask(r.db.QueryRow(ctx, query, id))
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, nil
@@ -88,28 +143,12 @@ func (r *TaskRepository) GetByID(ctx context.Context, id string) (*models.Task,
 	return task, nil
 }
 
+// GetByNumber ищет задачу по номеру в текущем или устаревшем формате
 func (r *TaskRepository) GetByNumber(ctx context.Context, number string) (*models.Task, error) {
-	query := `SELECT id, project_id, functional_block_id, number, title, description, status, priority, type, role, result, parent_task_id, created_at, updated_at 
-			  FROM tasks WHERE number = $1`
-
-	task := &models.Task{}
-	err := r.db.QueryRow(ctx, query, number).Scan(
-		&task.ID,
-		&task.ProjectID,
-		&task.FunctionalBlockID,
-		&task.Number,
-		&task.Title,
-		&task.Description,
-		&task.Status,
-		&task.Priority,
-		&task.Type,
-		&task.Role,
-		&task.Result,
-		&task.ParentTaskID,
-		&task.CreatedAt,
-		&task.UpdatedAt,
-	)
+	query := `SELECT ` + taskColumns + `
+			  FROM tasks WHERE number = ANY($1) AND deleted_at IS NULL LIMIT 1`
 
+	task, err := scanTask(r.db.QueryRow(ctx, query, models.TaskNumberCandidates(number)))
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, nil
@@ -120,79 +159,29 @@ func (r *TaskRepository) GetByNumber(ctx context.Context, number string) (*model
 }
 
 func (r *TaskRepository) GetAll(ctx context.Context) ([]models.Task, error) {
-	query := `SELECT id, project_id, functional_block_id, number, title, description, status, priority, type, role, result, parent_task_id, created_at, updated_at 
-			  FROM tasks ORDER BY created_at DESC`
+	query := `SELECT ` + taskColumns + `
+			  FROM tasks WHERE deleted_at IS NULL ORDER BY created_at DESC`
 
 	rows, err := r.db.Query(ctx, query)
 	if err != nil {
 		return nil, err
 	}
-	defer rows.Close()
-
-	var tasks []models.Task
-	for rows.Next() {
-		var task models.Task
-		err := rows.Scan(
-			&task.ID,
-			&task.ProjectID,
-			&task.FunctionalBlockID,
-			&task.Number,
-			&task.Title,
-			&task.Description,
-			&task.Status,
-			&task.Priority,
-			&task.Type,
-			&task.Role,
-			&task.Result,
-			&task.ParentTaskID,
-			&task.CreatedAt,
-			&task.UpdatedAt,
-		)
-		if err != nil {
-			return nil, err
-		}
-		tasks = append(tasks, task)
-	}
-	return tasks, nil
+	return collectTasks(rows)
 }
 
 func (r *TaskRepository) GetByProjectID(ctx context.Context, projectID string) ([]models.Task, error) {
-	query := `SELECT id, project_id, functional_block_id, number, title, description, status, priority, type, role, result, parent_task_id, created_at, updated_at 
-			  FROM tasks WHERE project_id = $1 ORDER BY created_at DESC`
+	query := `SELECT ` + taskColumns + `
+			  FROM tasks WHERE project_id = $1 AND deleted_at IS NULL ORDER BY created_at DESC`
 
 	rows, err := r.db.Query(ctx, query, projectID)
 	if err != nil {
 		return nil, err
 	}
-	defer rows.Close()
-
-	var tasks []models.Task
-	for rows.Next() {
-		var task models.Task
-		err := rows.Scan(
-			&task.ID,
-			&task.ProjectID,
-			&task.FunctionalBlockID,
-			&task.Number,
-			&task.Title,
-			&task.Description,
-			&task.Status,
-			&task.Priority,
-			&task.Type,
-			&task.Role,
-			&task.Result,
-			&task.ParentTaskID,
-			&task.CreatedAt,
-			&task.UpdatedAt,
-		)
-		if err != nil {
-			return nil, err
-		}
-		tasks = append(tasks, task)
-	}
-	return tasks, nil
+	return collectTasks(rows)
 }
 
+// Update сохраняет задачу, если ее версия в базе совпадает с task.Version.
+// При несовпадении возвращается ErrVersionConflict, при отсутствии задачи — pgx.ErrNoRows
 func (r *TaskRepository) Update(ctx context.Context, task *models.Task) error {
 	query := `UPDATE tasks SET 
 			  functional_block_id = $1, 
@@ -204,11 +193,12 @@ func (r *TaskRepository) Update(ctx context.Context, task *models.Task) error {
 			  role = $7, 
 			  result = $8, 
 			  parent_task_id = $9, 
+			  version = version + 1, 
 			  updated_at = CURRENT_TIMESTAMP 
-			  WHERE id = $10 
-			  RETURNING updated_at`
+			  WHERE id = $10 AND version = $11 AND deleted_at IS NULL 
+			  RETURNING version, updated_at`
 
-	return r.db.QueryRow(ctx, query,
+	err := r.db.QueryRow(ctx, query,
 		task.FunctionalBlockID,
 		task.Title,
 		task.Description,
@@ -219,91 +209,108 @@ func (r *TaskRepository) Update(ctx context.Context, task *models.Task) error {
 		task.Result,
 		task.ParentTaskID,
 		task.ID,
-	).Scan(&task.UpdatedAt)
+		task.Version,
+	).Scan(&task.Version, &task.UpdatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return versionMismatchError(ctx, r.db, "tasks", task.ID)
+	}
+	return err
 }
 
-func (r *TaskRepository) Delete(ctx context.Context, id string) error {
-	query := `DELETE FROM tasks WHERE id = $1`
-	result, err := r.db.Exec(ctx, query, id)
+// SetAssignee назначает задаче исполнителя; nil снимает назначение.
+// Как и Update, изменяет задачу только при совпадении task.Version.
+func (r *TaskRepository) SetAssignee(ctx context.Context, task *models.Task) error {
+	query := `UPDATE tasks SET
+			  assignee_id = $1,
+			  version = version + 1,
+			  updated_at = CURRENT_TIMESTAMP
+			  WHERE id = $2 AND version = $3 AND deleted_at IS NULL
+			  RETURNING version, updated_at`
+
+	err := r.db.QueryRow(ctx, query, task.AssigneeID, task.ID, task.Version).Scan(&task.Version, &task.UpdatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return versionMismatchError(ctx, r.db, "tasks", task.ID)
+	}
+	return err
+}
+
+// GetByIDWithDeleted ищет задачу по id, включая находящиеся в корзине
+func (r *TaskRepository) GetByIDWithDeleted(ctx context.Context, id string) (*models.Task, error) {
+	query := `SELECT ` + taskColumns + `
+			  FROM tasks WHERE id = $1`
+
+	task, err := scanTask(r.db.QueryRow(ctx, query, id))
 	if err != nil {
-		return err
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
 	}
-	if result.RowsAffected() == 0 {
-		return pgx.ErrNoRows
+	return task, nil
+}
+
+// GetDeleted возвращает задачи в корзине; пустой projectID — по всем проектам
+func (r *TaskRepository) GetDeleted(ctx context.Context, projectID string) ([]models.Task, error) {
+	query := `SELECT ` + taskColumns + `
+			  FROM tasks WHERE deleted_at IS NOT NULL AND ($1 = '' OR project_id::text = $1)
+			  ORDER BY deleted_at DESC`
+
+	rows, err := r.db.Query(ctx, query, projectID)
+	if err != nil {
+		return nil, err
 	}
-	return nil
+	return collectTasks(rows)
 }
 
-func (r *TaskRepository) GetByStatus(ctx context.Context, status string) ([]models.Task, error) {
-	query := `SELECT id, project_id, functional_block_id, number, title, description, status, priority, type, role, result, parent_task_id, created_at, updated_at 
-			  FROM tasks WHERE status = $1 ORDER BY created_at DESC`
+// Delete перемещает задачу в корзину
+func (r *TaskRepository) Delete(ctx context.Context, id string) error {
+	query := `UPDATE tasks SET deleted_at = CURRENT_TIMESTAMP WHERE id = $1 AND deleted_at IS NULL`
+	return execAffectingRow(ctx, r.db, query, id)
+}
 
-	rows, err := r.db.Query(ctx, query, status)
+// Restore возвращает задачу из корзины
+func (r *TaskRepository) Restore(ctx context.Context, id string) error {
+	query := `UPDATE tasks SET deleted_at = NULL, updated_at = CURRENT_TIMESTAMP WHERE id = $1 AND deleted_at IS NOT NULL`
+	return execAffectingRow(ctx, r.db, query, id)
+}
+
+// Purge окончательно удаляет задачу из корзины вместе с комментариями и связями.
+// Журнал операций сохраняется.
+func (r *TaskRepository) Purge(ctx context.Context, id string) error {
+	query := `DELETE FROM tasks WHERE id = $1 AND deleted_at IS NOT NULL`
+	return execAffectingRow(ctx, r.db, query, id)
+}
+
+// GetBySourceDocumentID возвращает задачи, созданные из документа
+func (r *TaskRepository) GetBySourceDocumentID(ctx context.Context, documentID string) ([]models.Task, error) {
+	query := `SELECT ` + taskColumns + `
+			  FROM tasks WHERE source_document_id = $1 AND deleted_at IS NULL ORDER BY created_at ASC`
+
+	rows, err := r.db.Query(ctx, query, documentID)
 	if err != nil {
 		return nil, err
 	}
-	defer rows.Close()
+	return collectTasks(rows)
+}
 
-	var tasks []models.Task
-	for rows.Next() {
-		var task models.Task
-		err := rows.Scan(
-			&task.ID,
-			&task.ProjectID,
-			&task.FunctionalBlockID,
-			&task.Number,
-			&task.Title,
-			&task.Description,
-			&task.Status,
-			&task.Priority,
-			&task.Type,
-			&task.Role,
-			&task.Result,
-			&task.ParentTaskID,
-			&task.CreatedAt,
-			&task.UpdatedAt,
-		)
-		if err != nil {
-			return nil, err
-		}
-		tasks = append(tasks, task)
+func (r *TaskRepository) GetByStatus(ctx context.Context, status string) ([]models.Task, error) {
+	query := `SELECT ` + taskColumns + `
+			  FROM tasks WHERE status = $1 AND deleted_at IS NULL ORDER BY created_at DESC`
+
+	rows, err := r.db.Query(ctx, query, status)
+	if err != nil {
+		return nil, err
 	}
-	return tasks, nil
+	return collectTasks(rows)
 }
 
 func (r *TaskRepository) GetByFunctionalBlockID(ctx context.Context, functionalBlockID string) ([]models.Task, error) {
-	query := `SELECT id, project_id, functional_block_id, number, title, description, status, priority, type, role, result, parent_task_id, created_at, updated_at 
-			  FROM tasks WHERE functional_block_id = $1 ORDER BY created_at DESC`
+	query := `SELECT ` + taskColumns + `
+			  FROM tasks WHERE functional_block_id = $1 AND deleted_at IS NULL ORDER BY created_at DESC`
 
 	rows, err := r.db.Query(ctx, query, functionalBlockID)
 	if err != nil {
 		return nil, err
 	}
-	defer rows.Close()
-
-	var tasks []models.Task
-	for rows.Next() {
-		var task models.Task
-		err := rows.Scan(
-			&task.ID,
-			&task.ProjectID,
-			&task.FunctionalBlockID,
-			&task.Number,
-			&task.Title,
-			&task.Description,
-			&task.Status,
-			&task.Priority,
-			&task.Type,
-			&task.Role,
-			&task.Result,
-			&task.ParentTaskID,
-			&task.CreatedAt,
-			&task.UpdatedAt,
-		)
-		if err != nil {
-			return nil, err
-		}
-		tasks = append(tasks, task)
-	}
-	return tasks, nil
+	return collectTasks(rows)
 }
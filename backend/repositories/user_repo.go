@@ -0,0 +1,232 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+
+	"project-manager/models"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// userColumns — список колонок пользователя в порядке, ожидаемом scanUser
+const userColumns = `id, username, display_name, email, is_admin, is_agent, is_active, created_at, updated_at`
+
+// apiTokenColumns — список колонок токена в порядке, ожидаемом scanAPIToken
+const apiTokenColumns = `id, user_id, name, token_prefix, created_at, last_used_at, expires_at, revoked_at`
+
+type UserRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewUserRepository(db *pgxpool.Pool) *UserRepository {
+	return &UserRepository{db: db}
+}
+
+func scanUser(row pgx.Row) (*models.User, error) {
+	user := &models.User{}
+	err := row.Scan(
+		&user.ID,
+		&user.Username,
+		&user.DisplayName,
+		&user.Email,
+		&user.IsAdmin,
+		&user.IsAgent,
+		&user.IsActive,
+		&user.CreatedAt,
+		&user.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+func scanAPIToken(row pgx.Row) (*models.APIToken, error) {
+	token := &models.APIToken{}
+	err := row.Scan(
+		&token.ID,
+		&token.UserID,
+		&token.Name,
+		&token.TokenPrefix,
+		&token.CreatedAt,
+		&token.LastUsedAt,
+		&token.ExpiresAt,
+		&token.RevokedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return token, nil
+}
+
+func (r *UserRepository) Create(ctx context.Context, user *models.User) error {
+	query := `INSERT INTO users (username, display_name, email, is_admin, is_agent, is_active)
+			  VALUES ($1, $2, $3, $4, $5, $6)
+			  RETURNING id, created_at, updated_at`
+
+	return r.db.QueryRow(ctx, query,
+		user.Username,
+		user.DisplayName,
+		user.Email,
+		user.IsAdmin,
+		user.IsAgent,
+		user.IsActive,
+	).Scan(&user.ID, &user.CreatedAt, &user.UpdatedAt)
+}
+
+func (r *UserRepository) GetByID(ctx context.Context, id string) (*models.User, error) {
+	query := `SELECT ` + userColumns + ` FROM users WHERE id = $1`
+
+	user, err := scanUser(r.db.QueryRow(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return user, nil
+}
+
+func (r *UserRepository) GetByUsername(ctx context.Context, username string) (*models.User, error) {
+	query := `SELECT ` + userColumns + ` FROM users WHERE username = $1`
+
+	user, err := scanUser(r.db.QueryRow(ctx, query, username))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return user, nil
+}
+
+func (r *UserRepository) GetAll(ctx context.Context) ([]models.User, error) {
+	query := `SELECT ` + userColumns + ` FROM users ORDER BY username ASC`
+
+	rows, err := r.db.Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var users []models.User
+	for rows.Next() {
+		user, err := scanUser(rows)
+		if err != nil {
+			return nil, err
+		}
+		users = append(users, *user)
+	}
+	return users, rows.Err()
+}
+
+// HasActiveAdmin проверяет, есть ли хотя бы один активный администратор
+func (r *UserRepository) HasActiveAdmin(ctx context.Context) (bool, error) {
+	var exists bool
+	err := r.db.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM users WHERE is_admin AND is_active)`).Scan(&exists)
+	return exists, err
+}
+
+func (r *UserRepository) Update(ctx context.Context, user *models.User) error {
+	query := `UPDATE users SET
+			  display_name = $1,
+			  email = $2,
+			  is_admin = $3,
+			  is_agent = $4,
+			  is_active = $5,
+			  updated_at = CURRENT_TIMESTAMP
+			  WHERE id = $6
+			  RETURNING updated_at`
+
+	return r.db.QueryRow(ctx, query,
+		user.DisplayName,
+		user.Email,
+		user.IsAdmin,
+		user.IsAgent,
+		user.IsActive,
+		user.ID,
+	).Scan(&user.UpdatedAt)
+}
+
+// CreateToken сохраняет токен с хешем tokenHash
+func (r *UserRepository) CreateToken(ctx context.Context, token *models.APIToken, tokenHash string) error {
+	query := `INSERT INTO api_tokens (user_id, name, token_hash, token_prefix, expires_at)
+			  VALUES ($1, $2, $3, $4, $5)
+			  RETURNING id, created_at`
+
+	return r.db.QueryRow(ctx, query,
+		token.UserID,
+		token.Name,
+		tokenHash,
+		token.TokenPrefix,
+		token.ExpiresAt,
+	).Scan(&token.ID, &token.CreatedAt)
+}
+
+func (r *UserRepository) GetTokensByUserID(ctx context.Context, userID string) ([]models.APIToken, error) {
+	query := `SELECT ` + apiTokenColumns + ` FROM api_tokens WHERE user_id = $1 ORDER BY created_at DESC`
+
+	rows, err := r.db.Query(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tokens []models.APIToken
+	for rows.Next() {
+		token, err := scanAPIToken(rows)
+		if err != nil {
+			return nil, err
+		}
+		tokens = append(tokens, *token)
+	}
+	return tokens, rows.Err()
+}
+
+// GetActiveTokenUser ищет действующий токен по хешу вместе с активным владельцем
+func (r *UserRepository) GetActiveTokenUser(ctx context.Context, tokenHash string) (*models.APIToken, *models.User, error) {
+	query := `SELECT t.id, t.user_id, t.name, t.token_prefix, t.created_at, t.last_used_at, t.expires_at, t.revoked_at,
+			  u.id, u.username, u.display_name, u.email, u.is_admin, u.is_agent, u.is_active, u.created_at, u.updated_at
+			  FROM api_tokens t JOIN users u ON u.id = t.user_id
+			  WHERE t.token_hash = $1
+			  AND t.revoked_at IS NULL
+			  AND (t.expires_at IS NULL OR t.expires_at > CURRENT_TIMESTAMP)
+			  AND u.is_active`
+
+	token := &models.APIToken{}
+	user := &models.User{}
+	err := r.db.QueryRow(ctx, query, tokenHash).Scan(
+		&token.ID, &token.UserID, &token.Name, &token.TokenPrefix,
+		&token.CreatedAt, &token.LastUsedAt, &token.ExpiresAt, &token.RevokedAt,
+		&user.ID, &user.Username, &user.DisplayName, &user.Email,
+		&user.IsAdmin, &user.IsAgent, &user.IsActive, &user.CreatedAt, &user.UpdatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil, nil
+		}
+		return nil, nil, err
+	}
+	return token, user, nil
+}
+
+// TouchToken обновляет время последнего использования не чаще раза в минуту
+func (r *UserRepository) TouchToken(ctx context.Context, tokenID string) error {
+	query := `UPDATE api_tokens SET last_used_at = CURRENT_TIMESTAMP
+			  WHERE id = $1 AND (last_used_at IS NULL OR last_used_at < CURRENT_TIMESTAMP - INTERVAL '1 minute')`
+	_, err := r.db.Exec(ctx, query, tokenID)
+	return err
+}
+
+// RevokeToken отзывает токен пользователя. Возвращает false, если токен не найден.
+func (r *UserRepository) RevokeToken(ctx context.Context, userID, tokenID string) (bool, error) {
+	query := `UPDATE api_tokens SET revoked_at = COALESCE(revoked_at, CURRENT_TIMESTAMP)
+			  WHERE id = $1 AND user_id = $2`
+	result, err := r.db.Exec(ctx, query, tokenID, userID)
+	if err != nil {
+		return false, err
+	}
+	return result.RowsAffected() > 0, nil
+}
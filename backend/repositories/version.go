@@ -0,0 +1,39 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// ErrVersionConflict возвращается, когда запись была изменена после того,
+// как клиент получил ее версию
+var ErrVersionConflict = errors.New("version conflict: the record was modified by another request")
+
+// softDeleteTables — таблицы с мягким удалением: запись в корзине для
+// обновлений считается отсутствующей
+var softDeleteTables = map[string]bool{
+	"tasks":    true,
+	"projects": true,
+}
+
+// versionMismatchError уточняет причину, по которой условное обновление не
+// затронуло ни одной строки: запись удалена (в том числе в корзину) или ее
+// версия изменилась
+func versionMismatchError(ctx context.Context, db *pgxpool.Pool, table, id string) error {
+	var exists bool
+	query := `SELECT EXISTS (SELECT 1 FROM ` + table + ` WHERE id = $1`
+	if softDeleteTables[table] {
+		query += ` AND deleted_at IS NULL`
+	}
+	query += `)`
+	if err := db.QueryRow(ctx, query, id).Scan(&exists); err != nil {
+		return err
+	}
+	if !exists {
+		return pgx.ErrNoRows
+	}
+	return ErrVersionConflict
+}
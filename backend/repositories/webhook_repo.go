@@ -0,0 +1,256 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"project-manager/models"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// webhookColumns — список колонок вебхука в порядке, ожидаемом scanWebhook
+const webhookColumns = `id, project_id, url, event_types, secret, is_active, created_by, created_at, updated_at`
+
+// webhookDeliveryColumns — список колонок доставки в порядке, ожидаемом scanWebhookDelivery
+const webhookDeliveryColumns = `id, webhook_id, operation_log_id, event_type, payload, status, attempts, next_attempt_at,
+			  last_attempt_at, response_status, response_body, error, redelivery_of, created_at, updated_at`
+
+type WebhookRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewWebhookRepository(db *pgxpool.Pool) *WebhookRepository {
+	return &WebhookRepository{db: db}
+}
+
+func scanWebhook(row pgx.Row) (*models.Webhook, error) {
+	webhook := &models.Webhook{}
+	err := row.Scan(
+		&webhook.ID,
+		&webhook.ProjectID,
+		&webhook.URL,
+		&webhook.EventTypes,
+		&webhook.Secret,
+		&webhook.IsActive,
+		&webhook.CreatedBy,
+		&webhook.CreatedAt,
+		&webhook.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return webhook, nil
+}
+
+func collectWebhooks(rows pgx.Rows) ([]models.Webhook, error) {
+	defer rows.Close()
+
+	webhooks := []models.Webhook{}
+	for rows.Next() {
+		webhook, err := scanWebhook(rows)
+		if err != nil {
+			return nil, err
+		}
+		webhooks = append(webhooks, *webhook)
+	}
+	return webhooks, rows.Err()
+}
+
+func scanWebhookDelivery(row pgx.Row) (*models.WebhookDelivery, error) {
+	delivery := &models.WebhookDelivery{}
+	err := row.Scan(
+		&delivery.ID,
+		&delivery.WebhookID,
+		&delivery.OperationLogID,
+		&delivery.EventType,
+		&delivery.Payload,
+		&delivery.Status,
+		&delivery.Attempts,
+		&delivery.NextAttemptAt,
+		&delivery.LastAttemptAt,
+		&delivery.ResponseStatus,
+		&delivery.ResponseBody,
+		&delivery.Error,
+		&delivery.RedeliveryOf,
+		&delivery.CreatedAt,
+		&delivery.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return delivery, nil
+}
+
+func collectWebhookDeliveries(rows pgx.Rows) ([]models.WebhookDelivery, error) {
+	defer rows.Close()
+
+	deliveries := []models.WebhookDelivery{}
+	for rows.Next() {
+		delivery, err := scanWebhookDelivery(rows)
+		if err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, *delivery)
+	}
+	return deliveries, rows.Err()
+}
+
+func (r *WebhookRepository) Create(ctx context.Context, webhook *models.Webhook) error {
+	query := `INSERT INTO webhooks (project_id, url, event_types, secret, is_active, created_by)
+			  VALUES ($1, $2, $3, $4, $5, $6)
+			  RETURNING id, created_at, updated_at`
+
+	return r.db.QueryRow(ctx, query,
+		webhook.ProjectID,
+		webhook.URL,
+		webhook.EventTypes,
+		webhook.Secret,
+		webhook.IsActive,
+		webhook.CreatedBy,
+	).Scan(&webhook.ID, &webhook.CreatedAt, &webhook.UpdatedAt)
+}
+
+func (r *WebhookRepository) GetByID(ctx context.Context, id string) (*models.Webhook, error) {
+	query := `SELECT ` + webhookColumns + ` FROM webhooks WHERE id = $1`
+
+	webhook, err := scanWebhook(r.db.QueryRow(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return webhook, nil
+}
+
+func (r *WebhookRepository) GetByProjectID(ctx context.Context, projectID string) ([]models.Webhook, error) {
+	query := `SELECT ` + webhookColumns + ` FROM webhooks WHERE project_id = $1 ORDER BY created_at ASC`
+
+	rows, err := r.db.Query(ctx, query, projectID)
+	if err != nil {
+		return nil, err
+	}
+	return collectWebhooks(rows)
+}
+
+// GetActiveByProjectID возвращает включенные вебхуки проекта
+func (r *WebhookRepository) GetActiveByProjectID(ctx context.Context, projectID string) ([]models.Webhook, error) {
+	query := `SELECT ` + webhookColumns + ` FROM webhooks WHERE project_id = $1 AND is_active ORDER BY created_at ASC`
+
+	rows, err := r.db.Query(ctx, query, projectID)
+	if err != nil {
+		return nil, err
+	}
+	return collectWebhooks(rows)
+}
+
+func (r *WebhookRepository) Update(ctx context.Context, webhook *models.Webhook) error {
+	query := `UPDATE webhooks
+			  SET url = $1, event_types = $2, secret = $3, is_active = $4, updated_at = CURRENT_TIMESTAMP
+			  WHERE id = $5
+			  RETURNING updated_at`
+
+	return r.db.QueryRow(ctx, query,
+		webhook.URL,
+		webhook.EventTypes,
+		webhook.Secret,
+		webhook.IsActive,
+		webhook.ID,
+	).Scan(&webhook.UpdatedAt)
+}
+
+func (r *WebhookRepository) Delete(ctx context.Context, id string) error {
+	query := `DELETE FROM webhooks WHERE id = $1`
+	return execAffectingRow(ctx, r.db, query, id)
+}
+
+// CreateDelivery ставит доставку в очередь
+func (r *WebhookRepository) CreateDelivery(ctx context.Context, delivery *models.WebhookDelivery) error {
+	query := `INSERT INTO webhook_deliveries (webhook_id, operation_log_id, event_type, payload, redelivery_of)
+			  VALUES ($1, $2, $3, $4, $5)
+			  RETURNING ` + webhookDeliveryColumns
+
+	created, err := scanWebhookDelivery(r.db.QueryRow(ctx, query,
+		delivery.WebhookID,
+		delivery.OperationLogID,
+		delivery.EventType,
+		delivery.Payload,
+		delivery.RedeliveryOf,
+	))
+	if err != nil {
+		return err
+	}
+	*delivery = *created
+	return nil
+}
+
+func (r *WebhookRepository) GetDelivery(ctx context.Context, id string) (*models.WebhookDelivery, error) {
+	query := `SELECT ` + webhookDeliveryColumns + ` FROM webhook_deliveries WHERE id = $1`
+
+	delivery, err := scanWebhookDelivery(r.db.QueryRow(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return delivery, nil
+}
+
+// GetDeliveries возвращает последние доставки вебхука, начиная с новых
+func (r *WebhookRepository) GetDeliveries(ctx context.Context, webhookID string, limit int) ([]models.WebhookDelivery, error) {
+	query := `SELECT ` + webhookDeliveryColumns + ` FROM webhook_deliveries
+			  WHERE webhook_id = $1 ORDER BY created_at DESC LIMIT $2`
+
+	rows, err := r.db.Query(ctx, query, webhookID, limit)
+	if err != nil {
+		return nil, err
+	}
+	return collectWebhookDeliveries(rows)
+}
+
+// ClaimDueDeliveries забирает до limit доставок, время которых наступило.
+// Забранные доставки откладываются на lease: если обработчик не запишет
+// результат (например, сервер остановится), доставка будет повторена.
+// SKIP LOCKED позволяет нескольким экземплярам сервера не мешать друг другу.
+func (r *WebhookRepository) ClaimDueDeliveries(ctx context.Context, limit int, lease time.Duration) ([]models.WebhookDelivery, error) {
+	query := `UPDATE webhook_deliveries
+			  SET next_attempt_at = CURRENT_TIMESTAMP + make_interval(secs => $2), updated_at = CURRENT_TIMESTAMP
+			  WHERE id IN (
+				  SELECT id FROM webhook_deliveries
+				  WHERE status = 'pending' AND next_attempt_at <= CURRENT_TIMESTAMP
+				  ORDER BY next_attempt_at ASC
+				  LIMIT $1
+				  FOR UPDATE SKIP LOCKED
+			  )
+			  RETURNING ` + webhookDeliveryColumns
+
+	rows, err := r.db.Query(ctx, query, limit, lease.Seconds())
+	if err != nil {
+		return nil, err
+	}
+	return collectWebhookDeliveries(rows)
+}
+
+// SaveAttempt сохраняет результат попытки доставки
+func (r *WebhookRepository) SaveAttempt(ctx context.Context, delivery *models.WebhookDelivery) error {
+	query := `UPDATE webhook_deliveries
+			  SET status = $1, attempts = $2, next_attempt_at = $3, last_attempt_at = $4,
+			  response_status = $5, response_body = $6, error = $7, updated_at = CURRENT_TIMESTAMP
+			  WHERE id = $8
+			  RETURNING updated_at`
+
+	return r.db.QueryRow(ctx, query,
+		delivery.Status,
+		delivery.Attempts,
+		delivery.NextAttemptAt,
+		delivery.LastAttemptAt,
+		delivery.ResponseStatus,
+		delivery.ResponseBody,
+		delivery.Error,
+		delivery.ID,
+	).Scan(&delivery.UpdatedAt)
+}
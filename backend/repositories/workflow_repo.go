@@ -0,0 +1,118 @@
+package repositories
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"project-manager/models"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type WorkflowRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewWorkflowRepository(db *pgxpool.Pool) *WorkflowRepository {
+	return &WorkflowRepository{db: db}
+}
+
+// workflowDefinition — часть процесса, хранимая в колонке definition
+type workflowDefinition struct {
+	InitialState   string                      `json:"initialState"`
+	States         []string                    `json:"states"`
+	TerminalStates []string                    `json:"terminalStates"`
+	DoneStates     []string                    `json:"doneStates"`
+	Transitions    []models.WorkflowTransition `json:"transitions"`
+}
+
+func newWorkflowDefinition(workflow *models.Workflow) workflowDefinition {
+	return workflowDefinition{
+		InitialState:   workflow.InitialState,
+		States:         workflow.States,
+		TerminalStates: workflow.TerminalStates,
+		DoneStates:     workflow.DoneStates,
+		Transitions:    workflow.Transitions,
+	}
+}
+
+// apply переносит определение в процесс. В определениях, сохраненных до
+// появления конечных и выполненных статусов, они восстанавливаются по
+// процессу по умолчанию.
+func (d workflowDefinition) apply(workflow *models.Workflow) {
+	workflow.InitialState = d.InitialState
+	workflow.States = d.States
+	workflow.TerminalStates = d.TerminalStates
+	workflow.DoneStates = d.DoneStates
+	workflow.Transitions = d.Transitions
+	workflow.FillTerminalStates()
+}
+
+// GetByProjectID возвращает процесс проекта или nil, если проект использует процесс по умолчанию
+func (r *WorkflowRepository) GetByProjectID(ctx context.Context, projectID string) (*models.Workflow, error) {
+	query := `SELECT definition, updated_by, updated_at FROM project_workflows WHERE project_id = $1`
+
+	var raw []byte
+	workflow := &models.Workflow{ProjectID: projectID}
+	err := r.db.QueryRow(ctx, query, projectID).Scan(&raw, &workflow.UpdatedBy, &workflow.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var definition workflowDefinition
+	if err := json.Unmarshal(raw, &definition); err != nil {
+		return nil, err
+	}
+	definition.apply(workflow)
+	return workflow, nil
+}
+
+// Upsert сохраняет процесс проекта
+func (r *WorkflowRepository) Upsert(ctx context.Context, workflow *models.Workflow) error {
+	definition, err := json.Marshal(newWorkflowDefinition(workflow))
+	if err != nil {
+		return err
+	}
+
+	query := `INSERT INTO project_workflows (project_id, definition, updated_by) VALUES ($1, $2, $3)
+			  ON CONFLICT (project_id) DO UPDATE SET
+			  definition = EXCLUDED.definition,
+			  updated_by = EXCLUDED.updated_by,
+			  updated_at = CURRENT_TIMESTAMP
+			  RETURNING updated_at`
+
+	return r.db.QueryRow(ctx, query, workflow.ProjectID, definition, workflow.UpdatedBy).Scan(&workflow.UpdatedAt)
+}
+
+// Delete удаляет процесс проекта, возвращая его к процессу по умолчанию
+func (r *WorkflowRepository) Delete(ctx context.Context, projectID string) error {
+	query := `DELETE FROM project_workflows WHERE project_id = $1`
+	_, err := r.db.Exec(ctx, query, projectID)
+	return err
+}
+
+// GetUsedStatuses возвращает статусы, в которых находятся задачи проекта
+func (r *WorkflowRepository) GetUsedStatuses(ctx context.Context, projectID string) ([]string, error) {
+	query := `SELECT DISTINCT status FROM tasks WHERE project_id = $1 AND status IS NOT NULL ORDER BY status`
+
+	rows, err := r.db.Query(ctx, query, projectID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var statuses []string
+	for rows.Next() {
+		var status string
+		if err := rows.Scan(&status); err != nil {
+			return nil, err
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, rows.Err()
+}
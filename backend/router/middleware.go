@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"io"
 	"net/http"
+	"net/url"
 	"time"
 	"unicode/utf8"
 
@@ -31,11 +32,22 @@ func (rw *responseWriter) WriteHeader(code int) {
 	rw.ResponseWriter.WriteHeader(code)
 }
 
+// Write сохраняет тело только для ответов с ошибкой: оно нужно лишь для лога,
+// а потоковые ответы иначе накапливались бы в памяти
 func (rw *responseWriter) Write(b []byte) (int, error) {
-	rw.body.Write(b)
+	if rw.statusCode >= 400 {
+		rw.body.Write(b)
+	}
 	return rw.ResponseWriter.Write(b)
 }
 
+// Flush передает накопленные данные клиенту; нужен для потоковых ответов (SSE)
+func (rw *responseWriter) Flush() {
+	if flusher, ok := rw.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
 // sanitizeUTF8 очищает строку от невалидных UTF-8 символов
 func sanitizeUTF8(s string) string {
 	if utf8.ValidString(s) {
@@ -81,7 +93,7 @@ func LoggingMiddleware(next http.Handler) http.Handler {
 		// Подготавливаем данные для лога
 		logData := map[string]interface{}{
 			"method":       r.Method,
-			"url":          r.URL.String(),
+			"url":          redactURL(r.URL),
 			"remote_addr":  r.RemoteAddr,
 			"user_agent":   r.UserAgent(),
 			"status_code":  wrapped.statusCode,
@@ -89,8 +101,8 @@ func LoggingMiddleware(next http.Handler) http.Handler {
 			"content_type": r.Header.Get("Content-Type"),
 		}
 
-		// Добавляем тело запроса для POST/PUT запросов
-		if len(requestBody) > 0 && (r.Method == "POST" || r.Method == "PUT") {
+		// Добавляем тело запроса для POST/PUT/PATCH запросов
+		if len(requestBody) > 0 && (r.Method == "POST" || r.Method == "PUT" || r.Method == "PATCH") {
 			if isJSON(r.Header.Get("Content-Type")) {
 				var jsonBody interface{}
 				if err := json.Unmarshal(requestBody, &jsonBody); err == nil {
@@ -129,8 +141,21 @@ func LoggingMiddleware(next http.Handler) http.Handler {
 	})
 }
 
+// redactURL скрывает в адресе для лога токен потока событий
+func redactURL(u *url.URL) string {
+	query := u.Query()
+	if query.Get("access_token") == "" {
+		return u.String()
+	}
+	query.Set("access_token", "REDACTED")
+	redacted := *u
+	redacted.RawQuery = query.Encode()
+	return redacted.String()
+}
+
 // isJSON проверяет, является ли content-type JSON
 func isJSON(contentType string) bool {
 	return contentType == "application/json" ||
-		contentType == "application/json; charset=utf-8"
+		contentType == "application/json; charset=utf-8" ||
+		contentType == "application/merge-patch+json"
 }
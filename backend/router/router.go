@@ -1,13 +1,17 @@
 package router
 
 import (
+	"context"
+	"errors"
 	"net/http"
 
 	"project-manager/config"
 	"project-manager/database"
 	"project-manager/handlers"
+	"project-manager/models"
 	"project-manager/repositories"
 	"project-manager/services"
+	"project-manager/utils"
 
 	"github.com/go-chi/chi/v5"
 )
@@ -16,8 +20,9 @@ import (
 func corsMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Access-Control-Allow-Origin", "http://localhost:3000")
-		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, X-API-Key, Cache-Control")
+		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, DELETE, OPTIONS")
+		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, X-API-Key, Cache-Control, If-Match")
+		w.Header().Set("Access-Control-Expose-Headers", "X-Total-Count, X-Next-Cursor, ETag")
 		if r.Method == "OPTIONS" {
 			w.WriteHeader(http.StatusOK)
 			return
@@ -27,14 +32,37 @@ func corsMiddleware(next http.Handler) http.Handler {
 }
 
 func AuthMiddleware(authService *services.AuthService) func(next http.Handler) http.Handler {
+	return authMiddleware(func(r *http.Request) (*models.Principal, error) {
+		return authService.Authenticate(r.Context(), r.Header.Get("X-API-Key"))
+	})
+}
+
+// EventStreamAuthMiddleware аутентифицирует подписку на поток событий. Без
+// заголовка X-API-Key принимается токен потока из параметра access_token:
+// EventSource в браузере не умеет передавать заголовки.
+func EventStreamAuthMiddleware(authService *services.AuthService) func(next http.Handler) http.Handler {
+	return authMiddleware(func(r *http.Request) (*models.Principal, error) {
+		if key := r.Header.Get("X-API-Key"); key != "" {
+			return authService.Authenticate(r.Context(), key)
+		}
+		return authService.AuthenticateStreamToken(r.URL.Query().Get("access_token"))
+	})
+}
+
+func authMiddleware(authenticate func(r *http.Request) (*models.Principal, error)) func(next http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			apiKey := r.Header.Get("X-API-Key")
-			if !authService.IsAPIKeyValid(apiKey) {
+			principal, err := authenticate(r)
+			if err != nil {
+				if !errors.Is(err, services.ErrUnauthorized) {
+					utils.Error("Authentication failed", map[string]interface{}{
+						"error": err.Error(),
+					})
+				}
 				http.Error(w, "Unauthorized", http.StatusUnauthorized)
 				return
 			}
-			next.ServeHTTP(w, r)
+			next.ServeHTTP(w, r.WithContext(services.WithPrincipal(r.Context(), principal)))
 		})
 	}
 }
@@ -52,45 +80,110 @@ func NewRouter(cfg *config.Config) *chi.Mux {
 	})
 
 	// Защищенные маршруты
-	if cfg.APIKey != "" && database.DB != nil {
-		authService := services.NewAuthService(cfg)
+	// Без общего API_KEY доступ возможен только по персональным токенам
+	if database.DB != nil {
+		userRepo := repositories.NewUserRepository(database.DB)
+		authService := services.NewAuthService(cfg, userRepo)
+		userService := services.NewUserService(userRepo)
+		userHandler := handlers.NewUserHandler(userService)
+
+		memberRepo := repositories.NewProjectMemberRepository(database.DB)
+		accessService := services.NewAccessService(memberRepo)
 
 		// Инициализация репозиториев и сервисов
-		fbRepo := repositories.NewFunctionalBlockRepository(database.DB)
-		fbService := services.NewFunctionalBlockService(fbRepo)
-		fbHandler := handlers.NewFunctionalBlockHandler(fbService)
+		taskRepo := repositories.NewTaskRepository(database.DB)
 
 		projectRepo := repositories.NewProjectRepository(database.DB)
-		projectService := services.NewProjectService(projectRepo)
-		projectHandler := handlers.NewProjectHandler(projectService)
+		documentRepo := repositories.NewDocumentRepository(database.DB)
 
-		taskRepo := repositories.NewTaskRepository(database.DB)
+		// Исходящие вебхуки: операции из журнала ставятся в очередь доставки,
+		// которую разбирает фоновый обработчик
+		webhookRepo := repositories.NewWebhookRepository(database.DB)
+		webhookService := services.NewWebhookService(webhookRepo, projectRepo, taskRepo, documentRepo, accessService)
+		webhookHandler := handlers.NewWebhookHandler(webhookService)
+		go services.NewWebhookDispatcher(webhookRepo, cfg.WebhookBlockPrivateNetworks).Run(context.Background())
 
 		logRepo := repositories.NewOperationLogRepository(database.DB)
-		logService := services.NewOperationLogService(logRepo, taskRepo)
+		logService := services.NewOperationLogService(logRepo, taskRepo, webhookService)
+
+		// События об изменениях для подписчиков /events
+		eventBroker := services.NewEventBroker(services.DefaultEventHistorySize)
+		eventHandler := handlers.NewEventHandler(eventBroker, authService)
 		logHandler := handlers.NewOperationLogHandler(logService)
 
+		fbRepo := repositories.NewFunctionalBlockRepository(database.DB)
+		fbService := services.NewFunctionalBlockService(fbRepo, logService, accessService)
+		fbHandler := handlers.NewFunctionalBlockHandler(fbService)
+
+		projectService := services.NewProjectService(projectRepo, logService, accessService)
+		projectHandler := handlers.NewProjectHandler(projectService)
+
+		archiveRepo := repositories.NewProjectArchiveRepository(database.DB)
+		archiveService := services.NewProjectArchiveService(archiveRepo, projectRepo, fbRepo, logService, accessService)
+		archiveHandler := handlers.NewProjectArchiveHandler(archiveService)
+
+		memberService := services.NewProjectMemberService(memberRepo, projectRepo, userRepo, accessService)
+		memberHandler := handlers.NewProjectMemberHandler(memberService)
+
+		// Входящие уведомления об упоминаниях, комментариях и смене статусов
+		notificationRepo := repositories.NewNotificationRepository(database.DB)
+		notificationService := services.NewNotificationService(notificationRepo)
+		notificationHandler := handlers.NewNotificationHandler(notificationService)
+
 		commentRepo := repositories.NewCommentRepository(database.DB)
-		commentService := services.NewCommentService(commentRepo, taskRepo, logService)
+		commentService := services.NewCommentService(commentRepo, taskRepo, userRepo, logService, notificationService, accessService, eventBroker)
 		commentHandler := handlers.NewCommentHandler(commentService)
 
-		taskService := services.NewTaskService(taskRepo, projectRepo, fbRepo, logService)
-		taskHandler := handlers.NewTaskHandler(taskService)
+		workflowRepo := repositories.NewWorkflowRepository(database.DB)
+		workflowService := services.NewWorkflowService(workflowRepo, projectRepo, accessService)
+		workflowHandler := handlers.NewWorkflowHandler(workflowService)
 
-		documentRepo := repositories.NewDocumentRepository(database.DB)
-		documentService := services.NewDocumentService(documentRepo, projectRepo)
+		executorRepo := repositories.NewExecutorRepository(database.DB)
+		executorService := services.NewExecutorService(executorRepo, userRepo, logService, notificationService, accessService, workflowService, eventBroker)
+		executorHandler := handlers.NewExecutorHandler(executorService)
+
+		taskService := services.NewTaskService(taskRepo, projectRepo, fbRepo, executorRepo, logService, accessService, workflowService, notificationService, eventBroker)
+
+		dependencyRepo := repositories.NewTaskDependencyRepository(database.DB)
+		dependencyService := services.NewTaskDependencyService(dependencyRepo, taskRepo, logService, accessService, workflowService)
+		dependencyHandler := handlers.NewTaskDependencyHandler(dependencyService)
+
+		documentRevisionRepo := repositories.NewDocumentRevisionRepository(database.DB)
+		documentService := services.NewDocumentService(documentRepo, documentRevisionRepo, projectRepo, logService, accessService, eventBroker)
 		documentHandler := handlers.NewDocumentHandler(documentService)
 
+		taskDocumentLinkRepo := repositories.NewTaskDocumentLinkRepository(database.DB)
+		taskDocumentLinkService := services.NewTaskDocumentLinkService(taskDocumentLinkRepo, taskRepo, documentRepo, logService, accessService)
+		taskDocumentLinkHandler := handlers.NewTaskDocumentLinkHandler(taskDocumentLinkService)
+		taskHandler := handlers.NewTaskHandler(taskService, taskDocumentLinkService)
+
+		templateRepo := repositories.NewDocumentTemplateRepository(database.DB)
+		templateService := services.NewDocumentTemplateService(templateRepo, projectRepo, documentService, accessService)
+		templateHandler := handlers.NewDocumentTemplateHandler(templateService)
+
 		planRepo := repositories.NewProjectPlanRepository(database.DB)
-		planService := services.NewProjectPlanService(planRepo, projectRepo, taskRepo)
+		planService := services.NewProjectPlanService(planRepo, projectRepo, taskRepo, dependencyRepo, logService, accessService, workflowService, eventBroker)
 		planHandler := handlers.NewProjectPlanHandler(planService)
 
-		executorRepo := repositories.NewExecutorRepository(database.DB)
-		executorService := services.NewExecutorService(executorRepo)
-		executorHandler := handlers.NewExecutorHandler(executorService)
+		documentTaskService := services.NewDocumentTaskService(documentRepo, taskRepo, fbRepo, taskDocumentLinkRepo, taskService, planService, accessService)
+		documentTaskHandler := handlers.NewDocumentTaskHandler(documentTaskService)
+
+		executionRepo := repositories.NewTaskExecutionRepository(database.DB)
+		executionCommentService := services.NewTaskExecutionCommentService(commentService)
+		executionService := services.NewTaskExecutionService(executionRepo, taskRepo, projectRepo, taskService, executionCommentService, accessService, workflowService)
+		go executionService.RunRecovery(context.Background())
+		executionHandler := handlers.NewTaskExecutionHandler(taskService, executionService)
+
+		searchRepo := repositories.NewSearchRepository(database.DB)
+		searchService := services.NewSearchService(searchRepo)
+		searchHandler := handlers.NewSearchHandler(searchService)
 
 		frontendLogHandler := handlers.NewFrontendLogHandler()
 
+		// Поток событий об изменениях (Server-Sent Events) принимает и токен
+		// потока в параметре запроса, поэтому аутентифицируется отдельно
+		r.With(EventStreamAuthMiddleware(authService)).Get("/api/v1/events", eventHandler.StreamEvents)
+
 		r.Group(func(r chi.Router) {
 			r.Use(AuthMiddleware(authService))
 
@@ -102,6 +195,7 @@ func NewRouter(cfg *config.Config) *chi.Mux {
 					r.Post("/", fbHandler.CreateFunctionalBlock)
 					r.Get("/{id}", fbHandler.GetFunctionalBlock)
 					r.Put("/{id}", fbHandler.UpdateFunctionalBlock)
+					r.Patch("/{id}", fbHandler.PatchFunctionalBlock)
 					r.Delete("/{id}", fbHandler.DeleteFunctionalBlock)
 				})
 
@@ -111,13 +205,53 @@ func NewRouter(cfg *config.Config) *chi.Mux {
 					r.Post("/", projectHandler.CreateProject)
 					r.Get("/{id}", projectHandler.GetProject)
 					r.Put("/{id}", projectHandler.UpdateProject)
+					r.Patch("/{id}", projectHandler.PatchProject)
 					r.Delete("/{id}", projectHandler.DeleteProject)
 
+					// Корзина
+					r.Get("/trash", projectHandler.GetDeletedProjects)
+					r.Post("/{id}/restore", projectHandler.RestoreProject)
+					r.Delete("/{id}/purge", projectHandler.PurgeProject)
+
+					// Перенос проекта между окружениями
+					r.Get("/{id}/export", archiveHandler.ExportProject)
+					r.Post("/import", archiveHandler.ImportProject)
+
+					// Участники проекта и их роли
+					r.Route("/{projectID}/members", func(r chi.Router) {
+						r.Get("/", memberHandler.GetMembers)
+						r.Post("/", memberHandler.AddMember)
+						r.Get("/roles", memberHandler.GetValidRoles)
+						r.Put("/{userID}", memberHandler.UpdateMember)
+						r.Delete("/{userID}", memberHandler.RemoveMember)
+					})
+
+					// Исходящие вебхуки проекта
+					r.Route("/{projectID}/webhooks", func(r chi.Router) {
+						r.Get("/", webhookHandler.GetProjectWebhooks)
+						r.Post("/", webhookHandler.CreateWebhook)
+					})
+
+					// Процесс статусов задач проекта
+					r.Route("/{projectID}/workflow", func(r chi.Router) {
+						r.Get("/", workflowHandler.GetWorkflow)
+						r.Put("/", workflowHandler.SetWorkflow)
+						r.Delete("/", workflowHandler.ResetWorkflow)
+					})
+
+					// Создание документа проекта из шаблона
+					r.Post("/{projectID}/documents/from-template", templateHandler.CreateDocumentFromTemplate)
+
+					// Граф зависимостей задач проекта
+					r.Get("/{projectID}/dependency-graph", dependencyHandler.GetProjectGraph)
+
 					// План разработки проекта
 					r.Route("/{projectID}/plan", func(r chi.Router) {
 						r.Get("/", planHandler.GetProjectPlan)
 						r.Get("/stats", planHandler.GetProjectPlanStats)
 						r.Put("/reorder", planHandler.ReorderTasks)
+						r.Post("/auto-order", planHandler.AutoOrderPlan)
+						r.Get("/next", planHandler.GetNextTask)
 
 						// Управление задачами в плане
 						r.Route("/tasks", func(r chi.Router) {
@@ -143,20 +277,67 @@ func NewRouter(cfg *config.Config) *chi.Mux {
 					r.Get("/statuses", taskHandler.GetValidStatuses)
 					r.Get("/priorities", taskHandler.GetValidPriorities)
 					r.Get("/types", taskHandler.GetValidTypes)
+					r.Get("/dependency-kinds", dependencyHandler.GetValidDependencyKinds)
 					r.Get("/number/{number}", taskHandler.GetTaskByNumber)
 					r.Get("/project/{projectId}", taskHandler.GetTasksByProject)
 					r.Get("/functional-block/{functionalBlockId}", taskHandler.GetTasksByFunctionalBlock)
+
+					// Автоматическое выполнение задач
+					r.Route("/execute", func(r chi.Router) {
+						r.Post("/", executionHandler.ExecuteTask)
+						r.Get("/history", executionHandler.GetExecutionHistory)
+						r.Get("/metrics", executionHandler.GetExecutionMetrics)
+						r.Get("/{executionID}/status", executionHandler.GetExecutionStatus)
+						r.Post("/{executionID}/cancel", executionHandler.CancelExecution)
+					})
+
 					r.Get("/{id}", taskHandler.GetTask)
+					r.Get("/{id}/execute-button", executionHandler.GetTaskExecuteButton)
 					r.Put("/{id}", taskHandler.UpdateTask)
+					r.Patch("/{id}", taskHandler.PatchTask)
 					r.Delete("/{id}", taskHandler.DeleteTask)
+
+					// Назначение исполнителя
+					r.Post("/{id}/assignee", taskHandler.AssignTask)
+					r.Delete("/{id}/assignee", taskHandler.UnassignTask)
+
+					// Корзина
+					r.Get("/trash", taskHandler.GetDeletedTasks)
+					r.Post("/{id}/restore", taskHandler.RestoreTask)
+					r.Delete("/{id}/purge", taskHandler.PurgeTask)
+
+					r.Get("/{id}/history", logHandler.GetTaskHistory)
+
+					// Зависимости между задачами
+					r.Get("/{id}/dependencies", dependencyHandler.GetTaskDependencies)
+					r.Post("/{id}/dependencies", dependencyHandler.AddDependency)
+					r.Delete("/{id}/dependencies/{dependencyID}", dependencyHandler.RemoveDependency)
+
+					// Связанные документы
+					r.Get("/{id}/document-links", taskDocumentLinkHandler.GetTaskLinks)
+					r.Post("/{id}/document-links", taskDocumentLinkHandler.CreateTaskLink)
+					r.Delete("/{id}/document-links/{linkID}", taskDocumentLinkHandler.DeleteTaskLink)
+
+					// Комментарии задачи деревом ответов
+					r.Get("/{id}/comments", commentHandler.GetTaskCommentThreads)
+					r.Post("/{id}/comments", commentHandler.CreateTaskComment)
+					r.Get("/{id}/backlinks", commentHandler.GetTaskBacklinks)
 				})
 
+				// Поток событий: сам поток подключен вне группы, см. выше
+				r.Post("/events/token", eventHandler.IssueStreamToken)
+				r.Get("/events/types", eventHandler.GetValidEventTypes)
+
 				// Комментарии
 				r.Route("/comments", func(r chi.Router) {
 					r.Get("/", commentHandler.GetAllComments)
 					r.Post("/", commentHandler.CreateComment)
+					r.Get("/types", commentHandler.GetValidCommentTypes)
 					r.Get("/task/{taskId}", commentHandler.GetCommentsByTask)
 					r.Get("/{id}", commentHandler.GetComment)
+					r.Put("/{id}", commentHandler.UpdateComment)
+					r.Get("/{id}/edits", commentHandler.GetCommentEdits)
+					r.Get("/{id}/references", commentHandler.GetCommentReferences)
 					r.Delete("/{id}", commentHandler.DeleteComment)
 				})
 
@@ -166,6 +347,7 @@ func NewRouter(cfg *config.Config) *chi.Mux {
 					r.Get("/by-type", logHandler.GetLogsByOperationType)
 					r.Get("/by-user", logHandler.GetLogsByUser)
 					r.Get("/operation-types", logHandler.GetValidOperationTypes)
+					r.Get("/entity-types", logHandler.GetValidEntityTypes)
 					r.Get("/task/{taskId}", logHandler.GetLogsByTask)
 					r.Get("/{id}", logHandler.GetLog)
 				})
@@ -176,19 +358,97 @@ func NewRouter(cfg *config.Config) *chi.Mux {
 					r.Post("/", documentHandler.CreateDocument)
 					r.Get("/by-type", documentHandler.GetDocumentsByType)
 					r.Get("/document-types", documentHandler.GetValidDocumentTypes)
+					r.Get("/required-sections", documentHandler.GetRequiredSections)
 					r.Get("/project/{projectId}", documentHandler.GetDocumentsByProject)
 					r.Get("/project/{projectId}/agent-editable", documentHandler.GetAgentEditableDocumentsByProject)
 					r.Get("/project/{projectId}/type/{type}", documentHandler.GetDocumentsByProjectAndType)
 					r.Get("/{id}", documentHandler.GetDocument)
 					r.Put("/{id}", documentHandler.UpdateDocument)
+					r.Patch("/{id}", documentHandler.PatchDocument)
 					r.Delete("/{id}", documentHandler.DeleteDocument)
+
+					// История ревизий
+					r.Get("/{id}/revisions", documentHandler.GetDocumentRevisions)
+					r.Get("/{id}/revisions/{revision}", documentHandler.GetDocumentRevision)
+					r.Post("/{id}/revisions/{revision}/restore", documentHandler.RestoreDocumentRevision)
+					r.Get("/{id}/diff", documentHandler.DiffDocumentRevisions)
+
+					// Разделы и отображение Markdown
+					r.Get("/{id}/sections", documentHandler.GetDocumentSections)
+					r.Get("/{id}/sections/{anchor}", documentHandler.GetDocumentSection)
+					r.Put("/{id}/sections/{anchor}", documentHandler.UpdateDocumentSection)
+					r.Get("/{id}/html", documentHandler.RenderDocumentHTML)
+
+					// Задачи из документа AI Executable
+					r.Get("/{id}/task-drafts", documentTaskHandler.PreviewTasks)
+					r.Get("/{id}/tasks", documentTaskHandler.GetTasksByDocument)
+					r.Post("/{id}/tasks", documentTaskHandler.CreateTasks)
+
+					// Связанные задачи
+					r.Get("/{id}/task-links", taskDocumentLinkHandler.GetDocumentLinks)
+					r.Post("/{id}/task-links", taskDocumentLinkHandler.CreateDocumentLink)
+					r.Delete("/{id}/task-links/{linkID}", taskDocumentLinkHandler.DeleteDocumentLink)
+				})
+
+				// Шаблоны документов
+				r.Route("/document-templates", func(r chi.Router) {
+					r.Get("/", templateHandler.GetTemplates)
+					r.Post("/", templateHandler.CreateTemplate)
+					r.Get("/{id}", templateHandler.GetTemplate)
+					r.Put("/{id}", templateHandler.UpdateTemplate)
+					r.Delete("/{id}", templateHandler.DeleteTemplate)
 				})
 
+				// Пользователи и персональные токены
+				r.Route("/users", func(r chi.Router) {
+					r.Get("/", userHandler.GetAllUsers)
+					r.Post("/", userHandler.CreateUser)
+					r.Route("/me", func(r chi.Router) {
+						r.Get("/", userHandler.GetCurrentUser)
+						r.Get("/tokens", userHandler.GetTokens)
+						r.Post("/tokens", userHandler.CreateToken)
+						r.Delete("/tokens/{tokenID}", userHandler.RevokeToken)
+					})
+					r.Get("/{id}", userHandler.GetUser)
+					r.Put("/{id}", userHandler.UpdateUser)
+					r.Patch("/{id}", userHandler.UpdateUser)
+					r.Post("/{id}/tokens", userHandler.CreateUserToken)
+				})
+
+				// Вебхуки и журнал доставок
+				r.Route("/webhooks", func(r chi.Router) {
+					r.Get("/event-types", webhookHandler.GetValidEventTypes)
+					r.Get("/{id}", webhookHandler.GetWebhook)
+					r.Put("/{id}", webhookHandler.UpdateWebhook)
+					r.Delete("/{id}", webhookHandler.DeleteWebhook)
+					r.Get("/{id}/deliveries", webhookHandler.GetDeliveries)
+					r.Post("/{id}/deliveries/{deliveryID}/redeliver", webhookHandler.Redeliver)
+				})
+
+				// Входящие уведомления текущего пользователя
+				r.Route("/notifications", func(r chi.Router) {
+					r.Get("/", notificationHandler.GetInbox)
+					r.Get("/types", notificationHandler.GetValidNotificationTypes)
+					r.Get("/unread-count", notificationHandler.GetUnreadCount)
+					r.Post("/read-all", notificationHandler.MarkAllRead)
+					r.Get("/preferences", notificationHandler.GetPreferences)
+					r.Put("/preferences", notificationHandler.UpdatePreferences)
+					r.Post("/{id}/read", notificationHandler.MarkRead)
+				})
+
+				// Полнотекстовый поиск
+				r.Get("/search", searchHandler.Search)
+
 				// Исполнители
 				// /api/v1/executors
 				r.Route("/executors", func(r chi.Router) {
 					r.Get("/", executorHandler.GetAllExecutors)
 					r.Post("/", executorHandler.CreateExecutor)
+					r.Get("/kinds", executorHandler.GetValidExecutorKinds)
+					r.Get("/{id}", executorHandler.GetExecutor)
+					r.Put("/{id}", executorHandler.UpdateExecutor)
+					r.Delete("/{id}", executorHandler.DeleteExecutor)
+					r.Post("/{id}/reassign", executorHandler.ReassignExecutorTasks)
 				})
 
 				// Frontend логи
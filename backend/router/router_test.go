@@ -3,11 +3,13 @@ package router
 import (
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"testing"
 
 	"project-manager/config"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestCORSMiddleware_AllowsCacheControlHeader(t *testing.T) {
@@ -36,7 +38,7 @@ func TestCORSMiddleware_AllowsCacheControlHeader(t *testing.T) {
 
 	// Проверяем CORS заголовки
 	assert.Equal(t, "http://localhost:3000", rr.Header().Get("Access-Control-Allow-Origin"))
-	assert.Equal(t, "GET, POST, PUT, DELETE, OPTIONS", rr.Header().Get("Access-Control-Allow-Methods"))
+	assert.Equal(t, "GET, POST, PUT, PATCH, DELETE, OPTIONS", rr.Header().Get("Access-Control-Allow-Methods"))
 
 	// Главная проверка: Cache-Control должен быть в разрешенных заголовках
 	allowedHeaders := rr.Header().Get("Access-Control-Allow-Headers")
@@ -74,3 +76,13 @@ func TestCORSMiddleware_AllowsActualHealthRequest(t *testing.T) {
 	// Проверяем тело ответа
 	assert.Equal(t, "OK", rr.Body.String())
 }
+
+func TestRedactURL(t *testing.T) {
+	u, err := url.Parse("/api/v1/events?project_id=p1&access_token=pms_secret")
+	require.NoError(t, err)
+	assert.Equal(t, "/api/v1/events?access_token=REDACTED&project_id=p1", redactURL(u))
+
+	u, err = url.Parse("/api/v1/tasks?limit=10")
+	require.NoError(t, err)
+	assert.Equal(t, "/api/v1/tasks?limit=10", redactURL(u))
+}
@@ -0,0 +1,154 @@
+package services
+
+import (
+	"context"
+
+	"project-manager/models"
+	"project-manager/repositories"
+)
+
+// AccessService проверяет права субъекта запроса на действия в проектах.
+// Запросы без субъекта (фоновые операции) и администраторы не ограничиваются.
+type AccessService struct {
+	memberRepo *repositories.ProjectMemberRepository
+}
+
+func NewAccessService(memberRepo *repositories.ProjectMemberRepository) *AccessService {
+	return &AccessService{memberRepo: memberRepo}
+}
+
+// Authorize проверяет, что субъекту разрешено действие permission в проекте
+func (s *AccessService) Authorize(ctx context.Context, projectID string, permission models.Permission) error {
+	principal := PrincipalFromContext(ctx)
+	if principal == nil {
+		return nil
+	}
+
+	// Агенты работают с документами только через AuthorizeDocumentUpdate
+	if principal.IsAgent && permission == models.PermissionEditDocuments {
+		return ErrForbidden
+	}
+	if principal.IsAdmin {
+		return nil
+	}
+
+	role, err := s.projectRole(ctx, principal, projectID)
+	if err != nil {
+		return err
+	}
+	if !models.RoleHasPermission(role, permission) {
+		return ErrForbidden
+	}
+	return nil
+}
+
+// AuthorizeDocumentUpdate проверяет право на изменение документа.
+// Агенты могут изменять только документы с флагом agent_editable.
+func (s *AccessService) AuthorizeDocumentUpdate(ctx context.Context, document *models.Document) error {
+	principal := PrincipalFromContext(ctx)
+	if principal == nil {
+		return nil
+	}
+
+	role, err := s.projectRole(ctx, principal, document.ProjectID)
+	if err != nil {
+		return err
+	}
+
+	if principal.IsAgent || role == string(models.ProjectRoleAgent) {
+		if !document.AgentEditable {
+			return ErrForbidden
+		}
+		if principal.IsAdmin || role == string(models.ProjectRoleAgent) || models.RoleHasPermission(role, models.PermissionEditDocuments) {
+			return nil
+		}
+		return ErrForbidden
+	}
+
+	if principal.IsAdmin || models.RoleHasPermission(role, models.PermissionEditDocuments) {
+		return nil
+	}
+	return ErrForbidden
+}
+
+// IsAgent проверяет, действует ли субъект как агент в проекте
+func (s *AccessService) IsAgent(ctx context.Context, projectID string) (bool, error) {
+	principal := PrincipalFromContext(ctx)
+	if principal == nil {
+		return false, nil
+	}
+	if principal.IsAgent {
+		return true, nil
+	}
+	role, err := s.projectRole(ctx, principal, projectID)
+	if err != nil {
+		return false, err
+	}
+	return role == string(models.ProjectRoleAgent), nil
+}
+
+// AuthorizeGlobal проверяет право на изменение общих справочников
+// (функциональные блоки, исполнители): администраторам и владельцам или
+// мейнтейнерам хотя бы одного проекта
+func (s *AccessService) AuthorizeGlobal(ctx context.Context) error {
+	principal := PrincipalFromContext(ctx)
+	if principal == nil {
+		return nil
+	}
+	if principal.IsAgent {
+		return ErrForbidden
+	}
+	if principal.IsAdmin {
+		return nil
+	}
+	if principal.UserID == "" {
+		return ErrForbidden
+	}
+
+	allowed, err := s.memberRepo.HasRoleInAnyProject(ctx, principal.UserID, []string{
+		string(models.ProjectRoleOwner),
+		string(models.ProjectRoleMaintainer),
+	})
+	if err != nil {
+		return err
+	}
+	if !allowed {
+		return ErrForbidden
+	}
+	return nil
+}
+
+// AuthorizeProjectCreate проверяет право на создание проекта: агентам
+// запрещено. Создатель становится владельцем проекта, поэтому без учетной
+// записи создавать проекты могут только администраторы.
+func (s *AccessService) AuthorizeProjectCreate(ctx context.Context) error {
+	principal := PrincipalFromContext(ctx)
+	if principal == nil {
+		return nil
+	}
+	if principal.IsAgent || (principal.UserID == "" && !principal.IsAdmin) {
+		return ErrForbidden
+	}
+	return nil
+}
+
+// GrantCreatorOwnership делает создателя проекта его владельцем
+func (s *AccessService) GrantCreatorOwnership(ctx context.Context, projectID string) error {
+	principal := PrincipalFromContext(ctx)
+	if principal == nil || principal.UserID == "" {
+		return nil
+	}
+	return s.memberRepo.Upsert(ctx, &models.ProjectMember{
+		ProjectID: projectID,
+		UserID:    principal.UserID,
+		Role:      string(models.ProjectRoleOwner),
+	})
+}
+
+// projectRole возвращает роль субъекта в проекте или пустую строку
+func (s *AccessService) projectRole(ctx context.Context, principal *models.Principal, projectID string) (string, error) {
+	if principal.UserID == "" || projectID == "" {
+		return "", nil
+	}
+	return s.memberRepo.GetRole(ctx, projectID, principal.UserID)
+}
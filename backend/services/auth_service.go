@@ -1,15 +1,189 @@
 package services
 
-import "project-manager/config"
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"project-manager/config"
+	"project-manager/models"
+	"project-manager/repositories"
+	"project-manager/utils"
+)
+
+// ErrUnauthorized возвращается при отсутствии или недействительности учетных данных
+var ErrUnauthorized = errors.New("unauthorized")
+
+// streamTokenTTL — срок действия токена потока событий. Токен проверяется
+// только при подключении: открытый поток по истечении срока не закрывается.
+const streamTokenTTL = 5 * time.Minute
 
 type AuthService struct {
-	apiKey string
+	apiKey       string
+	apiKeyAdmin  bool
+	streamSecret []byte
+	userRepo     *repositories.UserRepository
+}
+
+func NewAuthService(cfg *config.Config, userRepo *repositories.UserRepository) *AuthService {
+	streamSecret := []byte(cfg.StreamTokenSecret)
+	if len(streamSecret) == 0 {
+		streamSecret = make([]byte, 32)
+		if _, err := rand.Read(streamSecret); err != nil {
+			panic("failed to generate stream token secret: " + err.Error())
+		}
+	}
+	return &AuthService{
+		apiKey:       cfg.APIKey,
+		apiKeyAdmin:  cfg.APIKeyAdmin,
+		streamSecret: streamSecret,
+		userRepo:     userRepo,
+	}
+}
+
+// Authenticate определяет субъекта запроса по значению заголовка X-API-Key.
+// Персональные токены начинаются с models.APITokenPrefix; общий API_KEY
+// продолжает работать от имени models.APIKeyUsername. Права администратора
+// он дает при включенном APIKeyAdmin, а также пока в системе нет ни одного
+// активного администратора — так на новой установке создается первый из них.
+func (s *AuthService) Authenticate(ctx context.Context, key string) (*models.Principal, error) {
+	if key == "" {
+		return nil, ErrUnauthorized
+	}
+
+	if strings.HasPrefix(key, models.APITokenPrefix) && s.userRepo != nil {
+		token, user, err := s.userRepo.GetActiveTokenUser(ctx, hashAPIToken(key))
+		if err != nil {
+			return nil, err
+		}
+		if token != nil {
+			if err := s.userRepo.TouchToken(ctx, token.ID); err != nil {
+				utils.Warn("Failed to update token usage time", map[string]interface{}{
+					"token_id": token.ID,
+					"error":    err.Error(),
+				})
+			}
+			return &models.Principal{
+				UserID:   user.ID,
+				Username: user.Username,
+				IsAdmin:  user.IsAdmin,
+				IsAgent:  user.IsAgent,
+				TokenID:  token.ID,
+			}, nil
+		}
+	}
+
+	if s.apiKey != "" && subtle.ConstantTimeCompare([]byte(key), []byte(s.apiKey)) == 1 {
+		isAdmin := s.apiKeyAdmin
+		if !isAdmin {
+			var err error
+			if isAdmin, err = s.bootstrapping(ctx); err != nil {
+				return nil, err
+			}
+		}
+		return &models.Principal{
+			Username: models.APIKeyUsername,
+			IsAdmin:  isAdmin,
+			Legacy:   true,
+		}, nil
+	}
+
+	return nil, ErrUnauthorized
+}
+
+// bootstrapping проверяет, что активных администраторов еще нет
+func (s *AuthService) bootstrapping(ctx context.Context) (bool, error) {
+	if s.userRepo == nil {
+		return true, nil
+	}
+	hasAdmin, err := s.userRepo.HasActiveAdmin(ctx)
+	if err != nil {
+		return false, err
+	}
+	return !hasAdmin, nil
+}
+
+// streamTokenClaims — содержимое токена потока событий
+type streamTokenClaims struct {
+	Principal models.Principal `json:"p"`
+	ExpiresAt int64            `json:"exp"`
+}
+
+// IssueStreamToken выпускает субъекту запроса краткосрочный токен для
+// подключения к потоку событий. EventSource в браузере не умеет передавать
+// заголовок X-API-Key, поэтому токен передается в параметре access_token.
+func (s *AuthService) IssueStreamToken(ctx context.Context) (*models.StreamToken, error) {
+	principal := PrincipalFromContext(ctx)
+	if principal == nil {
+		return nil, ErrUnauthorized
+	}
+	return s.signStreamToken(principal, time.Now())
+}
+
+// AuthenticateStreamToken определяет субъекта по токену потока событий
+func (s *AuthService) AuthenticateStreamToken(token string) (*models.Principal, error) {
+	return s.verifyStreamToken(token, time.Now())
+}
+
+// signStreamToken подписывает субъекта и срок действия ключом streamSecret
+func (s *AuthService) signStreamToken(principal *models.Principal, now time.Time) (*models.StreamToken, error) {
+	expiresAt := now.Add(streamTokenTTL)
+	payload, err := json.Marshal(streamTokenClaims{Principal: *principal, ExpiresAt: expiresAt.Unix()})
+	if err != nil {
+		return nil, err
+	}
+	encoded := base64.RawURLEncoding.EncodeToString(payload)
+	return &models.StreamToken{
+		Token:     models.StreamTokenPrefix + encoded + "." + s.streamSignature(encoded),
+		ExpiresAt: expiresAt,
+	}, nil
+}
+
+// verifyStreamToken проверяет подпись и срок действия токена потока событий
+func (s *AuthService) verifyStreamToken(token string, now time.Time) (*models.Principal, error) {
+	encoded, signature, ok := strings.Cut(strings.TrimPrefix(token, models.StreamTokenPrefix), ".")
+	if !ok || !strings.HasPrefix(token, models.StreamTokenPrefix) ||
+		!hmac.Equal([]byte(signature), []byte(s.streamSignature(encoded))) {
+		return nil, ErrUnauthorized
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, ErrUnauthorized
+	}
+	var claims streamTokenClaims
+	if err := json.Unmarshal(payload, &claims); err != nil || now.Unix() >= claims.ExpiresAt {
+		return nil, ErrUnauthorized
+	}
+	return &claims.Principal, nil
+}
+
+func (s *AuthService) streamSignature(encoded string) string {
+	mac := hmac.New(sha256.New, s.streamSecret)
+	mac.Write([]byte(encoded))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
 }
 
-func NewAuthService(cfg *config.Config) *AuthService {
-	return &AuthService{apiKey: cfg.APIKey}
+// generateAPIToken создает новый токен и возвращает его вместе с хешем и отображаемым префиксом
+func generateAPIToken() (token, hash, prefix string, err error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", "", "", err
+	}
+	token = models.APITokenPrefix + base64.RawURLEncoding.EncodeToString(buf)
+	return token, hashAPIToken(token), token[:len(models.APITokenPrefix)+8], nil
 }
 
-func (s *AuthService) IsAPIKeyValid(key string) bool {
-	return key != "" && key == s.apiKey
+// hashAPIToken возвращает SHA-256 хеш токена в hex
+func hashAPIToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
 }
@@ -0,0 +1,46 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"project-manager/config"
+	"project-manager/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStreamToken(t *testing.T) {
+	service := NewAuthService(&config.Config{StreamTokenSecret: "secret"}, nil)
+	principal := &models.Principal{UserID: "u-1", Username: "ivan", TokenID: "t-1"}
+	now := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+
+	token, err := service.signStreamToken(principal, now)
+	require.NoError(t, err)
+	assert.Equal(t, now.Add(streamTokenTTL), token.ExpiresAt)
+
+	verified, err := service.verifyStreamToken(token.Token, now.Add(time.Minute))
+	require.NoError(t, err)
+	assert.Equal(t, principal, verified)
+
+	// Просроченный токен
+	_, err = service.verifyStreamToken(token.Token, now.Add(streamTokenTTL))
+	assert.ErrorIs(t, err, ErrUnauthorized)
+
+	// Токен, подписанный другим ключом
+	other := NewAuthService(&config.Config{StreamTokenSecret: "other"}, nil)
+	_, err = other.verifyStreamToken(token.Token, now)
+	assert.ErrorIs(t, err, ErrUnauthorized)
+
+	// Подмена содержимого
+	forged, err := other.signStreamToken(&models.Principal{Username: "root", IsAdmin: true}, now)
+	require.NoError(t, err)
+	_, err = service.verifyStreamToken(forged.Token, now)
+	assert.ErrorIs(t, err, ErrUnauthorized)
+
+	for _, invalid := range []string{"", "pms_", "pm_abc", token.Token[len(models.StreamTokenPrefix):]} {
+		_, err = service.verifyStreamToken(invalid, now)
+		assert.ErrorIs(t, err, ErrUnauthorized, invalid)
+	}
+}
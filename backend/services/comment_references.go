@@ -0,0 +1,39 @@
+package services
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	// mentionRegex находит упоминания @username, не считая адресов почты
+	mentionRegex = regexp.MustCompile(`(?:^|[^\w.@-])@([a-zA-Z0-9._-]{2,100})`)
+	// taskReferenceRegex находит ссылки на задачи вида #AUTH-0042 или #TASK-000123
+	taskReferenceRegex = regexp.MustCompile(`(?:^|[^\w#])#([A-Za-z]{1,6}-?\d+)\b`)
+)
+
+// parseCommentReferences извлекает из текста комментария упомянутые имена
+// пользователей и номера задач без повторов, в порядке появления
+func parseCommentReferences(content string) (usernames, taskNumbers []string) {
+	seenUsers := make(map[string]bool)
+	for _, match := range mentionRegex.FindAllStringSubmatch(content, -1) {
+		// Точка в конце относится к предложению, а не к имени
+		username := strings.TrimRight(match[1], ".")
+		if len(username) < 2 || seenUsers[username] {
+			continue
+		}
+		seenUsers[username] = true
+		usernames = append(usernames, username)
+	}
+
+	seenTasks := make(map[string]bool)
+	for _, match := range taskReferenceRegex.FindAllStringSubmatch(content, -1) {
+		number := strings.ToUpper(match[1])
+		if seenTasks[number] {
+			continue
+		}
+		seenTasks[number] = true
+		taskNumbers = append(taskNumbers, number)
+	}
+	return usernames, taskNumbers
+}
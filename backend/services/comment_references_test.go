@@ -0,0 +1,21 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseCommentReferences(t *testing.T) {
+	usernames, taskNumbers := parseCommentReferences("@alice, please sync with @bob.smith. See #AUTH-0042 and #task-000123; mail bob@example.com, ping @alice again, #AUTH-0042")
+
+	assert.Equal(t, []string{"alice", "bob.smith"}, usernames)
+	assert.Equal(t, []string{"AUTH-0042", "TASK-000123"}, taskNumbers)
+}
+
+func TestParseCommentReferences_IgnoresNonReferences(t *testing.T) {
+	usernames, taskNumbers := parseCommentReferences("email a@b.co, heading ##AUTH-1 and issue#12, @x")
+
+	assert.Empty(t, usernames)
+	assert.Empty(t, taskNumbers)
+}
@@ -7,19 +7,28 @@ import (
 
 	"project-manager/models"
 	"project-manager/repositories"
+	"project-manager/utils"
 )
 
 type CommentService struct {
-	commentRepo *repositories.CommentRepository
-	taskRepo    *repositories.TaskRepository
-	logService  *OperationLogService
+	commentRepo   *repositories.CommentRepository
+	taskRepo      *repositories.TaskRepository
+	userRepo      *repositories.UserRepository
+	logService    *OperationLogService
+	notifications *NotificationService
+	access        *AccessService
+	events        *EventBroker
 }
 
-func NewCommentService(commentRepo *repositories.CommentRepository, taskRepo *repositories.TaskRepository, logService *OperationLogService) *CommentService {
+func NewCommentService(commentRepo *repositories.CommentRepository, taskRepo *repositories.TaskRepository, userRepo *repositories.UserRepository, logService *OperationLogService, notifications *NotificationService, access *AccessService, events *EventBroker) *CommentService {
 	return &CommentService{
-		commentRepo: commentRepo,
-		taskRepo:    taskRepo,
-		logService:  logService,
+		commentRepo:   commentRepo,
+		taskRepo:      taskRepo,
+		userRepo:      userRepo,
+		logService:    logService,
+		notifications: notifications,
+		access:        access,
+		events:        events,
 	}
 }
 
@@ -29,6 +38,13 @@ func (s *CommentService) CreateComment(ctx context.Context, comment *models.Comm
 		return errors.New("task_id is required")
 	}
 
+	// Автор комментария — аутентифицированный субъект запроса; переданный
+	// клиентом идентификатор не учитывается
+	principal := PrincipalFromContext(ctx)
+	if principal != nil {
+		comment.UserIdentifier = principal.Username
+	}
+
 	if strings.TrimSpace(comment.UserIdentifier) == "" {
 		return errors.New("user_identifier is required")
 	}
@@ -37,6 +53,12 @@ func (s *CommentService) CreateComment(ctx context.Context, comment *models.Comm
 		return errors.New("content is required")
 	}
 
+	commentType, err := resolveCommentType(principal, comment.Type)
+	if err != nil {
+		return err
+	}
+	comment.Type = commentType
+
 	// Проверка существования задачи
 	task, err := s.taskRepo.GetByID(ctx, comment.TaskID)
 	if err != nil {
@@ -46,9 +68,34 @@ func (s *CommentService) CreateComment(ctx context.Context, comment *models.Comm
 		return errors.New("task not found")
 	}
 
+	// Ответ допускается только на комментарий той же задачи
+	if comment.ParentCommentID != nil && strings.TrimSpace(*comment.ParentCommentID) == "" {
+		comment.ParentCommentID = nil
+	}
+	if comment.ParentCommentID != nil {
+		parent, err := s.commentRepo.GetByID(ctx, *comment.ParentCommentID)
+		if err != nil {
+			return err
+		}
+		if parent == nil {
+			return errors.New("parent comment not found")
+		}
+		if parent.TaskID != comment.TaskID {
+			return errors.New("parent comment belongs to another task")
+		}
+		if parent.DeletedAt != nil {
+			return errors.New("parent comment is deleted")
+		}
+	}
+
+	if err := s.access.Authorize(ctx, task.ProjectID, models.PermissionEditTasks); err != nil {
+		return err
+	}
+
 	if err := s.commentRepo.Create(ctx, comment); err != nil {
 		return err
 	}
+	mentioned := s.saveReferences(ctx, comment)
 
 	// Логируем создание комментария
 	if s.logService != nil {
@@ -57,13 +104,213 @@ func (s *CommentService) CreateComment(ctx context.Context, comment *models.Comm
 			"task_id":         comment.TaskID,
 			"user_identifier": comment.UserIdentifier,
 			"content":         comment.Content,
+			"type":            comment.Type,
+		}
+		if comment.ParentCommentID != nil {
+			details["parent_comment_id"] = *comment.ParentCommentID
 		}
 		s.logService.LogTaskOperation(ctx, comment.TaskID, comment.UserIdentifier, string(models.OperationTypeComment), details)
 	}
 
+	s.events.Publish(ctx, models.Event{
+		Type:      models.EventCommentCreated,
+		ProjectID: task.ProjectID,
+		EntityID:  comment.ID,
+		Data:      comment,
+	})
+	// Системные комментарии (ход выполнения задачи) уведомлений не создают
+	if comment.Type != string(models.CommentTypeSystem) {
+		s.notifications.NotifyComment(ctx, task, comment, mentioned)
+	}
 	return nil
 }
 
+// UpdateComment изменяет текст комментария, сохраняя прежний в истории правок.
+// Чужие комментарии может править только управляющий проектом, системные
+// комментарии не редактируются.
+func (s *CommentService) UpdateComment(ctx context.Context, id, content string) (*models.Comment, error) {
+	if strings.TrimSpace(id) == "" {
+		return nil, errors.New("id is required")
+	}
+	if strings.TrimSpace(content) == "" {
+		return nil, errors.New("content is required")
+	}
+
+	comment, err := s.commentRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if comment == nil {
+		return nil, errors.New("comment not found")
+	}
+	if comment.Type == string(models.CommentTypeSystem) {
+		return nil, errors.New("system comments cannot be edited")
+	}
+	if comment.DeletedAt != nil {
+		return nil, errors.New("deleted comments cannot be edited")
+	}
+
+	task, err := s.taskRepo.GetByID(ctx, comment.TaskID)
+	if err != nil {
+		return nil, err
+	}
+	if task == nil {
+		return nil, errors.New("task not found")
+	}
+
+	if err := s.authorizeCommentChange(ctx, task.ProjectID, comment); err != nil {
+		return nil, err
+	}
+
+	if content == comment.Content {
+		return comment, nil
+	}
+
+	previous, err := s.commentRepo.GetReferences(ctx, comment.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	previousContent := comment.Content
+	comment.Content = content
+	if err := s.commentRepo.UpdateContent(ctx, comment, actorFromContext(ctx)); err != nil {
+		return nil, err
+	}
+
+	// Уведомление получают только пользователи, упомянутые при правке впервые
+	alreadyMentioned := make(map[string]bool, len(previous.Mentions))
+	for _, mention := range previous.Mentions {
+		alreadyMentioned[mention.UserID] = true
+	}
+	newlyMentioned := []models.NotificationRecipient{}
+	for _, recipient := range s.saveReferences(ctx, comment) {
+		if !alreadyMentioned[recipient.UserID] {
+			newlyMentioned = append(newlyMentioned, recipient)
+		}
+	}
+
+	// Логируем правку комментария
+	if s.logService != nil {
+		details := map[string]interface{}{
+			"comment_id":       comment.ID,
+			"task_id":          comment.TaskID,
+			"user_identifier":  comment.UserIdentifier,
+			"content":          comment.Content,
+			"previous_content": previousContent,
+			"edited":           true,
+		}
+		s.logService.LogTaskOperation(ctx, comment.TaskID, actorFromContext(ctx), string(models.OperationTypeComment), details)
+	}
+
+	s.events.Publish(ctx, models.Event{
+		Type:      models.EventCommentUpdated,
+		ProjectID: task.ProjectID,
+		EntityID:  comment.ID,
+		Data:      comment,
+	})
+	s.notifications.NotifyMentions(ctx, task, comment, newlyMentioned)
+	return comment, nil
+}
+
+// GetCommentReferences возвращает упомянутых в комментарии пользователей и задачи, на которые он ссылается
+func (s *CommentService) GetCommentReferences(ctx context.Context, id string) (*models.CommentReferences, error) {
+	if strings.TrimSpace(id) == "" {
+		return nil, errors.New("id is required")
+	}
+
+	comment, err := s.commentRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if comment == nil {
+		return nil, errors.New("comment not found")
+	}
+
+	return s.commentRepo.GetReferences(ctx, id)
+}
+
+// GetTaskBacklinks возвращает комментарии других задач, ссылающиеся на задачу
+func (s *CommentService) GetTaskBacklinks(ctx context.Context, taskID string) ([]models.CommentTaskReference, error) {
+	if strings.TrimSpace(taskID) == "" {
+		return nil, errors.New("task_id is required")
+	}
+
+	task, err := s.taskRepo.GetByID(ctx, taskID)
+	if err != nil {
+		return nil, err
+	}
+	if task == nil {
+		return nil, errors.New("task not found")
+	}
+
+	return s.commentRepo.GetTaskBacklinks(ctx, taskID)
+}
+
+// saveReferences сохраняет упоминания (@username) и ссылки на задачи
+// (#AUTH-0042) из текста комментария и возвращает упомянутых пользователей.
+// Неизвестные имена и номера пропускаются; ошибки только логируются,
+// так как сам комментарий уже сохранен.
+func (s *CommentService) saveReferences(ctx context.Context, comment *models.Comment) []models.NotificationRecipient {
+	usernames, taskNumbers := parseCommentReferences(comment.Content)
+
+	mentioned := []models.NotificationRecipient{}
+	userIDs := []string{}
+	for _, username := range usernames {
+		user, err := s.userRepo.GetByUsername(ctx, username)
+		if err != nil {
+			s.warnReferences(comment, err)
+			return nil
+		}
+		if user == nil || !user.IsActive {
+			continue
+		}
+		mentioned = append(mentioned, models.NotificationRecipient{UserID: user.ID, Username: user.Username})
+		userIDs = append(userIDs, user.ID)
+	}
+
+	taskIDs := []string{}
+	for _, number := range taskNumbers {
+		task, err := s.taskRepo.GetByNumber(ctx, number)
+		if err != nil {
+			s.warnReferences(comment, err)
+			return nil
+		}
+		if task != nil && task.ID != comment.TaskID {
+			taskIDs = append(taskIDs, task.ID)
+		}
+	}
+
+	if err := s.commentRepo.ReplaceReferences(ctx, comment.ID, userIDs, taskIDs); err != nil {
+		s.warnReferences(comment, err)
+		return nil
+	}
+	return mentioned
+}
+
+func (s *CommentService) warnReferences(comment *models.Comment, err error) {
+	utils.Warn("Failed to save comment references", map[string]interface{}{
+		"comment_id": comment.ID,
+		"error":      err.Error(),
+	})
+}
+
+// GetCommentEdits возвращает историю правок комментария
+func (s *CommentService) GetCommentEdits(ctx context.Context, id string) ([]models.CommentEdit, error) {
+	if strings.TrimSpace(id) == "" {
+		return nil, errors.New("id is required")
+	}
+
+	comment, err := s.commentRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if comment == nil {
+		return nil, errors.New("comment not found")
+	}
+
+	return s.commentRepo.GetEdits(ctx, id)
+}
+
 func (s *CommentService) GetCommentByID(ctx context.Context, id string) (*models.Comment, error) {
 	if strings.TrimSpace(id) == "" {
 		return nil, errors.New("id is required")
@@ -88,10 +335,33 @@ func (s *CommentService) GetCommentsByTaskID(ctx context.Context, taskID string)
 	return s.commentRepo.GetByTaskID(ctx, taskID)
 }
 
+// GetCommentThreads возвращает комментарии задачи деревом: ответы вложены в родителей
+func (s *CommentService) GetCommentThreads(ctx context.Context, taskID string) ([]models.CommentThread, error) {
+	comments, err := s.GetCommentsByTaskID(ctx, taskID)
+	if err != nil {
+		return nil, err
+	}
+	return buildCommentThreads(comments), nil
+}
+
 func (s *CommentService) GetAllComments(ctx context.Context) ([]models.Comment, error) {
 	return s.commentRepo.GetAll(ctx)
 }
 
+// authorizeCommentChange разрешает автору править и удалять свой комментарий,
+// а чужой — только управляющему проектом
+func (s *CommentService) authorizeCommentChange(ctx context.Context, projectID string, comment *models.Comment) error {
+	if err := s.access.Authorize(ctx, projectID, models.PermissionEditTasks); err != nil {
+		return err
+	}
+	if principal := PrincipalFromContext(ctx); principal != nil && principal.Username != comment.UserIdentifier {
+		return s.access.Authorize(ctx, projectID, models.PermissionManageProject)
+	}
+	return nil
+}
+
+// DeleteComment удаляет комментарий по тем же правилам доступа, что и правка.
+// Комментарий с ответами остается в ветке заглушкой.
 func (s *CommentService) DeleteComment(ctx context.Context, id string) error {
 	if strings.TrimSpace(id) == "" {
 		return errors.New("id is required")
@@ -102,9 +372,30 @@ func (s *CommentService) DeleteComment(ctx context.Context, id string) error {
 	if err != nil {
 		return err
 	}
-	if comment == nil {
+	if comment == nil || comment.DeletedAt != nil {
 		return errors.New("comment not found")
 	}
 
-	return s.commentRepo.Delete(ctx, id)
+	task, err := s.taskRepo.GetByID(ctx, comment.TaskID)
+	if err != nil {
+		return err
+	}
+	if task == nil {
+		return errors.New("task not found")
+	}
+	if err := s.authorizeCommentChange(ctx, task.ProjectID, comment); err != nil {
+		return err
+	}
+
+	if err := s.commentRepo.Delete(ctx, id); err != nil {
+		return err
+	}
+
+	s.events.Publish(ctx, models.Event{
+		Type:      models.EventCommentDeleted,
+		ProjectID: task.ProjectID,
+		EntityID:  comment.ID,
+		Data:      comment,
+	})
+	return nil
 }
@@ -0,0 +1,70 @@
+package services
+
+import (
+	"errors"
+
+	"project-manager/models"
+)
+
+// resolveCommentType определяет тип нового комментария. Без явного типа
+// комментарий агента получает тип agent, остальные — user. Системные
+// комментарии создает только сервер (запрос без субъекта), а тип agent
+// доступен агентам и клиентам с общим API_KEY.
+func resolveCommentType(principal *models.Principal, requested string) (string, error) {
+	if requested == "" {
+		if principal != nil && principal.IsAgent {
+			return string(models.CommentTypeAgent), nil
+		}
+		return string(models.CommentTypeUser), nil
+	}
+
+	if !models.IsValidCommentType(requested) {
+		return "", errors.New("invalid comment type")
+	}
+	if principal == nil {
+		return requested, nil
+	}
+
+	switch models.CommentType(requested) {
+	case models.CommentTypeSystem:
+		return "", errors.New("system comments are created by the server only")
+	case models.CommentTypeAgent:
+		if !principal.IsAgent && !principal.Legacy {
+			return "", errors.New("only agents can post agent comments")
+		}
+	}
+	return requested, nil
+}
+
+// buildCommentThreads раскладывает комментарии по веткам ответов, сохраняя
+// исходный порядок внутри каждого уровня. Ответ, родителя которого нет
+// в списке, становится комментарием верхнего уровня.
+func buildCommentThreads(comments []models.Comment) []models.CommentThread {
+	known := make(map[string]bool, len(comments))
+	for _, comment := range comments {
+		known[comment.ID] = true
+	}
+
+	children := make(map[string][]models.Comment)
+	roots := []models.Comment{}
+	for _, comment := range comments {
+		if comment.ParentCommentID != nil && known[*comment.ParentCommentID] && *comment.ParentCommentID != comment.ID {
+			children[*comment.ParentCommentID] = append(children[*comment.ParentCommentID], comment)
+			continue
+		}
+		roots = append(roots, comment)
+	}
+
+	var build func(level []models.Comment) []models.CommentThread
+	build = func(level []models.Comment) []models.CommentThread {
+		threads := make([]models.CommentThread, 0, len(level))
+		for _, comment := range level {
+			threads = append(threads, models.CommentThread{
+				Comment: comment,
+				Replies: build(children[comment.ID]),
+			})
+		}
+		return threads
+	}
+	return build(roots)
+}
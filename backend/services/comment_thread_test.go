@@ -0,0 +1,73 @@
+package services
+
+import (
+	"testing"
+
+	"project-manager/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testComment(id, parentID string) models.Comment {
+	comment := models.Comment{ID: id, TaskID: "t1"}
+	if parentID != "" {
+		comment.ParentCommentID = &parentID
+	}
+	return comment
+}
+
+func TestBuildCommentThreads(t *testing.T) {
+	comments := []models.Comment{
+		testComment("a", ""),
+		testComment("b", "a"),
+		testComment("c", ""),
+		testComment("d", "b"),
+		testComment("e", "a"),
+		testComment("f", "missing"),
+	}
+
+	threads := buildCommentThreads(comments)
+	require.Len(t, threads, 3)
+	assert.Equal(t, "a", threads[0].ID)
+	assert.Equal(t, "c", threads[1].ID)
+	assert.Equal(t, "f", threads[2].ID)
+
+	require.Len(t, threads[0].Replies, 2)
+	assert.Equal(t, "b", threads[0].Replies[0].ID)
+	assert.Equal(t, "e", threads[0].Replies[1].ID)
+	require.Len(t, threads[0].Replies[0].Replies, 1)
+	assert.Equal(t, "d", threads[0].Replies[0].Replies[0].ID)
+	assert.NotNil(t, threads[1].Replies)
+}
+
+func TestResolveCommentType(t *testing.T) {
+	user := &models.Principal{Username: "alice"}
+	agent := &models.Principal{Username: "bot", IsAgent: true}
+	legacy := &models.Principal{Username: models.APIKeyUsername, Legacy: true}
+
+	commentType, err := resolveCommentType(user, "")
+	require.NoError(t, err)
+	assert.Equal(t, string(models.CommentTypeUser), commentType)
+
+	commentType, err = resolveCommentType(agent, "")
+	require.NoError(t, err)
+	assert.Equal(t, string(models.CommentTypeAgent), commentType)
+
+	commentType, err = resolveCommentType(nil, string(models.CommentTypeSystem))
+	require.NoError(t, err)
+	assert.Equal(t, string(models.CommentTypeSystem), commentType)
+
+	_, err = resolveCommentType(user, string(models.CommentTypeSystem))
+	assert.Error(t, err)
+
+	_, err = resolveCommentType(user, string(models.CommentTypeAgent))
+	assert.Error(t, err)
+
+	commentType, err = resolveCommentType(legacy, string(models.CommentTypeAgent))
+	require.NoError(t, err)
+	assert.Equal(t, string(models.CommentTypeAgent), commentType)
+
+	_, err = resolveCommentType(user, "bogus")
+	assert.Error(t, err)
+}
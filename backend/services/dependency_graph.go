@@ -0,0 +1,83 @@
+package services
+
+import "project-manager/models"
+
+// dependencyGraph — ориентированный граф связей одного вида:
+// ребро ведет от DependsOnTaskID к TaskID (от блокирующей задачи к блокируемой)
+type dependencyGraph map[string][]string
+
+// buildDependencyGraph строит граф из связей вида kind
+func buildDependencyGraph(dependencies []models.TaskDependency, kind models.DependencyKind) dependencyGraph {
+	graph := make(dependencyGraph)
+	for _, dependency := range dependencies {
+		if dependency.Kind != string(kind) {
+			continue
+		}
+		graph[dependency.DependsOnTaskID] = append(graph[dependency.DependsOnTaskID], dependency.TaskID)
+	}
+	return graph
+}
+
+// path возвращает путь от from до to (включая концы) или nil, если пути нет
+func (g dependencyGraph) path(from, to string) []string {
+	previous := map[string]string{from: ""}
+	queue := []string{from}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		if current == to {
+			var path []string
+			for node := to; node != ""; node = previous[node] {
+				path = append([]string{node}, path...)
+			}
+			return path
+		}
+		for _, next := range g[current] {
+			if _, visited := previous[next]; !visited {
+				previous[next] = current
+				queue = append(queue, next)
+			}
+		}
+	}
+	return nil
+}
+
+// cycleWith возвращает цикл, который образует новое ребро from -> to,
+// в виде последовательности задач, или nil, если цикла не будет
+func (g dependencyGraph) cycleWith(from, to string) []string {
+	if from == to {
+		return []string{from, to}
+	}
+	path := g.path(to, from)
+	if path == nil {
+		return nil
+	}
+	return append(path, to)
+}
+
+// blockersByTask возвращает для каждой задачи список блокирующих ее задач
+func blockersByTask(dependencies []models.TaskDependency) map[string][]string {
+	blockers := make(map[string][]string)
+	for _, dependency := range dependencies {
+		if dependency.Kind == string(models.DependencyKindBlocks) {
+			blockers[dependency.TaskID] = append(blockers[dependency.TaskID], dependency.DependsOnTaskID)
+		}
+	}
+	return blockers
+}
+
+// isTaskReady проверяет, что задача открыта и все ее блокеры выполнены, то
+// есть находятся в статусах DoneStates процесса workflow. Отмененный блокер
+// работу не выполнил и блокировку не снимает. statuses содержит статусы задач
+// по ID; блокер, статус которого неизвестен, считается незавершенным.
+func isTaskReady(taskID string, statuses map[string]string, blockers map[string][]string, workflow *models.Workflow) bool {
+	if workflow.IsTerminal(statuses[taskID]) {
+		return false
+	}
+	for _, blockerID := range blockers[taskID] {
+		if status, ok := statuses[blockerID]; !ok || !workflow.IsDone(status) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,78 @@
+package services
+
+import (
+	"testing"
+
+	"project-manager/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func blocks(blocker, blocked string) models.TaskDependency {
+	return models.TaskDependency{TaskID: blocked, DependsOnTaskID: blocker, Kind: string(models.DependencyKindBlocks)}
+}
+
+func TestDependencyGraphCycleWith(t *testing.T) {
+	dependencies := []models.TaskDependency{
+		blocks("A", "B"),
+		blocks("B", "C"),
+		{TaskID: "A", DependsOnTaskID: "C", Kind: string(models.DependencyKindRelatesTo)},
+	}
+	graph := buildDependencyGraph(dependencies, models.DependencyKindBlocks)
+
+	// C -> A замыкает цепочку A -> B -> C
+	assert.Equal(t, []string{"A", "B", "C", "A"}, graph.cycleWith("C", "A"))
+
+	// Связи другого вида не учитываются
+	assert.Nil(t, graph.cycleWith("A", "C"))
+	assert.Nil(t, graph.cycleWith("D", "A"))
+
+	// Петля на себя
+	assert.Equal(t, []string{"A", "A"}, graph.cycleWith("A", "A"))
+}
+
+func TestIsTaskReady(t *testing.T) {
+	dependencies := []models.TaskDependency{blocks("A", "B"), blocks("C", "B")}
+	blockers := blockersByTask(dependencies)
+	workflow := models.DefaultWorkflow()
+	statuses := map[string]string{
+		"A": string(models.TaskStatusDone),
+		"B": string(models.TaskStatusNew),
+		"C": string(models.TaskStatusInProgress),
+	}
+
+	assert.False(t, isTaskReady("B", statuses, blockers, workflow))
+	assert.True(t, isTaskReady("C", statuses, blockers, workflow))
+	assert.False(t, isTaskReady("A", statuses, blockers, workflow))
+
+	// Отмененный блокер не снимает блокировку
+	statuses["C"] = string(models.TaskStatusCancelled)
+	assert.False(t, isTaskReady("B", statuses, blockers, workflow))
+
+	statuses["C"] = string(models.TaskStatusDone)
+	assert.True(t, isTaskReady("B", statuses, blockers, workflow))
+
+	// Блокер с неизвестным статусом считается незавершенным
+	delete(statuses, "C")
+	assert.False(t, isTaskReady("B", statuses, blockers, workflow))
+}
+
+func TestIsTaskReadyCustomWorkflow(t *testing.T) {
+	blockers := blockersByTask([]models.TaskDependency{blocks("A", "B")})
+	workflow := &models.Workflow{
+		InitialState:   "Open",
+		States:         []string{"Open", "Review", "Closed", "Rejected"},
+		TerminalStates: []string{"Closed", "Rejected"},
+		DoneStates:     []string{"Closed"},
+	}
+	statuses := map[string]string{"A": "Review", "B": "Open"}
+
+	assert.False(t, isTaskReady("B", statuses, blockers, workflow))
+
+	statuses["A"] = "Rejected"
+	assert.False(t, isTaskReady("B", statuses, blockers, workflow))
+
+	statuses["A"] = "Closed"
+	assert.True(t, isTaskReady("B", statuses, blockers, workflow))
+	assert.False(t, isTaskReady("A", statuses, blockers, workflow))
+}
@@ -3,24 +3,53 @@ package services
 import (
 	"context"
 	"errors"
+	"fmt"
 	"strings"
 
 	"project-manager/models"
 	"project-manager/repositories"
+	"project-manager/utils"
 )
 
+// ErrRequiredSectionsMissing возвращается, если в документе нет разделов, обязательных для его типа
+var ErrRequiredSectionsMissing = errors.New("required document sections are missing")
+
 type DocumentService struct {
 	documentRepo *repositories.DocumentRepository
+	revisionRepo *repositories.DocumentRevisionRepository
 	projectRepo  *repositories.ProjectRepository
+	logService   *OperationLogService
+	access       *AccessService
+	events       *EventBroker
 }
 
-func NewDocumentService(documentRepo *repositories.DocumentRepository, projectRepo *repositories.ProjectRepository) *DocumentService {
+func NewDocumentService(documentRepo *repositories.DocumentRepository, revisionRepo *repositories.DocumentRevisionRepository, projectRepo *repositories.ProjectRepository, logService *OperationLogService, access *AccessService, events *EventBroker) *DocumentService {
 	return &DocumentService{
 		documentRepo: documentRepo,
+		revisionRepo: revisionRepo,
 		projectRepo:  projectRepo,
+		logService:   logService,
+		access:       access,
+		events:       events,
 	}
 }
 
+// publishDocumentEvent сообщает подписчикам об изменении документа. Содержимое
+// документа в событие не входит: клиенты перечитывают его при необходимости.
+func (s *DocumentService) publishDocumentEvent(ctx context.Context, eventType models.EventType, document *models.Document) {
+	s.events.Publish(ctx, models.Event{
+		Type:      eventType,
+		ProjectID: document.ProjectID,
+		EntityID:  document.ID,
+		Data: map[string]interface{}{
+			"id":      document.ID,
+			"type":    document.Type,
+			"title":   document.Title,
+			"version": document.Version,
+		},
+	})
+}
+
 func (s *DocumentService) CreateDocument(ctx context.Context, document *models.Document) error {
 	// Валидация обязательных полей
 	if strings.TrimSpace(document.ProjectID) == "" {
@@ -43,6 +72,9 @@ func (s *DocumentService) CreateDocument(ctx context.Context, document *models.D
 	if !models.IsValidDocumentType(document.Type) {
 		return errors.New("invalid document type")
 	}
+	if err := checkRequiredSections(document.Type, nil, document.Content); err != nil {
+		return err
+	}
 
 	// Проверка существования проекта
 	project, err := s.projectRepo.GetByID(ctx, document.ProjectID)
@@ -53,7 +85,28 @@ func (s *DocumentService) CreateDocument(ctx context.Context, document *models.D
 		return errors.New("project not found")
 	}
 
-	return s.documentRepo.Create(ctx, document)
+	if err := s.access.Authorize(ctx, document.ProjectID, models.PermissionEditDocuments); err != nil {
+		return err
+	}
+
+	revision := &models.DocumentRevision{Author: actorFromContext(ctx)}
+	if err := s.documentRepo.Create(ctx, document, revision); err != nil {
+		return err
+	}
+
+	// Логируем создание документа
+	if s.logService != nil {
+		details := map[string]interface{}{
+			"document_id": document.ID,
+			"project_id":  document.ProjectID,
+			"type":        document.Type,
+			"title":       document.Title,
+		}
+		s.logService.LogEntityOperation(ctx, models.LogEntityDocument, document.ID, actorFromContext(ctx), string(models.OperationTypeCreate), details)
+	}
+
+	s.publishDocumentEvent(ctx, models.EventDocumentCreated, document)
+	return nil
 }
 
 func (s *DocumentService) GetDocumentByID(ctx context.Context, id string) (*models.Document, error) {
@@ -172,11 +225,49 @@ func (s *DocumentService) UpdateDocument(ctx context.Context, document *models.D
 		return errors.New("document not found")
 	}
 
+	// Права проверяются по сохраненному документу: агент не может
+	// обойти запрет, выставив agent_editable в запросе
+	if err := s.access.AuthorizeDocumentUpdate(ctx, existingDocument); err != nil {
+		return err
+	}
+	isAgent, err := s.access.IsAgent(ctx, existingDocument.ProjectID)
+	if err != nil {
+		return err
+	}
+	if isAgent {
+		document.AgentEditable = existingDocument.AgentEditable
+	}
+
+	// Версия 0 означает безусловное обновление поверх текущей версии
+	if err := checkVersion(&document.Version, existingDocument.Version); err != nil {
+		return err
+	}
+
+	if err := checkRequiredSections(document.Type, existingDocument, document.Content); err != nil {
+		return err
+	}
+
 	// Сохраняем неизменяемые поля
 	document.ProjectID = existingDocument.ProjectID
 	document.CreatedAt = existingDocument.CreatedAt
 
-	return s.documentRepo.Update(ctx, document)
+	revision := &models.DocumentRevision{Author: actorFromContext(ctx)}
+	if err := s.documentRepo.Update(ctx, document, revision); err != nil {
+		return err
+	}
+
+	// Логируем изменения полей документа
+	if s.logService != nil {
+		details := map[string]interface{}{
+			"document_id": document.ID,
+			"title":       document.Title,
+			"changes":     models.DiffFields(existingDocument, document),
+		}
+		s.logService.LogEntityOperation(ctx, models.LogEntityDocument, document.ID, actorFromContext(ctx), string(models.OperationTypeUpdate), details)
+	}
+
+	s.publishDocumentEvent(ctx, models.EventDocumentUpdated, document)
+	return nil
 }
 
 func (s *DocumentService) DeleteDocument(ctx context.Context, id string) error {
@@ -193,7 +284,265 @@ func (s *DocumentService) DeleteDocument(ctx context.Context, id string) error {
 		return errors.New("document not found")
 	}
 
-	return s.documentRepo.Delete(ctx, id)
+	if err := s.access.Authorize(ctx, document.ProjectID, models.PermissionEditDocuments); err != nil {
+		return err
+	}
+
+	if err := s.documentRepo.Delete(ctx, id); err != nil {
+		return err
+	}
+
+	// Логируем удаление документа
+	if s.logService != nil {
+		details := map[string]interface{}{
+			"document_id": document.ID,
+			"project_id":  document.ProjectID,
+			"type":        document.Type,
+			"title":       document.Title,
+		}
+		s.logService.LogEntityOperation(ctx, models.LogEntityDocument, document.ID, actorFromContext(ctx), string(models.OperationTypeDelete), details)
+	}
+
+	s.publishDocumentEvent(ctx, models.EventDocumentDeleted, document)
+	return nil
+}
+
+// GetDocumentRevisions возвращает историю ревизий документа без их содержимого
+func (s *DocumentService) GetDocumentRevisions(ctx context.Context, documentID string) ([]models.DocumentRevision, error) {
+	if _, err := s.getDocument(ctx, documentID); err != nil {
+		return nil, err
+	}
+	return s.revisionRepo.GetByDocumentID(ctx, documentID)
+}
+
+// GetDocumentRevision возвращает ревизию документа с содержимым или nil, если ее нет
+func (s *DocumentService) GetDocumentRevision(ctx context.Context, documentID string, number int) (*models.DocumentRevision, error) {
+	if _, err := s.getDocument(ctx, documentID); err != nil {
+		return nil, err
+	}
+	return s.revisionRepo.GetByRevision(ctx, documentID, number)
+}
+
+// DiffDocumentRevisions возвращает unified diff содержимого ревизий from и to;
+// to 0 означает текущую ревизию документа
+func (s *DocumentService) DiffDocumentRevisions(ctx context.Context, documentID string, from, to int) (*models.DocumentDiff, error) {
+	document, err := s.getDocument(ctx, documentID)
+	if err != nil {
+		return nil, err
+	}
+	if to == 0 {
+		to = document.Version
+	}
+
+	fromRevision, err := s.getRevision(ctx, documentID, from)
+	if err != nil {
+		return nil, err
+	}
+	toRevision, err := s.getRevision(ctx, documentID, to)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.DocumentDiff{
+		DocumentID: documentID,
+		From:       from,
+		To:         to,
+		Diff: utils.UnifiedDiff(
+			fmt.Sprintf("revision %d", from),
+			fmt.Sprintf("revision %d", to),
+			fromRevision.Content,
+			toRevision.Content,
+		),
+	}, nil
+}
+
+// RestoreDocumentRevision возвращает документу заголовок и содержимое старой ревизии.
+// История не переписывается: восстановление сохраняется как новая ревизия.
+// expectedVersion 0 означает восстановление поверх текущей версии.
+func (s *DocumentService) RestoreDocumentRevision(ctx context.Context, documentID string, number, expectedVersion int) (*models.Document, error) {
+	existingDocument, err := s.getDocument(ctx, documentID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.access.AuthorizeDocumentUpdate(ctx, existingDocument); err != nil {
+		return nil, err
+	}
+
+	revision, err := s.getRevision(ctx, documentID, number)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := checkVersion(&expectedVersion, existingDocument.Version); err != nil {
+		return nil, err
+	}
+
+	if err := checkRequiredSections(existingDocument.Type, existingDocument, revision.Content); err != nil {
+		return nil, err
+	}
+
+	document := *existingDocument
+	document.Title = revision.Title
+	document.Content = revision.Content
+	document.Version = expectedVersion
+
+	restored := &models.DocumentRevision{Author: actorFromContext(ctx), RestoredFrom: &number}
+	if err := s.documentRepo.Update(ctx, &document, restored); err != nil {
+		return nil, err
+	}
+
+	if s.logService != nil {
+		details := map[string]interface{}{
+			"document_id":   document.ID,
+			"title":         document.Title,
+			"restored_from": number,
+			"revision":      document.Version,
+			"changes":       models.DiffFields(existingDocument, &document),
+		}
+		s.logService.LogEntityOperation(ctx, models.LogEntityDocument, document.ID, actorFromContext(ctx), string(models.OperationTypeRestore), details)
+	}
+
+	s.publishDocumentEvent(ctx, models.EventDocumentUpdated, &document)
+	return &document, nil
+}
+
+// GetDocumentSections возвращает дерево разделов документа по его заголовкам
+func (s *DocumentService) GetDocumentSections(ctx context.Context, documentID string) ([]utils.MarkdownSection, error) {
+	document, err := s.getDocument(ctx, documentID)
+	if err != nil {
+		return nil, err
+	}
+	return utils.MarkdownSections(document.Content), nil
+}
+
+// GetDocumentSection возвращает раздел документа с содержимым или nil, если его нет
+func (s *DocumentService) GetDocumentSection(ctx context.Context, documentID, anchor string) (*utils.MarkdownSection, error) {
+	document, err := s.getDocument(ctx, documentID)
+	if err != nil {
+		return nil, err
+	}
+	return utils.FindMarkdownSection(document.Content, anchor), nil
+}
+
+// UpdateDocumentSection заменяет текст раздела документа, сохраняя остальное содержимое.
+// expectedVersion 0 означает замену поверх версии, прочитанной в этом запросе.
+func (s *DocumentService) UpdateDocumentSection(ctx context.Context, documentID, anchor, content string, expectedVersion int) (*models.Document, error) {
+	existingDocument, err := s.getDocument(ctx, documentID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Раздел вырезается из прочитанной версии, поэтому сохранять его можно
+	// только поверх нее же
+	if err := checkVersion(&expectedVersion, existingDocument.Version); err != nil {
+		return nil, err
+	}
+
+	updatedContent, err := utils.ReplaceMarkdownSection(existingDocument.Content, anchor, content)
+	if err != nil {
+		return nil, err
+	}
+
+	document := *existingDocument
+	document.Content = updatedContent
+	document.Version = expectedVersion
+	if err := s.UpdateDocument(ctx, &document); err != nil {
+		return nil, err
+	}
+	return &document, nil
+}
+
+// RenderDocumentHTML возвращает содержимое документа в виде безопасного HTML
+func (s *DocumentService) RenderDocumentHTML(ctx context.Context, documentID string) (string, error) {
+	document, err := s.getDocument(ctx, documentID)
+	if err != nil {
+		return "", err
+	}
+	return utils.RenderMarkdownHTML(document.Content), nil
+}
+
+// missingRequiredSections возвращает обязательные для типа документа разделы,
+// которых нет в содержимом
+func missingRequiredSections(docType, content string) []string {
+	anchors := make(map[string]bool)
+	var collect func(sections []utils.MarkdownSection)
+	collect = func(sections []utils.MarkdownSection) {
+		for _, section := range sections {
+			anchors[section.Anchor] = true
+			collect(section.Children)
+		}
+	}
+	collect(utils.MarkdownSections(content))
+
+	var missing []string
+	for _, title := range models.RequiredDocumentSections(docType) {
+		if !anchors[utils.MarkdownAnchor(title)] {
+			missing = append(missing, title)
+		}
+	}
+	return missing
+}
+
+// checkRequiredSections проверяет, что в содержимом документа типа docType
+// есть обязательные разделы. Для сохраненного документа того же типа
+// (previous) проверяются только разделы, которые в нем были: документы,
+// созданные до появления требований, можно править и без недостающих
+// разделов. Новый документ и смена типа требуют всех разделов.
+func checkRequiredSections(docType string, previous *models.Document, content string) error {
+	previouslyMissing := make(map[string]bool)
+	if previous != nil && previous.Type == docType {
+		for _, title := range missingRequiredSections(docType, previous.Content) {
+			previouslyMissing[title] = true
+		}
+	}
+
+	var missing []string
+	for _, title := range missingRequiredSections(docType, content) {
+		if !previouslyMissing[title] {
+			missing = append(missing, title)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("%w: %s", ErrRequiredSectionsMissing, strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// GetRequiredSections возвращает разделы, обязательные для типа документа
+func (s *DocumentService) GetRequiredSections(docType string) ([]string, error) {
+	if !models.IsValidDocumentType(docType) {
+		return nil, errors.New("invalid document type")
+	}
+	return models.RequiredDocumentSections(docType), nil
+}
+
+// getDocument возвращает существующий документ или ошибку, если его нет
+func (s *DocumentService) getDocument(ctx context.Context, id string) (*models.Document, error) {
+	if strings.TrimSpace(id) == "" {
+		return nil, errors.New("id is required")
+	}
+
+	document, err := s.documentRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if document == nil {
+		return nil, errors.New("document not found")
+	}
+	return document, nil
+}
+
+// getRevision возвращает существующую ревизию документа или ошибку, если ее нет
+func (s *DocumentService) getRevision(ctx context.Context, documentID string, number int) (*models.DocumentRevision, error) {
+	revision, err := s.revisionRepo.GetByRevision(ctx, documentID, number)
+	if err != nil {
+		return nil, err
+	}
+	if revision == nil {
+		return nil, fmt.Errorf("revision %d not found", number)
+	}
+	return revision, nil
 }
 
 // GetValidDocumentTypes возвращает список валидных типов документов
@@ -0,0 +1,45 @@
+package services
+
+import (
+	"strings"
+	"testing"
+
+	"project-manager/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// documentWithSections собирает содержимое с разделами titles
+func documentWithSections(titles []string) string {
+	var content strings.Builder
+	for _, title := range titles {
+		content.WriteString("## " + title + "\n\nТекст\n\n")
+	}
+	return content.String()
+}
+
+func TestCheckRequiredSections(t *testing.T) {
+	brd := string(models.DocumentTypeBRD)
+	required := models.RequiredDocumentSections(brd)
+	require.GreaterOrEqual(t, len(required), 2)
+
+	full := documentWithSections(required)
+	partial := documentWithSections(required[1:])
+
+	// Новый документ должен содержать все обязательные разделы
+	assert.NoError(t, checkRequiredSections(brd, nil, full))
+	assert.ErrorIs(t, checkRequiredSections(brd, nil, partial), ErrRequiredSectionsMissing)
+
+	// Старый документ без раздела можно править, но нельзя удалить имевшийся раздел
+	legacy := &models.Document{Type: brd, Content: partial}
+	assert.NoError(t, checkRequiredSections(brd, legacy, partial+"Дополнение"))
+	current := &models.Document{Type: brd, Content: full}
+	assert.ErrorIs(t, checkRequiredSections(brd, current, partial), ErrRequiredSectionsMissing)
+
+	// При смене типа учитываются все разделы нового типа
+	sad := string(models.DocumentTypeSAD)
+	require.NotEmpty(t, models.RequiredDocumentSections(sad))
+	assert.ErrorIs(t, checkRequiredSections(sad, current, full), ErrRequiredSectionsMissing)
+	assert.NoError(t, checkRequiredSections(sad, current, documentWithSections(models.RequiredDocumentSections(sad))))
+}
@@ -0,0 +1,227 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"project-manager/models"
+	"project-manager/repositories"
+)
+
+// DocumentTaskService создает задачи проекта по документам типа AI Executable
+type DocumentTaskService struct {
+	documentRepo *repositories.DocumentRepository
+	taskRepo     *repositories.TaskRepository
+	fbRepo       *repositories.FunctionalBlockRepository
+	linkRepo     *repositories.TaskDocumentLinkRepository
+	taskService  *TaskService
+	planService  *ProjectPlanService
+	access       *AccessService
+}
+
+func NewDocumentTaskService(documentRepo *repositories.DocumentRepository, taskRepo *repositories.TaskRepository, fbRepo *repositories.FunctionalBlockRepository, linkRepo *repositories.TaskDocumentLinkRepository, taskService *TaskService, planService *ProjectPlanService, access *AccessService) *DocumentTaskService {
+	return &DocumentTaskService{
+		documentRepo: documentRepo,
+		taskRepo:     taskRepo,
+		fbRepo:       fbRepo,
+		linkRepo:     linkRepo,
+		taskService:  taskService,
+		planService:  planService,
+		access:       access,
+	}
+}
+
+// PreviewTasks распознает задачи в документе, не создавая их. Черновики, по
+// которым задача уже создана, отмечаются ExistingTaskID.
+func (s *DocumentTaskService) PreviewTasks(ctx context.Context, documentID string) (*models.TaskDraftPreview, error) {
+	document, err := s.getExecutableDocument(ctx, documentID)
+	if err != nil {
+		return nil, err
+	}
+	return s.preview(ctx, document)
+}
+
+// GetTasksByDocument возвращает задачи, созданные из документа
+func (s *DocumentTaskService) GetTasksByDocument(ctx context.Context, documentID string) ([]models.Task, error) {
+	if strings.TrimSpace(documentID) == "" {
+		return nil, errors.New("id is required")
+	}
+	return s.taskRepo.GetBySourceDocumentID(ctx, documentID)
+}
+
+// CreateTasks создает задачи по выбранным черновикам и при необходимости
+// добавляет их в конец плана проекта. Черновики с ошибками и уже созданные
+// задачи пропускаются; уже созданная задача, которой нет в плане, добавляется
+// в него при повторном запросе. Ошибки отдельных черновиков не прерывают
+// обработку остальных: если задача создана, но не связана с документом или не
+// добавлена в план, она возвращается в Partial вместе с ошибками.
+func (s *DocumentTaskService) CreateTasks(ctx context.Context, documentID string, req *models.CreateTasksFromDocumentRequest) (*models.CreateTasksFromDocumentResult, error) {
+	document, err := s.getExecutableDocument(ctx, documentID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.access.Authorize(ctx, document.ProjectID, models.PermissionEditTasks); err != nil {
+		return nil, err
+	}
+
+	preview, err := s.preview(ctx, document)
+	if err != nil {
+		return nil, err
+	}
+
+	selected := preview.Drafts
+	if len(req.Indexes) > 0 {
+		selected = make([]models.TaskDraft, 0, len(req.Indexes))
+		for _, index := range req.Indexes {
+			if index < 0 || index >= len(preview.Drafts) {
+				return nil, fmt.Errorf("invalid draft index: %d", index)
+			}
+			selected = append(selected, preview.Drafts[index])
+		}
+	}
+
+	result := &models.CreateTasksFromDocumentResult{
+		Created: []models.Task{},
+		Partial: []models.PartiallyCreatedTask{},
+		Skipped: []models.TaskDraft{},
+	}
+	for _, draft := range selected {
+		if draft.ExistingTaskID != nil {
+			if req.AddToPlan {
+				if err := s.ensureInPlan(ctx, document.ProjectID, *draft.ExistingTaskID); err != nil {
+					draft.Errors = append(draft.Errors, "failed to add task to plan: "+err.Error())
+				}
+			}
+			result.Skipped = append(result.Skipped, draft)
+			continue
+		}
+		if len(draft.Errors) > 0 {
+			result.Skipped = append(result.Skipped, draft)
+			continue
+		}
+
+		anchor := draft.SectionAnchor
+		task := models.Task{
+			ProjectID:           document.ProjectID,
+			FunctionalBlockID:   draft.FunctionalBlockID,
+			Title:               draft.Title,
+			Description:         draft.Description,
+			Priority:            draft.Priority,
+			Type:                draft.Type,
+			SourceDocumentID:    &document.ID,
+			SourceSectionAnchor: &anchor,
+		}
+		if err := s.taskService.CreateTask(ctx, &task); err != nil {
+			draft.Errors = append(draft.Errors, err.Error())
+			result.Skipped = append(result.Skipped, draft)
+			continue
+		}
+
+		link := models.TaskDocumentLink{
+			TaskID:        task.ID,
+			DocumentID:    document.ID,
+			SectionAnchor: anchor,
+			CreatedBy:     actorFromContext(ctx),
+		}
+		if err := s.linkRepo.Create(ctx, &link); err != nil {
+			draft.Errors = append(draft.Errors, "failed to link task to document: "+err.Error())
+		}
+		if req.AddToPlan {
+			if err := s.planService.AddTaskToPlan(ctx, document.ProjectID, task.ID); err != nil {
+				draft.Errors = append(draft.Errors, "failed to add task to plan: "+err.Error())
+			}
+		}
+
+		if len(draft.Errors) > 0 {
+			result.Partial = append(result.Partial, models.PartiallyCreatedTask{
+				Index:  draft.Index,
+				Task:   task,
+				Errors: draft.Errors,
+			})
+			continue
+		}
+		result.Created = append(result.Created, task)
+	}
+
+	return result, nil
+}
+
+// ensureInPlan добавляет задачу в план проекта, если ее там еще нет
+func (s *DocumentTaskService) ensureInPlan(ctx context.Context, projectID, taskID string) error {
+	inPlan, err := s.planService.IsTaskInPlan(ctx, projectID, taskID)
+	if err != nil || inPlan {
+		return err
+	}
+	return s.planService.AddTaskToPlan(ctx, projectID, taskID)
+}
+
+// preview разбирает документ и дополняет черновики функциональными блоками
+// и ссылками на уже созданные задачи
+func (s *DocumentTaskService) preview(ctx context.Context, document *models.Document) (*models.TaskDraftPreview, error) {
+	drafts := parseTaskDrafts(document.Content)
+
+	existing, err := s.taskRepo.GetBySourceDocumentID(ctx, document.ID)
+	if err != nil {
+		return nil, err
+	}
+	created := make(map[string]string, len(existing))
+	for _, task := range existing {
+		if task.SourceSectionAnchor != nil {
+			created[*task.SourceSectionAnchor+"\x00"+task.Title] = task.ID
+		}
+	}
+
+	blocks := make(map[string]*models.FunctionalBlock)
+	for i := range drafts {
+		draft := &drafts[i]
+
+		if taskID, ok := created[draft.SectionAnchor+"\x00"+draft.Title]; ok {
+			draft.ExistingTaskID = &taskID
+		}
+
+		prefix := draft.FunctionalBlockPrefix
+		if prefix == "" || prefix == models.DefaultTaskNumberPrefix {
+			continue
+		}
+		block, ok := blocks[prefix]
+		if !ok {
+			if block, err = s.fbRepo.GetByPrefix(ctx, prefix); err != nil {
+				return nil, err
+			}
+			blocks[prefix] = block
+		}
+		if block == nil {
+			draft.Errors = append(draft.Errors, "functional block not found for prefix: "+prefix)
+			continue
+		}
+		draft.FunctionalBlockID = &block.ID
+	}
+
+	return &models.TaskDraftPreview{
+		DocumentID: document.ID,
+		ProjectID:  document.ProjectID,
+		Drafts:     drafts,
+	}, nil
+}
+
+// getExecutableDocument возвращает документ, из которого можно создавать задачи
+func (s *DocumentTaskService) getExecutableDocument(ctx context.Context, id string) (*models.Document, error) {
+	if strings.TrimSpace(id) == "" {
+		return nil, errors.New("id is required")
+	}
+
+	document, err := s.documentRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if document == nil {
+		return nil, errors.New("document not found")
+	}
+	if document.Type != string(models.DocumentTypeAIExecutable) {
+		return nil, fmt.Errorf("tasks can only be generated from %s documents", models.DocumentTypeAIExecutable)
+	}
+	return document, nil
+}
@@ -0,0 +1,206 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"project-manager/models"
+	"project-manager/repositories"
+)
+
+type DocumentTemplateService struct {
+	templateRepo    *repositories.DocumentTemplateRepository
+	projectRepo     *repositories.ProjectRepository
+	documentService *DocumentService
+	access          *AccessService
+}
+
+func NewDocumentTemplateService(templateRepo *repositories.DocumentTemplateRepository, projectRepo *repositories.ProjectRepository, documentService *DocumentService, access *AccessService) *DocumentTemplateService {
+	return &DocumentTemplateService{
+		templateRepo:    templateRepo,
+		projectRepo:     projectRepo,
+		documentService: documentService,
+		access:          access,
+	}
+}
+
+// GetTemplates возвращает встроенные и пользовательские шаблоны; пустой docType — все типы
+func (s *DocumentTemplateService) GetTemplates(ctx context.Context, docType string) ([]models.DocumentTemplate, error) {
+	if docType != "" && !models.IsValidDocumentType(docType) {
+		return nil, errors.New("invalid document type")
+	}
+
+	templates := []models.DocumentTemplate{}
+	for _, template := range models.BuiltinDocumentTemplates() {
+		if docType == "" || template.Type == docType {
+			templates = append(templates, template)
+		}
+	}
+
+	custom, err := s.templateRepo.GetAll(ctx, docType)
+	if err != nil {
+		return nil, err
+	}
+	return append(templates, custom...), nil
+}
+
+// GetTemplateByID возвращает шаблон по идентификатору или nil, если его нет
+func (s *DocumentTemplateService) GetTemplateByID(ctx context.Context, id string) (*models.DocumentTemplate, error) {
+	if strings.TrimSpace(id) == "" {
+		return nil, errors.New("id is required")
+	}
+	if strings.HasPrefix(id, models.BuiltinTemplatePrefix) {
+		for _, template := range models.BuiltinDocumentTemplates() {
+			if template.ID == id {
+				return &template, nil
+			}
+		}
+		return nil, nil
+	}
+	return s.templateRepo.GetByID(ctx, id)
+}
+
+func (s *DocumentTemplateService) CreateTemplate(ctx context.Context, template *models.DocumentTemplate) error {
+	if err := s.validateTemplate(ctx, template); err != nil {
+		return err
+	}
+
+	// Шаблоны общие для всех проектов
+	if err := s.access.AuthorizeGlobal(ctx); err != nil {
+		return err
+	}
+
+	template.CreatedBy = actorFromContext(ctx)
+	template.IsBuiltin = false
+	return s.templateRepo.Create(ctx, template)
+}
+
+func (s *DocumentTemplateService) UpdateTemplate(ctx context.Context, template *models.DocumentTemplate) error {
+	existing, err := s.getCustomTemplate(ctx, template.ID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.validateTemplate(ctx, template); err != nil {
+		return err
+	}
+
+	if err := s.access.AuthorizeGlobal(ctx); err != nil {
+		return err
+	}
+
+	// Сохраняем неизменяемые поля
+	template.CreatedBy = existing.CreatedBy
+	template.CreatedAt = existing.CreatedAt
+	return s.templateRepo.Update(ctx, template)
+}
+
+func (s *DocumentTemplateService) DeleteTemplate(ctx context.Context, id string) error {
+	if _, err := s.getCustomTemplate(ctx, id); err != nil {
+		return err
+	}
+
+	if err := s.access.AuthorizeGlobal(ctx); err != nil {
+		return err
+	}
+
+	return s.templateRepo.Delete(ctx, id)
+}
+
+// CreateDocumentFromTemplate создает документ проекта из шаблона, подставляя
+// в него данные проекта, автора и дату
+func (s *DocumentTemplateService) CreateDocumentFromTemplate(ctx context.Context, projectID string, req *models.DocumentFromTemplate) (*models.Document, error) {
+	if strings.TrimSpace(req.TemplateID) == "" {
+		return nil, errors.New("templateId is required")
+	}
+
+	project, err := s.projectRepo.GetByID(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+	if project == nil {
+		return nil, errors.New("project not found")
+	}
+
+	template, err := s.GetTemplateByID(ctx, req.TemplateID)
+	if err != nil {
+		return nil, err
+	}
+	if template == nil {
+		return nil, errors.New("template not found")
+	}
+
+	variables := map[string]string{
+		models.TemplateVarProjectName:        project.Name,
+		models.TemplateVarProjectDescription: project.Description,
+		models.TemplateVarProjectStatus:      project.Status,
+		models.TemplateVarDate:               time.Now().Format("2006-01-02"),
+		models.TemplateVarAuthor:             actorFromContext(ctx),
+	}
+	for name, value := range req.Variables {
+		variables[name] = value
+	}
+
+	title := strings.TrimSpace(req.Title)
+	if title == "" {
+		title = fmt.Sprintf("%s: %s", project.Name, template.Name)
+	}
+
+	document := &models.Document{
+		ProjectID:     project.ID,
+		Type:          template.Type,
+		Title:         title,
+		Content:       models.FillTemplate(template.Content, variables),
+		AgentEditable: req.AgentEditable,
+	}
+	if err := s.documentService.CreateDocument(ctx, document); err != nil {
+		return nil, err
+	}
+	return document, nil
+}
+
+// validateTemplate проверяет поля шаблона, наличие обязательных разделов
+// и уникальность имени в пределах типа документа
+func (s *DocumentTemplateService) validateTemplate(ctx context.Context, template *models.DocumentTemplate) error {
+	if strings.TrimSpace(template.Name) == "" {
+		return errors.New("name is required")
+	}
+	if strings.TrimSpace(template.Content) == "" {
+		return errors.New("content is required")
+	}
+	if !models.IsValidDocumentType(template.Type) {
+		return errors.New("invalid document type")
+	}
+
+	if missing := missingRequiredSections(template.Type, template.Content); len(missing) > 0 {
+		return fmt.Errorf("%w: %s", ErrRequiredSectionsMissing, strings.Join(missing, ", "))
+	}
+
+	exists, err := s.templateRepo.ExistsByName(ctx, template.Type, template.Name, template.ID)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return errors.New("template with this name already exists for the document type")
+	}
+	return nil
+}
+
+// getCustomTemplate возвращает пользовательский шаблон; встроенные изменять нельзя
+func (s *DocumentTemplateService) getCustomTemplate(ctx context.Context, id string) (*models.DocumentTemplate, error) {
+	if strings.HasPrefix(id, models.BuiltinTemplatePrefix) {
+		return nil, errors.New("built-in templates cannot be modified")
+	}
+
+	template, err := s.GetTemplateByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if template == nil {
+		return nil, errors.New("template not found")
+	}
+	return template, nil
+}
@@ -0,0 +1,168 @@
+package services
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"project-manager/models"
+)
+
+const (
+	// DefaultEventHistorySize — сколько последних событий хранится для возобновления потока
+	DefaultEventHistorySize = 1000
+	// eventSubscriberBuffer — размер очереди подписчика; подписчик, не успевающий
+	// ее разбирать, отключается и должен переподключиться с Last-Event-ID
+	eventSubscriberBuffer = 64
+)
+
+// recordedEvent — событие истории с порядковым номером
+type recordedEvent struct {
+	seq   uint64
+	event models.Event
+}
+
+// EventBroker рассылает события об изменениях подписчикам и хранит
+// ограниченную историю в памяти. Идентификатор события состоит из эпохи
+// экземпляра брокера и порядкового номера, поэтому после перезапуска сервера
+// старый Last-Event-ID распознается как устаревший.
+type EventBroker struct {
+	mu          sync.Mutex
+	epoch       string
+	seq         uint64
+	historySize int
+	history     []recordedEvent
+	subscribers map[*EventSubscription]struct{}
+}
+
+func NewEventBroker(historySize int) *EventBroker {
+	if historySize <= 0 {
+		historySize = DefaultEventHistorySize
+	}
+	return &EventBroker{
+		epoch:       strconv.FormatInt(time.Now().UnixNano(), 36),
+		historySize: historySize,
+		subscribers: make(map[*EventSubscription]struct{}),
+	}
+}
+
+// EventSubscription — подписка на поток событий
+type EventSubscription struct {
+	broker *EventBroker
+	filter models.EventFilter
+	events chan models.Event
+}
+
+// Events возвращает канал событий подписки. Канал закрывается, если подписчик
+// не успевает получать события или подписка закрыта.
+func (s *EventSubscription) Events() <-chan models.Event {
+	return s.events
+}
+
+// Close отменяет подписку
+func (s *EventSubscription) Close() {
+	s.broker.mu.Lock()
+	defer s.broker.mu.Unlock()
+	s.broker.unsubscribe(s)
+}
+
+// Publish присваивает событию идентификатор и рассылает его подписчикам.
+// Автором события по умолчанию становится пользователь из контекста.
+// Вызов на nil-брокере ничего не делает.
+func (b *EventBroker) Publish(ctx context.Context, event models.Event) {
+	if b == nil {
+		return
+	}
+	if event.Actor == "" {
+		event.Actor = actorFromContext(ctx)
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.seq++
+	event.ID = b.eventID(b.seq)
+	event.CreatedAt = time.Now().UTC()
+
+	if len(b.history) == b.historySize {
+		copy(b.history, b.history[1:])
+		b.history = b.history[:len(b.history)-1]
+	}
+	b.history = append(b.history, recordedEvent{seq: b.seq, event: event})
+
+	for subscription := range b.subscribers {
+		if !subscription.filter.Matches(&event) {
+			continue
+		}
+		select {
+		case subscription.events <- event:
+		default:
+			b.unsubscribe(subscription)
+		}
+	}
+}
+
+// Subscribe создает подписку и возвращает пропущенные после lastEventID
+// события. Если часть событий уже вытеснена из истории или lastEventID выдан
+// другим экземпляром брокера, первым возвращается событие reset.
+func (b *EventBroker) Subscribe(filter models.EventFilter, lastEventID string) (*EventSubscription, []models.Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	subscription := &EventSubscription{
+		broker: b,
+		filter: filter,
+		events: make(chan models.Event, eventSubscriberBuffer),
+	}
+	b.subscribers[subscription] = struct{}{}
+
+	lastEventID = strings.TrimSpace(lastEventID)
+	if lastEventID == "" {
+		return subscription, nil
+	}
+
+	missed := []models.Event{}
+	after, ok := b.parseEventID(lastEventID)
+	if !ok || (len(b.history) > 0 && after+1 < b.history[0].seq) {
+		missed = append(missed, models.Event{
+			ID:        b.eventID(b.seq),
+			Type:      models.EventReset,
+			CreatedAt: time.Now().UTC(),
+		})
+		after = 0
+	}
+	for _, recorded := range b.history {
+		if recorded.seq > after && filter.Matches(&recorded.event) {
+			missed = append(missed, recorded.event)
+		}
+	}
+	return subscription, missed
+}
+
+// unsubscribe удаляет подписку и закрывает ее канал; вызывается под b.mu
+func (b *EventBroker) unsubscribe(subscription *EventSubscription) {
+	if _, ok := b.subscribers[subscription]; !ok {
+		return
+	}
+	delete(b.subscribers, subscription)
+	close(subscription.events)
+}
+
+func (b *EventBroker) eventID(seq uint64) string {
+	return b.epoch + "-" + strconv.FormatUint(seq, 10)
+}
+
+// parseEventID возвращает порядковый номер события этого брокера
+func (b *EventBroker) parseEventID(id string) (uint64, bool) {
+	epoch, rawSeq, found := strings.Cut(id, "-")
+	if !found || epoch != b.epoch {
+		return 0, false
+	}
+	seq, err := strconv.ParseUint(rawSeq, 10, 64)
+	if err != nil || seq > b.seq {
+		return 0, false
+	}
+	return seq, true
+}
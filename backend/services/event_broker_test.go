@@ -0,0 +1,74 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"project-manager/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func publishTestEvents(broker *EventBroker, projectID string, count int) {
+	for i := 0; i < count; i++ {
+		broker.Publish(context.Background(), models.Event{Type: models.EventTaskUpdated, ProjectID: projectID})
+	}
+}
+
+func TestEventBroker_DeliversFilteredEvents(t *testing.T) {
+	broker := NewEventBroker(10)
+	subscription, missed := broker.Subscribe(models.EventFilter{ProjectID: "p1"}, "")
+	defer subscription.Close()
+	assert.Empty(t, missed)
+
+	publishTestEvents(broker, "p2", 1)
+	publishTestEvents(broker, "p1", 1)
+
+	event := <-subscription.Events()
+	assert.Equal(t, "p1", event.ProjectID)
+	assert.Equal(t, broker.eventID(2), event.ID)
+	assert.Empty(t, subscription.Events())
+}
+
+func TestEventBroker_ResumesAfterLastEventID(t *testing.T) {
+	broker := NewEventBroker(10)
+	publishTestEvents(broker, "p1", 3)
+
+	subscription, missed := broker.Subscribe(models.EventFilter{}, broker.eventID(1))
+	defer subscription.Close()
+
+	require.Len(t, missed, 2)
+	assert.Equal(t, broker.eventID(2), missed[0].ID)
+	assert.Equal(t, broker.eventID(3), missed[1].ID)
+}
+
+func TestEventBroker_ResetWhenHistoryIsLost(t *testing.T) {
+	broker := NewEventBroker(2)
+	publishTestEvents(broker, "p1", 5)
+
+	_, missed := broker.Subscribe(models.EventFilter{}, broker.eventID(1))
+	require.Len(t, missed, 3)
+	assert.Equal(t, models.EventReset, missed[0].Type)
+	assert.Equal(t, broker.eventID(5), missed[0].ID)
+	assert.Equal(t, broker.eventID(4), missed[1].ID)
+
+	// Идентификатор другого экземпляра сервера
+	_, missed = broker.Subscribe(models.EventFilter{}, "other-1")
+	require.NotEmpty(t, missed)
+	assert.Equal(t, models.EventReset, missed[0].Type)
+}
+
+func TestEventBroker_DropsSlowSubscriber(t *testing.T) {
+	broker := NewEventBroker(10)
+	subscription, _ := broker.Subscribe(models.EventFilter{}, "")
+
+	publishTestEvents(broker, "p1", eventSubscriberBuffer+1)
+
+	received := 0
+	for range subscription.Events() {
+		received++
+	}
+	assert.Equal(t, eventSubscriberBuffer, received)
+	subscription.Close()
+}
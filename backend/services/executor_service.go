@@ -2,22 +2,311 @@ package services
 
 import (
 	"context"
+	"errors"
+	"strings"
+
 	"project-manager/models"
 	"project-manager/repositories"
 )
 
+// ErrExecutorNotFound возвращается, если исполнителя нет
+var ErrExecutorNotFound = errors.New("executor not found")
+
 type ExecutorService struct {
-	repo *repositories.ExecutorRepository
+	repo          *repositories.ExecutorRepository
+	userRepo      *repositories.UserRepository
+	logService    *OperationLogService
+	notifications *NotificationService
+	access        *AccessService
+	workflows     *WorkflowService
+	events        *EventBroker
 }
 
-func NewExecutorService(repo *repositories.ExecutorRepository) *ExecutorService {
-	return &ExecutorService{repo: repo}
+func NewExecutorService(repo *repositories.ExecutorRepository, userRepo *repositories.UserRepository, logService *OperationLogService, notifications *NotificationService, access *AccessService, workflows *WorkflowService, events *EventBroker) *ExecutorService {
+	return &ExecutorService{
+		repo:          repo,
+		userRepo:      userRepo,
+		logService:    logService,
+		notifications: notifications,
+		access:        access,
+		workflows:     workflows,
+		events:        events,
+	}
 }
 
 func (s *ExecutorService) GetAllExecutors(ctx context.Context) ([]models.Executor, error) {
 	return s.repo.GetAll(ctx)
 }
 
-func (s *ExecutorService) CreateExecutor(ctx context.Context, name string) (*models.Executor, error) {
-	return s.repo.Create(ctx, name)
+func (s *ExecutorService) GetExecutorByID(ctx context.Context, id string) (*models.Executor, error) {
+	if strings.TrimSpace(id) == "" {
+		return nil, errors.New("id is required")
+	}
+	return s.repo.GetByID(ctx, id)
+}
+
+func (s *ExecutorService) CreateExecutor(ctx context.Context, executor *models.Executor) error {
+	if err := s.validateExecutor(ctx, executor); err != nil {
+		return err
+	}
+
+	if err := s.access.AuthorizeGlobal(ctx); err != nil {
+		return err
+	}
+
+	if err := s.repo.Create(ctx, executor); err != nil {
+		return err
+	}
+
+	// Логируем создание исполнителя
+	if s.logService != nil {
+		details := map[string]interface{}{
+			"executor_id": executor.ID,
+			"name":        executor.Name,
+			"kind":        executor.Kind,
+		}
+		s.logService.LogEntityOperation(ctx, models.LogEntityExecutor, executor.ID, actorFromContext(ctx), string(models.OperationTypeCreate), details)
+	}
+
+	return nil
+}
+
+func (s *ExecutorService) UpdateExecutor(ctx context.Context, executor *models.Executor) error {
+	if strings.TrimSpace(executor.ID) == "" {
+		return errors.New("id is required")
+	}
+	if err := s.validateExecutor(ctx, executor); err != nil {
+		return err
+	}
+
+	if err := s.access.AuthorizeGlobal(ctx); err != nil {
+		return err
+	}
+
+	previous, err := s.repo.GetByID(ctx, executor.ID)
+	if err != nil {
+		return err
+	}
+	if previous == nil {
+		return ErrExecutorNotFound
+	}
+
+	if err := s.repo.Update(ctx, executor); err != nil {
+		return err
+	}
+
+	// Логируем изменения полей исполнителя
+	if s.logService != nil {
+		details := map[string]interface{}{
+			"executor_id": executor.ID,
+			"name":        executor.Name,
+			"changes":     models.DiffFields(previous, executor),
+		}
+		s.logService.LogEntityOperation(ctx, models.LogEntityExecutor, executor.ID, actorFromContext(ctx), string(models.OperationTypeUpdate), details)
+	}
+
+	return nil
+}
+
+// DeleteExecutor удаляет исполнителя. Его открытые задачи передаются
+// исполнителю reassignTo, а при пустом значении остаются без исполнителя.
+func (s *ExecutorService) DeleteExecutor(ctx context.Context, id, reassignTo string) ([]models.Task, error) {
+	if strings.TrimSpace(id) == "" {
+		return nil, errors.New("id is required")
+	}
+
+	if err := s.access.AuthorizeGlobal(ctx); err != nil {
+		return nil, err
+	}
+
+	executor, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if executor == nil {
+		return nil, ErrExecutorNotFound
+	}
+	target, err := s.reassignTarget(ctx, id, reassignTo)
+	if err != nil {
+		return nil, err
+	}
+
+	openTaskIDs, err := s.openTaskIDs(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	tasks, err := s.repo.Delete(ctx, id, openTaskIDs, executorIDOf(target))
+	if err != nil {
+		return nil, err
+	}
+	s.afterReassign(ctx, executor, target, tasks)
+
+	// Логируем удаление исполнителя
+	if s.logService != nil {
+		details := map[string]interface{}{
+			"executor_id":      executor.ID,
+			"name":             executor.Name,
+			"reassigned_to":    executorIDOf(target),
+			"reassigned_tasks": len(tasks),
+		}
+		s.logService.LogEntityOperation(ctx, models.LogEntityExecutor, executor.ID, actorFromContext(ctx), string(models.OperationTypeDelete), details)
+	}
+
+	return tasks, nil
+}
+
+// ReassignExecutorTasks передает открытые задачи исполнителя исполнителю
+// reassignTo; пустое значение снимает назначение
+func (s *ExecutorService) ReassignExecutorTasks(ctx context.Context, id, reassignTo string) ([]models.Task, error) {
+	if strings.TrimSpace(id) == "" {
+		return nil, errors.New("id is required")
+	}
+
+	if err := s.access.AuthorizeGlobal(ctx); err != nil {
+		return nil, err
+	}
+
+	executor, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if executor == nil {
+		return nil, ErrExecutorNotFound
+	}
+	target, err := s.reassignTarget(ctx, id, reassignTo)
+	if err != nil {
+		return nil, err
+	}
+
+	openTaskIDs, err := s.openTaskIDs(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	tasks, err := s.repo.ReassignTasks(ctx, id, openTaskIDs, executorIDOf(target))
+	if err != nil {
+		return nil, err
+	}
+	s.afterReassign(ctx, executor, target, tasks)
+	return tasks, nil
+}
+
+// openTaskIDs возвращает задачи исполнителя, не находящиеся в конечных
+// статусах процесса своего проекта
+func (s *ExecutorService) openTaskIDs(ctx context.Context, executorID string) ([]string, error) {
+	tasks, err := s.repo.GetAssignedTasks(ctx, executorID)
+	if err != nil {
+		return nil, err
+	}
+
+	workflows := make(map[string]*models.Workflow)
+	ids := []string{}
+	for _, task := range tasks {
+		workflow, ok := workflows[task.ProjectID]
+		if !ok {
+			if workflow, err = s.workflows.GetWorkflow(ctx, task.ProjectID); err != nil {
+				return nil, err
+			}
+			workflows[task.ProjectID] = workflow
+		}
+		if !workflow.IsTerminal(task.Status) {
+			ids = append(ids, task.ID)
+		}
+	}
+	return ids, nil
+}
+
+// reassignTarget проверяет исполнителя, которому передаются задачи
+func (s *ExecutorService) reassignTarget(ctx context.Context, id, reassignTo string) (*models.Executor, error) {
+	reassignTo = strings.TrimSpace(reassignTo)
+	if reassignTo == "" {
+		return nil, nil
+	}
+	if reassignTo == id {
+		return nil, errors.New("cannot reassign tasks to the same executor")
+	}
+
+	target, err := s.repo.GetByID(ctx, reassignTo)
+	if err != nil {
+		return nil, err
+	}
+	if target == nil {
+		return nil, errors.New("reassign_to refers to an unknown executor")
+	}
+	return target, nil
+}
+
+// afterReassign журналирует смену исполнителя каждой переданной задачи,
+// сообщает подписчикам и уведомляет нового исполнителя
+func (s *ExecutorService) afterReassign(ctx context.Context, from, to *models.Executor, tasks []models.Task) {
+	for i := range tasks {
+		task := &tasks[i]
+		if s.logService != nil {
+			details := map[string]interface{}{
+				"task_id":         task.ID,
+				"title":           task.Title,
+				"old_assignee_id": from.ID,
+				"new_assignee_id": task.AssigneeID,
+				"changes": []models.FieldChange{
+					{Field: "assigneeId", Before: from.ID, After: task.AssigneeID},
+				},
+			}
+			s.logService.LogTaskOperation(ctx, task.ID, actorFromContext(ctx), string(models.OperationTypeUpdate), details)
+		}
+
+		s.events.Publish(ctx, models.Event{
+			Type:      models.EventTaskUpdated,
+			ProjectID: task.ProjectID,
+			EntityID:  task.ID,
+			Data:      task,
+		})
+		s.notifications.NotifyAssignment(ctx, task, to)
+	}
+}
+
+// validateExecutor проверяет поля исполнителя и связанную учетную запись
+func (s *ExecutorService) validateExecutor(ctx context.Context, executor *models.Executor) error {
+	executor.Name = strings.TrimSpace(executor.Name)
+	if executor.Name == "" {
+		return errors.New("name is required")
+	}
+
+	if executor.Kind == "" {
+		executor.Kind = string(models.ExecutorKindHuman)
+	}
+	if !models.IsValidExecutorKind(executor.Kind) {
+		return errors.New("invalid executor kind")
+	}
+
+	if executor.UserID == nil || *executor.UserID == "" {
+		executor.UserID = nil
+		return nil
+	}
+
+	user, err := s.userRepo.GetByID(ctx, *executor.UserID)
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		return errors.New("user_id refers to an unknown user")
+	}
+
+	// Учетная запись может быть связана только с одним исполнителем
+	linked, err := s.repo.GetByUserID(ctx, *executor.UserID)
+	if err != nil {
+		return err
+	}
+	if linked != nil && linked.ID != executor.ID {
+		return errors.New("user is already linked to another executor")
+	}
+	return nil
+}
+
+// executorIDOf возвращает идентификатор исполнителя или nil
+func executorIDOf(executor *models.Executor) *string {
+	if executor == nil {
+		return nil
+	}
+	return &executor.ID
 }
@@ -13,11 +13,13 @@ import (
 var prefixRegex = regexp.MustCompile(`^[A-Z]{1,6}$`)
 
 type FunctionalBlockService struct {
-	repo *repositories.FunctionalBlockRepository
+	repo       *repositories.FunctionalBlockRepository
+	logService *OperationLogService
+	access     *AccessService
 }
 
-func NewFunctionalBlockService(repo *repositories.FunctionalBlockRepository) *FunctionalBlockService {
-	return &FunctionalBlockService{repo: repo}
+func NewFunctionalBlockService(repo *repositories.FunctionalBlockRepository, logService *OperationLogService, access *AccessService) *FunctionalBlockService {
+	return &FunctionalBlockService{repo: repo, logService: logService, access: access}
 }
 
 func (s *FunctionalBlockService) CreateFunctionalBlock(ctx context.Context, fb *models.FunctionalBlock) error {
@@ -31,6 +33,16 @@ func (s *FunctionalBlockService) CreateFunctionalBlock(ctx context.Context, fb *
 		return errors.New("prefix must be 1-6 uppercase Latin letters")
 	}
 
+	// Префикс по умолчанию зарезервирован для задач без функционального блока
+	if fb.Prefix == models.DefaultTaskNumberPrefix {
+		return errors.New("prefix " + models.DefaultTaskNumberPrefix + " is reserved")
+	}
+
+	// Функциональные блоки общие для всех проектов
+	if err := s.access.AuthorizeGlobal(ctx); err != nil {
+		return err
+	}
+
 	// Проверка уникальности префикса
 	existing, err := s.repo.GetByPrefix(ctx, fb.Prefix)
 	if err != nil {
@@ -40,7 +52,21 @@ func (s *FunctionalBlockService) CreateFunctionalBlock(ctx context.Context, fb *
 		return errors.New("prefix already exists")
 	}
 
-	return s.repo.Create(ctx, fb)
+	if err := s.repo.Create(ctx, fb); err != nil {
+		return err
+	}
+
+	// Логируем создание функционального блока
+	if s.logService != nil {
+		details := map[string]interface{}{
+			"functional_block_id": fb.ID,
+			"name":                fb.Name,
+			"prefix":              fb.Prefix,
+		}
+		s.logService.LogEntityOperation(ctx, models.LogEntityFunctionalBlock, fb.ID, actorFromContext(ctx), string(models.OperationTypeCreate), details)
+	}
+
+	return nil
 }
 
 func (s *FunctionalBlockService) GetFunctionalBlockByID(ctx context.Context, id string) (*models.FunctionalBlock, error) {
@@ -65,6 +91,16 @@ func (s *FunctionalBlockService) UpdateFunctionalBlock(ctx context.Context, fb *
 		return errors.New("prefix must be 1-6 uppercase Latin letters")
 	}
 
+	// Префикс по умолчанию зарезервирован для задач без функционального блока
+	if fb.Prefix == models.DefaultTaskNumberPrefix {
+		return errors.New("prefix " + models.DefaultTaskNumberPrefix + " is reserved")
+	}
+
+	// Функциональные блоки общие для всех проектов
+	if err := s.access.AuthorizeGlobal(ctx); err != nil {
+		return err
+	}
+
 	// Проверка уникальности префикса (исключая текущий блок)
 	existing, err := s.repo.GetByPrefix(ctx, fb.Prefix)
 	if err != nil {
@@ -74,12 +110,55 @@ func (s *FunctionalBlockService) UpdateFunctionalBlock(ctx context.Context, fb *
 		return errors.New("prefix already exists")
 	}
 
-	return s.repo.Update(ctx, fb)
+	previous, err := s.repo.GetByID(ctx, fb.ID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.repo.Update(ctx, fb); err != nil {
+		return err
+	}
+
+	// Логируем изменения полей функционального блока
+	if s.logService != nil && previous != nil {
+		details := map[string]interface{}{
+			"functional_block_id": fb.ID,
+			"name":                fb.Name,
+			"changes":             models.DiffFields(previous, fb),
+		}
+		s.logService.LogEntityOperation(ctx, models.LogEntityFunctionalBlock, fb.ID, actorFromContext(ctx), string(models.OperationTypeUpdate), details)
+	}
+
+	return nil
 }
 
 func (s *FunctionalBlockService) DeleteFunctionalBlock(ctx context.Context, id string) error {
 	if strings.TrimSpace(id) == "" {
 		return errors.New("id is required")
 	}
-	return s.repo.Delete(ctx, id)
+
+	if err := s.access.AuthorizeGlobal(ctx); err != nil {
+		return err
+	}
+
+	fb, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if err := s.repo.Delete(ctx, id); err != nil {
+		return err
+	}
+
+	// Логируем удаление функционального блока
+	if s.logService != nil && fb != nil {
+		details := map[string]interface{}{
+			"functional_block_id": fb.ID,
+			"name":                fb.Name,
+			"prefix":              fb.Prefix,
+		}
+		s.logService.LogEntityOperation(ctx, models.LogEntityFunctionalBlock, fb.ID, actorFromContext(ctx), string(models.OperationTypeDelete), details)
+	}
+
+	return nil
 }
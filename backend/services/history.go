@@ -0,0 +1,48 @@
+package services
+
+import (
+	"encoding/json"
+
+	"project-manager/models"
+)
+
+// historyDetails — поля details, из которых строится история изменений
+type historyDetails struct {
+	Changes   []models.FieldChange `json:"changes"`
+	OldStatus *string              `json:"old_status"`
+	NewStatus *string              `json:"new_status"`
+}
+
+// buildHistory превращает логи обновлений в хронологию изменений полей.
+// Логи, записанные до появления details.changes, дают только смену статуса;
+// отдельные логи STATUS_CHANGE дублируют обновление и пропускаются.
+func buildHistory(logs []models.OperationLog) []models.HistoryEntry {
+	history := []models.HistoryEntry{}
+	for _, log := range logs {
+		if log.OperationType != string(models.OperationTypeUpdate) {
+			continue
+		}
+
+		var details historyDetails
+		if err := json.Unmarshal(log.Details, &details); err != nil {
+			continue
+		}
+
+		changes := details.Changes
+		if changes == nil && details.OldStatus != nil && details.NewStatus != nil && *details.OldStatus != *details.NewStatus {
+			changes = []models.FieldChange{{Field: "status", Before: *details.OldStatus, After: *details.NewStatus}}
+		}
+		if len(changes) == 0 {
+			continue
+		}
+
+		history = append(history, models.HistoryEntry{
+			LogID:          log.ID,
+			OperationType:  log.OperationType,
+			UserIdentifier: log.UserIdentifier,
+			Changes:        changes,
+			CreatedAt:      log.CreatedAt,
+		})
+	}
+	return history
+}
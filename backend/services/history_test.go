@@ -0,0 +1,47 @@
+package services
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"project-manager/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func operationLog(id, operationType string, details map[string]interface{}) models.OperationLog {
+	raw, _ := json.Marshal(details)
+	return models.OperationLog{
+		ID:             id,
+		OperationType:  operationType,
+		UserIdentifier: "alice",
+		Details:        raw,
+		CreatedAt:      time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+}
+
+func TestBuildHistory(t *testing.T) {
+	logs := []models.OperationLog{
+		operationLog("1", string(models.OperationTypeCreate), map[string]interface{}{"title": "Задача"}),
+		// Лог, записанный до появления details.changes
+		operationLog("2", string(models.OperationTypeUpdate), map[string]interface{}{"old_status": "Новая", "new_status": "В работе"}),
+		operationLog("3", string(models.OperationTypeStatusChange), map[string]interface{}{"old_status": "Новая", "new_status": "В работе"}),
+		operationLog("4", string(models.OperationTypeUpdate), map[string]interface{}{
+			"old_status": "В работе",
+			"new_status": "В работе",
+			"changes":    []models.FieldChange{{Field: "priority", Before: "Низкий", After: "Высокий"}},
+		}),
+		operationLog("5", string(models.OperationTypeUpdate), map[string]interface{}{"changes": []models.FieldChange{}}),
+	}
+
+	history := buildHistory(logs)
+
+	if assert.Len(t, history, 2) {
+		assert.Equal(t, "2", history[0].LogID)
+		assert.Equal(t, []models.FieldChange{{Field: "status", Before: "Новая", After: "В работе"}}, history[0].Changes)
+		assert.Equal(t, "4", history[1].LogID)
+		assert.Equal(t, "priority", history[1].Changes[0].Field)
+		assert.Equal(t, "alice", history[1].UserIdentifier)
+	}
+}
@@ -0,0 +1,250 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"project-manager/models"
+	"project-manager/repositories"
+	"project-manager/utils"
+)
+
+const (
+	defaultNotificationPageSize = 50
+	maxNotificationPageSize     = 200
+)
+
+type NotificationService struct {
+	notificationRepo *repositories.NotificationRepository
+}
+
+func NewNotificationService(notificationRepo *repositories.NotificationRepository) *NotificationService {
+	return &NotificationService{notificationRepo: notificationRepo}
+}
+
+// GetInbox возвращает уведомления текущего пользователя, начиная с новых
+func (s *NotificationService) GetInbox(ctx context.Context, filter *models.NotificationFilter) (*models.NotificationPage, error) {
+	principal, err := requireUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if filter.Limit < 0 || filter.Offset < 0 {
+		return nil, errors.New("limit and offset must not be negative")
+	}
+	if filter.Limit == 0 {
+		filter.Limit = defaultNotificationPageSize
+	}
+	if filter.Limit > maxNotificationPageSize {
+		filter.Limit = maxNotificationPageSize
+	}
+	filter.UserID = principal.UserID
+
+	items, err := s.notificationRepo.Find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	unread, err := s.notificationRepo.CountUnread(ctx, principal.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.NotificationPage{
+		Items:  items,
+		Unread: unread,
+		Limit:  filter.Limit,
+		Offset: filter.Offset,
+	}, nil
+}
+
+// GetUnreadCount возвращает число непрочитанных уведомлений текущего пользователя
+func (s *NotificationService) GetUnreadCount(ctx context.Context) (int, error) {
+	principal, err := requireUser(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return s.notificationRepo.CountUnread(ctx, principal.UserID)
+}
+
+// MarkRead отмечает уведомление текущего пользователя прочитанным
+func (s *NotificationService) MarkRead(ctx context.Context, id string) error {
+	principal, err := requireUser(ctx)
+	if err != nil {
+		return err
+	}
+	if strings.TrimSpace(id) == "" {
+		return errors.New("id is required")
+	}
+	return s.notificationRepo.MarkRead(ctx, principal.UserID, id)
+}
+
+// MarkAllRead отмечает прочитанными все уведомления текущего пользователя
+func (s *NotificationService) MarkAllRead(ctx context.Context) (int64, error) {
+	principal, err := requireUser(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return s.notificationRepo.MarkAllRead(ctx, principal.UserID)
+}
+
+// GetPreferences возвращает настройки уведомлений текущего пользователя
+func (s *NotificationService) GetPreferences(ctx context.Context) (*models.NotificationPreferences, error) {
+	principal, err := requireUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	preferences, err := s.notificationRepo.GetPreferences(ctx, principal.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if preferences == nil {
+		defaults := models.DefaultNotificationPreferences(principal.UserID)
+		preferences = &defaults
+	}
+	return preferences, nil
+}
+
+// UpdatePreferences сохраняет настройки уведомлений текущего пользователя
+func (s *NotificationService) UpdatePreferences(ctx context.Context, preferences *models.NotificationPreferences) error {
+	principal, err := requireUser(ctx)
+	if err != nil {
+		return err
+	}
+	preferences.UserID = principal.UserID
+	return s.notificationRepo.SavePreferences(ctx, preferences)
+}
+
+// NotifyComment уведомляет упомянутых в комментарии пользователей и
+// остальных участников задачи. Вызов на nil-сервисе ничего не делает.
+func (s *NotificationService) NotifyComment(ctx context.Context, task *models.Task, comment *models.Comment, mentioned []models.NotificationRecipient) {
+	if s == nil {
+		return
+	}
+	s.NotifyMentions(ctx, task, comment, mentioned)
+
+	participants, err := s.notificationRepo.GetTaskParticipants(ctx, task.ID)
+	if err != nil {
+		s.warn("Failed to load task participants", task.ID, err)
+		return
+	}
+
+	// Упомянутые уже получили уведомление об упоминании
+	mentionedIDs := make(map[string]bool, len(mentioned))
+	for _, recipient := range mentioned {
+		mentionedIDs[recipient.UserID] = true
+	}
+	s.deliver(ctx, models.Notification{
+		Type:      string(models.NotificationComment),
+		ProjectID: &task.ProjectID,
+		TaskID:    &task.ID,
+		CommentID: &comment.ID,
+		Actor:     comment.UserIdentifier,
+		Message:   fmt.Sprintf("%s комментирует задачу %s «%s»", comment.UserIdentifier, task.Number, task.Title),
+	}, selectNotificationRecipients(participants, comment.UserIdentifier, mentionedIDs))
+}
+
+// NotifyMentions уведомляет упомянутых в комментарии пользователей
+func (s *NotificationService) NotifyMentions(ctx context.Context, task *models.Task, comment *models.Comment, mentioned []models.NotificationRecipient) {
+	if s == nil {
+		return
+	}
+	s.deliver(ctx, models.Notification{
+		Type:      string(models.NotificationMention),
+		ProjectID: &task.ProjectID,
+		TaskID:    &task.ID,
+		CommentID: &comment.ID,
+		Actor:     comment.UserIdentifier,
+		Message:   fmt.Sprintf("%s упоминает вас в комментарии к задаче %s «%s»", comment.UserIdentifier, task.Number, task.Title),
+	}, selectNotificationRecipients(mentioned, comment.UserIdentifier, nil))
+}
+
+// NotifyStatusChange уведомляет участников задачи о смене ее статуса
+func (s *NotificationService) NotifyStatusChange(ctx context.Context, task *models.Task, oldStatus string) {
+	if s == nil {
+		return
+	}
+
+	participants, err := s.notificationRepo.GetTaskParticipants(ctx, task.ID)
+	if err != nil {
+		s.warn("Failed to load task participants", task.ID, err)
+		return
+	}
+
+	actor := actorFromContext(ctx)
+	s.deliver(ctx, models.Notification{
+		Type:      string(models.NotificationStatusChange),
+		ProjectID: &task.ProjectID,
+		TaskID:    &task.ID,
+		Actor:     actor,
+		Message:   fmt.Sprintf("%s меняет статус задачи %s «%s»: %s → %s", actor, task.Number, task.Title, oldStatus, task.Status),
+	}, selectNotificationRecipients(participants, actor, nil))
+}
+
+// NotifyAssignment уведомляет исполнителя, связанного с учетной записью,
+// о назначении ему задачи. Назначение самому себе не уведомляется.
+func (s *NotificationService) NotifyAssignment(ctx context.Context, task *models.Task, executor *models.Executor) {
+	if s == nil || executor == nil || executor.UserID == nil {
+		return
+	}
+
+	exclude := map[string]bool{}
+	if principal := PrincipalFromContext(ctx); principal != nil && principal.UserID != "" {
+		exclude[principal.UserID] = true
+	}
+
+	actor := actorFromContext(ctx)
+	s.deliver(ctx, models.Notification{
+		Type:      string(models.NotificationAssignment),
+		ProjectID: &task.ProjectID,
+		TaskID:    &task.ID,
+		Actor:     actor,
+		Message:   fmt.Sprintf("%s назначает вам задачу %s «%s»", actor, task.Number, task.Title),
+	}, selectNotificationRecipients([]models.NotificationRecipient{{UserID: *executor.UserID}}, actor, exclude))
+}
+
+// deliver создает копию уведомления для каждого получателя, включившего
+// уведомления этого типа. Ошибки только логируются.
+func (s *NotificationService) deliver(ctx context.Context, notification models.Notification, userIDs []string) {
+	if len(userIDs) == 0 {
+		return
+	}
+
+	recipients, err := s.notificationRepo.FilterRecipients(ctx, userIDs, models.NotificationType(notification.Type))
+	if err != nil {
+		s.warn("Failed to filter notification recipients", *notification.TaskID, err)
+		return
+	}
+
+	for _, userID := range recipients {
+		item := notification
+		item.UserID = userID
+		if err := s.notificationRepo.Create(ctx, &item); err != nil {
+			s.warn("Failed to create notification", *notification.TaskID, err)
+		}
+	}
+}
+
+func (s *NotificationService) warn(message, taskID string, err error) {
+	utils.Warn(message, map[string]interface{}{
+		"task_id": taskID,
+		"error":   err.Error(),
+	})
+}
+
+// selectNotificationRecipients возвращает идентификаторы получателей без
+// автора события и пользователей из exclude, без повторов
+func selectNotificationRecipients(candidates []models.NotificationRecipient, actor string, exclude map[string]bool) []string {
+	seen := make(map[string]bool, len(candidates))
+	userIDs := []string{}
+	for _, candidate := range candidates {
+		if candidate.Username == actor || exclude[candidate.UserID] || seen[candidate.UserID] {
+			continue
+		}
+		seen[candidate.UserID] = true
+		userIDs = append(userIDs, candidate.UserID)
+	}
+	return userIDs
+}
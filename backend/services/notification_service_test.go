@@ -0,0 +1,22 @@
+package services
+
+import (
+	"testing"
+
+	"project-manager/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSelectNotificationRecipients(t *testing.T) {
+	candidates := []models.NotificationRecipient{
+		{UserID: "u1", Username: "alice"},
+		{UserID: "u2", Username: "bob"},
+		{UserID: "u3", Username: "carol"},
+		{UserID: "u2", Username: "bob"},
+	}
+
+	assert.Equal(t, []string{"u2", "u3"}, selectNotificationRecipients(candidates, "alice", nil))
+	assert.Equal(t, []string{"u3"}, selectNotificationRecipients(candidates, "alice", map[string]bool{"u2": true}))
+	assert.Empty(t, selectNotificationRecipients(nil, "alice", nil))
+}
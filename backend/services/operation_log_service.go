@@ -10,22 +10,36 @@ import (
 	"project-manager/repositories"
 )
 
+const maxOperationLogPageSize = 1000
+
 type OperationLogService struct {
 	logRepo  *repositories.OperationLogRepository
 	taskRepo *repositories.TaskRepository
+	webhooks *WebhookService
 }
 
-func NewOperationLogService(logRepo *repositories.OperationLogRepository, taskRepo *repositories.TaskRepository) *OperationLogService {
+func NewOperationLogService(logRepo *repositories.OperationLogRepository, taskRepo *repositories.TaskRepository, webhooks *WebhookService) *OperationLogService {
 	return &OperationLogService{
 		logRepo:  logRepo,
 		taskRepo: taskRepo,
+		webhooks: webhooks,
 	}
 }
 
 func (s *OperationLogService) CreateLog(ctx context.Context, log *models.OperationLog) error {
+	// Лог без типа записи относится к задаче из task_id
+	if log.EntityType == "" && log.TaskID != nil {
+		log.EntityType = string(models.LogEntityTask)
+		log.EntityID = *log.TaskID
+	}
+
 	// Валидация обязательных полей
-	if strings.TrimSpace(log.TaskID) == "" {
-		return errors.New("task_id is required")
+	if !models.IsValidLogEntityType(log.EntityType) {
+		return errors.New("invalid entity_type")
+	}
+
+	if strings.TrimSpace(log.EntityID) == "" {
+		return errors.New("entity_id is required")
 	}
 
 	if strings.TrimSpace(log.UserIdentifier) == "" {
@@ -41,27 +55,43 @@ func (s *OperationLogService) CreateLog(ctx context.Context, log *models.Operati
 		return errors.New("invalid operation_type")
 	}
 
-	// Проверка существования задачи
-	task, err := s.taskRepo.GetByID(ctx, log.TaskID)
-	if err != nil {
-		return err
+	// Логи задач дополнительно связываются с задачей через task_id;
+	// задачи в корзине тоже журналируются (удаление, восстановление)
+	if log.EntityType == string(models.LogEntityTask) {
+		task, err := s.taskRepo.GetByIDWithDeleted(ctx, log.EntityID)
+		if err != nil {
+			return err
+		}
+		if task == nil {
+			return errors.New("task not found")
+		}
+		log.TaskID = &log.EntityID
 	}
-	if task == nil {
-		return errors.New("task not found")
+
+	if err := s.logRepo.Create(ctx, log); err != nil {
+		return err
 	}
 
-	return s.logRepo.Create(ctx, log)
+	// Записанная операция рассылается подписанным вебхукам проекта
+	s.webhooks.Enqueue(ctx, log)
+	return nil
 }
 
 // LogTaskOperation автоматически создает лог операции для задачи
 func (s *OperationLogService) LogTaskOperation(ctx context.Context, taskID, userIdentifier, operationType string, details interface{}) error {
+	return s.LogEntityOperation(ctx, models.LogEntityTask, taskID, userIdentifier, operationType, details)
+}
+
+// LogEntityOperation автоматически создает лог операции для записи любого типа
+func (s *OperationLogService) LogEntityOperation(ctx context.Context, entityType models.LogEntityType, entityID, userIdentifier, operationType string, details interface{}) error {
 	detailsJSON, err := json.Marshal(details)
 	if err != nil {
 		return err
 	}
 
 	log := &models.OperationLog{
-		TaskID:         taskID,
+		EntityType:     string(entityType),
+		EntityID:       entityID,
 		UserIdentifier: userIdentifier,
 		OperationType:  operationType,
 		Details:        detailsJSON,
@@ -82,8 +112,8 @@ func (s *OperationLogService) GetLogsByTaskID(ctx context.Context, taskID string
 		return nil, errors.New("task_id is required")
 	}
 
-	// Проверка существования задачи
-	task, err := s.taskRepo.GetByID(ctx, taskID)
+	// Проверка существования задачи (история доступна и для задач в корзине)
+	task, err := s.taskRepo.GetByIDWithDeleted(ctx, taskID)
 	if err != nil {
 		return nil, err
 	}
@@ -94,6 +124,58 @@ func (s *OperationLogService) GetLogsByTaskID(ctx context.Context, taskID string
 	return s.logRepo.GetByTaskID(ctx, taskID)
 }
 
+// GetTaskHistory возвращает хронологию изменений полей задачи
+func (s *OperationLogService) GetTaskHistory(ctx context.Context, taskID string) ([]models.HistoryEntry, error) {
+	if strings.TrimSpace(taskID) == "" {
+		return nil, errors.New("task_id is required")
+	}
+
+	// Проверка существования задачи (история доступна и для задач в корзине)
+	task, err := s.taskRepo.GetByIDWithDeleted(ctx, taskID)
+	if err != nil {
+		return nil, err
+	}
+	if task == nil {
+		return nil, errors.New("task not found")
+	}
+
+	logs, err := s.logRepo.GetByEntity(ctx, string(models.LogEntityTask), taskID)
+	if err != nil {
+		return nil, err
+	}
+	return buildHistory(logs), nil
+}
+
+// FindLogs выбирает записи журнала по записи, пользователю и периоду
+func (s *OperationLogService) FindLogs(ctx context.Context, filter *models.OperationLogFilter) (*models.OperationLogPage, error) {
+	if filter == nil {
+		filter = &models.OperationLogFilter{}
+	}
+
+	if filter.EntityType != "" && !models.IsValidLogEntityType(filter.EntityType) {
+		return nil, errors.New("invalid entity_type: " + filter.EntityType)
+	}
+	if filter.OperationType != "" && !models.IsValidOperationType(filter.OperationType) {
+		return nil, errors.New("invalid operation_type: " + filter.OperationType)
+	}
+	if filter.From != nil && filter.To != nil && filter.From.After(*filter.To) {
+		return nil, errors.New("from must not be after to")
+	}
+	if filter.Limit < 0 || filter.Offset < 0 {
+		return nil, errors.New("limit and offset must not be negative")
+	}
+	if filter.Limit > maxOperationLogPageSize {
+		filter.Limit = maxOperationLogPageSize
+	}
+
+	return s.logRepo.Find(ctx, filter)
+}
+
+// GetValidEntityTypes возвращает список типов журналируемых записей
+func (s *OperationLogService) GetValidEntityTypes() []string {
+	return models.ValidLogEntityTypes()
+}
+
 func (s *OperationLogService) GetAllLogs(ctx context.Context) ([]models.OperationLog, error) {
 	return s.logRepo.GetAll(ctx)
 }
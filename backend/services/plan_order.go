@@ -0,0 +1,98 @@
+package services
+
+import (
+	"sort"
+
+	"project-manager/models"
+)
+
+// planOrderViolation описывает задачу плана, стоящую раньше своего блокера
+type planOrderViolation struct {
+	TaskID    string
+	BlockerID string
+}
+
+// orderPlanTopologically упорядочивает задачи плана так, чтобы каждая задача
+// шла после блокирующих ее задач плана. Среди доступных задач первой идет
+// задача с более высоким приоритетом, при равенстве — стоявшая раньше.
+func orderPlanTopologically(items []models.ProjectPlanItem, dependencies []models.TaskDependency) ([]string, error) {
+	inPlan := make(map[string]models.ProjectPlanItem, len(items))
+	for _, item := range items {
+		inPlan[item.TaskID] = item
+	}
+
+	// Учитываются только связи blocks между задачами плана
+	blocked := make(map[string][]string)
+	inDegree := make(map[string]int, len(items))
+	for _, dependency := range dependencies {
+		if dependency.Kind != string(models.DependencyKindBlocks) {
+			continue
+		}
+		_, blockerInPlan := inPlan[dependency.DependsOnTaskID]
+		_, taskInPlan := inPlan[dependency.TaskID]
+		if !blockerInPlan || !taskInPlan {
+			continue
+		}
+		blocked[dependency.DependsOnTaskID] = append(blocked[dependency.DependsOnTaskID], dependency.TaskID)
+		inDegree[dependency.TaskID]++
+	}
+
+	less := func(a, b string) bool {
+		itemA, itemB := inPlan[a], inPlan[b]
+		rankA, rankB := models.PriorityRank(itemA.TaskPriority), models.PriorityRank(itemB.TaskPriority)
+		if rankA != rankB {
+			return rankA > rankB
+		}
+		return itemA.SequenceOrder < itemB.SequenceOrder
+	}
+
+	var available []string
+	for _, item := range items {
+		if inDegree[item.TaskID] == 0 {
+			available = append(available, item.TaskID)
+		}
+	}
+
+	order := make([]string, 0, len(items))
+	for len(available) > 0 {
+		sort.Slice(available, func(i, j int) bool { return less(available[i], available[j]) })
+		next := available[0]
+		available = available[1:]
+		order = append(order, next)
+
+		for _, taskID := range blocked[next] {
+			inDegree[taskID]--
+			if inDegree[taskID] == 0 {
+				available = append(available, taskID)
+			}
+		}
+	}
+
+	if len(order) != len(items) {
+		return nil, ErrDependencyCycle
+	}
+	return order, nil
+}
+
+// findPlanOrderViolation возвращает первое нарушение порядка: задачу, стоящую
+// в плане не позже своего блокера. positions содержит позиции задач плана.
+func findPlanOrderViolation(positions map[string]int, dependencies []models.TaskDependency) *planOrderViolation {
+	var violation *planOrderViolation
+	violationPosition := 0
+	for _, dependency := range dependencies {
+		if dependency.Kind != string(models.DependencyKindBlocks) {
+			continue
+		}
+		taskPosition, taskInPlan := positions[dependency.TaskID]
+		blockerPosition, blockerInPlan := positions[dependency.DependsOnTaskID]
+		if !taskInPlan || !blockerInPlan || blockerPosition < taskPosition {
+			continue
+		}
+		// Для стабильного сообщения выбираем нарушение с наименьшей позицией
+		if violation == nil || taskPosition < violationPosition {
+			violation = &planOrderViolation{TaskID: dependency.TaskID, BlockerID: dependency.DependsOnTaskID}
+			violationPosition = taskPosition
+		}
+	}
+	return violation
+}
@@ -0,0 +1,45 @@
+package services
+
+import (
+	"testing"
+
+	"project-manager/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func planItem(taskID string, order int, priority models.TaskPriority) models.ProjectPlanItem {
+	return models.ProjectPlanItem{TaskID: taskID, SequenceOrder: order, TaskPriority: string(priority)}
+}
+
+func TestOrderPlanTopologically(t *testing.T) {
+	items := []models.ProjectPlanItem{
+		planItem("A", 1, models.TaskPriorityLow),
+		planItem("B", 2, models.TaskPriorityCritical),
+		planItem("C", 3, models.TaskPriorityHigh),
+		planItem("D", 4, models.TaskPriorityHigh),
+	}
+	dependencies := []models.TaskDependency{
+		blocks("A", "B"),
+		// Блокер вне плана не влияет на порядок
+		blocks("X", "C"),
+	}
+
+	order, err := orderPlanTopologically(items, dependencies)
+	assert.NoError(t, err)
+	// C и D выше по приоритету, чем A; B доступна только после A
+	assert.Equal(t, []string{"C", "D", "A", "B"}, order)
+
+	_, err = orderPlanTopologically(items, append(dependencies, blocks("B", "A")))
+	assert.ErrorIs(t, err, ErrDependencyCycle)
+}
+
+func TestFindPlanOrderViolation(t *testing.T) {
+	dependencies := []models.TaskDependency{blocks("A", "B"), blocks("C", "D")}
+
+	assert.Nil(t, findPlanOrderViolation(map[string]int{"A": 1, "B": 2, "C": 3, "D": 4}, dependencies))
+	assert.Nil(t, findPlanOrderViolation(map[string]int{"B": 1, "D": 2}, dependencies))
+
+	violation := findPlanOrderViolation(map[string]int{"B": 1, "A": 2, "D": 3, "C": 4}, dependencies)
+	assert.Equal(t, &planOrderViolation{TaskID: "B", BlockerID: "A"}, violation)
+}
@@ -0,0 +1,46 @@
+package services
+
+import (
+	"context"
+	"errors"
+
+	"project-manager/models"
+)
+
+// ErrForbidden возвращается, когда у субъекта запроса недостаточно прав
+var ErrForbidden = errors.New("forbidden")
+
+type principalContextKey struct{}
+
+// WithPrincipal сохраняет субъекта запроса в контексте
+func WithPrincipal(ctx context.Context, principal *models.Principal) context.Context {
+	if principal == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, principalContextKey{}, principal)
+}
+
+// withoutPrincipal убирает субъекта запроса из контекста, например для
+// служебных записей, которые не должны приписываться пользователю
+func withoutPrincipal(ctx context.Context) context.Context {
+	return context.WithValue(ctx, principalContextKey{}, (*models.Principal)(nil))
+}
+
+// PrincipalFromContext возвращает субъекта запроса или nil
+func PrincipalFromContext(ctx context.Context) *models.Principal {
+	principal, _ := ctx.Value(principalContextKey{}).(*models.Principal)
+	return principal
+}
+
+// actorFromContext возвращает имя, под которым операция записывается в журнал
+func actorFromContext(ctx context.Context) string {
+	if principal := PrincipalFromContext(ctx); principal != nil && principal.Username != "" {
+		return principal.Username
+	}
+	return models.SystemUsername
+}
+
+// detachedContext создает контекст для фоновой работы, сохраняя субъекта запроса
+func detachedContext(ctx context.Context) context.Context {
+	return WithPrincipal(context.Background(), PrincipalFromContext(ctx))
+}
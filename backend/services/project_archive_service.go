@@ -0,0 +1,296 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"project-manager/models"
+	"project-manager/repositories"
+)
+
+// ErrImportConflicts — архив содержит конфликты, запрещающие импорт
+var ErrImportConflicts = errors.New("project archive has blocking conflicts")
+
+// ProjectArchiveService выгружает проекты в переносимый архив и создает проекты из архива
+type ProjectArchiveService struct {
+	archiveRepo *repositories.ProjectArchiveRepository
+	projectRepo *repositories.ProjectRepository
+	fbRepo      *repositories.FunctionalBlockRepository
+	logService  *OperationLogService
+	access      *AccessService
+}
+
+func NewProjectArchiveService(archiveRepo *repositories.ProjectArchiveRepository, projectRepo *repositories.ProjectRepository, fbRepo *repositories.FunctionalBlockRepository, logService *OperationLogService, access *AccessService) *ProjectArchiveService {
+	return &ProjectArchiveService{
+		archiveRepo: archiveRepo,
+		projectRepo: projectRepo,
+		fbRepo:      fbRepo,
+		logService:  logService,
+		access:      access,
+	}
+}
+
+// ExportProject возвращает архив проекта. Архив содержит журнал операций и
+// комментарии, поэтому выгрузка доступна только управляющим проектом.
+func (s *ProjectArchiveService) ExportProject(ctx context.Context, id string) (*models.ProjectArchive, error) {
+	if strings.TrimSpace(id) == "" {
+		return nil, errors.New("id is required")
+	}
+
+	if err := s.access.Authorize(ctx, id, models.PermissionManageProject); err != nil {
+		return nil, err
+	}
+
+	archive, err := s.archiveRepo.Export(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if archive == nil {
+		return nil, errors.New("project not found")
+	}
+	archive.ExportedAt = time.Now().UTC()
+	return archive, nil
+}
+
+// ImportProject проверяет архив и создает по нему новый проект. В режиме
+// DryRun возвращается только отчет. При блокирующих конфликтах возвращается
+// отчет вместе с ErrImportConflicts.
+func (s *ProjectArchiveService) ImportProject(ctx context.Context, archive *models.ProjectArchive, options models.ProjectImportOptions) (*models.ProjectImportResult, error) {
+	if archive.Format != models.ProjectArchiveFormat {
+		return nil, fmt.Errorf("unsupported archive format: %q", archive.Format)
+	}
+	if archive.Version != models.ProjectArchiveVersion {
+		return nil, fmt.Errorf("unsupported archive version: %d", archive.Version)
+	}
+
+	if err := s.access.AuthorizeProjectCreate(ctx); err != nil {
+		return nil, err
+	}
+
+	existingBlocks := make(map[string]*models.FunctionalBlock)
+	for _, block := range archive.FunctionalBlocks {
+		existing, err := s.fbRepo.GetByPrefix(ctx, block.Prefix)
+		if err != nil {
+			return nil, err
+		}
+		if existing != nil {
+			existingBlocks[block.Prefix] = existing
+		}
+	}
+
+	projects, err := s.projectRepo.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	projectNames := make(map[string]bool, len(projects))
+	for _, project := range projects {
+		projectNames[normalizeProjectName(project.Name)] = true
+	}
+
+	result := &models.ProjectImportResult{
+		DryRun:    options.DryRun,
+		Counts:    archive.Counts(),
+		Conflicts: checkProjectArchive(archive, existingBlocks, projectNames, options.ReuseBlocks),
+	}
+	if options.DryRun {
+		return result, nil
+	}
+	if result.HasBlockingConflicts() {
+		return result, ErrImportConflicts
+	}
+
+	actor := actorFromContext(ctx)
+	imported, err := s.archiveRepo.Import(ctx, archive, actor)
+	if err != nil {
+		return nil, err
+	}
+	result.ProjectID = imported.Project.ID
+	result.TaskNumbers = imported.TaskNumbers
+
+	if s.logService != nil {
+		details := map[string]interface{}{
+			"project_id":    imported.Project.ID,
+			"name":          imported.Project.Name,
+			"status":        imported.Project.Status,
+			"imported_from": archive.Project.ID,
+			"counts":        result.Counts,
+		}
+		s.logService.LogEntityOperation(ctx, models.LogEntityProject, imported.Project.ID, actor, string(models.OperationTypeCreate), details)
+	}
+
+	if err := s.access.GrantCreatorOwnership(ctx, imported.Project.ID); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func normalizeProjectName(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
+
+// checkProjectArchive ищет в архиве конфликты с текущими данными и ошибки
+// ссылочной целостности. existingBlocks содержит уже существующие блоки по
+// префиксу, projectNames — имена существующих проектов после normalizeProjectName.
+func checkProjectArchive(archive *models.ProjectArchive, existingBlocks map[string]*models.FunctionalBlock, projectNames map[string]bool, reuseBlocks bool) []models.ProjectImportConflict {
+	conflicts := []models.ProjectImportConflict{}
+	add := func(kind models.ImportConflictKind, entity, reference, message string, blocking bool) {
+		conflicts = append(conflicts, models.ProjectImportConflict{
+			Kind:      kind,
+			Entity:    entity,
+			Reference: reference,
+			Message:   message,
+			Blocking:  blocking,
+		})
+	}
+
+	name := strings.TrimSpace(archive.Project.Name)
+	if name == "" {
+		add(models.ImportConflictInvalidData, "project", archive.Project.ID, "project name is required", true)
+	} else if projectNames[normalizeProjectName(name)] {
+		add(models.ImportConflictProjectName, "project", name, "a project with this name already exists; the imported project will have the same name", false)
+	}
+
+	// Статусы задач проверяются по процессу из архива, без него — по процессу по умолчанию
+	workflow := models.DefaultWorkflow()
+	if archive.Workflow != nil {
+		// В архивах прежних версий конечные статусы не указаны
+		archive.Workflow.FillTerminalStates()
+		if err := archive.Workflow.Validate(); err != nil {
+			add(models.ImportConflictInvalidData, "workflow", archive.Project.ID, err.Error(), true)
+		} else {
+			workflow = archive.Workflow
+		}
+	}
+
+	blocks := make(map[string]bool, len(archive.FunctionalBlocks))
+	prefixes := make(map[string]bool, len(archive.FunctionalBlocks))
+	for _, block := range archive.FunctionalBlocks {
+		if blocks[block.ID] {
+			add(models.ImportConflictDuplicateID, "functional_block", block.ID, "functional block id is repeated in the archive", true)
+		}
+		blocks[block.ID] = true
+
+		switch {
+		case !prefixRegex.MatchString(block.Prefix) || block.Prefix == models.DefaultTaskNumberPrefix:
+			add(models.ImportConflictInvalidPrefix, "functional_block", block.Prefix, "prefix must be 1-6 uppercase Latin letters and must not be "+models.DefaultTaskNumberPrefix, true)
+		case prefixes[block.Prefix]:
+			add(models.ImportConflictDuplicatePrefix, "functional_block", block.Prefix, "prefix is repeated in the archive", true)
+		case existingBlocks[block.Prefix] != nil:
+			existing := existingBlocks[block.Prefix]
+			if existing.Name == block.Name {
+				add(models.ImportConflictDuplicatePrefix, "functional_block", block.Prefix, "existing functional block with this prefix will be reused", false)
+			} else {
+				message := fmt.Sprintf("prefix is already used by functional block %q", existing.Name)
+				if reuseBlocks {
+					message += "; it will be reused"
+				}
+				add(models.ImportConflictDuplicatePrefix, "functional_block", block.Prefix, message, !reuseBlocks)
+			}
+		}
+		prefixes[block.Prefix] = true
+	}
+
+	tasks := make(map[string]bool, len(archive.Tasks))
+	for _, task := range archive.Tasks {
+		if tasks[task.ID] {
+			add(models.ImportConflictDuplicateID, "task", task.ID, "task id is repeated in the archive", true)
+		}
+		tasks[task.ID] = true
+	}
+
+	documents := make(map[string]bool, len(archive.Documents))
+	for _, document := range archive.Documents {
+		if documents[document.ID] {
+			add(models.ImportConflictDuplicateID, "document", document.ID, "document id is repeated in the archive", true)
+		}
+		documents[document.ID] = true
+		if strings.TrimSpace(document.Title) == "" {
+			add(models.ImportConflictInvalidData, "document", document.ID, "document title is required", true)
+		}
+	}
+
+	for _, task := range archive.Tasks {
+		reference := task.Number
+		if reference == "" {
+			reference = task.ID
+		}
+		if strings.TrimSpace(task.Title) == "" {
+			add(models.ImportConflictInvalidData, "task", reference, "task title is required", true)
+		}
+		if !workflow.HasState(task.Status) {
+			add(models.ImportConflictInvalidData, "task", reference, fmt.Sprintf("status %q is not in the project workflow", task.Status), true)
+		}
+		if task.FunctionalBlockID != nil && !blocks[*task.FunctionalBlockID] {
+			add(models.ImportConflictMissingReference, "task", reference, "functional block is not in the archive; the task will be imported without it", false)
+		}
+		if task.ParentTaskID != nil && !tasks[*task.ParentTaskID] {
+			add(models.ImportConflictMissingReference, "task", reference, "parent task is not in the archive; the task will be imported without a parent", false)
+		}
+		if task.SourceDocumentID != nil && !documents[*task.SourceDocumentID] {
+			add(models.ImportConflictMissingReference, "task", reference, "source document is not in the archive; the reference will be dropped", false)
+		}
+	}
+
+	for _, dependency := range archive.TaskDependencies {
+		if !tasks[dependency.TaskID] || !tasks[dependency.DependsOnTaskID] {
+			add(models.ImportConflictMissingReference, "task_dependency", dependency.ID, "dependency refers to a task that is not in the archive and will be skipped", false)
+		}
+	}
+	comments := make(map[string]bool, len(archive.Comments))
+	for _, comment := range archive.Comments {
+		if !tasks[comment.TaskID] {
+			add(models.ImportConflictMissingReference, "comment", comment.ID, "comment refers to a task that is not in the archive and will be skipped", false)
+			continue
+		}
+		if comment.Type != "" && !models.IsValidCommentType(comment.Type) {
+			add(models.ImportConflictInvalidData, "comment", comment.ID, "invalid comment type: "+comment.Type, true)
+		}
+		if comment.ParentCommentID != nil && !comments[*comment.ParentCommentID] {
+			add(models.ImportConflictMissingReference, "comment", comment.ID, "reply refers to a comment that is not in the archive or comes later; it will be imported as a top-level comment", false)
+		}
+		comments[comment.ID] = true
+	}
+	for _, revision := range archive.DocumentRevisions {
+		if !documents[revision.DocumentID] {
+			add(models.ImportConflictMissingReference, "document_revision", revision.ID, "revision refers to a document that is not in the archive and will be skipped", false)
+		}
+	}
+	for _, link := range archive.TaskDocumentLinks {
+		if !tasks[link.TaskID] || !documents[link.DocumentID] {
+			add(models.ImportConflictMissingReference, "task_document_link", link.ID, "link refers to a task or document that is not in the archive and will be skipped", false)
+		}
+	}
+	for _, taskID := range archive.Plan {
+		if !tasks[taskID] {
+			add(models.ImportConflictMissingReference, "plan", taskID, "plan refers to a task that is not in the archive; the item will be skipped", false)
+		}
+	}
+
+	skippedLogs := 0
+	for _, log := range archive.OperationLogs {
+		switch models.LogEntityType(log.EntityType) {
+		case models.LogEntityProject, models.LogEntityProjectPlan:
+			if log.EntityID != archive.Project.ID {
+				skippedLogs++
+			}
+		case models.LogEntityTask:
+			if !tasks[log.EntityID] {
+				skippedLogs++
+			}
+		case models.LogEntityDocument:
+			if !documents[log.EntityID] {
+				skippedLogs++
+			}
+		default:
+			skippedLogs++
+		}
+	}
+	if skippedLogs > 0 {
+		add(models.ImportConflictMissingReference, "operation_log", "", fmt.Sprintf("%d operation log entries refer to records outside the archive and will be skipped", skippedLogs), false)
+	}
+
+	return conflicts
+}
@@ -0,0 +1,109 @@
+package services
+
+import (
+	"testing"
+
+	"project-manager/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testProjectArchive() *models.ProjectArchive {
+	blockID := "fb-1"
+	parentID := "task-1"
+	missingID := "missing"
+	return &models.ProjectArchive{
+		Format:           models.ProjectArchiveFormat,
+		Version:          models.ProjectArchiveVersion,
+		Project:          models.Project{ID: "project-1", Name: "Портал"},
+		FunctionalBlocks: []models.FunctionalBlock{{ID: blockID, Name: "Авторизация", Prefix: "AUTH"}},
+		Tasks: []models.Task{
+			{ID: "task-1", Number: "AUTH-0001", Title: "Вход", Status: string(models.TaskStatusNew), FunctionalBlockID: &blockID},
+			{ID: "task-2", Number: "TASK-0002", Title: "Дефект входа", Status: string(models.TaskStatusInProgress), ParentTaskID: &parentID, SourceDocumentID: &missingID},
+		},
+		Comments: []models.Comment{{ID: "comment-1", TaskID: "task-2"}},
+		Plan:     []string{"task-1", "task-3"},
+		OperationLogs: []models.OperationLog{
+			{EntityType: string(models.LogEntityTask), EntityID: "task-1"},
+			{EntityType: string(models.LogEntityProject), EntityID: "project-1"},
+			{EntityType: string(models.LogEntityFunctionalBlock), EntityID: blockID},
+		},
+	}
+}
+
+func conflictKinds(conflicts []models.ProjectImportConflict) []string {
+	kinds := make([]string, 0, len(conflicts))
+	for _, conflict := range conflicts {
+		kinds = append(kinds, string(conflict.Kind)+":"+conflict.Entity+":"+conflict.Reference)
+	}
+	return kinds
+}
+
+func TestCheckProjectArchive_MissingReferencesAreNotBlocking(t *testing.T) {
+	conflicts := checkProjectArchive(testProjectArchive(), nil, nil, false)
+
+	assert.Equal(t, []string{
+		"missing_reference:task:TASK-0002",
+		"missing_reference:plan:task-3",
+		"missing_reference:operation_log:",
+	}, conflictKinds(conflicts))
+	result := &models.ProjectImportResult{Conflicts: conflicts}
+	assert.False(t, result.HasBlockingConflicts())
+}
+
+func TestCheckProjectArchive_DuplicatePrefix(t *testing.T) {
+	archive := testProjectArchive()
+
+	sameName := map[string]*models.FunctionalBlock{"AUTH": {ID: "other", Name: "Авторизация", Prefix: "AUTH"}}
+	conflicts := checkProjectArchive(archive, sameName, nil, false)
+	require.Equal(t, models.ImportConflictDuplicatePrefix, conflicts[0].Kind)
+	assert.False(t, conflicts[0].Blocking)
+
+	otherName := map[string]*models.FunctionalBlock{"AUTH": {ID: "other", Name: "Аутентификация", Prefix: "AUTH"}}
+	conflicts = checkProjectArchive(archive, otherName, nil, false)
+	require.Equal(t, models.ImportConflictDuplicatePrefix, conflicts[0].Kind)
+	assert.True(t, conflicts[0].Blocking)
+
+	conflicts = checkProjectArchive(archive, otherName, nil, true)
+	assert.False(t, conflicts[0].Blocking)
+}
+
+func TestCheckProjectArchive_BlockingConflicts(t *testing.T) {
+	archive := testProjectArchive()
+	archive.Project.Name = " "
+	archive.FunctionalBlocks = append(archive.FunctionalBlocks, models.FunctionalBlock{ID: "fb-2", Prefix: "TASK"})
+	archive.Tasks = append(archive.Tasks, models.Task{ID: "task-1", Title: "Повтор"})
+
+	conflicts := checkProjectArchive(archive, nil, map[string]bool{"портал": true}, false)
+
+	kinds := conflictKinds(conflicts)
+	assert.Contains(t, kinds, "invalid_data:project:project-1")
+	assert.Contains(t, kinds, "invalid_prefix:functional_block:TASK")
+	assert.Contains(t, kinds, "duplicate_id:task:task-1")
+	assert.NotContains(t, kinds, "project_name_exists:project:Портал")
+	assert.True(t, (&models.ProjectImportResult{Conflicts: conflicts}).HasBlockingConflicts())
+}
+
+func TestCheckProjectArchive_TaskStatusOutsideWorkflow(t *testing.T) {
+	archive := testProjectArchive()
+	archive.Tasks[0].Status = "review"
+
+	conflicts := checkProjectArchive(archive, nil, nil, false)
+	assert.Contains(t, conflictKinds(conflicts), "invalid_data:task:AUTH-0001")
+	assert.True(t, (&models.ProjectImportResult{Conflicts: conflicts}).HasBlockingConflicts())
+
+	workflow := models.DefaultWorkflow()
+	workflow.States = append(workflow.States, "review")
+	archive.Workflow = workflow
+	conflicts = checkProjectArchive(archive, nil, nil, false)
+	assert.NotContains(t, conflictKinds(conflicts), "invalid_data:task:AUTH-0001")
+}
+
+func TestCheckProjectArchive_ProjectNameExists(t *testing.T) {
+	conflicts := checkProjectArchive(testProjectArchive(), nil, map[string]bool{normalizeProjectName(" ПОРТАЛ "): true}, false)
+
+	require.NotEmpty(t, conflicts)
+	assert.Equal(t, models.ImportConflictProjectName, conflicts[0].Kind)
+	assert.False(t, conflicts[0].Blocking)
+}
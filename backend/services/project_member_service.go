@@ -0,0 +1,136 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"project-manager/models"
+	"project-manager/repositories"
+)
+
+type ProjectMemberService struct {
+	memberRepo  *repositories.ProjectMemberRepository
+	projectRepo *repositories.ProjectRepository
+	userRepo    *repositories.UserRepository
+	access      *AccessService
+}
+
+func NewProjectMemberService(memberRepo *repositories.ProjectMemberRepository, projectRepo *repositories.ProjectRepository, userRepo *repositories.UserRepository, access *AccessService) *ProjectMemberService {
+	return &ProjectMemberService{
+		memberRepo:  memberRepo,
+		projectRepo: projectRepo,
+		userRepo:    userRepo,
+		access:      access,
+	}
+}
+
+func (s *ProjectMemberService) GetMembers(ctx context.Context, projectID string) ([]models.ProjectMember, error) {
+	if err := s.ensureProject(ctx, projectID); err != nil {
+		return nil, err
+	}
+	return s.memberRepo.GetByProjectID(ctx, projectID)
+}
+
+// SetMember добавляет участника или меняет его роль.
+// Назначать и снимать владельцев могут только владельцы.
+func (s *ProjectMemberService) SetMember(ctx context.Context, member *models.ProjectMember) error {
+	// Валидация обязательных полей
+	if strings.TrimSpace(member.UserID) == "" {
+		return errors.New("user_id is required")
+	}
+	if !models.IsValidProjectRole(member.Role) {
+		return errors.New("invalid role")
+	}
+
+	if err := s.ensureProject(ctx, member.ProjectID); err != nil {
+		return err
+	}
+	if err := s.access.Authorize(ctx, member.ProjectID, models.PermissionManageProject); err != nil {
+		return err
+	}
+
+	user, err := s.userRepo.GetByID(ctx, member.UserID)
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		return errors.New("user not found")
+	}
+	if user.IsAgent && member.Role != string(models.ProjectRoleAgent) && member.Role != string(models.ProjectRoleViewer) {
+		return errors.New("agent users can only have agent or viewer role")
+	}
+
+	currentRole, err := s.memberRepo.GetRole(ctx, member.ProjectID, member.UserID)
+	if err != nil {
+		return err
+	}
+	if member.Role == string(models.ProjectRoleOwner) || currentRole == string(models.ProjectRoleOwner) {
+		if err := s.access.Authorize(ctx, member.ProjectID, models.PermissionDeleteProject); err != nil {
+			return err
+		}
+	}
+	if currentRole == string(models.ProjectRoleOwner) && member.Role != currentRole {
+		if err := s.ensureAnotherOwner(ctx, member.ProjectID); err != nil {
+			return err
+		}
+	}
+
+	member.Username = user.Username
+	return s.memberRepo.Upsert(ctx, member)
+}
+
+func (s *ProjectMemberService) RemoveMember(ctx context.Context, projectID, userID string) error {
+	if err := s.ensureProject(ctx, projectID); err != nil {
+		return err
+	}
+	if err := s.access.Authorize(ctx, projectID, models.PermissionManageProject); err != nil {
+		return err
+	}
+
+	currentRole, err := s.memberRepo.GetRole(ctx, projectID, userID)
+	if err != nil {
+		return err
+	}
+	if currentRole == string(models.ProjectRoleOwner) {
+		if err := s.access.Authorize(ctx, projectID, models.PermissionDeleteProject); err != nil {
+			return err
+		}
+		if err := s.ensureAnotherOwner(ctx, projectID); err != nil {
+			return err
+		}
+	}
+
+	return s.memberRepo.Delete(ctx, projectID, userID)
+}
+
+// GetValidRoles возвращает список валидных ролей участников проекта
+func (s *ProjectMemberService) GetValidRoles() []string {
+	return models.ValidProjectRoles()
+}
+
+func (s *ProjectMemberService) ensureProject(ctx context.Context, projectID string) error {
+	if strings.TrimSpace(projectID) == "" {
+		return errors.New("project_id is required")
+	}
+	project, err := s.projectRepo.GetByID(ctx, projectID)
+	if err != nil {
+		return err
+	}
+	if project == nil {
+		return errors.New("project not found")
+	}
+	return nil
+}
+
+// ensureAnotherOwner запрещает оставить проект без владельца
+func (s *ProjectMemberService) ensureAnotherOwner(ctx context.Context, projectID string) error {
+	owners, err := s.memberRepo.CountOwners(ctx, projectID)
+	if err != nil {
+		return err
+	}
+	if owners <= 1 {
+		return errors.New("project must have at least one owner")
+	}
+	return nil
+}
@@ -3,26 +3,57 @@ package services
 import (
 	"context"
 	"errors"
+	"fmt"
 	"strings"
 
 	"project-manager/models"
 	"project-manager/repositories"
 )
 
+// ErrPlanOrderViolation возвращается, когда порядок плана ставит задачу раньше ее блокера
+var ErrPlanOrderViolation = errors.New("task cannot be placed before its blocker")
+
 type ProjectPlanService struct {
-	planRepo    *repositories.ProjectPlanRepository
-	projectRepo *repositories.ProjectRepository
-	taskRepo    *repositories.TaskRepository
+	planRepo       *repositories.ProjectPlanRepository
+	projectRepo    *repositories.ProjectRepository
+	taskRepo       *repositories.TaskRepository
+	dependencyRepo *repositories.TaskDependencyRepository
+	logService     *OperationLogService
+	access         *AccessService
+	workflows      *WorkflowService
+	events         *EventBroker
 }
 
-func NewProjectPlanService(planRepo *repositories.ProjectPlanRepository, projectRepo *repositories.ProjectRepository, taskRepo *repositories.TaskRepository) *ProjectPlanService {
+func NewProjectPlanService(planRepo *repositories.ProjectPlanRepository, projectRepo *repositories.ProjectRepository, taskRepo *repositories.TaskRepository, dependencyRepo *repositories.TaskDependencyRepository, logService *OperationLogService, access *AccessService, workflows *WorkflowService, events *EventBroker) *ProjectPlanService {
 	return &ProjectPlanService{
-		planRepo:    planRepo,
-		projectRepo: projectRepo,
-		taskRepo:    taskRepo,
+		planRepo:       planRepo,
+		projectRepo:    projectRepo,
+		taskRepo:       taskRepo,
+		dependencyRepo: dependencyRepo,
+		logService:     logService,
+		access:         access,
+		workflows:      workflows,
+		events:         events,
 	}
 }
 
+// logPlanOperation записывает в журнал изменение плана проекта и сообщает о
+// нем подписчикам. План журналируется как одна запись с идентификатором проекта.
+func (s *ProjectPlanService) logPlanOperation(ctx context.Context, projectID string, operationType models.OperationType, details map[string]interface{}) {
+	details["project_id"] = projectID
+	s.events.Publish(ctx, models.Event{
+		Type:      models.EventPlanUpdated,
+		ProjectID: projectID,
+		EntityID:  projectID,
+		Data:      details,
+	})
+
+	if s.logService == nil {
+		return
+	}
+	s.logService.LogEntityOperation(ctx, models.LogEntityProjectPlan, projectID, actorFromContext(ctx), string(operationType), details)
+}
+
 func (s *ProjectPlanService) AddTaskToPlan(ctx context.Context, projectID, taskID string) error {
 	// Валидация входных данных
 	if strings.TrimSpace(projectID) == "" {
@@ -42,6 +73,10 @@ func (s *ProjectPlanService) AddTaskToPlan(ctx context.Context, projectID, taskI
 		return errors.New("project not found")
 	}
 
+	if err := s.access.Authorize(ctx, projectID, models.PermissionEditTasks); err != nil {
+		return err
+	}
+
 	// Проверка существования задачи
 	task, err := s.taskRepo.GetByID(ctx, taskID)
 	if err != nil {
@@ -65,7 +100,15 @@ func (s *ProjectPlanService) AddTaskToPlan(ctx context.Context, projectID, taskI
 		return errors.New("task is already in the project plan")
 	}
 
-	return s.planRepo.AddTaskToPlan(ctx, projectID, taskID)
+	if err := s.planRepo.AddTaskToPlan(ctx, projectID, taskID); err != nil {
+		return err
+	}
+
+	s.logPlanOperation(ctx, projectID, models.OperationTypeCreate, map[string]interface{}{
+		"action":  "add_task",
+		"task_id": taskID,
+	})
+	return nil
 }
 
 func (s *ProjectPlanService) RemoveTaskFromPlan(ctx context.Context, projectID, taskID string) error {
@@ -87,6 +130,10 @@ func (s *ProjectPlanService) RemoveTaskFromPlan(ctx context.Context, projectID,
 		return errors.New("project not found")
 	}
 
+	if err := s.access.Authorize(ctx, projectID, models.PermissionEditTasks); err != nil {
+		return err
+	}
+
 	// Проверка, что задача в плане
 	inPlan, err := s.planRepo.IsTaskInPlan(ctx, projectID, taskID)
 	if err != nil {
@@ -96,7 +143,15 @@ func (s *ProjectPlanService) RemoveTaskFromPlan(ctx context.Context, projectID,
 		return errors.New("task is not in the project plan")
 	}
 
-	return s.planRepo.RemoveTaskFromPlan(ctx, projectID, taskID)
+	if err := s.planRepo.RemoveTaskFromPlan(ctx, projectID, taskID); err != nil {
+		return err
+	}
+
+	s.logPlanOperation(ctx, projectID, models.OperationTypeDelete, map[string]interface{}{
+		"action":  "remove_task",
+		"task_id": taskID,
+	})
+	return nil
 }
 
 func (s *ProjectPlanService) GetProjectPlan(ctx context.Context, projectID string) (*models.ProjectPlan, error) {
@@ -136,6 +191,10 @@ func (s *ProjectPlanService) ReorderTasks(ctx context.Context, projectID string,
 		return errors.New("project not found")
 	}
 
+	if err := s.access.Authorize(ctx, projectID, models.PermissionEditTasks); err != nil {
+		return err
+	}
+
 	// Валидация всех задач в запросе
 	for _, taskSeq := range reorderRequest.TaskSequences {
 		if strings.TrimSpace(taskSeq.TaskID) == "" {
@@ -177,7 +236,31 @@ func (s *ProjectPlanService) ReorderTasks(ctx context.Context, projectID string,
 		orderMap[taskSeq.SequenceOrder] = true
 	}
 
-	return s.planRepo.ReorderTasks(ctx, projectID, reorderRequest.TaskSequences)
+	// Проверка, что новый порядок не ставит задачи раньше их блокеров
+	plan, err := s.planRepo.GetProjectPlan(ctx, projectID)
+	if err != nil {
+		return err
+	}
+	positions := make(map[string]int, len(plan.Items))
+	for _, item := range plan.Items {
+		positions[item.TaskID] = item.SequenceOrder
+	}
+	for _, taskSeq := range reorderRequest.TaskSequences {
+		positions[taskSeq.TaskID] = taskSeq.SequenceOrder
+	}
+	if err := s.validatePlanOrder(ctx, projectID, plan.Items, positions); err != nil {
+		return err
+	}
+
+	if err := s.planRepo.ReorderTasks(ctx, projectID, reorderRequest.TaskSequences); err != nil {
+		return err
+	}
+
+	s.logPlanOperation(ctx, projectID, models.OperationTypeUpdate, map[string]interface{}{
+		"action":    "reorder",
+		"sequences": reorderRequest.TaskSequences,
+	})
+	return nil
 }
 
 func (s *ProjectPlanService) MoveTaskToPosition(ctx context.Context, projectID, taskID string, newPosition int) error {
@@ -203,6 +286,10 @@ func (s *ProjectPlanService) MoveTaskToPosition(ctx context.Context, projectID,
 		return errors.New("project not found")
 	}
 
+	if err := s.access.Authorize(ctx, projectID, models.PermissionEditTasks); err != nil {
+		return err
+	}
+
 	// Проверка существования задачи
 	task, err := s.taskRepo.GetByID(ctx, taskID)
 	if err != nil {
@@ -226,7 +313,40 @@ func (s *ProjectPlanService) MoveTaskToPosition(ctx context.Context, projectID,
 		return errors.New("task is not in the project plan")
 	}
 
-	return s.planRepo.MoveTaskToPosition(ctx, projectID, taskID, newPosition)
+	// Проверка, что после перемещения задачи не окажутся раньше своих блокеров
+	plan, err := s.planRepo.GetProjectPlan(ctx, projectID)
+	if err != nil {
+		return err
+	}
+	order := make([]string, 0, len(plan.Items))
+	for _, item := range plan.Items {
+		if item.TaskID != taskID {
+			order = append(order, item.TaskID)
+		}
+	}
+	index := newPosition - 1
+	if index > len(order) {
+		index = len(order)
+	}
+	order = append(order[:index], append([]string{taskID}, order[index:]...)...)
+	positions := make(map[string]int, len(order))
+	for i, id := range order {
+		positions[id] = i + 1
+	}
+	if err := s.validatePlanOrder(ctx, projectID, plan.Items, positions); err != nil {
+		return err
+	}
+
+	if err := s.planRepo.MoveTaskToPosition(ctx, projectID, taskID, newPosition); err != nil {
+		return err
+	}
+
+	s.logPlanOperation(ctx, projectID, models.OperationTypeUpdate, map[string]interface{}{
+		"action":       "move_task",
+		"task_id":      taskID,
+		"new_position": newPosition,
+	})
+	return nil
 }
 
 func (s *ProjectPlanService) GetTaskPosition(ctx context.Context, projectID, taskID string) (int, error) {
@@ -286,3 +406,131 @@ func (s *ProjectPlanService) IsTaskInPlan(ctx context.Context, projectID, taskID
 
 	return s.planRepo.IsTaskInPlan(ctx, projectID, taskID)
 }
+
+// AutoOrderPlan упорядочивает план с учетом зависимостей и приоритетов:
+// блокирующие задачи идут раньше блокируемых, более важные — раньше остальных
+func (s *ProjectPlanService) AutoOrderPlan(ctx context.Context, projectID string) (*models.ProjectPlan, error) {
+	if strings.TrimSpace(projectID) == "" {
+		return nil, errors.New("project_id is required")
+	}
+
+	// Проверка существования проекта
+	project, err := s.projectRepo.GetByID(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+	if project == nil {
+		return nil, errors.New("project not found")
+	}
+
+	if err := s.access.Authorize(ctx, projectID, models.PermissionEditTasks); err != nil {
+		return nil, err
+	}
+
+	plan, err := s.planRepo.GetProjectPlan(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+	if len(plan.Items) == 0 {
+		return plan, nil
+	}
+
+	dependencies, err := s.dependencyRepo.GetByProjectID(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+	order, err := orderPlanTopologically(plan.Items, dependencies)
+	if err != nil {
+		return nil, err
+	}
+
+	sequences := make([]models.TaskSequence, len(order))
+	for i, taskID := range order {
+		sequences[i] = models.TaskSequence{TaskID: taskID, SequenceOrder: i + 1}
+	}
+	if err := s.planRepo.ReorderTasks(ctx, projectID, sequences); err != nil {
+		return nil, err
+	}
+
+	s.logPlanOperation(ctx, projectID, models.OperationTypeUpdate, map[string]interface{}{
+		"action":    "auto_order",
+		"sequences": sequences,
+	})
+
+	return s.planRepo.GetProjectPlan(ctx, projectID)
+}
+
+// GetNextTask возвращает первую задачу плана в начальном статусе процесса
+// проекта, все блокеры которой находятся в конечных статусах, или nil, если
+// таких нет
+func (s *ProjectPlanService) GetNextTask(ctx context.Context, projectID string) (*models.ProjectPlanItem, error) {
+	if strings.TrimSpace(projectID) == "" {
+		return nil, errors.New("project_id is required")
+	}
+
+	// Проверка существования проекта
+	project, err := s.projectRepo.GetByID(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+	if project == nil {
+		return nil, errors.New("project not found")
+	}
+
+	plan, err := s.planRepo.GetProjectPlan(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+	if len(plan.Items) == 0 {
+		return nil, nil
+	}
+
+	dependencies, err := s.dependencyRepo.GetByProjectID(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+	tasks, err := s.taskRepo.GetByProjectID(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+	workflow, err := s.workflows.GetWorkflow(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+	statuses := make(map[string]string, len(tasks))
+	for _, task := range tasks {
+		statuses[task.ID] = task.Status
+	}
+	blockers := blockersByTask(dependencies)
+
+	for i := range plan.Items {
+		item := &plan.Items[i]
+		if item.TaskStatus != workflow.InitialState {
+			continue
+		}
+		if isTaskReady(item.TaskID, statuses, blockers, workflow) {
+			return item, nil
+		}
+	}
+	return nil, nil
+}
+
+// validatePlanOrder проверяет, что при позициях positions ни одна задача плана
+// не стоит раньше блокирующей ее задачи
+func (s *ProjectPlanService) validatePlanOrder(ctx context.Context, projectID string, items []models.ProjectPlanItem, positions map[string]int) error {
+	dependencies, err := s.dependencyRepo.GetByProjectID(ctx, projectID)
+	if err != nil {
+		return err
+	}
+
+	violation := findPlanOrderViolation(positions, dependencies)
+	if violation == nil {
+		return nil
+	}
+
+	numbers := make(map[string]string, len(items))
+	for _, item := range items {
+		numbers[item.TaskID] = item.TaskNumber
+	}
+	return fmt.Errorf("%w: %s is blocked by %s", ErrPlanOrderViolation, numbers[violation.TaskID], numbers[violation.BlockerID])
+}
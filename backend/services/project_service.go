@@ -18,11 +18,13 @@ var validProjectStatuses = map[string]bool{
 }
 
 type ProjectService struct {
-	repo *repositories.ProjectRepository
+	repo       *repositories.ProjectRepository
+	logService *OperationLogService
+	access     *AccessService
 }
 
-func NewProjectService(repo *repositories.ProjectRepository) *ProjectService {
-	return &ProjectService{repo: repo}
+func NewProjectService(repo *repositories.ProjectRepository, logService *OperationLogService, access *AccessService) *ProjectService {
+	return &ProjectService{repo: repo, logService: logService, access: access}
 }
 
 func (s *ProjectService) CreateProject(ctx context.Context, project *models.Project) error {
@@ -41,7 +43,26 @@ func (s *ProjectService) CreateProject(ctx context.Context, project *models.Proj
 		return errors.New("invalid status")
 	}
 
-	return s.repo.Create(ctx, project)
+	if err := s.access.AuthorizeProjectCreate(ctx); err != nil {
+		return err
+	}
+
+	if err := s.repo.Create(ctx, project); err != nil {
+		return err
+	}
+
+	// Логируем создание проекта
+	if s.logService != nil {
+		details := map[string]interface{}{
+			"project_id": project.ID,
+			"name":       project.Name,
+			"status":     project.Status,
+		}
+		s.logService.LogEntityOperation(ctx, models.LogEntityProject, project.ID, actorFromContext(ctx), string(models.OperationTypeCreate), details)
+	}
+
+	// Создатель проекта становится его владельцем
+	return s.access.GrantCreatorOwnership(ctx, project.ID)
 }
 
 func (s *ProjectService) GetProjectByID(ctx context.Context, id string) (*models.Project, error) {
@@ -66,12 +87,144 @@ func (s *ProjectService) UpdateProject(ctx context.Context, project *models.Proj
 		return errors.New("invalid status")
 	}
 
-	return s.repo.Update(ctx, project)
+	if err := s.access.Authorize(ctx, project.ID, models.PermissionManageProject); err != nil {
+		return err
+	}
+
+	existingProject, err := s.repo.GetByID(ctx, project.ID)
+	if err != nil {
+		return err
+	}
+	if existingProject != nil {
+		project.CreatedAt = existingProject.CreatedAt
+	}
+
+	if err := s.repo.Update(ctx, project); err != nil {
+		return err
+	}
+
+	// Логируем изменения полей проекта
+	if s.logService != nil && existingProject != nil {
+		details := map[string]interface{}{
+			"project_id": project.ID,
+			"name":       project.Name,
+			"changes":    models.DiffFields(existingProject, project),
+		}
+		s.logService.LogEntityOperation(ctx, models.LogEntityProject, project.ID, actorFromContext(ctx), string(models.OperationTypeUpdate), details)
+	}
+
+	return nil
+}
+
+// GetDeletedProjects возвращает проекты в корзине
+func (s *ProjectService) GetDeletedProjects(ctx context.Context) ([]models.Project, error) {
+	projects, err := s.repo.GetDeleted(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if projects == nil {
+		projects = []models.Project{}
+	}
+	return projects, nil
+}
+
+// RestoreProject возвращает проект из корзины вместе с задачами, удаленными вместе с ним
+func (s *ProjectService) RestoreProject(ctx context.Context, id string) (*models.Project, error) {
+	project, err := s.getDeletedProject(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.access.Authorize(ctx, id, models.PermissionDeleteProject); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Restore(ctx, id); err != nil {
+		return nil, err
+	}
+
+	if s.logService != nil {
+		details := map[string]interface{}{
+			"project_id": project.ID,
+			"name":       project.Name,
+		}
+		s.logService.LogEntityOperation(ctx, models.LogEntityProject, project.ID, actorFromContext(ctx), string(models.OperationTypeRestore), details)
+	}
+
+	return s.repo.GetByID(ctx, id)
+}
+
+// PurgeProject окончательно удаляет проект из корзины со всеми задачами и документами
+func (s *ProjectService) PurgeProject(ctx context.Context, id string) error {
+	project, err := s.getDeletedProject(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if err := s.access.Authorize(ctx, id, models.PermissionDeleteProject); err != nil {
+		return err
+	}
+
+	if err := s.repo.Purge(ctx, id); err != nil {
+		return err
+	}
+
+	if s.logService != nil {
+		details := map[string]interface{}{
+			"project_id": project.ID,
+			"name":       project.Name,
+		}
+		s.logService.LogEntityOperation(ctx, models.LogEntityProject, project.ID, actorFromContext(ctx), string(models.OperationTypePurge), details)
+	}
+
+	return nil
+}
+
+// getDeletedProject возвращает проект, находящийся в корзине
+func (s *ProjectService) getDeletedProject(ctx context.Context, id string) (*models.Project, error) {
+	if strings.TrimSpace(id) == "" {
+		return nil, errors.New("id is required")
+	}
+
+	project, err := s.repo.GetByIDWithDeleted(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if project == nil {
+		return nil, errors.New("project not found")
+	}
+	if project.DeletedAt == nil {
+		return nil, errors.New("project is not in trash")
+	}
+	return project, nil
 }
 
 func (s *ProjectService) DeleteProject(ctx context.Context, id string) error {
 	if strings.TrimSpace(id) == "" {
 		return errors.New("id is required")
 	}
-	return s.repo.Delete(ctx, id)
+
+	if err := s.access.Authorize(ctx, id, models.PermissionDeleteProject); err != nil {
+		return err
+	}
+
+	project, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if err := s.repo.Delete(ctx, id); err != nil {
+		return err
+	}
+
+	// Логируем удаление проекта; запись журнала переживает сам проект
+	if s.logService != nil && project != nil {
+		details := map[string]interface{}{
+			"project_id": project.ID,
+			"name":       project.Name,
+		}
+		s.logService.LogEntityOperation(ctx, models.LogEntityProject, project.ID, actorFromContext(ctx), string(models.OperationTypeDelete), details)
+	}
+
+	return nil
 }
@@ -0,0 +1,80 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"project-manager/models"
+	"project-manager/repositories"
+)
+
+// ErrInvalidSearchQuery возвращается при некорректных параметрах поиска
+var ErrInvalidSearchQuery = errors.New("invalid search query")
+
+const (
+	defaultSearchLimit = 20
+	maxSearchLimit     = 100
+	maxSearchQueryLen  = 256
+)
+
+type SearchService struct {
+	searchRepo *repositories.SearchRepository
+}
+
+func NewSearchService(searchRepo *repositories.SearchRepository) *SearchService {
+	return &SearchService{searchRepo: searchRepo}
+}
+
+// Search выполняет полнотекстовый поиск по задачам, комментариям и документам
+func (s *SearchService) Search(ctx context.Context, query *models.SearchQuery) (*models.SearchResponse, error) {
+	query.Query = strings.TrimSpace(query.Query)
+	if query.Query == "" {
+		return nil, fmt.Errorf("%w: query is required", ErrInvalidSearchQuery)
+	}
+	if utf8.RuneCountInString(query.Query) > maxSearchQueryLen {
+		return nil, fmt.Errorf("%w: query is too long", ErrInvalidSearchQuery)
+	}
+
+	// По умолчанию поиск ведется по всем типам сущностей
+	if len(query.EntityTypes) == 0 {
+		query.EntityTypes = models.ValidSearchEntityTypes()
+	}
+	seen := make(map[string]bool)
+	entityTypes := make([]string, 0, len(query.EntityTypes))
+	for _, entityType := range query.EntityTypes {
+		if !models.IsValidSearchEntityType(entityType) {
+			return nil, fmt.Errorf("%w: unknown entity type %s", ErrInvalidSearchQuery, entityType)
+		}
+		if !seen[entityType] {
+			seen[entityType] = true
+			entityTypes = append(entityTypes, entityType)
+		}
+	}
+	query.EntityTypes = entityTypes
+
+	if query.Limit <= 0 {
+		query.Limit = defaultSearchLimit
+	}
+	if query.Limit > maxSearchLimit {
+		query.Limit = maxSearchLimit
+	}
+	if query.Offset < 0 {
+		return nil, fmt.Errorf("%w: offset must not be negative", ErrInvalidSearchQuery)
+	}
+
+	results, total, err := s.searchRepo.Search(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.SearchResponse{
+		Query:   query.Query,
+		Total:   total,
+		Limit:   query.Limit,
+		Offset:  query.Offset,
+		Results: results,
+	}, nil
+}
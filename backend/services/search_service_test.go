@@ -0,0 +1,27 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"project-manager/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSearchValidation(t *testing.T) {
+	service := NewSearchService(nil)
+	cases := map[string]*models.SearchQuery{
+		"empty query": {Query: "  "},
+		"long query":  {Query: strings.Repeat("q", maxSearchQueryLen+1)},
+		"entity type": {Query: "вход", EntityTypes: []string{"user"}},
+		"offset":      {Query: "вход", Offset: -1},
+	}
+	for name, query := range cases {
+		t.Run(name, func(t *testing.T) {
+			_, err := service.Search(context.Background(), query)
+			assert.ErrorIs(t, err, ErrInvalidSearchQuery)
+		})
+	}
+}
@@ -0,0 +1,270 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"project-manager/models"
+	"project-manager/repositories"
+)
+
+var (
+	ErrDependencyCycle    = errors.New("dependency would create a cycle")
+	ErrDependencyNotFound = errors.New("dependency not found")
+)
+
+type TaskDependencyService struct {
+	dependencyRepo *repositories.TaskDependencyRepository
+	taskRepo       *repositories.TaskRepository
+	logService     *OperationLogService
+	access         *AccessService
+	workflows      *WorkflowService
+}
+
+func NewTaskDependencyService(dependencyRepo *repositories.TaskDependencyRepository, taskRepo *repositories.TaskRepository, logService *OperationLogService, access *AccessService, workflows *WorkflowService) *TaskDependencyService {
+	return &TaskDependencyService{
+		dependencyRepo: dependencyRepo,
+		taskRepo:       taskRepo,
+		logService:     logService,
+		access:         access,
+		workflows:      workflows,
+	}
+}
+
+// AddDependency создает связь между задачами одного проекта.
+// Для связей blocks и duplicates запрещены циклы.
+func (s *TaskDependencyService) AddDependency(ctx context.Context, dependency *models.TaskDependency) error {
+	// Валидация обязательных полей
+	if strings.TrimSpace(dependency.TaskID) == "" {
+		return errors.New("task_id is required")
+	}
+	if strings.TrimSpace(dependency.DependsOnTaskID) == "" {
+		return errors.New("depends_on_task_id is required")
+	}
+	if dependency.Kind == "" {
+		dependency.Kind = string(models.DependencyKindBlocks)
+	}
+	if !models.IsValidDependencyKind(dependency.Kind) {
+		return errors.New("invalid dependency kind")
+	}
+	if dependency.TaskID == dependency.DependsOnTaskID {
+		return errors.New("task cannot depend on itself")
+	}
+
+	// Проверка существования задач
+	task, err := s.taskRepo.GetByID(ctx, dependency.TaskID)
+	if err != nil {
+		return err
+	}
+	if task == nil {
+		return errors.New("task not found")
+	}
+	dependsOn, err := s.taskRepo.GetByID(ctx, dependency.DependsOnTaskID)
+	if err != nil {
+		return err
+	}
+	if dependsOn == nil {
+		return errors.New("depends_on task not found")
+	}
+	if task.ProjectID != dependsOn.ProjectID {
+		return errors.New("tasks must belong to the same project")
+	}
+
+	if err := s.access.Authorize(ctx, task.ProjectID, models.PermissionEditTasks); err != nil {
+		return err
+	}
+
+	exists, err := s.dependencyRepo.Exists(ctx, dependency.TaskID, dependency.DependsOnTaskID, dependency.Kind)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return errors.New("dependency already exists")
+	}
+
+	switch models.DependencyKind(dependency.Kind) {
+	case models.DependencyKindRelatesTo:
+		// Связь relates_to симметрична, обратная связь ей эквивалентна
+		reverse, err := s.dependencyRepo.Exists(ctx, dependency.DependsOnTaskID, dependency.TaskID, dependency.Kind)
+		if err != nil {
+			return err
+		}
+		if reverse {
+			return errors.New("dependency already exists")
+		}
+	default:
+		if err := s.checkCycle(ctx, task.ProjectID, dependency); err != nil {
+			return err
+		}
+	}
+
+	dependency.CreatedBy = actorFromContext(ctx)
+	if err := s.dependencyRepo.Create(ctx, dependency); err != nil {
+		return err
+	}
+
+	// Логируем добавление связи
+	if s.logService != nil {
+		details := map[string]interface{}{
+			"dependency_added": map[string]interface{}{
+				"dependency_id":      dependency.ID,
+				"depends_on_task_id": dependency.DependsOnTaskID,
+				"depends_on_number":  dependsOn.Number,
+				"kind":               dependency.Kind,
+			},
+		}
+		s.logService.LogTaskOperation(ctx, task.ID, dependency.CreatedBy, string(models.OperationTypeUpdate), details)
+	}
+
+	return nil
+}
+
+// RemoveDependency удаляет связь, в которой участвует задача taskID
+func (s *TaskDependencyService) RemoveDependency(ctx context.Context, taskID, dependencyID string) error {
+	if strings.TrimSpace(dependencyID) == "" {
+		return errors.New("dependency id is required")
+	}
+
+	dependency, err := s.dependencyRepo.GetByID(ctx, dependencyID)
+	if err != nil {
+		return err
+	}
+	if dependency == nil || (dependency.TaskID != taskID && dependency.DependsOnTaskID != taskID) {
+		return ErrDependencyNotFound
+	}
+
+	task, err := s.taskRepo.GetByID(ctx, dependency.TaskID)
+	if err != nil {
+		return err
+	}
+	if task == nil {
+		return errors.New("task not found")
+	}
+
+	if err := s.access.Authorize(ctx, task.ProjectID, models.PermissionEditTasks); err != nil {
+		return err
+	}
+
+	if err := s.dependencyRepo.Delete(ctx, dependencyID); err != nil {
+		return err
+	}
+
+	// Логируем удаление связи
+	if s.logService != nil {
+		details := map[string]interface{}{
+			"dependency_removed": map[string]interface{}{
+				"dependency_id":      dependency.ID,
+				"depends_on_task_id": dependency.DependsOnTaskID,
+				"kind":               dependency.Kind,
+			},
+		}
+		s.logService.LogTaskOperation(ctx, task.ID, actorFromContext(ctx), string(models.OperationTypeUpdate), details)
+	}
+
+	return nil
+}
+
+// GetTaskDependencies возвращает все связи задачи (в обе стороны)
+func (s *TaskDependencyService) GetTaskDependencies(ctx context.Context, taskID string) ([]models.TaskDependency, error) {
+	if strings.TrimSpace(taskID) == "" {
+		return nil, errors.New("task_id is required")
+	}
+
+	task, err := s.taskRepo.GetByID(ctx, taskID)
+	if err != nil {
+		return nil, err
+	}
+	if task == nil {
+		return nil, errors.New("task not found")
+	}
+
+	return s.dependencyRepo.GetByTaskID(ctx, taskID)
+}
+
+// GetProjectGraph возвращает граф зависимостей задач проекта
+// с признаком готовности каждой задачи к началу работы
+func (s *TaskDependencyService) GetProjectGraph(ctx context.Context, projectID string) (*models.DependencyGraph, error) {
+	if strings.TrimSpace(projectID) == "" {
+		return nil, errors.New("project_id is required")
+	}
+
+	tasks, err := s.taskRepo.GetByProjectID(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+	dependencies, err := s.dependencyRepo.GetByProjectID(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+	workflow, err := s.workflows.GetWorkflow(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make(map[string]string, len(tasks))
+	for _, task := range tasks {
+		statuses[task.ID] = task.Status
+	}
+	blockers := blockersByTask(dependencies)
+
+	graph := &models.DependencyGraph{
+		ProjectID: projectID,
+		Nodes:     make([]models.DependencyGraphNode, 0, len(tasks)),
+		Edges:     dependencies,
+	}
+	for _, task := range tasks {
+		blockedBy := blockers[task.ID]
+		if blockedBy == nil {
+			blockedBy = []string{}
+		}
+		graph.Nodes = append(graph.Nodes, models.DependencyGraphNode{
+			TaskID:    task.ID,
+			Number:    task.Number,
+			Title:     task.Title,
+			Status:    task.Status,
+			Priority:  task.Priority,
+			BlockedBy: blockedBy,
+			Ready:     isTaskReady(task.ID, statuses, blockers, workflow),
+		})
+	}
+	return graph, nil
+}
+
+// GetValidDependencyKinds возвращает список видов связей
+func (s *TaskDependencyService) GetValidDependencyKinds() []string {
+	return models.ValidDependencyKinds()
+}
+
+// checkCycle проверяет, что новая связь не замыкает цикл среди связей того же вида
+func (s *TaskDependencyService) checkCycle(ctx context.Context, projectID string, dependency *models.TaskDependency) error {
+	dependencies, err := s.dependencyRepo.GetByProjectID(ctx, projectID)
+	if err != nil {
+		return err
+	}
+
+	graph := buildDependencyGraph(dependencies, models.DependencyKind(dependency.Kind))
+	cycle := graph.cycleWith(dependency.DependsOnTaskID, dependency.TaskID)
+	if cycle == nil {
+		return nil
+	}
+
+	// Для сообщения используем номера задач вместо идентификаторов
+	tasks, err := s.taskRepo.GetByProjectID(ctx, projectID)
+	if err != nil {
+		return err
+	}
+	numbers := make(map[string]string, len(tasks))
+	for _, task := range tasks {
+		numbers[task.ID] = task.Number
+	}
+	labels := make([]string, len(cycle))
+	for i, id := range cycle {
+		labels[i] = id
+		if number, ok := numbers[id]; ok {
+			labels[i] = number
+		}
+	}
+	return fmt.Errorf("%w: %s", ErrDependencyCycle, strings.Join(labels, " -> "))
+}
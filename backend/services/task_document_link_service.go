@@ -0,0 +1,203 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"project-manager/models"
+	"project-manager/repositories"
+	"project-manager/utils"
+)
+
+type TaskDocumentLinkService struct {
+	linkRepo     *repositories.TaskDocumentLinkRepository
+	taskRepo     *repositories.TaskRepository
+	documentRepo *repositories.DocumentRepository
+	logService   *OperationLogService
+	access       *AccessService
+}
+
+func NewTaskDocumentLinkService(linkRepo *repositories.TaskDocumentLinkRepository, taskRepo *repositories.TaskRepository, documentRepo *repositories.DocumentRepository, logService *OperationLogService, access *AccessService) *TaskDocumentLinkService {
+	return &TaskDocumentLinkService{
+		linkRepo:     linkRepo,
+		taskRepo:     taskRepo,
+		documentRepo: documentRepo,
+		logService:   logService,
+		access:       access,
+	}
+}
+
+// GetTaskLinks возвращает связи задачи с документами
+func (s *TaskDocumentLinkService) GetTaskLinks(ctx context.Context, taskID string) ([]models.TaskDocumentLink, error) {
+	if strings.TrimSpace(taskID) == "" {
+		return nil, errors.New("task_id is required")
+	}
+	return s.linkRepo.GetByTaskID(ctx, taskID)
+}
+
+// GetDocumentLinks возвращает связи документа с задачами
+func (s *TaskDocumentLinkService) GetDocumentLinks(ctx context.Context, documentID string) ([]models.TaskDocumentLink, error) {
+	if strings.TrimSpace(documentID) == "" {
+		return nil, errors.New("document_id is required")
+	}
+	return s.linkRepo.GetByDocumentID(ctx, documentID)
+}
+
+// GetLinkedDocuments возвращает связанные с задачей документы с содержимым
+// разделов. Если раздел переименован или удален, возвращается документ целиком.
+func (s *TaskDocumentLinkService) GetLinkedDocuments(ctx context.Context, taskID string) ([]models.LinkedDocument, error) {
+	links, err := s.linkRepo.GetByTaskID(ctx, taskID)
+	if err != nil {
+		return nil, err
+	}
+
+	documents := make(map[string]*models.Document)
+	linked := make([]models.LinkedDocument, 0, len(links))
+	for _, link := range links {
+		document, ok := documents[link.DocumentID]
+		if !ok {
+			if document, err = s.documentRepo.GetByID(ctx, link.DocumentID); err != nil {
+				return nil, err
+			}
+			documents[link.DocumentID] = document
+		}
+		if document == nil {
+			continue
+		}
+
+		item := models.LinkedDocument{
+			LinkID:        link.ID,
+			DocumentID:    document.ID,
+			Title:         document.Title,
+			Type:          document.Type,
+			SectionAnchor: link.SectionAnchor,
+			Content:       document.Content,
+		}
+		if link.SectionAnchor != "" {
+			if section := utils.FindMarkdownSection(document.Content, link.SectionAnchor); section != nil {
+				item.SectionTitle = section.Title
+				item.Content = section.Content
+			}
+		}
+		linked = append(linked, item)
+	}
+	return linked, nil
+}
+
+// CreateLink связывает задачу с документом того же проекта или с его разделом
+func (s *TaskDocumentLinkService) CreateLink(ctx context.Context, link *models.TaskDocumentLink) error {
+	if strings.TrimSpace(link.TaskID) == "" {
+		return errors.New("task_id is required")
+	}
+	if strings.TrimSpace(link.DocumentID) == "" {
+		return errors.New("document_id is required")
+	}
+	link.SectionAnchor = strings.TrimSpace(link.SectionAnchor)
+
+	task, err := s.taskRepo.GetByID(ctx, link.TaskID)
+	if err != nil {
+		return err
+	}
+	if task == nil {
+		return errors.New("task not found")
+	}
+
+	document, err := s.documentRepo.GetByID(ctx, link.DocumentID)
+	if err != nil {
+		return err
+	}
+	if document == nil {
+		return errors.New("document not found")
+	}
+	if document.ProjectID != task.ProjectID {
+		return errors.New("document belongs to another project")
+	}
+
+	if err := s.access.Authorize(ctx, task.ProjectID, models.PermissionEditTasks); err != nil {
+		return err
+	}
+
+	if link.SectionAnchor != "" && utils.FindMarkdownSection(document.Content, link.SectionAnchor) == nil {
+		return fmt.Errorf("%w: %s", utils.ErrSectionNotFound, link.SectionAnchor)
+	}
+
+	exists, err := s.linkRepo.Exists(ctx, link.TaskID, link.DocumentID, link.SectionAnchor)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return errors.New("task is already linked to this document section")
+	}
+
+	link.CreatedBy = actorFromContext(ctx)
+	if err := s.linkRepo.Create(ctx, link); err != nil {
+		return err
+	}
+	link.DocumentTitle = document.Title
+	link.DocumentType = document.Type
+	link.TaskNumber = task.Number
+	link.TaskTitle = task.Title
+	link.TaskStatus = task.Status
+
+	s.logLinkChange(ctx, link, nil, linkReference(link))
+	return nil
+}
+
+// DeleteLink удаляет связь. Непустые taskID и documentID ограничивают удаление
+// связями указанной задачи или документа.
+func (s *TaskDocumentLinkService) DeleteLink(ctx context.Context, linkID, taskID, documentID string) error {
+	if strings.TrimSpace(linkID) == "" {
+		return errors.New("link_id is required")
+	}
+
+	link, err := s.linkRepo.GetByID(ctx, linkID)
+	if err != nil {
+		return err
+	}
+	if link == nil || (taskID != "" && link.TaskID != taskID) || (documentID != "" && link.DocumentID != documentID) {
+		return errors.New("link not found")
+	}
+
+	task, err := s.taskRepo.GetByID(ctx, link.TaskID)
+	if err != nil {
+		return err
+	}
+	if task == nil {
+		return errors.New("task not found")
+	}
+
+	if err := s.access.Authorize(ctx, task.ProjectID, models.PermissionEditTasks); err != nil {
+		return err
+	}
+
+	if err := s.linkRepo.Delete(ctx, linkID); err != nil {
+		return err
+	}
+
+	s.logLinkChange(ctx, link, linkReference(link), nil)
+	return nil
+}
+
+// logLinkChange записывает изменение связей в историю задачи
+func (s *TaskDocumentLinkService) logLinkChange(ctx context.Context, link *models.TaskDocumentLink, before, after interface{}) {
+	if s.logService == nil {
+		return
+	}
+	details := map[string]interface{}{
+		"task_id":     link.TaskID,
+		"document_id": link.DocumentID,
+		"changes":     []models.FieldChange{{Field: "linkedDocuments", Before: before, After: after}},
+	}
+	s.logService.LogTaskOperation(ctx, link.TaskID, actorFromContext(ctx), string(models.OperationTypeUpdate), details)
+}
+
+// linkReference — краткое описание связи для истории изменений
+func linkReference(link *models.TaskDocumentLink) map[string]string {
+	return map[string]string{
+		"documentId":    link.DocumentID,
+		"documentTitle": link.DocumentTitle,
+		"sectionAnchor": link.SectionAnchor,
+	}
+}
@@ -0,0 +1,204 @@
+package services
+
+import (
+	"regexp"
+	"strings"
+
+	"project-manager/models"
+	"project-manager/utils"
+)
+
+var (
+	// draftChecklistPattern распознает пункт списка задач: "- [ ] Заголовок"
+	draftChecklistPattern = regexp.MustCompile(`^(\s*)[-*+]\s+\[([ xX])\]\s+(.+?)\s*$`)
+	// draftPrefixPattern выделяет префикс функционального блока: "API: Заголовок"
+	draftPrefixPattern = regexp.MustCompile(`^([A-Z]{1,6}):\s+(.+)$`)
+	// draftAttributePattern распознает строки описания с атрибутами задачи
+	draftAttributePattern = regexp.MustCompile(`(?i)^\s*(?:[-*]\s+)?(приоритет|priority|тип|type)\s*:\s*(.+?)\s*$`)
+)
+
+// draftTaskSections — якоря разделов, подразделы которых считаются задачами
+var draftTaskSections = []string{"задачи", "tasks"}
+
+// draftPriorityAliases сопоставляет английские названия приоритетов с принятыми в системе
+var draftPriorityAliases = map[string]models.TaskPriority{
+	"low":      models.TaskPriorityLow,
+	"medium":   models.TaskPriorityMedium,
+	"high":     models.TaskPriorityHigh,
+	"critical": models.TaskPriorityCritical,
+}
+
+// draftTypeAliases сопоставляет английские названия типов с принятыми в системе
+var draftTypeAliases = map[string]models.TaskType{
+	"feature":       models.TaskTypeNewFeature,
+	"bug":           models.TaskTypeBugfix,
+	"bugfix":        models.TaskTypeBugfix,
+	"improvement":   models.TaskTypeImprovement,
+	"refactoring":   models.TaskTypeRefactoring,
+	"documentation": models.TaskTypeDocumentation,
+	"docs":          models.TaskTypeDocumentation,
+}
+
+// parseTaskDrafts распознает задачи в Markdown-документе. Если в документе
+// есть список задач ("- [ ] ..."), каждый невыполненный пункт становится
+// задачей, а строки с отступом под ним — ее описанием. Иначе задачами
+// считаются подразделы раздела «Задачи» (или «Tasks»). Строки описания вида
+// "Приоритет: Высокий" и "Тип: Улучшение" задают атрибуты задачи, а префикс
+// заголовка "API: ..." — функциональный блок.
+func parseTaskDrafts(content string) []models.TaskDraft {
+	drafts := parseChecklistDrafts(utils.ParseMarkdownLines(content))
+	if len(drafts) == 0 {
+		drafts = parseHeadingDrafts(content)
+	}
+
+	for i := range drafts {
+		drafts[i].Index = i
+		applyDraftAttributes(&drafts[i])
+	}
+	return drafts
+}
+
+// parseChecklistDrafts собирает невыполненные пункты списков задач вне блоков кода
+func parseChecklistDrafts(lines []utils.MarkdownLine) []models.TaskDraft {
+	var drafts []models.TaskDraft
+
+	for i := 0; i < len(lines); i++ {
+		if lines[i].InCode {
+			continue
+		}
+		match := draftChecklistPattern.FindStringSubmatch(lines[i].Text)
+		if match == nil {
+			continue
+		}
+
+		// Описанием пункта служат следующие строки с большим отступом
+		indent := len(match[1])
+		var description []string
+		for i+1 < len(lines) {
+			next := lines[i+1]
+			if next.HeadingLevel > 0 || (!next.InCode && draftChecklistPattern.MatchString(next.Text)) {
+				break
+			}
+			trimmed := strings.TrimSpace(next.Text)
+			if trimmed != "" && lineIndent(next.Text) <= indent {
+				break
+			}
+			description = append(description, trimmed)
+			i++
+		}
+
+		// Выполненные пункты задачами не становятся
+		if match[2] != " " {
+			continue
+		}
+
+		drafts = append(drafts, models.TaskDraft{
+			Title:         match[3],
+			Description:   strings.TrimSpace(strings.Join(description, "\n")),
+			SectionAnchor: lines[i-len(description)].Section,
+			Line:          i - len(description) + 1,
+		})
+	}
+	return drafts
+}
+
+// parseHeadingDrafts превращает подразделы раздела задач в черновики
+func parseHeadingDrafts(content string) []models.TaskDraft {
+	var drafts []models.TaskDraft
+
+	var visit func(sections []utils.MarkdownSection) bool
+	visit = func(sections []utils.MarkdownSection) bool {
+		for _, section := range sections {
+			if isDraftTaskSection(section.Anchor) {
+				for _, child := range section.Children {
+					body := ""
+					if found := utils.FindMarkdownSection(content, child.Anchor); found != nil {
+						body = found.Content
+					}
+					drafts = append(drafts, models.TaskDraft{
+						Title:         child.Title,
+						Description:   strings.TrimSpace(body),
+						SectionAnchor: child.Anchor,
+						Line:          child.StartLine,
+					})
+				}
+				return true
+			}
+			if visit(section.Children) {
+				return true
+			}
+		}
+		return false
+	}
+	visit(utils.MarkdownSections(content))
+
+	return drafts
+}
+
+func isDraftTaskSection(anchor string) bool {
+	for _, name := range draftTaskSections {
+		if anchor == name {
+			return true
+		}
+	}
+	return false
+}
+
+// applyDraftAttributes выделяет из заголовка и описания префикс функционального
+// блока, приоритет и тип; нераспознанные значения попадают в Errors
+func applyDraftAttributes(draft *models.TaskDraft) {
+	if match := draftPrefixPattern.FindStringSubmatch(draft.Title); match != nil {
+		draft.FunctionalBlockPrefix = match[1]
+		draft.Title = match[2]
+	}
+
+	var description []string
+	for _, line := range strings.Split(draft.Description, "\n") {
+		match := draftAttributePattern.FindStringSubmatch(line)
+		if match == nil {
+			description = append(description, line)
+			continue
+		}
+
+		value := strings.TrimSpace(match[2])
+		switch strings.ToLower(match[1]) {
+		case "приоритет", "priority":
+			draft.Priority = normalizeDraftValue(value, models.ValidPriorities(), func(alias string) string {
+				return string(draftPriorityAliases[alias])
+			})
+			if draft.Priority == "" {
+				draft.Errors = append(draft.Errors, "invalid priority: "+value)
+			}
+		default:
+			draft.Type = normalizeDraftValue(value, models.ValidTypes(), func(alias string) string {
+				return string(draftTypeAliases[alias])
+			})
+			if draft.Type == "" {
+				draft.Errors = append(draft.Errors, "invalid type: "+value)
+			}
+		}
+	}
+	draft.Description = strings.TrimSpace(strings.Join(description, "\n"))
+
+	if draft.Priority == "" {
+		draft.Priority = string(models.TaskPriorityMedium)
+	}
+	if draft.Type == "" {
+		draft.Type = string(models.TaskTypeNewFeature)
+	}
+}
+
+// normalizeDraftValue находит допустимое значение без учета регистра или по псевдониму
+func normalizeDraftValue(value string, valid []string, alias func(string) string) string {
+	for _, candidate := range valid {
+		if strings.EqualFold(candidate, value) {
+			return candidate
+		}
+	}
+	return alias(strings.ToLower(value))
+}
+
+// lineIndent возвращает число пробельных символов в начале строки
+func lineIndent(line string) int {
+	return len(line) - len(strings.TrimLeft(line, " \t"))
+}
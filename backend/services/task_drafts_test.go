@@ -0,0 +1,66 @@
+package services
+
+import (
+	"testing"
+
+	"project-manager/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTaskDrafts_Checklist(t *testing.T) {
+	content := "# План\n\n" +
+		"## Бэкенд\n\n" +
+		"- [ ] API: Добавить эндпоинт экспорта\n" +
+		"  Экспорт проекта в JSON.\n" +
+		"  Приоритет: high\n" +
+		"- [x] Уже сделано\n" +
+		"- [ ] Исправить пагинацию\n" +
+		"  Тип: Исправление ошибки\n\n" +
+		"```\n- [ ] не задача\n```\n"
+
+	drafts := parseTaskDrafts(content)
+
+	require.Len(t, drafts, 2)
+	assert.Equal(t, 0, drafts[0].Index)
+	assert.Equal(t, "Добавить эндпоинт экспорта", drafts[0].Title)
+	assert.Equal(t, "API", drafts[0].FunctionalBlockPrefix)
+	assert.Equal(t, "Экспорт проекта в JSON.", drafts[0].Description)
+	assert.Equal(t, string(models.TaskPriorityHigh), drafts[0].Priority)
+	assert.Equal(t, string(models.TaskTypeNewFeature), drafts[0].Type)
+	assert.Equal(t, "бэкенд", drafts[0].SectionAnchor)
+	assert.Equal(t, 5, drafts[0].Line)
+
+	assert.Equal(t, "Исправить пагинацию", drafts[1].Title)
+	assert.Equal(t, string(models.TaskTypeBugfix), drafts[1].Type)
+	assert.Equal(t, string(models.TaskPriorityMedium), drafts[1].Priority)
+	assert.Empty(t, drafts[1].Description)
+	assert.Equal(t, 9, drafts[1].Line)
+}
+
+func TestParseTaskDrafts_Headings(t *testing.T) {
+	content := "# План выполнения\n\n" +
+		"## Контекст\n\nОписание.\n\n" +
+		"## Задачи\n\n" +
+		"### UI: Экран корзины\n\nСписок удаленных задач.\nПриоритет: срочно\n\n" +
+		"### Документация\n\nТип: docs\n\n" +
+		"## Критерии приемки\n"
+
+	drafts := parseTaskDrafts(content)
+
+	require.Len(t, drafts, 2)
+	assert.Equal(t, "Экран корзины", drafts[0].Title)
+	assert.Equal(t, "UI", drafts[0].FunctionalBlockPrefix)
+	assert.Equal(t, "Список удаленных задач.", drafts[0].Description)
+	assert.Equal(t, "ui-экран-корзины", drafts[0].SectionAnchor)
+	assert.Equal(t, []string{"invalid priority: срочно"}, drafts[0].Errors)
+
+	assert.Equal(t, "Документация", drafts[1].Title)
+	assert.Equal(t, string(models.TaskTypeDocumentation), drafts[1].Type)
+	assert.Empty(t, drafts[1].Errors)
+}
+
+func TestParseTaskDrafts_NoTasks(t *testing.T) {
+	assert.Empty(t, parseTaskDrafts("# Документ\n\nТекст без задач.\n"))
+}
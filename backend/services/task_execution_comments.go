@@ -0,0 +1,74 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"project-manager/models"
+)
+
+// executionCommentAuthor — идентификатор, от имени которого пишутся комментарии о выполнении
+const executionCommentAuthor = "AI-Agent"
+
+type TaskExecutionCommentService struct {
+	commentService *CommentService
+}
+
+func NewTaskExecutionCommentService(commentService *CommentService) *TaskExecutionCommentService {
+	return &TaskExecutionCommentService{
+		commentService: commentService,
+	}
+}
+
+// AddExecutionStartComment добавляет комментарий о начале выполнения задачи
+func (s *TaskExecutionCommentService) AddExecutionStartComment(ctx context.Context, taskID, executionID, strategy string) error {
+	content := fmt.Sprintf("🤖 Начато автоматическое выполнение задачи\n\n**ID выполнения:** %s\n**Стратегия:** %s\n**Время начала:** %s",
+		executionID, strategy, time.Now().Format("15:04:05"))
+
+	return s.addComment(ctx, taskID, content)
+}
+
+// AddExecutionProgressComment добавляет комментарий о прогрессе выполнения
+func (s *TaskExecutionCommentService) AddExecutionProgressComment(ctx context.Context, taskID, executionID, step string, progress int) error {
+	content := fmt.Sprintf("⚙️ Прогресс выполнения: %d%%\n\n**Текущий этап:** %s\n**ID выполнения:** %s", progress, step, executionID)
+
+	return s.addComment(ctx, taskID, content)
+}
+
+// AddExecutionCompleteComment добавляет комментарий о завершении выполнения
+func (s *TaskExecutionCommentService) AddExecutionCompleteComment(ctx context.Context, taskID, executionID string, success bool, executionTime int64, result string) error {
+	var icon, status string
+	if success {
+		icon = "✅"
+		status = "успешно завершено"
+	} else {
+		icon = "❌"
+		status = "завершено с ошибкой"
+	}
+
+	content := fmt.Sprintf("%s Выполнение задачи %s\n\n**ID выполнения:** %s\n**Время выполнения:** %d мс\n**Результат:** %s",
+		icon, status, executionID, executionTime, result)
+
+	return s.addComment(ctx, taskID, content)
+}
+
+// AddExecutionErrorComment добавляет комментарий об ошибке выполнения
+func (s *TaskExecutionCommentService) AddExecutionErrorComment(ctx context.Context, taskID, executionID, errorMsg string) error {
+	content := fmt.Sprintf("⚠️ Ошибка при выполнении задачи\n\n**ID выполнения:** %s\n**Ошибка:** %s\n**Время:** %s",
+		executionID, errorMsg, time.Now().Format("15:04:05"))
+
+	return s.addComment(ctx, taskID, content)
+}
+
+func (s *TaskExecutionCommentService) addComment(ctx context.Context, taskID, content string) error {
+	comment := &models.Comment{
+		TaskID:         taskID,
+		Type:           string(models.CommentTypeSystem),
+		UserIdentifier: executionCommentAuthor,
+		Content:        content,
+	}
+
+	// Комментарии о выполнении пишутся от имени агента, а не автора запуска
+	return s.commentService.CreateComment(withoutPrincipal(ctx), comment)
+}
@@ -0,0 +1,629 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"project-manager/models"
+	"project-manager/repositories"
+	"project-manager/utils"
+)
+
+var (
+	ErrExecutionNotFound       = errors.New("execution not found")
+	ErrExecutionNotCancellable = errors.New("execution cannot be cancelled in current state")
+)
+
+const (
+	// executionTimeout ограничивает длительность одного выполнения
+	executionTimeout = 5 * time.Minute
+	// executionPersistTimeout ограничивает запись состояния выполнения в БД
+	executionPersistTimeout = 5 * time.Second
+	// executionLease — срок, после которого активное выполнение считается
+	// брошенным: запустивший его сервер завершил бы его по тайм-ауту раньше
+	executionLease = executionTimeout + time.Minute
+	// executionRecoveryInterval — период поиска брошенных выполнений
+	executionRecoveryInterval = time.Minute
+	maxCommandLength          = 500
+)
+
+var (
+	executionUUIDRegex   = regexp.MustCompile(`[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}`)
+	executionNumberRegex = regexp.MustCompile(`\b([A-Za-z]{1,6}-\d+)\b`)
+	executionQuotedRegex = regexp.MustCompile(`["«“]([^"»”]+)["»”]`)
+	executionDigitsRegex = regexp.MustCompile(`\b(\d+)\b`)
+)
+
+var validExecutionSources = map[string]bool{
+	"web":    true,
+	"vscode": true,
+	"api":    true,
+}
+
+// ExecutionContext описывает параметры запуска выполнения задачи
+type ExecutionContext struct {
+	Command   string
+	Source    string
+	UserID    string
+	ProjectID *string
+}
+
+// taskReference — ссылка на задачу, извлеченная из текстовой команды
+type taskReference struct {
+	ID     string
+	Number string
+	Title  string
+}
+
+type TaskExecutionService struct {
+	executionRepo  *repositories.TaskExecutionRepository
+	taskRepo       *repositories.TaskRepository
+	projectRepo    *repositories.ProjectRepository
+	taskService    *TaskService
+	commentService *TaskExecutionCommentService
+	access         *AccessService
+	workflows      *WorkflowService
+
+	mu      sync.Mutex
+	running map[string]context.CancelFunc
+}
+
+func NewTaskExecutionService(executionRepo *repositories.TaskExecutionRepository, taskRepo *repositories.TaskRepository, projectRepo *repositories.ProjectRepository, taskService *TaskService, commentService *TaskExecutionCommentService, access *AccessService, workflows *WorkflowService) *TaskExecutionService {
+	return &TaskExecutionService{
+		executionRepo:  executionRepo,
+		taskRepo:       taskRepo,
+		projectRepo:    projectRepo,
+		taskService:    taskService,
+		commentService: commentService,
+		access:         access,
+		workflows:      workflows,
+		running:        make(map[string]context.CancelFunc),
+	}
+}
+
+// RunRecovery периодически завершает брошенные выполнения до отмены контекста
+func (s *TaskExecutionService) RunRecovery(ctx context.Context) {
+	ticker := time.NewTicker(executionRecoveryInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := s.RecoverInterruptedExecutions(ctx); err != nil && ctx.Err() == nil {
+			utils.Warn("Failed to recover interrupted task executions", map[string]interface{}{
+				"error": err.Error(),
+			})
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RecoverInterruptedExecutions завершает выполнения, прерванные остановкой
+// сервера. Выполнения, запущенные позже executionLease назад, могут еще
+// работать на другом экземпляре сервера и не затрагиваются.
+func (s *TaskExecutionService) RecoverInterruptedExecutions(ctx context.Context) error {
+	count, err := s.executionRepo.FailActive(ctx, "execution interrupted by server shutdown", time.Now().Add(-executionLease))
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		utils.Warn("Interrupted task executions marked as failed", map[string]interface{}{
+			"count": count,
+		})
+	}
+	return nil
+}
+
+// StartExecution создает запись о выполнении и запускает его в фоне
+func (s *TaskExecutionService) StartExecution(ctx context.Context, execCtx *ExecutionContext) (string, error) {
+	if execCtx == nil {
+		return "", errors.New("execution context is required")
+	}
+
+	command := strings.TrimSpace(execCtx.Command)
+	if command == "" || utf8.RuneCountInString(command) > maxCommandLength {
+		return "", fmt.Errorf("command must be between 1 and %d characters", maxCommandLength)
+	}
+
+	source := execCtx.Source
+	if source == "" {
+		source = "api"
+	}
+	if !validExecutionSources[source] {
+		return "", errors.New("invalid source")
+	}
+
+	// Проверка существования проекта (если указан)
+	if execCtx.ProjectID != nil && *execCtx.ProjectID != "" {
+		project, err := s.projectRepo.GetByID(ctx, *execCtx.ProjectID)
+		if err != nil {
+			return "", err
+		}
+		if project == nil {
+			return "", errors.New("project not found")
+		}
+		if err := s.access.Authorize(ctx, *execCtx.ProjectID, models.PermissionEditTasks); err != nil {
+			return "", err
+		}
+	} else {
+		execCtx.ProjectID = nil
+	}
+
+	// Запуск привязывается к аутентифицированному пользователю
+	if principal := PrincipalFromContext(ctx); principal != nil {
+		execCtx.UserID = principal.Username
+	}
+
+	execution := &models.TaskExecution{
+		ProjectID: execCtx.ProjectID,
+		Command:   command,
+		Source:    source,
+		UserID:    execCtx.UserID,
+		Status:    string(models.TaskExecutionStatusPending),
+		Message:   "Execution queued",
+	}
+
+	if err := s.executionRepo.Create(ctx, execution); err != nil {
+		return "", err
+	}
+
+	// Фоновое выполнение переживает запрос, но операции записываются от имени его автора
+	runCtx, cancel := context.WithTimeout(detachedContext(ctx), executionTimeout)
+	s.mu.Lock()
+	s.running[execution.ID] = cancel
+	s.mu.Unlock()
+
+	go s.run(runCtx, execution)
+
+	return execution.ID, nil
+}
+
+func (s *TaskExecutionService) GetExecutionStatus(ctx context.Context, id string) (*models.TaskExecution, error) {
+	if strings.TrimSpace(id) == "" {
+		return nil, errors.New("id is required")
+	}
+
+	execution, err := s.executionRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if execution == nil {
+		return nil, ErrExecutionNotFound
+	}
+	return execution, nil
+}
+
+// GetExecutionHistory возвращает выполнения пользователя userID; доступ
+// определяется executionOwner
+func (s *TaskExecutionService) GetExecutionHistory(ctx context.Context, userID string, limit, offset int) ([]models.TaskExecution, int, error) {
+	userID, err := executionOwner(ctx, userID)
+	if err != nil {
+		return nil, 0, err
+	}
+	return s.executionRepo.GetHistory(ctx, userID, limit, offset)
+}
+
+func (s *TaskExecutionService) GetActiveExecutionsForTask(ctx context.Context, taskID string) ([]models.TaskExecution, error) {
+	if strings.TrimSpace(taskID) == "" {
+		return nil, errors.New("task_id is required")
+	}
+	return s.executionRepo.GetActiveByTaskID(ctx, taskID)
+}
+
+// GetExecutionMetrics возвращает статистику выполнений пользователя userID;
+// доступ определяется executionOwner
+func (s *TaskExecutionService) GetExecutionMetrics(ctx context.Context, userID string, days int) (*models.TaskExecutionMetrics, error) {
+	userID, err := executionOwner(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	since := time.Now().AddDate(0, 0, -days)
+	return s.executionRepo.GetMetrics(ctx, userID, since)
+}
+
+// executionOwner определяет, чьи выполнения запрошены. Свои выполнения видит
+// любой пользователь, чужие и выполнения всех пользователей (пустой userID) —
+// только администратор. Без userID обычный пользователь получает свои.
+func executionOwner(ctx context.Context, userID string) (string, error) {
+	principal := PrincipalFromContext(ctx)
+	if principal == nil || principal.IsAdmin || userID == principal.Username {
+		return userID, nil
+	}
+	if userID == "" {
+		return principal.Username, nil
+	}
+	return "", ErrForbidden
+}
+
+// CancelExecution отменяет активное выполнение
+func (s *TaskExecutionService) CancelExecution(ctx context.Context, id string) error {
+	execution, err := s.GetExecutionStatus(ctx, id)
+	if err != nil {
+		return err
+	}
+	if !execution.IsActive() {
+		return ErrExecutionNotCancellable
+	}
+
+	// Отменить выполнение может его автор или участник проекта с правом на задачи
+	if principal := PrincipalFromContext(ctx); principal != nil && principal.Username != execution.UserID {
+		projectID := ""
+		if execution.ProjectID != nil {
+			projectID = *execution.ProjectID
+		}
+		if err := s.access.Authorize(ctx, projectID, models.PermissionEditTasks); err != nil {
+			return err
+		}
+	}
+
+	cancelled, err := s.executionRepo.Cancel(ctx, id, "Execution cancelled by user")
+	if err != nil {
+		return err
+	}
+	if !cancelled {
+		return ErrExecutionNotCancellable
+	}
+
+	s.mu.Lock()
+	if cancel, ok := s.running[id]; ok {
+		cancel()
+	}
+	s.mu.Unlock()
+
+	if execution.TaskID != nil {
+		s.comment(func(ctx context.Context) error {
+			return s.commentService.AddExecutionErrorComment(ctx, *execution.TaskID, execution.ID, "выполнение отменено пользователем")
+		})
+	}
+
+	return nil
+}
+
+// run последовательно выполняет этапы запуска задачи
+func (s *TaskExecutionService) run(ctx context.Context, execution *models.TaskExecution) {
+	defer s.release(execution.ID)
+	defer func() {
+		if r := recover(); r != nil {
+			s.fail(execution, fmt.Errorf("execution panicked: %v", r))
+		}
+	}()
+
+	execution.Status = string(models.TaskExecutionStatusRunning)
+	execution.Message = "Execution started"
+	if !s.save(execution) {
+		return
+	}
+
+	var ref taskReference
+	var task *models.Task
+	var workflow *models.Workflow
+
+	steps := []struct {
+		id          string
+		name        string
+		description string
+		fn          func(ctx context.Context) error
+	}{
+		{"parse", "Разбор команды", "Определение задачи из текста команды", func(ctx context.Context) error {
+			var ok bool
+			ref, ok = parseTaskReference(execution.Command)
+			if !ok {
+				return errors.New("could not determine task from command")
+			}
+			return nil
+		}},
+		{"resolve", "Поиск задачи", "Поиск задачи в базе данных", func(ctx context.Context) error {
+			var err error
+			task, err = s.resolveTask(ctx, ref, execution.ProjectID)
+			if err != nil {
+				return err
+			}
+			execution.TaskID = &task.ID
+			execution.TaskTitle = task.Title
+			return nil
+		}},
+		{"validate", "Проверка задачи", "Проверка возможности выполнения задачи", func(ctx context.Context) error {
+			var err error
+			if workflow, err = s.workflows.GetWorkflow(ctx, task.ProjectID); err != nil {
+				return err
+			}
+			if !canExecuteTaskStatus(workflow, task.Status) {
+				return fmt.Errorf("task in status %q cannot be executed", task.Status)
+			}
+			active, err := s.executionRepo.GetActiveByTaskID(ctx, task.ID)
+			if err != nil {
+				return err
+			}
+			for _, other := range active {
+				if other.ID != execution.ID {
+					return errors.New("task is already being executed")
+				}
+			}
+			return nil
+		}},
+		{"strategy", "Выбор стратегии", "Выбор стратегии выполнения по типу задачи", func(ctx context.Context) error {
+			execution.Strategy = string(chooseExecutionStrategy(task))
+			return nil
+		}},
+		{"start", "Перевод задачи в работу", "Установка статуса задачи и уведомление исполнителя", func(ctx context.Context) error {
+			if start := executionStartState(workflow); task.Status == workflow.InitialState && start != "" {
+				updated := *task
+				updated.Status = start
+				if err := s.taskService.UpdateTask(ctx, &updated); err != nil {
+					return err
+				}
+				task = &updated
+			}
+			s.comment(func(ctx context.Context) error {
+				return s.commentService.AddExecutionStartComment(ctx, task.ID, execution.ID, execution.Strategy)
+			})
+			return nil
+		}},
+	}
+
+	for i, step := range steps {
+		if err := ctx.Err(); err != nil {
+			s.interrupt(execution, err)
+			return
+		}
+
+		current := models.TaskExecutionStep{
+			ID:          step.id,
+			Name:        step.name,
+			Status:      string(models.TaskExecutionStatusRunning),
+			StartTime:   time.Now(),
+			Description: step.description,
+		}
+		execution.Steps = append(execution.Steps, current)
+		execution.Message = step.name
+		if !s.save(execution) {
+			return
+		}
+
+		err := step.fn(ctx)
+
+		now := time.Now()
+		last := &execution.Steps[len(execution.Steps)-1]
+		last.EndTime = &now
+		if err != nil {
+			last.Status = string(models.TaskExecutionStatusFailed)
+			last.Error = err.Error()
+			s.fail(execution, err)
+			return
+		}
+
+		last.Status = string(models.TaskExecutionStatusCompleted)
+		execution.Progress = (i + 1) * 100 / len(steps)
+		execution.Logs = append(execution.Logs, fmt.Sprintf("[%s] %s: ok", now.Format("15:04:05"), step.name))
+		if !s.save(execution) {
+			return
+		}
+	}
+
+	finished := time.Now()
+	execution.Status = string(models.TaskExecutionStatusCompleted)
+	execution.Progress = 100
+	execution.Message = "Task handed over for execution"
+	execution.FinishedAt = &finished
+	execution.Result = map[string]interface{}{
+		"task_id":     task.ID,
+		"task_number": task.Number,
+		"task_title":  task.Title,
+		"task_status": task.Status,
+		"strategy":    execution.Strategy,
+	}
+	if !s.save(execution) {
+		return
+	}
+
+	s.comment(func(ctx context.Context) error {
+		return s.commentService.AddExecutionCompleteComment(ctx, task.ID, execution.ID, true,
+			finished.Sub(execution.StartedAt).Milliseconds(), "задача переведена в статус «"+task.Status+"»")
+	})
+}
+
+// interrupt обрабатывает остановку выполнения по отмене или тайм-ауту
+func (s *TaskExecutionService) interrupt(execution *models.TaskExecution, err error) {
+	if errors.Is(err, context.DeadlineExceeded) {
+		s.fail(execution, errors.New("execution timed out"))
+	}
+	// При отмене состояние уже сохранено в CancelExecution
+}
+
+// fail фиксирует ошибку выполнения
+func (s *TaskExecutionService) fail(execution *models.TaskExecution, err error) {
+	finished := time.Now()
+	execution.Status = string(models.TaskExecutionStatusFailed)
+	execution.Error = err.Error()
+	execution.Message = "Execution failed"
+	execution.FinishedAt = &finished
+	execution.Logs = append(execution.Logs, fmt.Sprintf("[%s] error: %s", finished.Format("15:04:05"), err.Error()))
+
+	if !s.save(execution) {
+		return
+	}
+
+	if execution.TaskID != nil {
+		s.comment(func(ctx context.Context) error {
+			return s.commentService.AddExecutionErrorComment(ctx, *execution.TaskID, execution.ID, err.Error())
+		})
+	}
+}
+
+// save сохраняет состояние выполнения. Возвращает false, если выполнение
+// больше не активно (например, было отменено) или запись не удалась.
+func (s *TaskExecutionService) save(execution *models.TaskExecution) bool {
+	ctx, cancel := context.WithTimeout(context.Background(), executionPersistTimeout)
+	defer cancel()
+
+	saved, err := s.executionRepo.UpdateProgress(ctx, execution)
+	if err != nil {
+		utils.Error("Failed to persist task execution", map[string]interface{}{
+			"execution_id": execution.ID,
+			"error":        err.Error(),
+		})
+		return false
+	}
+	return saved
+}
+
+// comment добавляет служебный комментарий, не прерывая выполнение при ошибке
+func (s *TaskExecutionService) comment(add func(ctx context.Context) error) {
+	if s.commentService == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), executionPersistTimeout)
+	defer cancel()
+
+	if err := add(ctx); err != nil {
+		utils.Warn("Failed to add task execution comment", map[string]interface{}{
+			"error": err.Error(),
+		})
+	}
+}
+
+func (s *TaskExecutionService) release(id string) {
+	s.mu.Lock()
+	if cancel, ok := s.running[id]; ok {
+		cancel()
+		delete(s.running, id)
+	}
+	s.mu.Unlock()
+}
+
+// resolveTask находит задачу по ссылке из команды
+func (s *TaskExecutionService) resolveTask(ctx context.Context, ref taskReference, projectID *string) (*models.Task, error) {
+	var task *models.Task
+	var err error
+
+	switch {
+	case ref.ID != "":
+		task, err = s.taskRepo.GetByID(ctx, ref.ID)
+	case ref.Number != "":
+		task, err = s.taskRepo.GetByNumber(ctx, ref.Number)
+	case ref.Title != "":
+		task, err = s.findTaskByTitle(ctx, ref.Title, projectID)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if task == nil {
+		return nil, errors.New("task not found")
+	}
+	if projectID != nil && task.ProjectID != *projectID {
+		return nil, errors.New("task does not belong to the specified project")
+	}
+	return task, nil
+}
+
+// findTaskByTitle ищет задачу проекта по названию. Без проекта поиск по
+// названию не выполняется: он затронул бы задачи всех проектов.
+func (s *TaskExecutionService) findTaskByTitle(ctx context.Context, title string, projectID *string) (*models.Task, error) {
+	if projectID == nil {
+		return nil, errors.New("project_id is required to find a task by title")
+	}
+	tasks, err := s.taskRepo.GetByProjectID(ctx, *projectID)
+	if err != nil {
+		return nil, err
+	}
+	return matchTaskByTitle(tasks, title)
+}
+
+// matchTaskByTitle выбирает задачу по точному, а при его отсутствии — по
+// частичному совпадению названия без учета регистра. Если подходят несколько
+// задач, возвращается ошибка: выполнять нужно однозначно указанную задачу.
+func matchTaskByTitle(tasks []models.Task, title string) (*models.Task, error) {
+	needle := strings.ToLower(strings.TrimSpace(title))
+	var exact, partial []*models.Task
+	for i := range tasks {
+		candidate := strings.ToLower(tasks[i].Title)
+		if candidate == needle {
+			exact = append(exact, &tasks[i])
+		} else if strings.Contains(candidate, needle) {
+			partial = append(partial, &tasks[i])
+		}
+	}
+
+	matches := exact
+	if len(matches) == 0 {
+		matches = partial
+	}
+	switch len(matches) {
+	case 0:
+		return nil, nil
+	case 1:
+		return matches[0], nil
+	}
+	numbers := make([]string, 0, len(matches))
+	for _, task := range matches {
+		numbers = append(numbers, task.Number)
+	}
+	return nil, fmt.Errorf("several tasks match title %q: %s; specify the task number", title, strings.Join(numbers, ", "))
+}
+
+// parseTaskReference извлекает ссылку на задачу из команды вида
+// «Выполнить задачу TASK-000123», «Выполнить задачу 123» или «Выполнить задачу "Название"»
+func parseTaskReference(command string) (taskReference, bool) {
+	if id := executionUUIDRegex.FindString(command); id != "" {
+		return taskReference{ID: strings.ToLower(id)}, true
+	}
+	if match := executionQuotedRegex.FindStringSubmatch(command); match != nil {
+		return taskReference{Title: strings.TrimSpace(match[1])}, true
+	}
+	if match := executionNumberRegex.FindStringSubmatch(command); match != nil {
+		return taskReference{Number: strings.ToUpper(match[1])}, true
+	}
+	if match := executionDigitsRegex.FindStringSubmatch(command); match != nil {
+		return taskReference{Number: models.DefaultTaskNumberPrefix + "-" + match[1]}, true
+	}
+	return taskReference{}, false
+}
+
+// CanExecuteTask проверяет, можно ли запускать выполнение задачи в ее текущем статусе
+func (s *TaskExecutionService) CanExecuteTask(ctx context.Context, task *models.Task) (bool, error) {
+	workflow, err := s.workflows.GetWorkflow(ctx, task.ProjectID)
+	if err != nil {
+		return false, err
+	}
+	return canExecuteTaskStatus(workflow, task.Status), nil
+}
+
+// canExecuteTaskStatus проверяет, можно ли запускать выполнение задачи в данном
+// статусе: в начальном статусе процесса или в статусе, в который ее переводит выполнение
+func canExecuteTaskStatus(workflow *models.Workflow, status string) bool {
+	if status == workflow.InitialState {
+		return true
+	}
+	start := executionStartState(workflow)
+	return start != "" && status == start
+}
+
+// executionStartState возвращает статус, в который выполнение переводит задачу
+// из начального: цель первого перехода из InitialState в неконечный статус.
+// Пустая строка — процесс не позволяет взять задачу в работу.
+func executionStartState(workflow *models.Workflow) string {
+	for _, transition := range workflow.Transitions {
+		if transition.From == workflow.InitialState && transition.To != workflow.InitialState && !workflow.IsTerminal(transition.To) {
+			return transition.To
+		}
+	}
+	return ""
+}
+
+// chooseExecutionStrategy выбирает стратегию выполнения по типу задачи
+func chooseExecutionStrategy(task *models.Task) models.TaskExecutionStrategy {
+	if task.Type == string(models.TaskTypeBugfix) {
+		return models.TaskExecutionStrategyBugFix
+	}
+	return models.TaskExecutionStrategyDevelopment
+}
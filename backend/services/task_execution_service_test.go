@@ -0,0 +1,155 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"project-manager/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTaskReference(t *testing.T) {
+	cases := []struct {
+		name    string
+		command string
+		want    taskReference
+		ok      bool
+	}{
+		{"uuid", "Выполнить задачу 3F2504E0-4F89-11D3-9A0C-0305E82C3301", taskReference{ID: "3f2504e0-4f89-11d3-9a0c-0305e82c3301"}, true},
+		{"uuid wins over number", "Выполнить TASK-1 3f2504e0-4f89-11d3-9a0c-0305e82c3301", taskReference{ID: "3f2504e0-4f89-11d3-9a0c-0305e82c3301"}, true},
+		{"quoted title", `Выполнить задачу "Настроить CI"`, taskReference{Title: "Настроить CI"}, true},
+		{"guillemets", "Выполнить задачу « Экспорт в PDF »", taskReference{Title: "Экспорт в PDF"}, true},
+		{"quoted title with number", `Выполнить задачу "Исправить API-42"`, taskReference{Title: "Исправить API-42"}, true},
+		{"prefix number", "Выполнить задачу task-000123", taskReference{Number: "TASK-000123"}, true},
+		{"custom prefix", "Выполнить задачу AUTH-7", taskReference{Number: "AUTH-7"}, true},
+		{"bare digits", "Выполнить задачу 123", taskReference{Number: models.DefaultTaskNumberPrefix + "-123"}, true},
+		{"no reference", "Выполнить задачу", taskReference{}, false},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			ref, ok := parseTaskReference(tc.command)
+			assert.Equal(t, tc.ok, ok)
+			assert.Equal(t, tc.want, ref)
+		})
+	}
+}
+
+func TestCanExecuteTaskStatus(t *testing.T) {
+	defaultWorkflow := models.DefaultWorkflow()
+	custom := &models.Workflow{
+		InitialState:   "backlog",
+		States:         []string{"backlog", "rejected", "doing", "review", "closed"},
+		TerminalStates: []string{"rejected", "closed"},
+		Transitions: []models.WorkflowTransition{
+			{From: "backlog", To: "rejected"},
+			{From: "backlog", To: "doing"},
+			{From: "doing", To: "review"},
+			{From: "review", To: "closed"},
+		},
+	}
+
+	cases := []struct {
+		name     string
+		workflow *models.Workflow
+		status   string
+		want     bool
+	}{
+		{"default new", defaultWorkflow, string(models.TaskStatusNew), true},
+		{"default in progress", defaultWorkflow, string(models.TaskStatusInProgress), true},
+		{"default testing", defaultWorkflow, string(models.TaskStatusTesting), false},
+		{"default done", defaultWorkflow, string(models.TaskStatusDone), false},
+		{"default cancelled", defaultWorkflow, string(models.TaskStatusCancelled), false},
+		{"custom initial", custom, "backlog", true},
+		{"custom start state", custom, "doing", true},
+		{"custom review", custom, "review", false},
+		{"custom terminal", custom, "rejected", false},
+		{"custom default status", custom, string(models.TaskStatusNew), false},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, canExecuteTaskStatus(tc.workflow, tc.status))
+		})
+	}
+}
+
+func TestExecutionStartState(t *testing.T) {
+	assert.Equal(t, string(models.TaskStatusInProgress), executionStartState(models.DefaultWorkflow()))
+
+	// Из начального статуса есть только переход в конечный
+	closedOnly := &models.Workflow{
+		InitialState:   "open",
+		States:         []string{"open", "closed"},
+		TerminalStates: []string{"closed"},
+		Transitions:    []models.WorkflowTransition{{From: "open", To: "closed"}},
+	}
+	assert.Empty(t, executionStartState(closedOnly))
+	assert.True(t, canExecuteTaskStatus(closedOnly, "open"))
+	assert.False(t, canExecuteTaskStatus(closedOnly, "closed"))
+}
+
+func TestChooseExecutionStrategy(t *testing.T) {
+	cases := []struct {
+		taskType models.TaskType
+		want     models.TaskExecutionStrategy
+	}{
+		{models.TaskTypeBugfix, models.TaskExecutionStrategyBugFix},
+		{models.TaskTypeNewFeature, models.TaskExecutionStrategyDevelopment},
+		{models.TaskTypeImprovement, models.TaskExecutionStrategyDevelopment},
+		{"", models.TaskExecutionStrategyDevelopment},
+	}
+	for _, tc := range cases {
+		t.Run(string(tc.taskType), func(t *testing.T) {
+			assert.Equal(t, tc.want, chooseExecutionStrategy(&models.Task{Type: string(tc.taskType)}))
+		})
+	}
+}
+
+func TestMatchTaskByTitle(t *testing.T) {
+	tasks := []models.Task{
+		{ID: "1", Number: "TASK-1", Title: "Экспорт в PDF"},
+		{ID: "2", Number: "TASK-2", Title: "Экспорт в PDF для отчетов"},
+		{ID: "3", Number: "TASK-3", Title: "Импорт из CSV"},
+	}
+
+	task, err := matchTaskByTitle(tasks, "экспорт в pdf")
+	require.NoError(t, err)
+	assert.Equal(t, "1", task.ID)
+
+	task, err = matchTaskByTitle(tasks, "CSV")
+	require.NoError(t, err)
+	assert.Equal(t, "3", task.ID)
+
+	// Несколько частичных совпадений — задача не определена однозначно
+	_, err = matchTaskByTitle(tasks, "PDF")
+	assert.ErrorContains(t, err, "TASK-1, TASK-2")
+
+	task, err = matchTaskByTitle(tasks, "Настроить CI")
+	require.NoError(t, err)
+	assert.Nil(t, task)
+}
+
+func TestExecutionOwner(t *testing.T) {
+	user := WithPrincipal(context.Background(), &models.Principal{UserID: "u-1", Username: "ivan"})
+	admin := WithPrincipal(context.Background(), &models.Principal{UserID: "u-2", Username: "root", IsAdmin: true})
+
+	owner, err := executionOwner(user, "ivan")
+	require.NoError(t, err)
+	assert.Equal(t, "ivan", owner)
+
+	owner, err = executionOwner(user, "")
+	require.NoError(t, err)
+	assert.Equal(t, "ivan", owner)
+
+	_, err = executionOwner(user, "petr")
+	assert.ErrorIs(t, err, ErrForbidden)
+
+	owner, err = executionOwner(admin, "")
+	require.NoError(t, err)
+	assert.Empty(t, owner)
+
+	owner, err = executionOwner(admin, "petr")
+	require.NoError(t, err)
+	assert.Equal(t, "petr", owner)
+}
@@ -9,22 +9,53 @@ import (
 	"project-manager/repositories"
 )
 
+// ErrTaskNotFound возвращается, если задачи нет или она находится в корзине
+var ErrTaskNotFound = errors.New("task not found")
+
+// ErrInvalidCursor возвращается при передаче поврежденного курсора пагинации
+var ErrInvalidCursor = repositories.ErrInvalidCursor
+
+const (
+	defaultTaskPageSize = 50
+	maxTaskPageSize     = 500
+)
+
 type TaskService struct {
-	taskRepo    *repositories.TaskRepository
-	projectRepo *repositories.ProjectRepository
-	fbRepo      *repositories.FunctionalBlockRepository
-	logService  *OperationLogService
+	taskRepo      *repositories.TaskRepository
+	projectRepo   *repositories.ProjectRepository
+	fbRepo        *repositories.FunctionalBlockRepository
+	executorRepo  *repositories.ExecutorRepository
+	logService    *OperationLogService
+	access        *AccessService
+	workflows     *WorkflowService
+	notifications *NotificationService
+	events        *EventBroker
 }
 
-func NewTaskService(taskRepo *repositories.TaskRepository, projectRepo *repositories.ProjectRepository, fbRepo *repositories.FunctionalBlockRepository, logService *OperationLogService) *TaskService {
+func NewTaskService(taskRepo *repositories.TaskRepository, projectRepo *repositories.ProjectRepository, fbRepo *repositories.FunctionalBlockRepository, executorRepo *repositories.ExecutorRepository, logService *OperationLogService, access *AccessService, workflows *WorkflowService, notifications *NotificationService, events *EventBroker) *TaskService {
 	return &TaskService{
-		taskRepo:    taskRepo,
-		projectRepo: projectRepo,
-		fbRepo:      fbRepo,
-		logService:  logService,
+		taskRepo:      taskRepo,
+		projectRepo:   projectRepo,
+		fbRepo:        fbRepo,
+		executorRepo:  executorRepo,
+		logService:    logService,
+		access:        access,
+		workflows:     workflows,
+		notifications: notifications,
+		events:        events,
 	}
 }
 
+// publishTaskEvent сообщает подписчикам об изменении задачи
+func (s *TaskService) publishTaskEvent(ctx context.Context, eventType models.EventType, task *models.Task) {
+	s.events.Publish(ctx, models.Event{
+		Type:      eventType,
+		ProjectID: task.ProjectID,
+		EntityID:  task.ID,
+		Data:      task,
+	})
+}
+
 func (s *TaskService) CreateTask(ctx context.Context, task *models.Task) error {
 	// Валидация обязательных полей
 	if strings.TrimSpace(task.Title) == "" {
@@ -44,6 +75,10 @@ func (s *TaskService) CreateTask(ctx context.Context, task *models.Task) error {
 		return errors.New("project not found")
 	}
 
+	if err := s.access.Authorize(ctx, task.ProjectID, models.PermissionEditTasks); err != nil {
+		return err
+	}
+
 	// Проверка существования функционального блока (если указан)
 	if task.FunctionalBlockID != nil && *task.FunctionalBlockID != "" {
 		fb, err := s.fbRepo.GetByID(ctx, *task.FunctionalBlockID)
@@ -67,9 +102,6 @@ func (s *TaskService) CreateTask(ctx context.Context, task *models.Task) error {
 	}
 
 	// Установка значений по умолчанию
-	if task.Status == "" {
-		task.Status = string(models.TaskStatusNew)
-	}
 	if task.Priority == "" {
 		task.Priority = string(models.TaskPriorityMedium)
 	}
@@ -77,9 +109,9 @@ func (s *TaskService) CreateTask(ctx context.Context, task *models.Task) error {
 		task.Type = string(models.TaskTypeNewFeature)
 	}
 
-	// Валидация статуса
-	if !models.IsValidStatus(task.Status) {
-		return errors.New("invalid status")
+	// Валидация статуса по процессу проекта (пустой статус — начальный)
+	if err := s.workflows.ValidateInitialStatus(ctx, task); err != nil {
+		return err
 	}
 
 	// Валидация приоритета
@@ -92,6 +124,9 @@ func (s *TaskService) CreateTask(ctx context.Context, task *models.Task) error {
 		return errors.New("invalid type")
 	}
 
+	// Исполнитель назначается отдельным запросом
+	task.AssigneeID = nil
+
 	if err := s.taskRepo.Create(ctx, task); err != nil {
 		return err
 	}
@@ -103,9 +138,10 @@ func (s *TaskService) CreateTask(ctx context.Context, task *models.Task) error {
 			"title":   task.Title,
 			"status":  task.Status,
 		}
-		s.logService.LogTaskOperation(ctx, task.ID, "system", string(models.OperationTypeCreate), details)
+		s.logService.LogTaskOperation(ctx, task.ID, actorFromContext(ctx), string(models.OperationTypeCreate), details)
 	}
 
+	s.publishTaskEvent(ctx, models.EventTaskCreated, task)
 	return nil
 }
 
@@ -127,6 +163,26 @@ func (s *TaskService) GetAllTasks(ctx context.Context) ([]models.Task, error) {
 	return s.taskRepo.GetAll(ctx)
 }
 
+// FindTasks выбирает задачи по фильтру с сортировкой и пагинацией
+func (s *TaskService) FindTasks(ctx context.Context, filter *models.TaskFilter) (*models.TaskPage, error) {
+	if filter == nil {
+		filter = &models.TaskFilter{}
+	}
+
+	if err := filter.Validate(); err != nil {
+		return nil, err
+	}
+
+	if filter.Limit > maxTaskPageSize {
+		filter.Limit = maxTaskPageSize
+	}
+	if filter.Cursor != "" && filter.Limit == 0 {
+		filter.Limit = defaultTaskPageSize
+	}
+
+	return s.taskRepo.Find(ctx, filter)
+}
+
 func (s *TaskService) GetTasksByProjectID(ctx context.Context, projectID string) ([]models.Task, error) {
 	if strings.TrimSpace(projectID) == "" {
 		return nil, errors.New("project_id is required")
@@ -149,11 +205,6 @@ func (s *TaskService) GetTasksByStatus(ctx context.Context, status string) ([]mo
 		return nil, errors.New("status is required")
 	}
 
-	// Валидация статуса
-	if !models.IsValidStatus(status) {
-		return nil, errors.New("invalid status")
-	}
-
 	return s.taskRepo.GetByStatus(ctx, status)
 }
 
@@ -190,7 +241,11 @@ func (s *TaskService) UpdateTask(ctx context.Context, task *models.Task) error {
 		return err
 	}
 	if existingTask == nil {
-		return errors.New("task not found")
+		return ErrTaskNotFound
+	}
+
+	if err := s.access.Authorize(ctx, existingTask.ProjectID, models.PermissionEditTasks); err != nil {
+		return err
 	}
 
 	// Проверка существования функционального блока (если указан)
@@ -220,9 +275,12 @@ func (s *TaskService) UpdateTask(ctx context.Context, task *models.Task) error {
 		}
 	}
 
-	// Валидация статуса
-	if task.Status != "" && !models.IsValidStatus(task.Status) {
-		return errors.New("invalid status")
+	// Проверка перехода статуса по процессу проекта
+	if task.Status == "" {
+		task.Status = existingTask.Status
+	}
+	if err := s.workflows.ValidateTransition(ctx, existingTask, task); err != nil {
+		return err
 	}
 
 	// Валидация приоритета
@@ -235,9 +293,17 @@ func (s *TaskService) UpdateTask(ctx context.Context, task *models.Task) error {
 		return errors.New("invalid type")
 	}
 
+	// Версия 0 означает безусловное обновление поверх текущей версии
+	if err := checkVersion(&task.Version, existingTask.Version); err != nil {
+		return err
+	}
+
 	// Сохраняем неизменяемые поля
 	task.ProjectID = existingTask.ProjectID
 	task.Number = existingTask.Number
+	task.SourceDocumentID = existingTask.SourceDocumentID
+	task.SourceSectionAnchor = existingTask.SourceSectionAnchor
+	task.AssigneeID = existingTask.AssigneeID
 	task.CreatedAt = existingTask.CreatedAt
 
 	// Проверяем изменение статуса для специального логирования
@@ -254,8 +320,9 @@ func (s *TaskService) UpdateTask(ctx context.Context, task *models.Task) error {
 			"old_status": existingTask.Status,
 			"new_status": task.Status,
 			"title":      task.Title,
+			"changes":    models.DiffFields(existingTask, task),
 		}
-		s.logService.LogTaskOperation(ctx, task.ID, "system", string(models.OperationTypeUpdate), details)
+		s.logService.LogTaskOperation(ctx, task.ID, actorFromContext(ctx), string(models.OperationTypeUpdate), details)
 
 		// Дополнительное логирование изменения статуса
 		if statusChanged {
@@ -264,13 +331,100 @@ func (s *TaskService) UpdateTask(ctx context.Context, task *models.Task) error {
 				"old_status": existingTask.Status,
 				"new_status": task.Status,
 			}
-			s.logService.LogTaskOperation(ctx, task.ID, "system", string(models.OperationTypeStatusChange), statusDetails)
+			s.logService.LogTaskOperation(ctx, task.ID, actorFromContext(ctx), string(models.OperationTypeStatusChange), statusDetails)
 		}
 	}
 
+	s.publishTaskEvent(ctx, models.EventTaskUpdated, task)
+	if statusChanged {
+		s.notifications.NotifyStatusChange(ctx, task, existingTask.Status)
+	}
 	return nil
 }
 
+// AssignTask назначает задаче исполнителя. Ненулевой version — ожидаемая
+// версия задачи, как в UpdateTask. Исполнитель получает уведомление, только
+// если назначение действительно изменилось.
+func (s *TaskService) AssignTask(ctx context.Context, id, executorID string, version int) (*models.Task, error) {
+	if strings.TrimSpace(executorID) == "" {
+		return nil, errors.New("executor_id is required")
+	}
+
+	executor, err := s.executorRepo.GetByID(ctx, executorID)
+	if err != nil {
+		return nil, err
+	}
+	if executor == nil {
+		return nil, ErrExecutorNotFound
+	}
+
+	task, changed, err := s.setAssignee(ctx, id, &executor.ID, version)
+	if err != nil {
+		return nil, err
+	}
+	if changed {
+		s.notifications.NotifyAssignment(ctx, task, executor)
+	}
+	return task, nil
+}
+
+// UnassignTask снимает назначение исполнителя с задачи
+func (s *TaskService) UnassignTask(ctx context.Context, id string, version int) (*models.Task, error) {
+	task, _, err := s.setAssignee(ctx, id, nil, version)
+	return task, err
+}
+
+// setAssignee меняет исполнителя задачи и журналирует изменение. Повторное
+// назначение того же исполнителя ничего не меняет; второе значение сообщает,
+// изменилось ли назначение.
+func (s *TaskService) setAssignee(ctx context.Context, id string, executorID *string, version int) (*models.Task, bool, error) {
+	if strings.TrimSpace(id) == "" {
+		return nil, false, errors.New("id is required")
+	}
+
+	existingTask, err := s.taskRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, false, err
+	}
+	if existingTask == nil {
+		return nil, false, ErrTaskNotFound
+	}
+
+	if err := s.access.Authorize(ctx, existingTask.ProjectID, models.PermissionEditTasks); err != nil {
+		return nil, false, err
+	}
+
+	task := *existingTask
+	task.AssigneeID = executorID
+	task.Version = version
+	if err := checkVersion(&task.Version, existingTask.Version); err != nil {
+		return nil, false, err
+	}
+	changes := models.DiffFields(existingTask, &task)
+	if len(changes) == 0 {
+		return existingTask, false, nil
+	}
+
+	if err := s.taskRepo.SetAssignee(ctx, &task); err != nil {
+		return nil, false, err
+	}
+
+	// Логируем смену исполнителя
+	if s.logService != nil {
+		details := map[string]interface{}{
+			"task_id":         task.ID,
+			"title":           task.Title,
+			"old_assignee_id": existingTask.AssigneeID,
+			"new_assignee_id": task.AssigneeID,
+			"changes":         changes,
+		}
+		s.logService.LogTaskOperation(ctx, task.ID, actorFromContext(ctx), string(models.OperationTypeUpdate), details)
+	}
+
+	s.publishTaskEvent(ctx, models.EventTaskUpdated, &task)
+	return &task, true, nil
+}
+
 func (s *TaskService) DeleteTask(ctx context.Context, id string) error {
 	if strings.TrimSpace(id) == "" {
 		return errors.New("id is required")
@@ -282,7 +436,11 @@ func (s *TaskService) DeleteTask(ctx context.Context, id string) error {
 		return err
 	}
 	if task == nil {
-		return errors.New("task not found")
+		return ErrTaskNotFound
+	}
+
+	if err := s.access.Authorize(ctx, task.ProjectID, models.PermissionEditTasks); err != nil {
+		return err
 	}
 
 	if err := s.taskRepo.Delete(ctx, id); err != nil {
@@ -296,17 +454,129 @@ func (s *TaskService) DeleteTask(ctx context.Context, id string) error {
 			"title":   task.Title,
 			"number":  task.Number,
 		}
-		s.logService.LogTaskOperation(ctx, task.ID, "system", string(models.OperationTypeDelete), details)
+		s.logService.LogTaskOperation(ctx, task.ID, actorFromContext(ctx), string(models.OperationTypeDelete), details)
 	}
 
+	s.publishTaskEvent(ctx, models.EventTaskDeleted, task)
 	return nil
 }
 
-// GetValidStatuses возвращает список валидных статусов
+// GetDeletedTasks возвращает задачи в корзине; пустой projectID — по всем проектам
+func (s *TaskService) GetDeletedTasks(ctx context.Context, projectID string) ([]models.Task, error) {
+	tasks, err := s.taskRepo.GetDeleted(ctx, strings.TrimSpace(projectID))
+	if err != nil {
+		return nil, err
+	}
+	if tasks == nil {
+		tasks = []models.Task{}
+	}
+	return tasks, nil
+}
+
+// RestoreTask возвращает задачу из корзины
+func (s *TaskService) RestoreTask(ctx context.Context, id string) (*models.Task, error) {
+	task, err := s.getDeletedTask(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	// Задачи удаленного проекта восстанавливаются вместе с проектом
+	project, err := s.projectRepo.GetByID(ctx, task.ProjectID)
+	if err != nil {
+		return nil, err
+	}
+	if project == nil {
+		return nil, errors.New("project is deleted, restore the project first")
+	}
+
+	if err := s.access.Authorize(ctx, task.ProjectID, models.PermissionEditTasks); err != nil {
+		return nil, err
+	}
+
+	if err := s.taskRepo.Restore(ctx, id); err != nil {
+		return nil, err
+	}
+
+	if s.logService != nil {
+		details := map[string]interface{}{
+			"task_id": task.ID,
+			"title":   task.Title,
+			"number":  task.Number,
+		}
+		s.logService.LogTaskOperation(ctx, task.ID, actorFromContext(ctx), string(models.OperationTypeRestore), details)
+	}
+
+	restored, err := s.taskRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if restored != nil {
+		s.publishTaskEvent(ctx, models.EventTaskRestored, restored)
+	}
+	return restored, nil
+}
+
+// PurgeTask окончательно удаляет задачу из корзины. Журнал операций задачи
+// сохраняется, комментарии и связи удаляются.
+func (s *TaskService) PurgeTask(ctx context.Context, id string) error {
+	task, err := s.getDeletedTask(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if err := s.access.Authorize(ctx, task.ProjectID, models.PermissionManageProject); err != nil {
+		return err
+	}
+
+	// Запись пишется до удаления: после него задача уже не найдется,
+	// а task_id в журнале обнулится, сохранив entity_id
+	if s.logService != nil {
+		details := map[string]interface{}{
+			"task_id":    task.ID,
+			"project_id": task.ProjectID,
+			"title":      task.Title,
+			"number":     task.Number,
+		}
+		s.logService.LogTaskOperation(ctx, task.ID, actorFromContext(ctx), string(models.OperationTypePurge), details)
+	}
+
+	if err := s.taskRepo.Purge(ctx, id); err != nil {
+		return err
+	}
+
+	s.publishTaskEvent(ctx, models.EventTaskPurged, task)
+	return nil
+}
+
+// getDeletedTask возвращает задачу, находящуюся в корзине
+func (s *TaskService) getDeletedTask(ctx context.Context, id string) (*models.Task, error) {
+	if strings.TrimSpace(id) == "" {
+		return nil, errors.New("id is required")
+	}
+
+	task, err := s.taskRepo.GetByIDWithDeleted(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if task == nil {
+		return nil, ErrTaskNotFound
+	}
+	if task.DeletedAt == nil {
+		return nil, errors.New("task is not in trash")
+	}
+	return task, nil
+}
+
+// GetValidStatuses возвращает список статусов процесса по умолчанию
 func (s *TaskService) GetValidStatuses() []string {
 	return models.ValidStatuses()
 }
 
+// GetWorkflow возвращает процесс проекта (или процесс по умолчанию)
+func (s *TaskService) GetWorkflow(ctx context.Context, projectID string) (*models.Workflow, error) {
+	return s.workflows.GetWorkflow(ctx, projectID)
+}
+
 // GetValidPriorities возвращает список валидных приоритетов
 func (s *TaskService) GetValidPriorities() []string {
 	return models.ValidPriorities()
@@ -0,0 +1,250 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"project-manager/models"
+	"project-manager/repositories"
+)
+
+const maxTokenLifetimeDays = 365
+
+// ErrTokenNotFound возвращается при отзыве несуществующего токена
+var ErrTokenNotFound = errors.New("token not found")
+
+var usernameRegex = regexp.MustCompile(`^[a-zA-Z0-9._-]{2,100}$`)
+
+type UserService struct {
+	userRepo *repositories.UserRepository
+}
+
+func NewUserService(userRepo *repositories.UserRepository) *UserService {
+	return &UserService{userRepo: userRepo}
+}
+
+// requireAdmin проверяет, что субъект запроса — администратор
+func requireAdmin(ctx context.Context) error {
+	principal := PrincipalFromContext(ctx)
+	if principal == nil || !principal.IsAdmin {
+		return ErrForbidden
+	}
+	return nil
+}
+
+// requireUser возвращает субъекта запроса, привязанного к учетной записи
+func requireUser(ctx context.Context) (*models.Principal, error) {
+	principal := PrincipalFromContext(ctx)
+	if principal == nil {
+		return nil, ErrUnauthorized
+	}
+	if principal.UserID == "" {
+		return nil, errors.New("shared API key is not bound to a user account")
+	}
+	return principal, nil
+}
+
+func (s *UserService) CreateUser(ctx context.Context, user *models.User) error {
+	if err := requireAdmin(ctx); err != nil {
+		return err
+	}
+
+	// Валидация обязательных полей
+	user.Username = strings.TrimSpace(user.Username)
+	if !usernameRegex.MatchString(user.Username) {
+		return errors.New("username must be 2-100 characters: letters, digits, '.', '_' or '-'")
+	}
+	if user.Username == models.SystemUsername || user.Username == models.APIKeyUsername {
+		return errors.New("username " + user.Username + " is reserved")
+	}
+
+	if err := validateUserProfile(user); err != nil {
+		return err
+	}
+
+	existing, err := s.userRepo.GetByUsername(ctx, user.Username)
+	if err != nil {
+		return err
+	}
+	if existing != nil {
+		return errors.New("username already exists")
+	}
+
+	user.IsActive = true
+	return s.userRepo.Create(ctx, user)
+}
+
+func (s *UserService) GetAllUsers(ctx context.Context) ([]models.User, error) {
+	if err := requireAdmin(ctx); err != nil {
+		return nil, err
+	}
+	return s.userRepo.GetAll(ctx)
+}
+
+func (s *UserService) GetUserByID(ctx context.Context, id string) (*models.User, error) {
+	if err := requireAdmin(ctx); err != nil {
+		return nil, err
+	}
+	return s.userRepo.GetByID(ctx, id)
+}
+
+// UpdateUser изменяет профиль, роль администратора и активность пользователя.
+// Изменяемые поля переносятся на сохраненную учетную запись, остальные
+// (имя входа, даты) не меняются; в user возвращается итоговое состояние.
+func (s *UserService) UpdateUser(ctx context.Context, user *models.User) error {
+	if err := requireAdmin(ctx); err != nil {
+		return err
+	}
+
+	existing, err := s.userRepo.GetByID(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+	if existing == nil {
+		return errors.New("user not found")
+	}
+
+	existing.DisplayName = user.DisplayName
+	existing.Email = user.Email
+	existing.IsAdmin = user.IsAdmin
+	existing.IsAgent = user.IsAgent
+	existing.IsActive = user.IsActive
+	if err := validateUserProfile(existing); err != nil {
+		return err
+	}
+
+	if err := s.userRepo.Update(ctx, existing); err != nil {
+		return err
+	}
+	*user = *existing
+	return nil
+}
+
+// validateUserProfile нормализует и проверяет отображаемое имя и email;
+// пустые значения сохраняются как отсутствующие
+func validateUserProfile(user *models.User) error {
+	user.DisplayName = trimmedOrNil(user.DisplayName)
+	if user.DisplayName != nil && utf8.RuneCountInString(*user.DisplayName) > 255 {
+		return errors.New("display name must not exceed 255 characters")
+	}
+
+	user.Email = trimmedOrNil(user.Email)
+	if user.Email != nil {
+		at := strings.Index(*user.Email, "@")
+		if len(*user.Email) > 255 || at <= 0 || at == len(*user.Email)-1 {
+			return errors.New("invalid email")
+		}
+	}
+	return nil
+}
+
+// trimmedOrNil убирает пробелы по краям; пустая строка превращается в nil
+func trimmedOrNil(value *string) *string {
+	if value == nil {
+		return nil
+	}
+	trimmed := strings.TrimSpace(*value)
+	if trimmed == "" {
+		return nil
+	}
+	return &trimmed
+}
+
+// GetCurrentUser возвращает учетную запись субъекта запроса
+func (s *UserService) GetCurrentUser(ctx context.Context) (*models.User, error) {
+	principal, err := requireUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return s.userRepo.GetByID(ctx, principal.UserID)
+}
+
+// CreateToken выпускает персональный токен для текущего пользователя.
+// Открытое значение токена возвращается только один раз.
+func (s *UserService) CreateToken(ctx context.Context, name string, expiresInDays int) (*models.APIToken, error) {
+	principal, err := requireUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return s.issueToken(ctx, principal.UserID, name, expiresInDays)
+}
+
+// CreateUserToken выпускает токен для пользователя userID от имени
+// администратора, например первому администратору при настройке по общему API_KEY
+func (s *UserService) CreateUserToken(ctx context.Context, userID, name string, expiresInDays int) (*models.APIToken, error) {
+	if err := requireAdmin(ctx); err != nil {
+		return nil, err
+	}
+
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, errors.New("user not found")
+	}
+	if !user.IsActive {
+		return nil, errors.New("user is not active")
+	}
+	return s.issueToken(ctx, user.ID, name, expiresInDays)
+}
+
+// issueToken создает токен пользователя; открытое значение есть только в результате
+func (s *UserService) issueToken(ctx context.Context, userID, name string, expiresInDays int) (*models.APIToken, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || len(name) > 100 {
+		return nil, errors.New("token name must be between 1 and 100 characters")
+	}
+	if expiresInDays < 0 || expiresInDays > maxTokenLifetimeDays {
+		return nil, errors.New("expires_in_days must be between 0 and 365")
+	}
+
+	plain, hash, prefix, err := generateAPIToken()
+	if err != nil {
+		return nil, err
+	}
+
+	token := &models.APIToken{
+		UserID:      userID,
+		Name:        name,
+		TokenPrefix: prefix,
+	}
+	if expiresInDays > 0 {
+		expiresAt := time.Now().AddDate(0, 0, expiresInDays)
+		token.ExpiresAt = &expiresAt
+	}
+
+	if err := s.userRepo.CreateToken(ctx, token, hash); err != nil {
+		return nil, err
+	}
+	token.Token = plain
+	return token, nil
+}
+
+func (s *UserService) GetTokens(ctx context.Context) ([]models.APIToken, error) {
+	principal, err := requireUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return s.userRepo.GetTokensByUserID(ctx, principal.UserID)
+}
+
+func (s *UserService) RevokeToken(ctx context.Context, tokenID string) error {
+	principal, err := requireUser(ctx)
+	if err != nil {
+		return err
+	}
+
+	revoked, err := s.userRepo.RevokeToken(ctx, principal.UserID, tokenID)
+	if err != nil {
+		return err
+	}
+	if !revoked {
+		return ErrTokenNotFound
+	}
+	return nil
+}
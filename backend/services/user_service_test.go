@@ -0,0 +1,33 @@
+package services
+
+import (
+	"strings"
+	"testing"
+
+	"project-manager/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func stringPtr(value string) *string {
+	return &value
+}
+
+func TestValidateUserProfile(t *testing.T) {
+	user := &models.User{DisplayName: stringPtr("  Alice  "), Email: stringPtr(" alice@example.com ")}
+	require.NoError(t, validateUserProfile(user))
+	assert.Equal(t, "Alice", *user.DisplayName)
+	assert.Equal(t, "alice@example.com", *user.Email)
+
+	// Пустые значения очищают поля
+	user = &models.User{DisplayName: stringPtr("   "), Email: stringPtr("")}
+	require.NoError(t, validateUserProfile(user))
+	assert.Nil(t, user.DisplayName)
+	assert.Nil(t, user.Email)
+
+	for _, email := range []string{"alice", "@example.com", "alice@", strings.Repeat("a", 250) + "@example.com"} {
+		assert.Error(t, validateUserProfile(&models.User{Email: stringPtr(email)}), email)
+	}
+	assert.Error(t, validateUserProfile(&models.User{DisplayName: stringPtr(strings.Repeat("я", 256))}))
+}
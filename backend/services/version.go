@@ -0,0 +1,20 @@
+package services
+
+import "project-manager/repositories"
+
+// ErrVersionConflict возвращается при обновлении устаревшей версии записи
+var ErrVersionConflict = repositories.ErrVersionConflict
+
+// checkVersion сверяет ожидаемую клиентом версию с текущей. Нулевая версия
+// заменяется текущей, чтобы обновление было защищено хотя бы от гонки
+// между чтением и записью внутри запроса.
+func checkVersion(expected *int, current int) error {
+	if *expected == 0 {
+		*expected = current
+		return nil
+	}
+	if *expected != current {
+		return ErrVersionConflict
+	}
+	return nil
+}
@@ -0,0 +1,19 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckVersion(t *testing.T) {
+	expected := 0
+	assert.NoError(t, checkVersion(&expected, 3))
+	assert.Equal(t, 3, expected, "zero version is replaced with current")
+
+	expected = 3
+	assert.NoError(t, checkVersion(&expected, 3))
+
+	expected = 2
+	assert.ErrorIs(t, checkVersion(&expected, 3), ErrVersionConflict)
+}
@@ -0,0 +1,255 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"syscall"
+	"time"
+
+	"project-manager/models"
+	"project-manager/repositories"
+	"project-manager/utils"
+)
+
+const (
+	// webhookPollInterval — период проверки очереди доставок
+	webhookPollInterval = 5 * time.Second
+	// webhookBatchSize — сколько доставок забирается из очереди за раз
+	webhookBatchSize = 20
+	// webhookRequestTimeout — таймаут одного запроса к подписчику
+	webhookRequestTimeout = 10 * time.Second
+	// webhookClaimLease — на сколько откладывается забранная доставка; должно
+	// превышать время отправки пачки, иначе доставка может уйти повторно
+	webhookClaimLease = 5 * time.Minute
+	// webhookMaxAttempts — после стольких неудачных попыток доставка считается проваленной
+	webhookMaxAttempts = 8
+	// webhookInitialRetryDelay и webhookMaxRetryDelay задают экспоненциальную паузу между попытками
+	webhookInitialRetryDelay = 30 * time.Second
+	webhookMaxRetryDelay     = time.Hour
+	// webhookResponseBodyLimit — сколько байт ответа подписчика сохраняется в журнале
+	webhookResponseBodyLimit = 2048
+)
+
+// Заголовки запроса доставки. Подпись — HMAC-SHA256 от "<timestamp>.<тело>"
+// с секретом вебхука в hex с префиксом "sha256=".
+const (
+	WebhookEventHeader     = "X-Webhook-Event"
+	WebhookDeliveryHeader  = "X-Webhook-Delivery"
+	WebhookTimestampHeader = "X-Webhook-Timestamp"
+	WebhookSignatureHeader = "X-Webhook-Signature"
+)
+
+// ErrWebhookDestinationBlocked возвращается при попытке доставки на адрес
+// локальной или частной сети, если такие адреса запрещены конфигурацией
+var ErrWebhookDestinationBlocked = errors.New("webhook destination address is not allowed")
+
+// webhookAttemptResult — итог одной попытки отправки
+type webhookAttemptResult struct {
+	StatusCode int
+	Body       string
+	Err        error
+}
+
+// WebhookDispatcher отправляет доставки из очереди в базе данных. Очередь
+// переживает перезапуск сервера, а несколько экземпляров могут работать
+// одновременно.
+type WebhookDispatcher struct {
+	repo   *repositories.WebhookRepository
+	client *http.Client
+	now    func() time.Time
+}
+
+// NewWebhookDispatcher создает обработчик очереди. Перенаправления не
+// выполняются: ответ 3xx считается неудачной попыткой. При blockPrivateNetworks
+// запросы на loopback, частные и link-local адреса отклоняются; проверяется
+// адрес после разрешения имени, непосредственно перед соединением.
+func NewWebhookDispatcher(repo *repositories.WebhookRepository, blockPrivateNetworks bool) *WebhookDispatcher {
+	dialer := &net.Dialer{Timeout: webhookRequestTimeout}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if blockPrivateNetworks {
+		// Через прокси проверялся бы адрес прокси, а не подписчика
+		dialer.Control = webhookDialControl
+		transport.Proxy = nil
+	}
+	transport.DialContext = dialer.DialContext
+
+	return &WebhookDispatcher{
+		repo: repo,
+		client: &http.Client{
+			Timeout:   webhookRequestTimeout,
+			Transport: transport,
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+		now: time.Now,
+	}
+}
+
+// webhookDialControl отклоняет соединение с запрещенным адресом
+func webhookDialControl(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip := net.ParseIP(host)
+	if ip == nil || isBlockedWebhookIP(ip) {
+		return fmt.Errorf("%w: %s", ErrWebhookDestinationBlocked, host)
+	}
+	return nil
+}
+
+// isBlockedWebhookIP проверяет, что адрес относится к локальной или частной сети
+func isBlockedWebhookIP(ip net.IP) bool {
+	return ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast()
+}
+
+// Run обрабатывает очередь до отмены контекста
+func (d *WebhookDispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(webhookPollInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := d.DispatchDue(ctx); err != nil && ctx.Err() == nil {
+			utils.Warn("Failed to dispatch webhook deliveries", map[string]interface{}{
+				"error": err.Error(),
+			})
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// DispatchDue отправляет доставки, время которых наступило
+func (d *WebhookDispatcher) DispatchDue(ctx context.Context) error {
+	deliveries, err := d.repo.ClaimDueDeliveries(ctx, webhookBatchSize, webhookClaimLease)
+	if err != nil {
+		return err
+	}
+
+	webhooks := make(map[string]*models.Webhook)
+	for i := range deliveries {
+		delivery := &deliveries[i]
+
+		webhook, ok := webhooks[delivery.WebhookID]
+		if !ok {
+			if webhook, err = d.repo.GetByID(ctx, delivery.WebhookID); err != nil {
+				return err
+			}
+			webhooks[delivery.WebhookID] = webhook
+		}
+
+		var result webhookAttemptResult
+		switch {
+		case webhook == nil:
+			result.Err = errors.New("webhook not found")
+		case !webhook.IsActive:
+			result.Err = errors.New("webhook is disabled")
+		default:
+			result = d.send(ctx, webhook, delivery)
+		}
+
+		applyWebhookAttempt(delivery, result, d.now().UTC(), webhook != nil && webhook.IsActive)
+		if err := d.repo.SaveAttempt(ctx, delivery); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// send выполняет одну попытку отправки доставки
+func (d *WebhookDispatcher) send(ctx context.Context, webhook *models.Webhook, delivery *models.WebhookDelivery) webhookAttemptResult {
+	timestamp := strconv.FormatInt(d.now().Unix(), 10)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return webhookAttemptResult{Err: err}
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "project-manager-webhooks")
+	req.Header.Set(WebhookEventHeader, delivery.EventType)
+	req.Header.Set(WebhookDeliveryHeader, delivery.ID)
+	req.Header.Set(WebhookTimestampHeader, timestamp)
+	req.Header.Set(WebhookSignatureHeader, SignWebhookPayload(webhook.Secret, timestamp, delivery.Payload))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return webhookAttemptResult{Err: err}
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, webhookResponseBodyLimit))
+	result := webhookAttemptResult{StatusCode: resp.StatusCode, Body: string(body)}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		result.Err = fmt.Errorf("unexpected response status %d", resp.StatusCode)
+	}
+	return result
+}
+
+// SignWebhookPayload возвращает значение заголовка подписи доставки.
+// Получатель проверяет подпись, вычисляя ее по заголовку времени и телу запроса.
+func SignWebhookPayload(secret, timestamp string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(payload)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// webhookRetryDelay возвращает паузу перед следующей попыткой после attempt неудачных
+func webhookRetryDelay(attempt int) time.Duration {
+	delay := webhookInitialRetryDelay
+	for i := 1; i < attempt; i++ {
+		delay *= 2
+		if delay >= webhookMaxRetryDelay {
+			return webhookMaxRetryDelay
+		}
+	}
+	return delay
+}
+
+// applyWebhookAttempt записывает в доставку результат попытки и назначает
+// следующую. Если повтор бессмысленен (вебхук отключен или удален) или
+// попытки исчерпаны, доставка помечается проваленной.
+func applyWebhookAttempt(delivery *models.WebhookDelivery, result webhookAttemptResult, now time.Time, retryable bool) {
+	delivery.Attempts++
+	delivery.LastAttemptAt = &now
+	delivery.ResponseBody = result.Body
+	delivery.ResponseStatus = nil
+	if result.StatusCode != 0 {
+		status := result.StatusCode
+		delivery.ResponseStatus = &status
+	}
+
+	if result.Err == nil {
+		delivery.Status = string(models.WebhookDeliverySucceeded)
+		delivery.Error = ""
+		delivery.NextAttemptAt = nil
+		return
+	}
+
+	delivery.Error = result.Err.Error()
+	if !retryable || delivery.Attempts >= webhookMaxAttempts {
+		delivery.Status = string(models.WebhookDeliveryFailed)
+		delivery.NextAttemptAt = nil
+		return
+	}
+
+	next := now.Add(webhookRetryDelay(delivery.Attempts))
+	delivery.Status = string(models.WebhookDeliveryPending)
+	delivery.NextAttemptAt = &next
+}
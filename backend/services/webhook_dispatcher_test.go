@@ -0,0 +1,159 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"project-manager/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSignWebhookPayload(t *testing.T) {
+	payload := []byte(`{"event":"CREATE"}`)
+
+	signature := SignWebhookPayload("secret", "1700000000", payload)
+	assert.Regexp(t, `^sha256=[0-9a-f]{64}$`, signature)
+	assert.Equal(t, signature, SignWebhookPayload("secret", "1700000000", payload))
+	assert.NotEqual(t, signature, SignWebhookPayload("other", "1700000000", payload))
+	assert.NotEqual(t, signature, SignWebhookPayload("secret", "1700000001", payload))
+}
+
+func TestWebhookRetryDelay(t *testing.T) {
+	assert.Equal(t, 30*time.Second, webhookRetryDelay(1))
+	assert.Equal(t, time.Minute, webhookRetryDelay(2))
+	assert.Equal(t, 16*time.Minute, webhookRetryDelay(6))
+	assert.Equal(t, 32*time.Minute, webhookRetryDelay(7))
+	assert.Equal(t, time.Hour, webhookRetryDelay(8))
+	assert.Equal(t, time.Hour, webhookRetryDelay(20))
+}
+
+func TestApplyWebhookAttempt(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	t.Run("success", func(t *testing.T) {
+		delivery := &models.WebhookDelivery{Error: "previous"}
+		applyWebhookAttempt(delivery, webhookAttemptResult{StatusCode: 204}, now, true)
+
+		assert.Equal(t, string(models.WebhookDeliverySucceeded), delivery.Status)
+		assert.Equal(t, 1, delivery.Attempts)
+		assert.Empty(t, delivery.Error)
+		assert.Nil(t, delivery.NextAttemptAt)
+		require.NotNil(t, delivery.ResponseStatus)
+		assert.Equal(t, 204, *delivery.ResponseStatus)
+	})
+
+	t.Run("failure is retried with backoff", func(t *testing.T) {
+		delivery := &models.WebhookDelivery{Attempts: 1}
+		applyWebhookAttempt(delivery, webhookAttemptResult{StatusCode: 500, Err: errors.New("unexpected response status 500")}, now, true)
+
+		assert.Equal(t, string(models.WebhookDeliveryPending), delivery.Status)
+		require.NotNil(t, delivery.NextAttemptAt)
+		assert.Equal(t, now.Add(time.Minute), *delivery.NextAttemptAt)
+	})
+
+	t.Run("attempts exhausted", func(t *testing.T) {
+		delivery := &models.WebhookDelivery{Attempts: webhookMaxAttempts - 1}
+		applyWebhookAttempt(delivery, webhookAttemptResult{Err: errors.New("connection refused")}, now, true)
+
+		assert.Equal(t, string(models.WebhookDeliveryFailed), delivery.Status)
+		assert.Nil(t, delivery.NextAttemptAt)
+		assert.Nil(t, delivery.ResponseStatus)
+	})
+
+	t.Run("not retryable", func(t *testing.T) {
+		delivery := &models.WebhookDelivery{}
+		applyWebhookAttempt(delivery, webhookAttemptResult{Err: errors.New("webhook is disabled")}, now, false)
+
+		assert.Equal(t, string(models.WebhookDeliveryFailed), delivery.Status)
+		assert.Equal(t, "webhook is disabled", delivery.Error)
+	})
+}
+
+func TestWebhookDispatcher_SendSignsRequest(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	payload := []byte(`{"event":"UPDATE"}`)
+
+	var received *http.Request
+	var body []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r
+		body, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusTeapot)
+		w.Write([]byte("nope"))
+	}))
+	defer server.Close()
+
+	dispatcher := NewWebhookDispatcher(nil, false)
+	dispatcher.now = func() time.Time { return now }
+
+	webhook := &models.Webhook{URL: server.URL, Secret: "secret"}
+	delivery := &models.WebhookDelivery{ID: "d1", EventType: "UPDATE", Payload: payload}
+	result := dispatcher.send(context.Background(), webhook, delivery)
+
+	require.NotNil(t, received)
+	assert.Equal(t, payload, body)
+	assert.Equal(t, "UPDATE", received.Header.Get(WebhookEventHeader))
+	assert.Equal(t, "d1", received.Header.Get(WebhookDeliveryHeader))
+	assert.Equal(t, "1700000000", received.Header.Get(WebhookTimestampHeader))
+	assert.Equal(t, SignWebhookPayload("secret", "1700000000", payload), received.Header.Get(WebhookSignatureHeader))
+
+	assert.Equal(t, http.StatusTeapot, result.StatusCode)
+	assert.Equal(t, "nope", result.Body)
+	assert.Error(t, result.Err)
+}
+
+func TestWebhookDispatcherDoesNotFollowRedirects(t *testing.T) {
+	var redirected bool
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		redirected = true
+	}))
+	defer target.Close()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, target.URL, http.StatusFound)
+	}))
+	defer server.Close()
+
+	dispatcher := NewWebhookDispatcher(nil, false)
+	webhook := &models.Webhook{URL: server.URL, Secret: "secret"}
+	result := dispatcher.send(context.Background(), webhook, &models.WebhookDelivery{ID: "d1", Payload: []byte(`{}`)})
+
+	assert.False(t, redirected)
+	assert.Equal(t, http.StatusFound, result.StatusCode)
+	assert.Error(t, result.Err)
+}
+
+func TestWebhookDispatcherBlocksPrivateNetworks(t *testing.T) {
+	var called bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	}))
+	defer server.Close()
+
+	dispatcher := NewWebhookDispatcher(nil, true)
+	webhook := &models.Webhook{URL: server.URL, Secret: "secret"}
+	result := dispatcher.send(context.Background(), webhook, &models.WebhookDelivery{ID: "d1", Payload: []byte(`{}`)})
+
+	assert.False(t, called)
+	assert.Zero(t, result.StatusCode)
+	assert.ErrorIs(t, result.Err, ErrWebhookDestinationBlocked)
+}
+
+func TestIsBlockedWebhookIP(t *testing.T) {
+	blocked := []string{"127.0.0.1", "::1", "10.1.2.3", "172.16.0.1", "192.168.1.1", "169.254.169.254", "fe80::1", "fd00::1", "0.0.0.0", "::"}
+	for _, address := range blocked {
+		assert.True(t, isBlockedWebhookIP(net.ParseIP(address)), address)
+	}
+
+	allowed := []string{"93.184.216.34", "8.8.8.8", "172.32.0.1", "2001:4860:4860::8888"}
+	for _, address := range allowed {
+		assert.False(t, isBlockedWebhookIP(net.ParseIP(address)), address)
+	}
+}
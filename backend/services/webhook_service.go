@@ -0,0 +1,344 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/url"
+	"strings"
+
+	"project-manager/models"
+	"project-manager/repositories"
+	"project-manager/utils"
+)
+
+// ErrWebhookNotFound возвращается, если вебхука нет
+var ErrWebhookNotFound = errors.New("webhook not found")
+
+const (
+	// maxWebhookDeliveriesPage — сколько последних доставок возвращает журнал
+	maxWebhookDeliveriesPage = 100
+	// minWebhookSecretLength — минимальная длина секрета, заданного вручную
+	minWebhookSecretLength = 16
+)
+
+type WebhookService struct {
+	webhookRepo  *repositories.WebhookRepository
+	projectRepo  *repositories.ProjectRepository
+	taskRepo     *repositories.TaskRepository
+	documentRepo *repositories.DocumentRepository
+	access       *AccessService
+}
+
+func NewWebhookService(webhookRepo *repositories.WebhookRepository, projectRepo *repositories.ProjectRepository, taskRepo *repositories.TaskRepository, documentRepo *repositories.DocumentRepository, access *AccessService) *WebhookService {
+	return &WebhookService{
+		webhookRepo:  webhookRepo,
+		projectRepo:  projectRepo,
+		taskRepo:     taskRepo,
+		documentRepo: documentRepo,
+		access:       access,
+	}
+}
+
+// GetProjectWebhooks возвращает вебхуки проекта без секретов
+func (s *WebhookService) GetProjectWebhooks(ctx context.Context, projectID string) ([]models.Webhook, error) {
+	if strings.TrimSpace(projectID) == "" {
+		return nil, errors.New("project_id is required")
+	}
+	if err := s.access.Authorize(ctx, projectID, models.PermissionManageProject); err != nil {
+		return nil, err
+	}
+
+	webhooks, err := s.webhookRepo.GetByProjectID(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range webhooks {
+		webhooks[i].Secret = ""
+	}
+	return webhooks, nil
+}
+
+// GetWebhook возвращает вебхук без секрета
+func (s *WebhookService) GetWebhook(ctx context.Context, id string) (*models.Webhook, error) {
+	webhook, err := s.getAuthorizedWebhook(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	webhook.Secret = ""
+	return webhook, nil
+}
+
+// CreateWebhook создает вебхук. Если секрет не задан, он генерируется;
+// секрет возвращается только в ответе на создание.
+func (s *WebhookService) CreateWebhook(ctx context.Context, webhook *models.Webhook) error {
+	if strings.TrimSpace(webhook.ProjectID) == "" {
+		return errors.New("project_id is required")
+	}
+	if err := normalizeWebhook(webhook); err != nil {
+		return err
+	}
+
+	project, err := s.projectRepo.GetByID(ctx, webhook.ProjectID)
+	if err != nil {
+		return err
+	}
+	if project == nil {
+		return errors.New("project not found")
+	}
+
+	if err := s.access.Authorize(ctx, webhook.ProjectID, models.PermissionManageProject); err != nil {
+		return err
+	}
+
+	if webhook.Secret == "" {
+		if webhook.Secret, err = generateWebhookSecret(); err != nil {
+			return err
+		}
+	}
+	webhook.CreatedBy = actorFromContext(ctx)
+
+	return s.webhookRepo.Create(ctx, webhook)
+}
+
+// UpdateWebhook изменяет адрес, типы событий и активность вебхука.
+// Секрет заменяется, только если передан новый.
+func (s *WebhookService) UpdateWebhook(ctx context.Context, webhook *models.Webhook) error {
+	existing, err := s.getAuthorizedWebhook(ctx, webhook.ID)
+	if err != nil {
+		return err
+	}
+	if err := normalizeWebhook(webhook); err != nil {
+		return err
+	}
+
+	webhook.ProjectID = existing.ProjectID
+	webhook.CreatedBy = existing.CreatedBy
+	webhook.CreatedAt = existing.CreatedAt
+	if webhook.Secret == "" {
+		webhook.Secret = existing.Secret
+	}
+
+	if err := s.webhookRepo.Update(ctx, webhook); err != nil {
+		return err
+	}
+	webhook.Secret = ""
+	return nil
+}
+
+func (s *WebhookService) DeleteWebhook(ctx context.Context, id string) error {
+	if _, err := s.getAuthorizedWebhook(ctx, id); err != nil {
+		return err
+	}
+	return s.webhookRepo.Delete(ctx, id)
+}
+
+// GetDeliveries возвращает журнал последних доставок вебхука
+func (s *WebhookService) GetDeliveries(ctx context.Context, webhookID string) ([]models.WebhookDelivery, error) {
+	if _, err := s.getAuthorizedWebhook(ctx, webhookID); err != nil {
+		return nil, err
+	}
+	return s.webhookRepo.GetDeliveries(ctx, webhookID, maxWebhookDeliveriesPage)
+}
+
+// Redeliver ставит в очередь повторную отправку доставки с тем же телом.
+// Исходная доставка не меняется, новая ссылается на нее через redeliveryOf.
+func (s *WebhookService) Redeliver(ctx context.Context, webhookID, deliveryID string) (*models.WebhookDelivery, error) {
+	if _, err := s.getAuthorizedWebhook(ctx, webhookID); err != nil {
+		return nil, err
+	}
+
+	original, err := s.webhookRepo.GetDelivery(ctx, deliveryID)
+	if err != nil {
+		return nil, err
+	}
+	if original == nil || original.WebhookID != webhookID {
+		return nil, errors.New("delivery not found")
+	}
+
+	delivery := &models.WebhookDelivery{
+		WebhookID:      webhookID,
+		OperationLogID: original.OperationLogID,
+		EventType:      original.EventType,
+		Payload:        original.Payload,
+		RedeliveryOf:   &original.ID,
+	}
+	if err := s.webhookRepo.CreateDelivery(ctx, delivery); err != nil {
+		return nil, err
+	}
+	return delivery, nil
+}
+
+// Enqueue ставит в очередь доставки записи журнала операций для всех
+// подписанных вебхуков проекта. Ошибки только логируются: уведомления
+// не должны мешать самой операции. Вызов на nil-сервисе ничего не делает.
+func (s *WebhookService) Enqueue(ctx context.Context, log *models.OperationLog) {
+	if s == nil || !models.IsValidWebhookEventType(log.OperationType) {
+		return
+	}
+
+	projectID, err := s.resolveProjectID(ctx, log)
+	if err != nil {
+		utils.Warn("Failed to resolve webhook project", map[string]interface{}{
+			"log_id": log.ID,
+			"error":  err.Error(),
+		})
+		return
+	}
+	if projectID == "" {
+		return
+	}
+
+	webhooks, err := s.webhookRepo.GetActiveByProjectID(ctx, projectID)
+	if err != nil {
+		utils.Warn("Failed to load project webhooks", map[string]interface{}{
+			"project_id": projectID,
+			"error":      err.Error(),
+		})
+		return
+	}
+
+	var payload []byte
+	for _, webhook := range webhooks {
+		if !webhook.Subscribed(log.OperationType) {
+			continue
+		}
+		if payload == nil {
+			if payload, err = json.Marshal(webhookPayloadFromLog(log, projectID)); err != nil {
+				utils.Warn("Failed to encode webhook payload", map[string]interface{}{
+					"log_id": log.ID,
+					"error":  err.Error(),
+				})
+				return
+			}
+		}
+
+		delivery := &models.WebhookDelivery{
+			WebhookID:      webhook.ID,
+			OperationLogID: &log.ID,
+			EventType:      log.OperationType,
+			Payload:        payload,
+		}
+		if err := s.webhookRepo.CreateDelivery(ctx, delivery); err != nil {
+			utils.Warn("Failed to enqueue webhook delivery", map[string]interface{}{
+				"webhook_id": webhook.ID,
+				"log_id":     log.ID,
+				"error":      err.Error(),
+			})
+		}
+	}
+}
+
+// resolveProjectID определяет проект записи журнала. Глобальные записи
+// (функциональные блоки, исполнители) проекту не принадлежат.
+func (s *WebhookService) resolveProjectID(ctx context.Context, log *models.OperationLog) (string, error) {
+	switch models.LogEntityType(log.EntityType) {
+	case models.LogEntityProject, models.LogEntityProjectPlan:
+		return log.EntityID, nil
+	case models.LogEntityTask:
+		task, err := s.taskRepo.GetByIDWithDeleted(ctx, log.EntityID)
+		if err != nil || task == nil {
+			return "", err
+		}
+		return task.ProjectID, nil
+	case models.LogEntityDocument:
+		document, err := s.documentRepo.GetByID(ctx, log.EntityID)
+		if err != nil {
+			return "", err
+		}
+		if document != nil {
+			return document.ProjectID, nil
+		}
+		// Удаленный документ уже отсутствует в базе, проект берется из деталей лога
+		var details struct {
+			ProjectID string `json:"project_id"`
+		}
+		if len(log.Details) > 0 {
+			_ = json.Unmarshal(log.Details, &details)
+		}
+		return details.ProjectID, nil
+	default:
+		return "", nil
+	}
+}
+
+func (s *WebhookService) getAuthorizedWebhook(ctx context.Context, id string) (*models.Webhook, error) {
+	if strings.TrimSpace(id) == "" {
+		return nil, errors.New("id is required")
+	}
+
+	webhook, err := s.webhookRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if webhook == nil {
+		return nil, ErrWebhookNotFound
+	}
+
+	if err := s.access.Authorize(ctx, webhook.ProjectID, models.PermissionManageProject); err != nil {
+		return nil, err
+	}
+	return webhook, nil
+}
+
+// normalizeWebhook проверяет адрес, типы событий и секрет вебхука
+func normalizeWebhook(webhook *models.Webhook) error {
+	webhook.URL = strings.TrimSpace(webhook.URL)
+	if webhook.URL == "" {
+		return errors.New("url is required")
+	}
+	parsed, err := url.Parse(webhook.URL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return errors.New("url must be an absolute http or https URL")
+	}
+
+	eventTypes := make([]string, 0, len(webhook.EventTypes))
+	seen := make(map[string]bool)
+	for _, eventType := range webhook.EventTypes {
+		eventType = strings.ToUpper(strings.TrimSpace(eventType))
+		if !models.IsValidWebhookEventType(eventType) {
+			return errors.New("invalid event type: " + eventType)
+		}
+		if !seen[eventType] {
+			seen[eventType] = true
+			eventTypes = append(eventTypes, eventType)
+		}
+	}
+	webhook.EventTypes = eventTypes
+
+	webhook.Secret = strings.TrimSpace(webhook.Secret)
+	if webhook.Secret != "" && len(webhook.Secret) < minWebhookSecretLength {
+		return errors.New("secret must be at least 16 characters")
+	}
+	return nil
+}
+
+// webhookPayloadFromLog формирует тело уведомления по записи журнала
+func webhookPayloadFromLog(log *models.OperationLog, projectID string) models.WebhookPayload {
+	details := log.Details
+	if len(details) == 0 {
+		details = json.RawMessage("null")
+	}
+	return models.WebhookPayload{
+		Event:      log.OperationType,
+		EntityType: log.EntityType,
+		EntityID:   log.EntityID,
+		ProjectID:  projectID,
+		TaskID:     log.TaskID,
+		Actor:      log.UserIdentifier,
+		Details:    details,
+		LogID:      log.ID,
+		OccurredAt: log.CreatedAt,
+	}
+}
+
+// generateWebhookSecret создает случайный секрет для подписи доставок
+func generateWebhookSecret() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
@@ -0,0 +1,176 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"project-manager/models"
+	"project-manager/repositories"
+)
+
+var (
+	ErrTransitionNotAllowed  = errors.New("status transition is not allowed")
+	ErrRequiredFieldsMissing = errors.New("required fields are missing for status transition")
+	ErrStatusNotInWorkflow   = errors.New("status is not defined in project workflow")
+)
+
+type WorkflowService struct {
+	workflowRepo *repositories.WorkflowRepository
+	projectRepo  *repositories.ProjectRepository
+	access       *AccessService
+}
+
+func NewWorkflowService(workflowRepo *repositories.WorkflowRepository, projectRepo *repositories.ProjectRepository, access *AccessService) *WorkflowService {
+	return &WorkflowService{
+		workflowRepo: workflowRepo,
+		projectRepo:  projectRepo,
+		access:       access,
+	}
+}
+
+// GetWorkflow возвращает процесс проекта; без projectID — процесс по умолчанию
+func (s *WorkflowService) GetWorkflow(ctx context.Context, projectID string) (*models.Workflow, error) {
+	if strings.TrimSpace(projectID) == "" {
+		return models.DefaultWorkflow(), nil
+	}
+
+	workflow, err := s.workflowRepo.GetByProjectID(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+	if workflow == nil {
+		workflow = models.DefaultWorkflow()
+		workflow.ProjectID = projectID
+	}
+	return workflow, nil
+}
+
+// SetWorkflow сохраняет процесс проекта. Все статусы, в которых уже
+// находятся задачи проекта, должны остаться в процессе.
+func (s *WorkflowService) SetWorkflow(ctx context.Context, workflow *models.Workflow) error {
+	if err := s.ensureProject(ctx, workflow.ProjectID); err != nil {
+		return err
+	}
+	if err := s.access.Authorize(ctx, workflow.ProjectID, models.PermissionManageProject); err != nil {
+		return err
+	}
+
+	// Без terminalStates и doneStates они берутся по процессу по умолчанию
+	workflow.FillTerminalStates()
+	if err := workflow.Validate(); err != nil {
+		return err
+	}
+
+	usedStatuses, err := s.workflowRepo.GetUsedStatuses(ctx, workflow.ProjectID)
+	if err != nil {
+		return err
+	}
+	for _, status := range usedStatuses {
+		if !workflow.HasState(status) {
+			return fmt.Errorf("state %s is used by project tasks and cannot be removed", status)
+		}
+	}
+
+	workflow.IsDefault = false
+	workflow.UpdatedBy = actorFromContext(ctx)
+	return s.workflowRepo.Upsert(ctx, workflow)
+}
+
+// ResetWorkflow возвращает проекту процесс по умолчанию
+func (s *WorkflowService) ResetWorkflow(ctx context.Context, projectID string) error {
+	if err := s.ensureProject(ctx, projectID); err != nil {
+		return err
+	}
+	if err := s.access.Authorize(ctx, projectID, models.PermissionManageProject); err != nil {
+		return err
+	}
+
+	defaultWorkflow := models.DefaultWorkflow()
+	usedStatuses, err := s.workflowRepo.GetUsedStatuses(ctx, projectID)
+	if err != nil {
+		return err
+	}
+	for _, status := range usedStatuses {
+		if !defaultWorkflow.HasState(status) {
+			return fmt.Errorf("state %s is used by project tasks and is not in the default workflow", status)
+		}
+	}
+
+	return s.workflowRepo.Delete(ctx, projectID)
+}
+
+// ValidateInitialStatus проверяет статус новой задачи. Задача создается в
+// начальном статусе; другой статус допустим, только если в него разрешен
+// переход из начального, и тогда проверяются обязательные поля этого перехода.
+func (s *WorkflowService) ValidateInitialStatus(ctx context.Context, task *models.Task) error {
+	workflow, err := s.GetWorkflow(ctx, task.ProjectID)
+	if err != nil {
+		return err
+	}
+
+	if task.Status == "" {
+		task.Status = workflow.InitialState
+	}
+	return validateInitialStatus(workflow, task)
+}
+
+// validateInitialStatus проверяет статус новой задачи по процессу
+func validateInitialStatus(workflow *models.Workflow, task *models.Task) error {
+	if !workflow.HasState(task.Status) {
+		return fmt.Errorf("%w: %s", ErrStatusNotInWorkflow, task.Status)
+	}
+	if task.Status == workflow.InitialState {
+		return nil
+	}
+
+	transition := workflow.Transition(workflow.InitialState, task.Status)
+	if transition == nil {
+		return fmt.Errorf("%w: %s -> %s", ErrTransitionNotAllowed, workflow.InitialState, task.Status)
+	}
+	if missing := transition.MissingFields(task); len(missing) > 0 {
+		return fmt.Errorf("%w: %s", ErrRequiredFieldsMissing, strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// ValidateTransition проверяет смену статуса задачи existing на статус updated
+func (s *WorkflowService) ValidateTransition(ctx context.Context, existing, updated *models.Task) error {
+	if existing.Status == updated.Status {
+		return nil
+	}
+
+	workflow, err := s.GetWorkflow(ctx, existing.ProjectID)
+	if err != nil {
+		return err
+	}
+
+	if !workflow.HasState(updated.Status) {
+		return fmt.Errorf("%w: %s", ErrStatusNotInWorkflow, updated.Status)
+	}
+
+	transition := workflow.Transition(existing.Status, updated.Status)
+	if transition == nil {
+		return fmt.Errorf("%w: %s -> %s", ErrTransitionNotAllowed, existing.Status, updated.Status)
+	}
+
+	if missing := transition.MissingFields(updated); len(missing) > 0 {
+		return fmt.Errorf("%w: %s", ErrRequiredFieldsMissing, strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+func (s *WorkflowService) ensureProject(ctx context.Context, projectID string) error {
+	if strings.TrimSpace(projectID) == "" {
+		return errors.New("project_id is required")
+	}
+	project, err := s.projectRepo.GetByID(ctx, projectID)
+	if err != nil {
+		return err
+	}
+	if project == nil {
+		return errors.New("project not found")
+	}
+	return nil
+}
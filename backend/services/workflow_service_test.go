@@ -0,0 +1,33 @@
+package services
+
+import (
+	"testing"
+
+	"project-manager/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateInitialStatus(t *testing.T) {
+	workflow := models.DefaultWorkflow()
+	workflow.Transitions = append(workflow.Transitions, models.WorkflowTransition{
+		From:           string(models.TaskStatusNew),
+		To:             string(models.TaskStatusDone),
+		RequiredFields: []string{models.WorkflowFieldResult},
+	})
+
+	assert.NoError(t, validateInitialStatus(workflow, &models.Task{Status: string(models.TaskStatusNew)}))
+	assert.NoError(t, validateInitialStatus(workflow, &models.Task{Status: string(models.TaskStatusInProgress)}))
+
+	// Перехода из начального статуса нет
+	err := validateInitialStatus(workflow, &models.Task{Status: string(models.TaskStatusTesting)})
+	assert.ErrorIs(t, err, ErrTransitionNotAllowed)
+
+	// Переход есть, но не заполнен результат
+	err = validateInitialStatus(workflow, &models.Task{Status: string(models.TaskStatusDone)})
+	assert.ErrorIs(t, err, ErrRequiredFieldsMissing)
+	assert.NoError(t, validateInitialStatus(workflow, &models.Task{Status: string(models.TaskStatusDone), Result: "Готово"}))
+
+	err = validateInitialStatus(workflow, &models.Task{Status: "unknown"})
+	assert.ErrorIs(t, err, ErrStatusNotInWorkflow)
+}
@@ -0,0 +1,57 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnifiedDiff_Identical(t *testing.T) {
+	assert.Equal(t, "", UnifiedDiff("a", "b", "one\ntwo\n", "one\ntwo\n"))
+	assert.Equal(t, "", UnifiedDiff("a", "b", "", ""))
+}
+
+func TestUnifiedDiff_ChangedLine(t *testing.T) {
+	diff := UnifiedDiff("r1", "r2", "one\ntwo\nthree\n", "one\nTWO\nthree\n")
+
+	expected := "--- r1\n+++ r2\n" +
+		"@@ -1,3 +1,3 @@\n" +
+		" one\n" +
+		"-two\n" +
+		"+TWO\n" +
+		" three\n"
+	assert.Equal(t, expected, diff)
+}
+
+func TestUnifiedDiff_FromEmpty(t *testing.T) {
+	diff := UnifiedDiff("r1", "r2", "", "one\ntwo")
+
+	expected := "--- r1\n+++ r2\n" +
+		"@@ -0,0 +1,2 @@\n" +
+		"+one\n" +
+		"+two\n"
+	assert.Equal(t, expected, diff)
+}
+
+func TestUnifiedDiff_SeparateHunks(t *testing.T) {
+	var from, to []string
+	for i := 1; i <= 20; i++ {
+		line := "line " + string(rune('a'+i-1))
+		from = append(from, line)
+		switch i {
+		case 2:
+			to = append(to, "changed")
+		case 18:
+			// строка удалена
+		default:
+			to = append(to, line)
+		}
+	}
+
+	diff := UnifiedDiff("r1", "r2", strings.Join(from, "\n"), strings.Join(to, "\n"))
+
+	assert.Equal(t, 2, strings.Count(diff, "@@ -"))
+	assert.Contains(t, diff, "@@ -1,5 +1,5 @@\n line a\n-line b\n+changed\n line c\n")
+	assert.Contains(t, diff, "@@ -15,6 +15,5 @@\n line o\n line p\n line q\n-line r\n line s\n line t\n")
+}
@@ -0,0 +1,260 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// ErrSectionNotFound возвращается, если в документе нет раздела с указанным якорем
+var ErrSectionNotFound = errors.New("section not found")
+
+// markdownHeadingPattern распознает ATX-заголовки: "## Заголовок ##"
+var markdownHeadingPattern = regexp.MustCompile(`^ {0,3}(#{1,6})(?:[ \t]+(.*?))?(?:[ \t]+#+)?[ \t]*$`)
+
+// MarkdownSection — раздел Markdown-документа: заголовок и все строки до
+// следующего заголовка того же или более высокого уровня. Номера строк
+// начинаются с 1, EndLine включительно.
+type MarkdownSection struct {
+	Anchor    string            `json:"anchor"`
+	Title     string            `json:"title"`
+	Level     int               `json:"level"`
+	StartLine int               `json:"startLine"`
+	EndLine   int               `json:"endLine"`
+	Content   string            `json:"content,omitempty"`
+	Children  []MarkdownSection `json:"children,omitempty"`
+}
+
+// markdownHeading — заголовок, найденный в тексте; line начинается с 0
+type markdownHeading struct {
+	level  int
+	title  string
+	anchor string
+	line   int
+	end    int
+}
+
+// MarkdownSections возвращает дерево разделов документа без их содержимого
+func MarkdownSections(content string) []MarkdownSection {
+	lines := strings.Split(content, "\n")
+	headings := scanMarkdownHeadings(lines)
+	index := 0
+	return buildMarkdownSections(headings, &index, 0)
+}
+
+// FindMarkdownSection возвращает раздел с содержимым (текст под заголовком,
+// включая подразделы) или nil, если раздела нет
+func FindMarkdownSection(content, anchor string) *MarkdownSection {
+	lines := strings.Split(content, "\n")
+	headings := scanMarkdownHeadings(lines)
+
+	for i, heading := range headings {
+		if heading.anchor != anchor {
+			continue
+		}
+		next := i + 1
+		section := newMarkdownSection(heading)
+		section.Content = strings.Join(lines[heading.line+1:heading.end+1], "\n")
+		section.Children = buildMarkdownSections(headings, &next, heading.level)
+		return &section
+	}
+	return nil
+}
+
+// ReplaceMarkdownSection заменяет текст под заголовком раздела на body и
+// возвращает новый документ. Заголовок раздела сохраняется, а body не может
+// содержать заголовки того же или более высокого уровня, иначе раздел
+// распался бы на несколько.
+func ReplaceMarkdownSection(content, anchor, body string) (string, error) {
+	lines := strings.Split(content, "\n")
+
+	var section *markdownHeading
+	for _, heading := range scanMarkdownHeadings(lines) {
+		if heading.anchor == anchor {
+			section = &heading
+			break
+		}
+	}
+	if section == nil {
+		return "", ErrSectionNotFound
+	}
+
+	var bodyLines []string
+	if body != "" {
+		bodyLines = strings.Split(strings.TrimSuffix(body, "\n"), "\n")
+	}
+	for _, heading := range scanMarkdownHeadings(bodyLines) {
+		if heading.level <= section.level {
+			return "", fmt.Errorf("section content must not contain headings of level %d or higher: %q", section.level, heading.title)
+		}
+	}
+
+	result := make([]string, 0, len(lines)+len(bodyLines))
+	result = append(result, lines[:section.line+1]...)
+	result = append(result, bodyLines...)
+	result = append(result, lines[section.end+1:]...)
+	return strings.Join(result, "\n"), nil
+}
+
+// MarkdownAnchor строит якорь заголовка по правилам GitHub: строчные буквы,
+// пробелы заменяются дефисами, знаки препинания отбрасываются
+func MarkdownAnchor(title string) string {
+	var anchor strings.Builder
+	for _, r := range strings.ToLower(strings.TrimSpace(title)) {
+		switch {
+		case unicode.IsLetter(r), unicode.IsDigit(r), r == '-', r == '_':
+			anchor.WriteRune(r)
+		case r == ' ':
+			anchor.WriteByte('-')
+		}
+	}
+	if anchor.Len() == 0 {
+		return "section"
+	}
+	return anchor.String()
+}
+
+// MarkdownLine — строка документа с учетом его структуры
+type MarkdownLine struct {
+	Text string
+	// Section — якорь ближайшего раздела, которому принадлежит строка
+	Section string
+	// HeadingLevel — уровень заголовка, если строка является заголовком
+	HeadingLevel int
+	// InCode — строка относится к блоку кода (включая ограждения)
+	InCode bool
+}
+
+// ParseMarkdownLines разбивает документ на строки и для каждой определяет
+// раздел, принадлежность к заголовку и к блоку кода
+func ParseMarkdownLines(content string) []MarkdownLine {
+	lines := strings.Split(content, "\n")
+	inCode := markdownCodeLines(lines)
+	headings := scanMarkdownHeadings(lines)
+
+	result := make([]MarkdownLine, len(lines))
+	next := 0
+	section := ""
+	for i, line := range lines {
+		result[i] = MarkdownLine{Text: strings.TrimSuffix(line, "\r"), InCode: inCode[i]}
+		if next < len(headings) && headings[next].line == i {
+			section = headings[next].anchor
+			result[i].HeadingLevel = headings[next].level
+			next++
+		}
+		result[i].Section = section
+	}
+	return result
+}
+
+// markdownCodeLines отмечает строки, относящиеся к огражденным блокам кода
+func markdownCodeLines(lines []string) []bool {
+	inCode := make([]bool, len(lines))
+	var fence string
+	for i, raw := range lines {
+		line := strings.TrimSuffix(raw, "\r")
+		if marker := markdownFence(line); marker != "" {
+			switch {
+			case fence == "":
+				fence = marker
+			case strings.HasPrefix(marker, fence) && strings.TrimSpace(line) == marker:
+				fence = ""
+			}
+			inCode[i] = true
+			continue
+		}
+		inCode[i] = fence != ""
+	}
+	return inCode
+}
+
+// scanMarkdownHeadings находит заголовки вне блоков кода, назначает им
+// уникальные якоря и вычисляет последнюю строку каждого раздела
+func scanMarkdownHeadings(lines []string) []markdownHeading {
+	var headings []markdownHeading
+	inCode := markdownCodeLines(lines)
+	used := make(map[string]int)
+
+	for i, raw := range lines {
+		if inCode[i] {
+			continue
+		}
+
+		match := markdownHeadingPattern.FindStringSubmatch(strings.TrimSuffix(raw, "\r"))
+		if match == nil {
+			continue
+		}
+
+		title := strings.TrimSpace(match[2])
+		anchor := MarkdownAnchor(title)
+		if count, ok := used[anchor]; ok {
+			used[anchor] = count + 1
+			anchor += "-" + strconv.Itoa(count+1)
+		} else {
+			used[anchor] = 0
+		}
+		headings = append(headings, markdownHeading{level: len(match[1]), title: title, anchor: anchor, line: i})
+	}
+
+	// Завершающий перевод строки не относится к последнему разделу
+	last := len(lines) - 1
+	if last > 0 && lines[last] == "" {
+		last--
+	}
+	for i := range headings {
+		headings[i].end = last
+		for _, next := range headings[i+1:] {
+			if next.level <= headings[i].level {
+				headings[i].end = next.line - 1
+				break
+			}
+		}
+	}
+	return headings
+}
+
+// markdownFence возвращает маркер ограждения блока кода (``` или ~~~) в начале строки
+func markdownFence(line string) string {
+	trimmed := strings.TrimLeft(line, " ")
+	if len(line)-len(trimmed) > 3 || len(trimmed) < 3 {
+		return ""
+	}
+	char := trimmed[0]
+	if char != '`' && char != '~' {
+		return ""
+	}
+	n := 0
+	for n < len(trimmed) && trimmed[n] == char {
+		n++
+	}
+	if n < 3 {
+		return ""
+	}
+	return trimmed[:n]
+}
+
+// buildMarkdownSections собирает разделы уровня глубже parentLevel начиная с headings[*index]
+func buildMarkdownSections(headings []markdownHeading, index *int, parentLevel int) []MarkdownSection {
+	sections := []MarkdownSection{}
+	for *index < len(headings) && headings[*index].level > parentLevel {
+		heading := headings[*index]
+		*index++
+		section := newMarkdownSection(heading)
+		section.Children = buildMarkdownSections(headings, index, heading.level)
+		sections = append(sections, section)
+	}
+	return sections
+}
+
+func newMarkdownSection(heading markdownHeading) MarkdownSection {
+	return MarkdownSection{
+		Anchor:    heading.anchor,
+		Title:     heading.title,
+		Level:     heading.level,
+		StartLine: heading.line + 1,
+		EndLine:   heading.end + 1,
+	}
+}
@@ -0,0 +1,326 @@
+package utils
+
+import (
+	"html"
+	"regexp"
+	"strings"
+)
+
+var (
+	markdownRulePattern      = regexp.MustCompile(`^ {0,3}(?:(?:-[ \t]*){3,}|(?:\*[ \t]*){3,}|(?:_[ \t]*){3,})$`)
+	markdownBulletPattern    = regexp.MustCompile(`^ {0,3}[-*+][ \t]+(.*)$`)
+	markdownOrderedPattern   = regexp.MustCompile(`^ {0,3}\d{1,9}[.)][ \t]+(.*)$`)
+	markdownQuotePattern     = regexp.MustCompile(`^ {0,3}> ?(.*)$`)
+	markdownFenceLangPattern = regexp.MustCompile(`^[A-Za-z0-9_+-]+$`)
+)
+
+// RenderMarkdownHTML преобразует Markdown в HTML. Поддерживаются заголовки
+// (с id, совпадающими с якорями разделов), абзацы, списки, цитаты, блоки
+// кода, разделители и основная строчная разметка. Вывод безопасен для
+// встраивания: весь текст экранируется, сырой HTML не пропускается, а
+// ссылки допускаются только со схемами http, https и mailto или относительные.
+func RenderMarkdownHTML(content string) string {
+	lines := strings.Split(strings.ReplaceAll(content, "\r\n", "\n"), "\n")
+
+	anchors := make(map[int]string)
+	for _, heading := range scanMarkdownHeadings(lines) {
+		anchors[heading.line] = heading.anchor
+	}
+
+	var out strings.Builder
+	renderMarkdownBlocks(&out, lines, anchors)
+	return out.String()
+}
+
+// renderMarkdownBlocks выводит блочные элементы; anchors задает id заголовков
+// по номерам строк и пуст для вложенных блоков
+func renderMarkdownBlocks(out *strings.Builder, lines []string, anchors map[int]string) {
+	var paragraph []string
+	flush := func() {
+		if len(paragraph) > 0 {
+			out.WriteString("<p>" + renderMarkdownInline(strings.Join(paragraph, "\n")) + "</p>\n")
+			paragraph = nil
+		}
+	}
+
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+
+		if fence := markdownFence(line); fence != "" {
+			flush()
+			lang := strings.TrimSpace(strings.TrimLeft(line, " ")[len(fence):])
+			var code []string
+			for i++; i < len(lines); i++ {
+				if marker := markdownFence(lines[i]); strings.HasPrefix(marker, fence) && strings.TrimSpace(lines[i]) == marker {
+					break
+				}
+				code = append(code, lines[i])
+			}
+			out.WriteString("<pre><code")
+			if markdownFenceLangPattern.MatchString(lang) {
+				out.WriteString(` class="language-` + lang + `"`)
+			}
+			out.WriteString(">" + html.EscapeString(strings.Join(code, "\n")) + "</code></pre>\n")
+			continue
+		}
+
+		if strings.TrimSpace(line) == "" {
+			flush()
+			continue
+		}
+
+		if match := markdownHeadingPattern.FindStringSubmatch(line); match != nil {
+			flush()
+			tag := "h" + string(rune('0'+len(match[1])))
+			out.WriteString("<" + tag)
+			if anchor, ok := anchors[i]; ok {
+				out.WriteString(` id="` + html.EscapeString(anchor) + `"`)
+			}
+			out.WriteString(">" + renderMarkdownInline(strings.TrimSpace(match[2])) + "</" + tag + ">\n")
+			continue
+		}
+
+		if markdownRulePattern.MatchString(line) {
+			flush()
+			out.WriteString("<hr>\n")
+			continue
+		}
+
+		if markdownQuotePattern.MatchString(line) {
+			flush()
+			var quoted []string
+			for ; i < len(lines); i++ {
+				match := markdownQuotePattern.FindStringSubmatch(lines[i])
+				if match == nil {
+					break
+				}
+				quoted = append(quoted, match[1])
+			}
+			i--
+			out.WriteString("<blockquote>\n")
+			renderMarkdownBlocks(out, quoted, nil)
+			out.WriteString("</blockquote>\n")
+			continue
+		}
+
+		if pattern, tag := markdownListPattern(line); pattern != nil {
+			flush()
+			out.WriteString("<" + tag + ">\n")
+			var item []string
+			writeItem := func() {
+				if item != nil {
+					out.WriteString("<li>" + renderMarkdownInline(strings.Join(item, "\n")) + "</li>\n")
+				}
+			}
+			for ; i < len(lines); i++ {
+				if match := pattern.FindStringSubmatch(lines[i]); match != nil {
+					writeItem()
+					item = []string{match[1]}
+					continue
+				}
+				// Строки с отступом продолжают текущий пункт
+				if strings.TrimSpace(lines[i]) != "" && strings.HasPrefix(lines[i], "  ") {
+					item = append(item, strings.TrimSpace(lines[i]))
+					continue
+				}
+				break
+			}
+			i--
+			writeItem()
+			out.WriteString("</" + tag + ">\n")
+			continue
+		}
+
+		paragraph = append(paragraph, strings.TrimSpace(line))
+	}
+	flush()
+}
+
+// markdownListPattern определяет, начинает ли строка пункт списка
+func markdownListPattern(line string) (*regexp.Regexp, string) {
+	switch {
+	case markdownBulletPattern.MatchString(line):
+		return markdownBulletPattern, "ul"
+	case markdownOrderedPattern.MatchString(line):
+		return markdownOrderedPattern, "ol"
+	default:
+		return nil, ""
+	}
+}
+
+// renderMarkdownInline выводит строчную разметку: код, ссылки, изображения,
+// полужирный и курсив. Все остальное экранируется.
+func renderMarkdownInline(text string) string {
+	var out strings.Builder
+
+	for i := 0; i < len(text); {
+		c := text[i]
+
+		switch {
+		case c == '\\' && i+1 < len(text) && strings.IndexByte("\\`*_[]()#+-.!>", text[i+1]) >= 0:
+			out.WriteString(html.EscapeString(text[i+1 : i+2]))
+			i += 2
+			continue
+
+		case c == '`':
+			n := countRun(text, i, '`')
+			marker := text[i : i+n]
+			if end := strings.Index(text[i+n:], marker); end >= 0 {
+				code := text[i+n : i+n+end]
+				if len(code) > 1 && code[0] == ' ' && code[len(code)-1] == ' ' {
+					code = code[1 : len(code)-1]
+				}
+				out.WriteString("<code>" + html.EscapeString(code) + "</code>")
+				i += n + end + n
+				continue
+			}
+			out.WriteString(marker)
+			i += n
+			continue
+
+		case c == '!' && i+1 < len(text) && text[i+1] == '[':
+			if label, target, width, ok := parseMarkdownLink(text[i+1:]); ok {
+				if isSafeMarkdownURL(target) {
+					out.WriteString(`<img src="` + html.EscapeString(target) + `" alt="` + html.EscapeString(label) + `">`)
+				} else {
+					out.WriteString(html.EscapeString(label))
+				}
+				i += 1 + width
+				continue
+			}
+
+		case c == '[':
+			if label, target, width, ok := parseMarkdownLink(text[i:]); ok {
+				if isSafeMarkdownURL(target) {
+					out.WriteString(`<a href="` + html.EscapeString(target) + `" rel="nofollow noopener">` + renderMarkdownInline(label) + `</a>`)
+				} else {
+					out.WriteString(renderMarkdownInline(label))
+				}
+				i += width
+				continue
+			}
+
+		case c == '*' || c == '_':
+			n := countRun(text, i, c)
+			if n > 2 {
+				n = 2
+			}
+			marker := text[i : i+n]
+			// Подчеркивания внутри слов (snake_case) разметкой не считаются
+			if c != '_' || i == 0 || !isWordByte(text[i-1]) {
+				if end := findEmphasisEnd(text, i+n, marker); end >= 0 {
+					tag := "em"
+					if n == 2 {
+						tag = "strong"
+					}
+					out.WriteString("<" + tag + ">" + renderMarkdownInline(text[i+n:end]) + "</" + tag + ">")
+					i = end + n
+					continue
+				}
+			}
+			out.WriteString(marker)
+			i += n
+			continue
+		}
+
+		out.WriteString(html.EscapeString(text[i : i+1]))
+		i++
+	}
+	return out.String()
+}
+
+// parseMarkdownLink разбирает "[текст](адрес)" в начале text и возвращает
+// длину разобранного фрагмента
+func parseMarkdownLink(text string) (label, target string, width int, ok bool) {
+	depth := 0
+	closing := -1
+	for i := 0; i < len(text) && closing < 0; i++ {
+		switch text[i] {
+		case '[':
+			depth++
+		case ']':
+			depth--
+			if depth == 0 {
+				closing = i
+			}
+		}
+	}
+	if closing < 0 || closing+1 >= len(text) || text[closing+1] != '(' {
+		return "", "", 0, false
+	}
+	// Адрес может содержать парные скобки
+	end := -1
+	depth = 0
+	for i, c := range []byte(text[closing+2:]) {
+		if c == '(' {
+			depth++
+		} else if c == ')' {
+			if depth == 0 {
+				end = i
+				break
+			}
+			depth--
+		}
+	}
+	if end < 0 {
+		return "", "", 0, false
+	}
+
+	target = strings.TrimSpace(text[closing+2 : closing+2+end])
+	// Необязательный заголовок ссылки после адреса не выводится
+	if space := strings.IndexAny(target, " \t"); space >= 0 {
+		target = target[:space]
+	}
+	target = strings.TrimSuffix(strings.TrimPrefix(target, "<"), ">")
+	return text[1:closing], target, closing + 2 + end + 1, true
+}
+
+// isSafeMarkdownURL допускает относительные адреса и схемы http, https и mailto
+func isSafeMarkdownURL(target string) bool {
+	colon := strings.IndexByte(target, ':')
+	if colon < 0 || strings.ContainsAny(target[:colon], "/?#") {
+		return true
+	}
+	switch strings.ToLower(target[:colon]) {
+	case "http", "https", "mailto":
+		return true
+	default:
+		return false
+	}
+}
+
+// findEmphasisEnd ищет закрывающий маркер выделения, не начинающийся с пробела
+func findEmphasisEnd(text string, start int, marker string) int {
+	if start >= len(text) || text[start] == ' ' {
+		return -1
+	}
+	for i := start + 1; i+len(marker) <= len(text); i++ {
+		if text[i:i+len(marker)] != marker || text[i-1] == ' ' {
+			continue
+		}
+		if marker[0] == '_' && i+len(marker) < len(text) && isWordByte(text[i+len(marker)]) {
+			continue
+		}
+		if len(marker) == 1 && i+1 < len(text) && text[i+1] == marker[0] {
+			// Одиночный маркер не закрывается началом двойного
+			i++
+			continue
+		}
+		return i
+	}
+	return -1
+}
+
+func countRun(text string, start int, c byte) int {
+	n := 0
+	for start+n < len(text) && text[start+n] == c {
+		n++
+	}
+	return n
+}
+
+// isWordByte сообщает, является ли байт частью слова; байты UTF-8 старше
+// ASCII считаются буквами
+func isWordByte(c byte) bool {
+	return c >= 0x80 || c == '_' || ('0' <= c && c <= '9') || ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z')
+}
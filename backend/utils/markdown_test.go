@@ -0,0 +1,92 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const sampleMarkdown = `# SAD
+
+Введение.
+
+## Архитектура
+
+Общее описание.
+
+### Компоненты
+
+` + "```bash\n# не заголовок\n```" + `
+
+## Архитектура
+
+Повтор заголовка.
+`
+
+func TestMarkdownSections(t *testing.T) {
+	sections := MarkdownSections(sampleMarkdown)
+
+	require.Len(t, sections, 1)
+	root := sections[0]
+	assert.Equal(t, "sad", root.Anchor)
+	assert.Equal(t, 1, root.StartLine)
+	assert.Equal(t, 17, root.EndLine)
+
+	require.Len(t, root.Children, 2)
+	assert.Equal(t, "архитектура", root.Children[0].Anchor)
+	assert.Equal(t, 14, root.Children[0].EndLine)
+	assert.Equal(t, "архитектура-1", root.Children[1].Anchor)
+
+	require.Len(t, root.Children[0].Children, 1)
+	assert.Equal(t, "компоненты", root.Children[0].Children[0].Anchor)
+	assert.Equal(t, 3, root.Children[0].Children[0].Level)
+	assert.Empty(t, root.Children[0].Children[0].Children)
+}
+
+func TestFindMarkdownSection(t *testing.T) {
+	section := FindMarkdownSection(sampleMarkdown, "архитектура-1")
+	require.NotNil(t, section)
+	assert.Equal(t, "Архитектура", section.Title)
+	assert.Equal(t, "\nПовтор заголовка.", section.Content)
+
+	assert.Nil(t, FindMarkdownSection(sampleMarkdown, "missing"))
+}
+
+func TestReplaceMarkdownSection(t *testing.T) {
+	content := "# A\n\ntext\n\n## B\n\nold\n\n## C\n\nkeep\n"
+
+	updated, err := ReplaceMarkdownSection(content, "b", "\nnew\n\n### B1\n\ndetails\n")
+	require.NoError(t, err)
+	assert.Equal(t, "# A\n\ntext\n\n## B\n\nnew\n\n### B1\n\ndetails\n## C\n\nkeep\n", updated)
+
+	_, err = ReplaceMarkdownSection(content, "b", "## Другой раздел")
+	assert.Error(t, err)
+
+	_, err = ReplaceMarkdownSection(content, "missing", "x")
+	assert.ErrorIs(t, err, ErrSectionNotFound)
+}
+
+func TestMarkdownAnchor(t *testing.T) {
+	assert.Equal(t, "api-v1-обзор", MarkdownAnchor("API v1: обзор"))
+	assert.Equal(t, "section", MarkdownAnchor("!!!"))
+}
+
+func TestRenderMarkdownHTML(t *testing.T) {
+	content := "# Заголовок\n\n" +
+		"Текст с **жирным**, *курсивом*, `code <b>` и task_id.\n\n" +
+		"- [ссылка](https://example.com)\n" +
+		"- [плохая](javascript:alert(1))\n\n" +
+		"<script>alert(1)</script>\n\n" +
+		"```go\nfmt.Println(\"<x>\")\n```\n"
+
+	expected := `<h1 id="заголовок">Заголовок</h1>` + "\n" +
+		"<p>Текст с <strong>жирным</strong>, <em>курсивом</em>, <code>code &lt;b&gt;</code> и task_id.</p>\n" +
+		"<ul>\n" +
+		`<li><a href="https://example.com" rel="nofollow noopener">ссылка</a></li>` + "\n" +
+		"<li>плохая</li>\n" +
+		"</ul>\n" +
+		"<p>&lt;script&gt;alert(1)&lt;/script&gt;</p>\n" +
+		`<pre><code class="language-go">fmt.Println(&#34;&lt;x&gt;&#34;)</code></pre>` + "\n"
+	assert.Equal(t, expected, RenderMarkdownHTML(content))
+}
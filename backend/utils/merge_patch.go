@@ -0,0 +1,64 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// MergePatch применяет JSON Merge Patch (RFC 7396) к документу original:
+// поля патча заменяют поля документа, null удаляет поле, вложенные объекты
+// объединяются рекурсивно, а любое другое значение (включая массивы)
+// заменяется целиком.
+func MergePatch(original, patch []byte) ([]byte, error) {
+	patchValue, err := decodeJSONValue(patch)
+	if err != nil {
+		return nil, fmt.Errorf("invalid merge patch: %w", err)
+	}
+
+	var originalValue interface{}
+	if len(bytes.TrimSpace(original)) > 0 {
+		originalValue, err = decodeJSONValue(original)
+		if err != nil {
+			return nil, fmt.Errorf("invalid original document: %w", err)
+		}
+	}
+
+	return json.Marshal(mergePatchValue(originalValue, patchValue))
+}
+
+// decodeJSONValue разбирает JSON, сохраняя числа без потери точности
+func decodeJSONValue(data []byte) (interface{}, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+	if decoder.More() {
+		return nil, fmt.Errorf("unexpected data after JSON value")
+	}
+	return value, nil
+}
+
+func mergePatchValue(target, patch interface{}) interface{} {
+	patchObject, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+
+	targetObject, ok := target.(map[string]interface{})
+	if !ok {
+		targetObject = map[string]interface{}{}
+	}
+
+	for key, value := range patchObject {
+		if value == nil {
+			delete(targetObject, key)
+			continue
+		}
+		targetObject[key] = mergePatchValue(targetObject[key], value)
+	}
+	return targetObject
+}
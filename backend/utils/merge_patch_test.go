@@ -0,0 +1,53 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Примеры из приложения A RFC 7396
+func TestMergePatch(t *testing.T) {
+	cases := []struct {
+		original string
+		patch    string
+		expected string
+	}{
+		{`{"a":"b"}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"b"}`, `{"b":"c"}`, `{"a":"b","b":"c"}`},
+		{`{"a":"b"}`, `{"a":null}`, `{}`},
+		{`{"a":"b","b":"c"}`, `{"a":null}`, `{"b":"c"}`},
+		{`{"a":["b"]}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"c"}`, `{"a":["b"]}`, `{"a":["b"]}`},
+		{`{"a":{"b":"c"}}`, `{"a":{"b":"d","c":null}}`, `{"a":{"b":"d"}}`},
+		{`{"a":[{"b":"c"}]}`, `{"a":[1]}`, `{"a":[1]}`},
+		{`["a","b"]`, `["c","d"]`, `["c","d"]`},
+		{`{"a":"b"}`, `["c"]`, `["c"]`},
+		{`{"a":"foo"}`, `null`, `null`},
+		{`{"a":"foo"}`, `"bar"`, `"bar"`},
+		{`{"e":null}`, `{"a":1}`, `{"a":1,"e":null}`},
+		{`[1,2]`, `{"a":"b","c":null}`, `{"a":"b"}`},
+		{`{}`, `{"a":{"bb":{"ccc":null}}}`, `{"a":{"bb":{}}}`},
+	}
+
+	for _, c := range cases {
+		result, err := MergePatch([]byte(c.original), []byte(c.patch))
+		require.NoError(t, err, "patch %s", c.patch)
+		assert.JSONEq(t, c.expected, string(result), "original %s, patch %s", c.original, c.patch)
+	}
+}
+
+func TestMergePatchPreservesLargeNumbers(t *testing.T) {
+	result, err := MergePatch([]byte(`{"id":9007199254740993}`), []byte(`{"name":"x"}`))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"id":9007199254740993,"name":"x"}`, string(result))
+}
+
+func TestMergePatchInvalidJSON(t *testing.T) {
+	_, err := MergePatch([]byte(`{}`), []byte(`{"a":`))
+	assert.Error(t, err)
+
+	_, err = MergePatch([]byte(`{}`), []byte(`{} {}`))
+	assert.Error(t, err)
+}